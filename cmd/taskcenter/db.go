@@ -0,0 +1,60 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+// dbFlags 数据库连接参数，未指定 -dsn 时按 migrate.sh 相同的环境变量拼接
+type dbFlags struct {
+	dsn string
+}
+
+// register 注册数据库连接参数
+func (f *dbFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.dsn, "dsn", os.Getenv("TASKCENTER_DSN"),
+		"数据库连接串，如 user:pass@tcp(host:3306)/task_center，默认读取 TASKCENTER_DSN 或 DB_* 环境变量")
+}
+
+// open 打开数据库连接
+func (f *dbFlags) open() (*sql.DB, error) {
+	dsn := f.dsn
+	if dsn == "" {
+		dsn = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s",
+			envOr("DB_USER", "root"), envOr("DB_PASSWORD", "root123"),
+			envOr("DB_HOST", "localhost"), envOr("DB_PORT", "3306"), envOr("DB_NAME", "task_center"))
+	}
+	// 兼容 golang-migrate CLI 的 mysql:// 前缀
+	dsn = strings.TrimPrefix(dsn, "mysql://")
+
+	cfg, err := mysqldriver.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dsn: %w", err)
+	}
+	// 迁移文件包含多条语句
+	cfg.MultiStatements = true
+	cfg.ParseTime = true
+
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not connect to database: %w", err)
+	}
+	return db, nil
+}
+
+// envOr 读取环境变量，为空时返回默认值
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
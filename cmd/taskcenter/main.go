@@ -0,0 +1,50 @@
+// taskcenter 运维命令行工具
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command 子命令，返回进程退出码
+type command struct {
+	usage string
+	run   func(args []string) int
+}
+
+// commands 所有子命令
+var commands = map[string]command{
+	"migrate": {usage: "管理数据库迁移", run: runMigrate},
+	"schema":  {usage: "检查数据库表结构是否与期望结构一致", run: runSchema},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", os.Args[1])
+		printUsage()
+		os.Exit(2)
+	}
+	os.Exit(cmd.run(os.Args[2:]))
+}
+
+// printUsage 打印子命令列表
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "用法: taskcenter <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\n命令:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+
+	"task-center/database"
+	"task-center/model"
+)
+
+const migrateUsage = `用法: taskcenter migrate [flags] <subcommand>
+
+子命令:
+  up          执行所有未执行的迁移
+  down N      回滚 N 个迁移
+  goto V      迁移到版本 V（自动判断升级或回滚）
+  version     显示当前版本
+  force V     强制设置版本并清除 dirty 标记，迁移中断时使用
+  verify      校验已执行的迁移文件是否被修改
+
+参数:
+`
+
+// runMigrate 执行 migrate 子命令
+func runMigrate(args []string) int {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	var dbf dbFlags
+	dbf.register(fs)
+	path := fs.String("path", "", "迁移文件目录，默认使用编译进二进制的迁移文件")
+	lockWait := fs.Duration("lock-wait", 0, "等待迁移锁的最长时间，默认 10m")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), migrateUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		fs.Usage()
+		return 2
+	}
+	sub, rest := rest[0], rest[1:]
+
+	// 先校验参数再连接数据库
+	var n int
+	switch sub {
+	case "up", "version", "verify":
+		if len(rest) != 0 {
+			fs.Usage()
+			return 2
+		}
+	case "down", "goto", "force":
+		if len(rest) != 1 {
+			fs.Usage()
+			return 2
+		}
+		v, err := strconv.Atoi(rest[0])
+		if err != nil || (sub == "down" && v <= 0) || (sub == "goto" && v < 0) || (sub == "force" && v < -1) {
+			fmt.Fprintf(os.Stderr, "invalid argument for %s: %s\n", sub, rest[0])
+			return 2
+		}
+		n = v
+	default:
+		fmt.Fprintf(os.Stderr, "unknown subcommand: %s\n", sub)
+		fs.Usage()
+		return 2
+	}
+
+	db, err := dbf.open()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer db.Close()
+
+	config := database.DefaultMigrationConfig()
+	config.MigrationsPath = *path
+	if *lockWait > 0 {
+		config.LockWaitTimeout = *lockWait
+	}
+	manager := database.NewMigrationManager(db, config)
+	// 通过 migration_locks 与自动迁移的服务实例互斥，并在 migrations 表记录每个迁移文件
+	conn := sqlx.NewSqlConnFromDB(db)
+	manager.SetLocker(model.NewMigrationLockStore(conn))
+	manager.SetHistory(model.NewMigrationHistoryStore(conn))
+
+	if sub == "verify" {
+		return verifyMigrations(manager)
+	}
+
+	switch sub {
+	case "up":
+		err = manager.RunMigrations()
+	case "down":
+		err = manager.RollbackSteps(n)
+	case "goto":
+		err = manager.MigrateTo(uint(n))
+	case "force":
+		err = manager.Force(n)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return printVersion(manager)
+}
+
+// verifyMigrations 打印被修改的迁移文件，存在不一致时退出码为 1
+func verifyMigrations(manager *database.MigrationManager) int {
+	mismatches, err := manager.Verify()
+	for _, mismatch := range mismatches {
+		fmt.Printf("%s %s: %s\n", mismatch.Version, mismatch.Filename, mismatch.Reason)
+		fmt.Printf("    recorded: %s\n", mismatch.Expected)
+		if mismatch.Actual != "" {
+			fmt.Printf("    current:  %s\n", mismatch.Actual)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Println("all applied migrations match their files")
+	return 0
+}
+
+// printVersion 打印当前迁移版本
+func printVersion(manager *database.MigrationManager) int {
+	version, dirty, err := manager.GetVersion()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Println("version: none")
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Printf("version: %d", version)
+	if dirty {
+		fmt.Print(" (dirty)")
+	}
+	fmt.Println()
+	if dirty {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"task-center/database"
+)
+
+// schema 命令的退出码，CI 可以区分表结构漂移和检查失败
+const (
+	schemaExitMatch   = 0
+	schemaExitDrifted = 1
+	schemaExitUsage   = 2
+	schemaExitError   = 3
+)
+
+const schemaUsage = `用法: taskcenter schema [flags]
+
+对比数据库中的表结构与 database/core_tables_no_fk.sql，检查列、类型、默认值和索引是否一致。
+
+退出码:
+  0  表结构一致
+  1  存在差异
+  2  参数错误
+  3  检查失败，如无法连接数据库
+
+参数:
+`
+
+// runSchema 执行 schema 子命令
+func runSchema(args []string) int {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	var dbf dbFlags
+	dbf.register(fs)
+	asJSON := fs.Bool("json", false, "以 JSON 格式输出检查结果")
+	tables := fs.String("tables", strings.Join(database.SchemaCheckedTables, ","), "检查的表，逗号分隔")
+	timeout := fs.Duration("timeout", 30*time.Second, "检查超时时间")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), schemaUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		if err == nil {
+			fs.Usage()
+		}
+		return schemaExitUsage
+	}
+
+	var names []string
+	for _, name := range strings.Split(*tables, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	db, err := dbf.open()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return schemaExitError
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, err := database.CheckSchema(ctx, db, names...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return schemaExitError
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return schemaExitError
+		}
+	} else {
+		report.WriteText(os.Stdout)
+	}
+
+	if report.Drifted {
+		return schemaExitDrifted
+	}
+	return schemaExitMatch
+}
@@ -47,6 +47,8 @@ CREATE TABLE `tasks` (
   `completed_at` timestamp NULL DEFAULT NULL COMMENT '完成时间',
   `error_message` text COMMENT '最新的错误信息',
   `metadata` text COMMENT '扩展元数据，JSON格式存储',
+  `version` bigint(20) NOT NULL DEFAULT '1' COMMENT '版本号，每次修改加一，用于乐观锁',
+  `trace_parent` varchar(64) DEFAULT NULL COMMENT '创建任务时的 W3C traceparent，用于串联创建、调度和回调的链路',
   `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   PRIMARY KEY (`id`),
@@ -56,7 +58,9 @@ CREATE TABLE `tasks` (
   KEY `idx_next_execute_at` (`next_execute_at`),
   KEY `idx_scheduled_at` (`scheduled_at`),
   KEY `idx_business_id_status` (`business_id`, `status`),
-  KEY `idx_created_at` (`created_at`)
+  KEY `idx_created_at` (`created_at`),
+  KEY `idx_business_created_id` (`business_id`, `created_at`, `id`),
+  KEY `idx_business_next_execute_id` (`business_id`, `next_execute_at`, `id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
 COMMENT='任务表，存储所有待执行的任务信息';
 
@@ -100,6 +104,102 @@ CREATE TABLE `task_locks` (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
 COMMENT='任务锁表，用于分布式环境下的任务执行锁';
 
+-- 任务标签表
+CREATE TABLE `task_tags` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT COMMENT '主键ID，自增',
+  `task_id` bigint(20) NOT NULL COMMENT '任务ID，关联 tasks.id',
+  `business_id` bigint(20) NOT NULL COMMENT '业务系统ID，冗余存储便于按业务统计',
+  `tag` varchar(32) NOT NULL COMMENT '标签',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_task_tag` (`task_id`, `tag`),
+  KEY `idx_business_tag_task` (`business_id`, `tag`, `task_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+COMMENT='任务标签表，tasks.tags 的规范化索引，用于按标签过滤和统计';
+
+-- 任务事件表
+CREATE TABLE `task_events` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT COMMENT '主键ID，自增',
+  `task_id` bigint(20) NOT NULL COMMENT '任务ID，关联 tasks.id',
+  `business_id` bigint(20) NOT NULL COMMENT '业务系统ID，冗余存储便于按业务查询',
+  `from_status` tinyint(4) NOT NULL COMMENT '变更前的任务状态',
+  `to_status` tinyint(4) NOT NULL COMMENT '变更后的任务状态',
+  `actor` varchar(64) NOT NULL COMMENT '操作者，如 api、executor、bulk 或业务系统传入的用户',
+  `reason` varchar(512) DEFAULT NULL COMMENT '变更原因',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '变更时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_task_id_id` (`task_id`, `id`),
+  KEY `idx_business_created` (`business_id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+COMMENT='任务事件表，记录每次任务状态变更';
+
+-- 事件订阅表
+CREATE TABLE `event_subscriptions` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT COMMENT '主键ID，自增',
+  `business_id` bigint(20) NOT NULL COMMENT '业务系统ID，关联 business_systems.id',
+  `url` varchar(512) NOT NULL COMMENT '事件通知地址，与任务自身的 callback_url 无关',
+  `event_types` text NOT NULL COMMENT '订阅的事件类型，JSON数组，如：["task.failed","task.retrying"]',
+  `secret` varchar(128) NOT NULL COMMENT '通知签名密钥',
+  `status` tinyint(4) NOT NULL DEFAULT 1 COMMENT '订阅状态：0-停用，1-启用',
+  `description` varchar(255) DEFAULT NULL COMMENT '订阅描述',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_business_status` (`business_id`, `status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+COMMENT='事件订阅表，按业务系统配置需要推送的任务生命周期事件';
+
+-- 幂等键表
+CREATE TABLE `idempotency_keys` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT COMMENT '主键ID，自增',
+  `business_id` bigint(20) NOT NULL COMMENT '业务系统ID，关联 business_systems.id',
+  `idempotency_key` varchar(128) NOT NULL COMMENT '客户端传入的 Idempotency-Key',
+  `request_hash` char(64) NOT NULL COMMENT '请求方法、路径和请求体的SHA256，用于识别同一幂等键的不同请求',
+  `status` tinyint(4) NOT NULL DEFAULT 0 COMMENT '状态：0-处理中，1-已完成',
+  `status_code` int(11) NOT NULL DEFAULT 0 COMMENT '原始响应的HTTP状态码',
+  `content_type` varchar(128) NOT NULL DEFAULT '' COMMENT '原始响应的 Content-Type',
+  `response_headers` text DEFAULT NULL COMMENT '需要重放的其他响应头，JSON 对象',
+  `response_body` mediumtext DEFAULT NULL COMMENT '原始响应体',
+  `expires_at` timestamp NOT NULL COMMENT '过期时间，过期后同一幂等键视为新请求',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_business_key` (`business_id`, `idempotency_key`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+COMMENT='幂等键表，保存写操作的原始响应，重复请求直接返回该响应';
+
+-- 批量操作任务表
+CREATE TABLE `bulk_jobs` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT COMMENT '主键ID，自增',
+  `job_id` varchar(36) NOT NULL COMMENT '批量任务ID，对外暴露',
+  `business_id` bigint(20) NOT NULL COMMENT '业务系统ID',
+  `action` varchar(16) NOT NULL COMMENT '操作类型：cancel、retry、delete、reschedule',
+  `filter` text NOT NULL COMMENT '任务过滤条件，JSON格式',
+  `params` text COMMENT '操作参数，JSON格式，如 reschedule 的改期时间',
+  `dry_run` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否只统计不执行：1-是，0-否',
+  `status` tinyint(4) NOT NULL DEFAULT 0 COMMENT '状态：0-待执行，1-执行中，2-已完成，3-失败，4-已取消',
+  `total` int(11) NOT NULL DEFAULT 0 COMMENT '匹配的任务数',
+  `processed` int(11) NOT NULL DEFAULT 0 COMMENT '已处理的任务数',
+  `succeeded` int(11) NOT NULL DEFAULT 0 COMMENT '操作成功的任务数',
+  `skipped` int(11) NOT NULL DEFAULT 0 COMMENT '状态已变化而跳过的任务数',
+  `failed` int(11) NOT NULL DEFAULT 0 COMMENT '操作失败的任务数',
+  `last_cursor` varchar(255) NOT NULL DEFAULT '' COMMENT '已处理到的游标位置，用于中断后继续',
+  `error_message` text COMMENT '失败原因',
+  `cancel_requested` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否已请求取消：1-是，0-否',
+  `locked_by` varchar(64) DEFAULT NULL COMMENT '执行该任务的节点ID',
+  `locked_until` timestamp NULL DEFAULT NULL COMMENT '执行租约到期时间',
+  `started_at` timestamp NULL DEFAULT NULL COMMENT '开始执行时间',
+  `completed_at` timestamp NULL DEFAULT NULL COMMENT '结束时间',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_job_id` (`job_id`),
+  KEY `idx_status_locked_until` (`status`, `locked_until`),
+  KEY `idx_business_created` (`business_id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci
+COMMENT='批量操作任务表，按过滤条件异步取消、重试或删除任务';
+
 -- 迁移状态跟踪表
 CREATE TABLE `migrations` (
   `id` bigint(20) NOT NULL AUTO_INCREMENT COMMENT '主键ID，自增',
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// embeddedMigrations 编译进二进制的迁移文件，部署时不再依赖源码目录
+//
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// MigrationsFS 返回内嵌的迁移文件，文件位于根目录下
+func MigrationsFS() fs.FS {
+	sub, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		// migrations 是编译期确定的目录，不会出错
+		panic(err)
+	}
+	return sub
+}
+
+// coreTablesDDL 核心表结构，作为表结构漂移检查的期望结构
+//
+//go:embed core_tables_no_fk.sql
+var coreTablesDDL string
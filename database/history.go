@@ -0,0 +1,413 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+
+	"task-center/model"
+)
+
+// ErrMigrationModified 已执行的迁移文件在执行后被修改、重命名或删除
+var ErrMigrationModified = errors.New("applied migrations have been modified")
+
+// MigrationHistory 迁移执行记录，model.MigrationsModel 实现了该接口
+type MigrationHistory interface {
+	EnsureTable(ctx context.Context) error
+	FindAll(ctx context.Context) ([]*model.Migrations, error)
+	Save(ctx context.Context, data *model.Migrations) error
+	DeleteByVersion(ctx context.Context, version string) error
+}
+
+var (
+	_ MigrationHistory = model.MigrationsModel(nil)
+	_ MigrationHistory = (*model.MigrationHistoryStore)(nil)
+)
+
+// SetHistory 设置迁移执行记录
+// 设置后迁移逐个文件执行，每个文件执行前后写入 migrations 表，记录校验和、耗时和结果
+func (m *MigrationManager) SetHistory(history MigrationHistory) {
+	m.history = history
+}
+
+// MigrationMismatch 已执行的迁移与当前迁移文件不一致的记录
+type MigrationMismatch struct {
+	Version  string // 迁移版本
+	Filename string // 执行时的文件名
+	Expected string // 执行时记录的校验和
+	Actual   string // 当前文件的校验和，文件已删除时为空
+	Reason   string // 不一致的原因
+}
+
+// migrationFile 一个版本的迁移文件
+type migrationFile struct {
+	version  uint
+	name     string // 文件名中的描述部分
+	upFile   string
+	downFile string
+}
+
+// versionString 文件名中的版本号，保留前导零，写入 migrations.version
+func (f *migrationFile) versionString() string {
+	return strings.SplitN(f.upFile, "_", 2)[0]
+}
+
+// migrationFiles 按版本升序返回迁移文件
+func (m *MigrationManager) migrationFiles() ([]*migrationFile, error) {
+	entries, err := fs.ReadDir(m.source, ".")
+	if err != nil {
+		return nil, fmt.Errorf("could not read migration files: %w", err)
+	}
+
+	byVersion := make(map[uint]*migrationFile)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		parsed, err := source.DefaultParse(entry.Name())
+		if err != nil {
+			// 与 golang-migrate 一致，忽略不符合命名规则的文件
+			continue
+		}
+		file, ok := byVersion[parsed.Version]
+		if !ok {
+			file = &migrationFile{version: parsed.Version, name: parsed.Identifier}
+			byVersion[parsed.Version] = file
+		}
+		if parsed.Direction == source.Up {
+			file.upFile = entry.Name()
+		} else {
+			file.downFile = entry.Name()
+		}
+	}
+
+	files := make([]*migrationFile, 0, len(byVersion))
+	for _, file := range byVersion {
+		if file.upFile != "" {
+			files = append(files, file)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].version < files[j].version })
+	return files, nil
+}
+
+// checksum 计算迁移文件内容的 SHA256
+func (m *MigrationManager) checksum(filename string) (string, error) {
+	content, err := fs.ReadFile(m.source, filename)
+	if err != nil {
+		return "", fmt.Errorf("could not read migration file %s: %w", filename, err)
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// record 生成迁移文件对应的记录
+func (m *MigrationManager) record(file *migrationFile, status string) (*model.Migrations, error) {
+	checksum, err := m.checksum(file.upFile)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &model.Migrations{
+		Version:  file.versionString(),
+		Name:     file.name,
+		Filename: file.upFile,
+		Checksum: checksum,
+		Status:   status,
+	}
+	if file.downFile != "" {
+		record.RollbackAvailable = 1
+	}
+	return record, nil
+}
+
+// currentVersion 当前迁移版本，没有执行过任何迁移时 applied 为 false
+func currentVersion(migrator *migrate.Migrate) (version uint, applied bool, err error) {
+	version, dirty, err := migrator.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if dirty {
+		return 0, false, migrate.ErrDirty{Version: int(version)}
+	}
+	return version, true, nil
+}
+
+// recordedUp 逐个执行未执行的迁移文件并记录
+func (m *MigrationManager) recordedUp(migrator *migrate.Migrate, lost <-chan struct{}) error {
+	files, err := m.prepareHistory(migrator)
+	if err != nil {
+		return err
+	}
+
+	current, applied, err := currentVersion(migrator)
+	if err != nil {
+		return fmt.Errorf("could not run migrations: %w", err)
+	}
+	for _, file := range files {
+		if applied && file.version <= current {
+			continue
+		}
+		if err := m.stepUp(migrator, lost, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordedMigrateTo 逐个执行或回滚迁移文件直到指定版本并记录
+func (m *MigrationManager) recordedMigrateTo(migrator *migrate.Migrate, lost <-chan struct{}, version uint) error {
+	files, err := m.prepareHistory(migrator)
+	if err != nil {
+		return err
+	}
+
+	target := -1
+	for i, file := range files {
+		if file.version == version {
+			target = i
+		}
+	}
+	if target < 0 {
+		return fmt.Errorf("could not migrate to version %d: %w", version, fs.ErrNotExist)
+	}
+
+	current, applied, err := currentVersion(migrator)
+	if err != nil {
+		return fmt.Errorf("could not migrate to version %d: %w", version, err)
+	}
+
+	if !applied || current < version {
+		for _, file := range files[:target+1] {
+			if applied && file.version <= current {
+				continue
+			}
+			if err := m.stepUp(migrator, lost, file); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i := len(files) - 1; i > target; i-- {
+		if files[i].version > current {
+			continue
+		}
+		if err := m.stepDown(migrator, lost, files[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordedRollback 逐个回滚迁移文件并删除记录
+func (m *MigrationManager) recordedRollback(migrator *migrate.Migrate, lost <-chan struct{}, steps int) error {
+	files, err := m.prepareHistory(migrator)
+	if err != nil {
+		return err
+	}
+
+	for i := len(files) - 1; i >= 0 && steps > 0; i-- {
+		current, applied, err := currentVersion(migrator)
+		if err != nil {
+			return fmt.Errorf("could not rollback %d steps: %w", steps, err)
+		}
+		if !applied {
+			return nil
+		}
+		if files[i].version > current {
+			continue
+		}
+		if err := m.stepDown(migrator, lost, files[i]); err != nil {
+			return err
+		}
+		steps--
+	}
+	return nil
+}
+
+// prepareHistory 创建记录表，并为启用记录之前已执行的迁移补录当前文件的校验和
+func (m *MigrationManager) prepareHistory(migrator *migrate.Migrate) ([]*migrationFile, error) {
+	ctx := context.Background()
+	if err := m.history.EnsureTable(ctx); err != nil {
+		return nil, fmt.Errorf("could not create migrations table: %w", err)
+	}
+
+	files, err := m.migrationFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	current, applied, err := currentVersion(migrator)
+	if err != nil || !applied {
+		// dirty 状态由后续迁移返回错误
+		return files, nil
+	}
+
+	records, err := m.history.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not load migration history: %w", err)
+	}
+	recorded := make(map[string]bool, len(records))
+	for _, record := range records {
+		recorded[record.Version] = true
+	}
+
+	for _, file := range files {
+		if file.version > current || recorded[file.versionString()] {
+			continue
+		}
+		// 补录的记录没有执行耗时，applied_at 为补录时间
+		record, err := m.record(file, model.MigrationStatusSuccess)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.history.Save(ctx, record); err != nil {
+			return nil, fmt.Errorf("could not record migration %s: %w", file.upFile, err)
+		}
+	}
+	return files, nil
+}
+
+// stepUp 执行一个迁移文件，执行前写入 RUNNING 记录，执行后更新结果和耗时
+func (m *MigrationManager) stepUp(migrator *migrate.Migrate, lost <-chan struct{}, file *migrationFile) error {
+	select {
+	case <-lost:
+		return ErrMigrationLockLost
+	default:
+	}
+
+	ctx := context.Background()
+	record, err := m.record(file, model.MigrationStatusRunning)
+	if err != nil {
+		return err
+	}
+	if err := m.history.Save(ctx, record); err != nil {
+		return fmt.Errorf("could not record migration %s: %w", file.upFile, err)
+	}
+
+	start := time.Now()
+	err = migrator.Steps(1)
+	record.ExecutionTime = sql.NullInt64{Int64: time.Since(start).Milliseconds(), Valid: true}
+	record.Status = model.MigrationStatusSuccess
+	if err != nil {
+		record.Status = model.MigrationStatusFailed
+		record.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
+	}
+
+	if saveErr := m.history.Save(ctx, record); saveErr != nil && err == nil {
+		return fmt.Errorf("could not record migration %s: %w", file.upFile, saveErr)
+	}
+	if err != nil {
+		return fmt.Errorf("could not apply migration %s: %w", file.upFile, err)
+	}
+	return nil
+}
+
+// stepDown 回滚一个迁移文件，成功后删除记录，失败时将记录标记为 FAILED
+func (m *MigrationManager) stepDown(migrator *migrate.Migrate, lost <-chan struct{}, file *migrationFile) error {
+	select {
+	case <-lost:
+		return ErrMigrationLockLost
+	default:
+	}
+
+	ctx := context.Background()
+	if err := migrator.Steps(-1); err != nil {
+		if record, recordErr := m.record(file, model.MigrationStatusFailed); recordErr == nil {
+			record.ErrorMessage = sql.NullString{String: "rollback failed: " + err.Error(), Valid: true}
+			_ = m.history.Save(ctx, record)
+		}
+		return fmt.Errorf("could not rollback migration %s: %w", file.upFile, err)
+	}
+
+	if err := m.history.DeleteByVersion(ctx, file.versionString()); err != nil {
+		return fmt.Errorf("could not delete migration record %s: %w", file.upFile, err)
+	}
+	return nil
+}
+
+// Verify 校验已执行的迁移文件是否被修改
+// 对比 migrations 表中记录的校验和与当前迁移文件，存在不一致时返回所有不一致的记录和 ErrMigrationModified
+func (m *MigrationManager) Verify() ([]MigrationMismatch, error) {
+	if m.history == nil {
+		return nil, errors.New("migration history is not configured")
+	}
+
+	ctx := context.Background()
+	if err := m.history.EnsureTable(ctx); err != nil {
+		return nil, fmt.Errorf("could not create migrations table: %w", err)
+	}
+
+	version, _, err := m.GetVersion()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := m.migrationFiles()
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[string]*migrationFile, len(files))
+	for _, file := range files {
+		byVersion[file.versionString()] = file
+	}
+
+	records, err := m.history.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not load migration history: %w", err)
+	}
+
+	var mismatches []MigrationMismatch
+	for _, record := range records {
+		// 失败的记录以及回滚或 force 之后残留的记录不属于已执行的迁移
+		recordVersion, err := strconv.ParseUint(record.Version, 10, 64)
+		if err != nil || record.Status != model.MigrationStatusSuccess || uint(recordVersion) > version {
+			continue
+		}
+		file := byVersion[record.Version]
+
+		mismatch := MigrationMismatch{Version: record.Version, Filename: record.Filename, Expected: record.Checksum}
+		if file == nil {
+			mismatch.Reason = "migration file removed"
+			mismatches = append(mismatches, mismatch)
+			continue
+		}
+
+		mismatch.Actual, err = m.checksum(file.upFile)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case mismatch.Actual != record.Checksum:
+			mismatch.Reason = "migration file modified after it was applied"
+		case file.upFile != record.Filename:
+			mismatch.Reason = "migration file renamed to " + file.upFile
+		default:
+			continue
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+
+	if len(mismatches) > 0 {
+		return mismatches, fmt.Errorf("%w: %d file(s)", ErrMigrationModified, len(mismatches))
+	}
+	return nil, nil
+}
@@ -1,139 +1,180 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"path/filepath"
-	"runtime"
+	"io/fs"
+	"os"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/mysql"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
 // MigrationConfig 迁移配置
 type MigrationConfig struct {
 	DatabaseURL    string // 数据库连接字符串
-	MigrationsPath string // 迁移文件路径
+	MigrationsPath string // 迁移文件目录，为空时使用编译进二进制的迁移文件
 	AutoMigrate    bool   // 是否自动执行迁移
+
+	LockName         string        // 迁移锁名称，共用同一个数据库的实例使用同一把锁
+	LockedBy         string        // 当前进程标识，写入 migration_locks.locked_by
+	LockLease        time.Duration // 锁租约时长，进程宕机后超过该时长由其他实例接管
+	LockWaitTimeout  time.Duration // 等待其他实例完成迁移的最长时间
+	LockPollInterval time.Duration // 等待期间检查锁和迁移版本的间隔
 }
 
 // MigrationManager 迁移管理器
 type MigrationManager struct {
-	config *MigrationConfig
-	db     *sql.DB
+	config  *MigrationConfig
+	db      *sql.DB
+	source  fs.FS
+	locker  MigrationLocker
+	history MigrationHistory
 }
 
 // NewMigrationManager 创建迁移管理器
 func NewMigrationManager(db *sql.DB, config *MigrationConfig) *MigrationManager {
-	if config.MigrationsPath == "" {
-		// 默认使用相对于当前文件的迁移路径
-		_, currentFile, _, _ := runtime.Caller(0)
-		config.MigrationsPath = filepath.Join(filepath.Dir(currentFile), "migrations")
+	if config == nil {
+		config = DefaultMigrationConfig()
+	}
+
+	source := MigrationsFS()
+	if config.MigrationsPath != "" {
+		source = os.DirFS(config.MigrationsPath)
+	}
+
+	defaults := DefaultMigrationConfig()
+	if config.LockName == "" {
+		config.LockName = defaults.LockName
+	}
+	if config.LockedBy == "" {
+		config.LockedBy = defaults.LockedBy
+	}
+	if config.LockLease <= 0 {
+		config.LockLease = defaults.LockLease
+	}
+	if config.LockWaitTimeout <= 0 {
+		config.LockWaitTimeout = defaults.LockWaitTimeout
+	}
+	if config.LockPollInterval <= 0 {
+		config.LockPollInterval = defaults.LockPollInterval
 	}
 
 	return &MigrationManager{
 		config: config,
 		db:     db,
+		source: source,
 	}
 }
 
-// RunMigrations 执行数据库迁移
-func (m *MigrationManager) RunMigrations() error {
-	driver, err := mysql.WithInstance(m.db, &mysql.Config{})
+// withMigrator 创建迁移实例并执行 fn
+// 迁移实例独占一个连接，关闭时只归还该连接，不会关闭调用方传入的 *sql.DB
+func (m *MigrationManager) withMigrator(fn func(migrator *migrate.Migrate) error) error {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get database connection: %w", err)
+	}
+
+	driver, err := mysql.WithConnection(ctx, conn, &mysql.Config{})
 	if err != nil {
+		conn.Close()
 		return fmt.Errorf("could not create mysql driver: %w", err)
 	}
 
-	migrator, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", m.config.MigrationsPath),
-		"mysql",
-		driver,
-	)
+	source, err := iofs.New(m.source, ".")
+	if err != nil {
+		driver.Close()
+		return fmt.Errorf("could not load migration files: %w", err)
+	}
+
+	migrator, err := migrate.NewWithInstance("iofs", source, "mysql", driver)
 	if err != nil {
+		source.Close()
+		driver.Close()
 		return fmt.Errorf("could not create migrate instance: %w", err)
 	}
 	defer migrator.Close()
 
-	if err := migrator.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("could not run migrations: %w", err)
-	}
+	return fn(migrator)
+}
 
-	return nil
+// RunMigrations 执行数据库迁移
+// 设置了迁移锁时，未抢到锁的实例等待持有者完成，迁移版本已是最新时直接返回
+func (m *MigrationManager) RunMigrations() error {
+	return m.withLock(m.isCurrent, func(migrator *migrate.Migrate, lost <-chan struct{}) error {
+		if m.history != nil {
+			return m.recordedUp(migrator, lost)
+		}
+		if err := migrator.Up(); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("could not run migrations: %w", err)
+		}
+		return nil
+	})
 }
 
 // GetVersion 获取当前迁移版本
 func (m *MigrationManager) GetVersion() (uint, bool, error) {
-	driver, err := mysql.WithInstance(m.db, &mysql.Config{})
-	if err != nil {
-		return 0, false, fmt.Errorf("could not create mysql driver: %w", err)
-	}
-
-	migrator, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", m.config.MigrationsPath),
-		"mysql",
-		driver,
-	)
-	if err != nil {
-		return 0, false, fmt.Errorf("could not create migrate instance: %w", err)
-	}
-	defer migrator.Close()
-
-	return migrator.Version()
+	var version uint
+	var dirty bool
+	err := m.withMigrator(func(migrator *migrate.Migrate) error {
+		var err error
+		version, dirty, err = migrator.Version()
+		return err
+	})
+	return version, dirty, err
 }
 
 // MigrateTo 迁移到指定版本
 func (m *MigrationManager) MigrateTo(version uint) error {
-	driver, err := mysql.WithInstance(m.db, &mysql.Config{})
-	if err != nil {
-		return fmt.Errorf("could not create mysql driver: %w", err)
-	}
-
-	migrator, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", m.config.MigrationsPath),
-		"mysql",
-		driver,
-	)
-	if err != nil {
-		return fmt.Errorf("could not create migrate instance: %w", err)
-	}
-	defer migrator.Close()
-
-	if err := migrator.Migrate(version); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("could not migrate to version %d: %w", version, err)
-	}
-
-	return nil
+	return m.withLock(nil, func(migrator *migrate.Migrate, lost <-chan struct{}) error {
+		if m.history != nil {
+			return m.recordedMigrateTo(migrator, lost, version)
+		}
+		if err := migrator.Migrate(version); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("could not migrate to version %d: %w", version, err)
+		}
+		return nil
+	})
 }
 
 // RollbackSteps 回滚指定步数
 func (m *MigrationManager) RollbackSteps(steps int) error {
-	driver, err := mysql.WithInstance(m.db, &mysql.Config{})
-	if err != nil {
-		return fmt.Errorf("could not create mysql driver: %w", err)
-	}
-
-	migrator, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", m.config.MigrationsPath),
-		"mysql",
-		driver,
-	)
-	if err != nil {
-		return fmt.Errorf("could not create migrate instance: %w", err)
-	}
-	defer migrator.Close()
-
-	if err := migrator.Steps(-steps); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("could not rollback %d steps: %w", steps, err)
-	}
+	return m.withLock(nil, func(migrator *migrate.Migrate, lost <-chan struct{}) error {
+		if m.history != nil {
+			return m.recordedRollback(migrator, lost, steps)
+		}
+		if err := migrator.Steps(-steps); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("could not rollback %d steps: %w", steps, err)
+		}
+		return nil
+	})
+}
 
-	return nil
+// Force 强制设置迁移版本并清除 dirty 标记，用于迁移中断后手动修复
+// version 为 -1 表示没有任何已执行的迁移
+func (m *MigrationManager) Force(version int) error {
+	return m.withLock(nil, func(migrator *migrate.Migrate, _ <-chan struct{}) error {
+		if err := migrator.Force(version); err != nil {
+			return fmt.Errorf("could not force version %d: %w", version, err)
+		}
+		return nil
+	})
 }
 
 // DefaultMigrationConfig 返回默认迁移配置
 func DefaultMigrationConfig() *MigrationConfig {
+	hostname, _ := os.Hostname()
 	return &MigrationConfig{
-		AutoMigrate: false, // 默认不自动迁移，在生产环境中应该手动控制
+		AutoMigrate:      false, // 默认不自动迁移，在生产环境中应该手动控制
+		LockName:         "schema_migrations",
+		LockedBy:         fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		LockLease:        30 * time.Second,
+		LockWaitTimeout:  10 * time.Minute,
+		LockPollInterval: 2 * time.Second,
 	}
 }
 
@@ -147,9 +188,10 @@ func Example() {
 	// defer db.Close()
 
 	// 2. 创建迁移管理器
-	// config := DefaultMigrationConfig()
-	// config.MigrationsPath = "./database/migrations"
+	// config := DefaultMigrationConfig() // 默认使用编译进二进制的迁移文件
 	// migrator := NewMigrationManager(db, config)
+	// 多实例同时启动时，通过 migration_locks 保证只有一个实例执行迁移
+	// migrator.SetLocker(model.NewMigrationLockStore(sqlx.NewSqlConnFromDB(db)))
 
 	// 3. 执行迁移
 	// if err := migrator.RunMigrations(); err != nil {
@@ -163,4 +205,4 @@ func Example() {
 	// } else {
 	//     log.Printf("Current migration version: %d, dirty: %t", version, dirty)
 	// }
-}
\ No newline at end of file
+}
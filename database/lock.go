@@ -0,0 +1,189 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+
+	"task-center/model"
+)
+
+var (
+	// ErrMigrationLockTimeout 等待迁移锁超时，其他实例仍在迁移或锁未释放
+	ErrMigrationLockTimeout = errors.New("timed out waiting for migration lock")
+	// ErrMigrationLockLost 迁移过程中锁被其他实例接管，当前迁移完成后已停止
+	ErrMigrationLockLost = errors.New("migration lock lost")
+)
+
+// MigrationLocker 跨实例的迁移锁，model.MigrationLocksModel 实现了该接口
+type MigrationLocker interface {
+	EnsureTable(ctx context.Context) error
+	Acquire(ctx context.Context, lockName, lockedBy string, lease time.Duration) (bool, error)
+	Renew(ctx context.Context, lockName, lockedBy string, lease time.Duration) (bool, error)
+	Release(ctx context.Context, lockName, lockedBy string) error
+}
+
+var (
+	_ MigrationLocker = model.MigrationLocksModel(nil)
+	_ MigrationLocker = (*model.MigrationLockStore)(nil)
+)
+
+// SetLocker 设置迁移锁，未设置时只依赖 golang-migrate 自带的会话级锁，并发迁移的实例会直接失败
+func (m *MigrationManager) SetLocker(locker MigrationLocker) {
+	m.locker = locker
+}
+
+// withLock 获取迁移锁后执行 fn，执行期间定期续约，结束后释放
+// done 不为空时，等待锁期间若 done 返回 true 则不再执行 fn，直接返回
+// lost 在锁被其他实例接管时关闭，未设置迁移锁时为 nil
+func (m *MigrationManager) withLock(done func() (bool, error), fn func(migrator *migrate.Migrate, lost <-chan struct{}) error) error {
+	if m.locker == nil {
+		return m.withMigrator(func(migrator *migrate.Migrate) error {
+			return fn(migrator, nil)
+		})
+	}
+
+	if err := m.acquireLock(done); err != nil {
+		if errors.Is(err, errMigrationDone) {
+			return nil
+		}
+		return err
+	}
+	defer m.locker.Release(context.Background(), m.config.LockName, m.config.LockedBy)
+
+	return m.withMigrator(func(migrator *migrate.Migrate) error {
+		stop := make(chan struct{})
+		lost := make(chan struct{})
+		go m.renewLock(migrator, stop, lost)
+
+		err := fn(migrator, lost)
+		close(stop)
+
+		select {
+		case <-lost:
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrMigrationLockLost, err)
+			}
+			return ErrMigrationLockLost
+		default:
+			return err
+		}
+	})
+}
+
+// errMigrationDone 等待期间其他实例已完成迁移
+var errMigrationDone = errors.New("migration done by another instance")
+
+// acquireLock 循环获取迁移锁，直到成功、done 返回 true 或等待超时
+func (m *MigrationManager) acquireLock(done func() (bool, error)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.config.LockWaitTimeout)
+	defer cancel()
+
+	if err := m.locker.EnsureTable(ctx); err != nil {
+		return fmt.Errorf("could not create migration lock table: %w", err)
+	}
+
+	for {
+		acquired, err := m.locker.Acquire(ctx, m.config.LockName, m.config.LockedBy, m.config.LockLease)
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("could not acquire migration lock: %w", err)
+		}
+		if acquired {
+			return nil
+		}
+
+		if done != nil {
+			// 查询失败时继续等待，由持有者或超时决定结果
+			if ok, err := done(); err == nil && ok {
+				return errMigrationDone
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w %q after %s", ErrMigrationLockTimeout, m.config.LockName, m.config.LockWaitTimeout)
+		case <-time.After(m.config.LockPollInterval):
+		}
+	}
+}
+
+// renewLock 在迁移期间定期续约，锁被其他实例接管或超过租约仍未续约成功时，
+// 通知 golang-migrate 在当前迁移文件执行完后停止，并关闭 lost
+func (m *MigrationManager) renewLock(migrator *migrate.Migrate, stop <-chan struct{}, lost chan<- struct{}) {
+	ticker := time.NewTicker(m.config.LockLease / 3)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.config.LockLease/3)
+		held, err := m.locker.Renew(ctx, m.config.LockName, m.config.LockedBy, m.config.LockLease)
+		cancel()
+		if err == nil && held {
+			renewed = time.Now()
+			continue
+		}
+		if err != nil && time.Since(renewed) < m.config.LockLease {
+			// 临时错误，租约到期前继续重试
+			continue
+		}
+
+		select {
+		case migrator.GracefulStop <- true:
+		default:
+		}
+		close(lost)
+		return
+	}
+}
+
+// isCurrent 数据库版本是否已是最新的迁移版本且不是 dirty 状态
+func (m *MigrationManager) isCurrent() (bool, error) {
+	latest, err := m.LatestVersion()
+	if err != nil {
+		return false, err
+	}
+
+	version, dirty, err := m.GetVersion()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !dirty && version >= latest, nil
+}
+
+// LatestVersion 返回迁移文件中的最新版本
+func (m *MigrationManager) LatestVersion() (uint, error) {
+	source, err := iofs.New(m.source, ".")
+	if err != nil {
+		return 0, fmt.Errorf("could not load migration files: %w", err)
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		return 0, fmt.Errorf("could not read migration files: %w", err)
+	}
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("could not read migration files: %w", err)
+		}
+		version = next
+	}
+}
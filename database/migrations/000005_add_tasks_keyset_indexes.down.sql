@@ -0,0 +1,3 @@
+ALTER TABLE tasks
+  DROP KEY idx_business_created_id,
+  DROP KEY idx_business_next_execute_id;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE tasks
+  ADD KEY idx_business_created_id (business_id, created_at, id),
+  ADD KEY idx_business_next_execute_id (business_id, next_execute_at, id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS task_tags;
\ No newline at end of file
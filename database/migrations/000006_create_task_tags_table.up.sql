@@ -0,0 +1,34 @@
+CREATE TABLE task_tags (
+  id bigint(20) NOT NULL AUTO_INCREMENT,
+  task_id bigint(20) NOT NULL,
+  business_id bigint(20) NOT NULL,
+  tag varchar(32) NOT NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE KEY uk_task_tag (task_id, tag),
+  KEY idx_business_tag_task (business_id, tag, task_id),
+  CONSTRAINT fk_task_tags_task_id FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 从 tasks.tags 的 JSON 数组回填，兼容 MySQL 5.7（不使用 JSON_TABLE）
+-- 非法 JSON 视为没有标签；每个任务最多展开前 64 个元素，非字符串、空白和超过 32 个字符（MaxTaskTagLength）的标签跳过
+INSERT IGNORE INTO task_tags (task_id, business_id, tag)
+SELECT x.id, x.business_id, x.tag
+FROM (
+  SELECT t.id, t.business_id, TRIM(JSON_UNQUOTE(JSON_EXTRACT(t.j, CONCAT('$[', seq.n, ']')))) AS tag,
+         JSON_TYPE(JSON_EXTRACT(t.j, CONCAT('$[', seq.n, ']'))) AS tag_type
+  FROM (
+    SELECT id, business_id, CASE WHEN JSON_VALID(tags) THEN CAST(tags AS JSON) END AS j
+    FROM tasks
+    WHERE tags IS NOT NULL AND tags <> ''
+  ) t
+  JOIN (
+    SELECT a.n + b.n * 8 AS n
+    FROM (SELECT 0 AS n UNION ALL SELECT 1 UNION ALL SELECT 2 UNION ALL SELECT 3
+          UNION ALL SELECT 4 UNION ALL SELECT 5 UNION ALL SELECT 6 UNION ALL SELECT 7) a
+    CROSS JOIN (SELECT 0 AS n UNION ALL SELECT 1 UNION ALL SELECT 2 UNION ALL SELECT 3
+          UNION ALL SELECT 4 UNION ALL SELECT 5 UNION ALL SELECT 6 UNION ALL SELECT 7) b
+  ) seq ON JSON_TYPE(t.j) = 'ARRAY' AND seq.n < JSON_LENGTH(t.j)
+) x
+WHERE x.tag_type = 'STRING'
+  AND CHAR_LENGTH(x.tag) BETWEEN 1 AND 32;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS bulk_jobs;
\ No newline at end of file
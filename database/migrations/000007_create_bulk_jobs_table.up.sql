@@ -0,0 +1,28 @@
+CREATE TABLE bulk_jobs (
+  id bigint(20) NOT NULL AUTO_INCREMENT,
+  job_id varchar(36) NOT NULL,
+  business_id bigint(20) NOT NULL,
+  action varchar(16) NOT NULL,
+  filter text NOT NULL,
+  dry_run tinyint(1) NOT NULL DEFAULT 0,
+  status tinyint(4) NOT NULL DEFAULT 0,
+  total int(11) NOT NULL DEFAULT 0,
+  processed int(11) NOT NULL DEFAULT 0,
+  succeeded int(11) NOT NULL DEFAULT 0,
+  skipped int(11) NOT NULL DEFAULT 0,
+  failed int(11) NOT NULL DEFAULT 0,
+  last_cursor varchar(255) NOT NULL DEFAULT '',
+  error_message text,
+  cancel_requested tinyint(1) NOT NULL DEFAULT 0,
+  locked_by varchar(64) DEFAULT NULL,
+  locked_until timestamp NULL DEFAULT NULL,
+  started_at timestamp NULL DEFAULT NULL,
+  completed_at timestamp NULL DEFAULT NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE KEY uk_job_id (job_id),
+  KEY idx_status_locked_until (status, locked_until),
+  KEY idx_business_created (business_id, created_at),
+  CONSTRAINT fk_bulk_jobs_business_id FOREIGN KEY (business_id) REFERENCES business_systems (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
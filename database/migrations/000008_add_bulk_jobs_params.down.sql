@@ -0,0 +1 @@
+ALTER TABLE bulk_jobs DROP COLUMN params;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE bulk_jobs ADD COLUMN params text AFTER filter;
\ No newline at end of file
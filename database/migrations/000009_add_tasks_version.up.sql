@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN version bigint(20) NOT NULL DEFAULT '1' COMMENT '版本号，每次修改加一，用于乐观锁' AFTER metadata;
\ No newline at end of file
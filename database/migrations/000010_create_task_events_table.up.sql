@@ -0,0 +1,14 @@
+CREATE TABLE task_events (
+  id bigint(20) NOT NULL AUTO_INCREMENT,
+  task_id bigint(20) NOT NULL,
+  business_id bigint(20) NOT NULL,
+  from_status tinyint(4) NOT NULL,
+  to_status tinyint(4) NOT NULL,
+  actor varchar(64) NOT NULL,
+  reason varchar(512) DEFAULT NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  KEY idx_task_id_id (task_id, id),
+  KEY idx_business_created (business_id, created_at),
+  CONSTRAINT fk_task_events_task_id FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,14 @@
+CREATE TABLE event_subscriptions (
+  id bigint(20) NOT NULL AUTO_INCREMENT,
+  business_id bigint(20) NOT NULL,
+  url varchar(512) NOT NULL,
+  event_types text NOT NULL,
+  secret varchar(128) NOT NULL,
+  status tinyint(4) NOT NULL DEFAULT 1,
+  description varchar(255) DEFAULT NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  KEY idx_business_status (business_id, status),
+  CONSTRAINT fk_event_subscriptions_business_id FOREIGN KEY (business_id) REFERENCES business_systems (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
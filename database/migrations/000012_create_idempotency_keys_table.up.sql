@@ -0,0 +1,18 @@
+CREATE TABLE idempotency_keys (
+  id bigint(20) NOT NULL AUTO_INCREMENT,
+  business_id bigint(20) NOT NULL,
+  idempotency_key varchar(128) NOT NULL,
+  request_hash char(64) NOT NULL,
+  status tinyint(4) NOT NULL DEFAULT 0,
+  status_code int(11) NOT NULL DEFAULT 0,
+  content_type varchar(128) NOT NULL DEFAULT '',
+  response_headers text DEFAULT NULL,
+  response_body mediumtext DEFAULT NULL,
+  expires_at timestamp NOT NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE KEY uk_business_key (business_id, idempotency_key),
+  KEY idx_expires_at (expires_at),
+  CONSTRAINT fk_idempotency_keys_business_id FOREIGN KEY (business_id) REFERENCES business_systems (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN trace_parent varchar(64) DEFAULT NULL COMMENT '创建任务时的 W3C traceparent，用于串联创建、调度和回调的链路' AFTER version;
\ No newline at end of file
@@ -0,0 +1,398 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SchemaCheckedTables 默认检查表结构漂移的表
+var SchemaCheckedTables = []string{"tasks", "task_executions", "task_locks", "business_systems"}
+
+// 表结构差异类型
+const (
+	SchemaDiffMissing    = "missing"    // 期望存在但数据库中不存在
+	SchemaDiffUnexpected = "unexpected" // 数据库中存在但不在期望结构中，通常是手工添加的索引
+	SchemaDiffType       = "type"       // 列类型不一致
+	SchemaDiffNullable   = "nullable"   // 列是否允许 NULL 不一致
+	SchemaDiffDefault    = "default"    // 列默认值不一致
+	SchemaDiffExtra      = "extra"      // 自增、ON UPDATE 等列属性不一致
+	SchemaDiffColumns    = "columns"    // 索引列不一致
+	SchemaDiffUnique     = "unique"     // 索引唯一性不一致
+)
+
+// SchemaDiff 一处表结构差异
+type SchemaDiff struct {
+	Table    string `json:"table"`
+	Object   string `json:"object"` // table、column 或 index
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// SchemaReport 表结构漂移检查结果
+type SchemaReport struct {
+	Database string       `json:"database"`
+	Tables   []string     `json:"tables"`
+	Drifted  bool         `json:"drifted"`
+	Diffs    []SchemaDiff `json:"diffs"`
+}
+
+// WriteText 输出便于阅读的检查结果
+func (r *SchemaReport) WriteText(w io.Writer) {
+	if !r.Drifted {
+		fmt.Fprintf(w, "schema of %s matches expected (%s)\n", r.Database, strings.Join(r.Tables, ", "))
+		return
+	}
+
+	fmt.Fprintf(w, "schema of %s has drifted, %d difference(s):\n", r.Database, len(r.Diffs))
+	table := ""
+	for _, diff := range r.Diffs {
+		if diff.Table != table {
+			table = diff.Table
+			fmt.Fprintf(w, "\n%s\n", table)
+		}
+		fmt.Fprintf(w, "  %-6s %-32s %s", diff.Object, diff.Name, diff.Kind)
+		if diff.Expected != "" || diff.Actual != "" {
+			fmt.Fprintf(w, ": expected %s, actual %s", orNone(diff.Expected), orNone(diff.Actual))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// orNone 空值显示为 (none)
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// TableSchema 表结构，只包含漂移检查关心的部分
+type TableSchema struct {
+	Name    string
+	Columns map[string]*ColumnSchema
+	Indexes map[string]*IndexSchema
+}
+
+// ColumnSchema 列结构
+type ColumnSchema struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  *string // nil 表示没有默认值或默认值为 NULL
+	Extra    string  // auto_increment、on update current_timestamp
+}
+
+// IndexSchema 索引结构，主键的名称为 PRIMARY
+type IndexSchema struct {
+	Name    string
+	Unique  bool
+	Columns []string
+}
+
+var (
+	createTableRe = regexp.MustCompile("(?is)CREATE TABLE\\s+(?:IF NOT EXISTS\\s+)?`?(\\w+)`?\\s*\\((.*?)\\n\\)\\s*ENGINE")
+	columnRe      = regexp.MustCompile("^`?(\\w+)`?\\s+(\\w+(?:\\([^)]*\\))?(?:\\s+unsigned)?)(.*)$")
+	indexRe       = regexp.MustCompile("(?i)^(PRIMARY KEY|UNIQUE KEY|KEY|INDEX|UNIQUE INDEX)\\s*`?(\\w*)`?\\s*\\((.*)\\)$")
+	commentRe     = regexp.MustCompile(`(?i)\s+COMMENT\s+'(?:[^'\\]|\\.|'')*'`)
+	defaultRe     = regexp.MustCompile(`(?i)\bDEFAULT\s+('(?:[^']|'')*'|\S+)`)
+	intWidthRe    = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|bigint)\(\d+\)`)
+)
+
+// ExpectedSchema 从 core_tables_no_fk.sql 解析期望的表结构
+// 该文件与迁移文件同步维护，迁移新增的列和索引需要同时更新该文件
+func ExpectedSchema() (map[string]*TableSchema, error) {
+	return ParseSchema(coreTablesDDL)
+}
+
+// ParseSchema 解析 CREATE TABLE 语句（可带 IF NOT EXISTS），忽略其他语句
+func ParseSchema(ddl string) (map[string]*TableSchema, error) {
+	tables := make(map[string]*TableSchema)
+	for _, match := range createTableRe.FindAllStringSubmatch(ddl, -1) {
+		table := &TableSchema{
+			Name:    match[1],
+			Columns: make(map[string]*ColumnSchema),
+			Indexes: make(map[string]*IndexSchema),
+		}
+
+		for _, line := range strings.Split(match[2], "\n") {
+			line = strings.TrimSuffix(strings.TrimSpace(line), ",")
+			if line == "" || strings.HasPrefix(line, "--") {
+				continue
+			}
+
+			if m := indexRe.FindStringSubmatch(line); m != nil {
+				index := &IndexSchema{Name: m[2], Unique: !strings.EqualFold(m[1], "KEY") && !strings.EqualFold(m[1], "INDEX")}
+				if strings.EqualFold(m[1], "PRIMARY KEY") {
+					index.Name = "PRIMARY"
+				}
+				for _, column := range strings.Split(m[3], ",") {
+					index.Columns = append(index.Columns, strings.Trim(strings.TrimSpace(column), "`"))
+				}
+				table.Indexes[index.Name] = index
+				continue
+			}
+			if strings.HasPrefix(strings.ToUpper(line), "CONSTRAINT") {
+				continue
+			}
+
+			m := columnRe.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("table %s: could not parse line %q", table.Name, line)
+			}
+			attrs := commentRe.ReplaceAllString(m[3], "")
+			upper := strings.ToUpper(attrs)
+			column := &ColumnSchema{
+				Name:     m[1],
+				Type:     normalizeType(m[2]),
+				Nullable: !strings.Contains(upper, "NOT NULL"),
+			}
+			if d := defaultRe.FindStringSubmatch(attrs); d != nil {
+				column.Default = normalizeDefault(&d[1])
+			}
+			var extras []string
+			if strings.Contains(upper, "AUTO_INCREMENT") {
+				extras = append(extras, "auto_increment")
+			}
+			if strings.Contains(upper, "ON UPDATE CURRENT_TIMESTAMP") {
+				extras = append(extras, "on update current_timestamp")
+			}
+			column.Extra = strings.Join(extras, " ")
+			table.Columns[column.Name] = column
+		}
+
+		tables[table.Name] = table
+	}
+	return tables, nil
+}
+
+// InspectSchema 从 information_schema 读取当前数据库中的表结构，表不存在时返回 nil
+func InspectSchema(ctx context.Context, db *sql.DB, table string) (*TableSchema, error) {
+	rows, err := db.QueryContext(ctx, "select COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, EXTRA "+
+		"from information_schema.COLUMNS where TABLE_SCHEMA = database() and TABLE_NAME = ?", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schema := &TableSchema{
+		Name:    table,
+		Columns: make(map[string]*ColumnSchema),
+		Indexes: make(map[string]*IndexSchema),
+	}
+	for rows.Next() {
+		var column ColumnSchema
+		var nullable, extra string
+		var def sql.NullString
+		if err := rows.Scan(&column.Name, &column.Type, &nullable, &def, &extra); err != nil {
+			return nil, err
+		}
+		column.Type = normalizeType(column.Type)
+		column.Nullable = nullable == "YES"
+		if def.Valid {
+			column.Default = normalizeDefault(&def.String)
+		}
+		column.Extra = normalizeExtra(extra)
+		schema.Columns[column.Name] = &column
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(schema.Columns) == 0 {
+		return nil, nil
+	}
+
+	rows, err = db.QueryContext(ctx, "select INDEX_NAME, NON_UNIQUE, COLUMN_NAME, SUB_PART "+
+		"from information_schema.STATISTICS where TABLE_SCHEMA = database() and TABLE_NAME = ? "+
+		"order by INDEX_NAME, SEQ_IN_INDEX", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var nonUnique int
+		var column sql.NullString
+		var subPart sql.NullInt64
+		if err := rows.Scan(&name, &nonUnique, &column, &subPart); err != nil {
+			return nil, err
+		}
+		index, ok := schema.Indexes[name]
+		if !ok {
+			index = &IndexSchema{Name: name, Unique: nonUnique == 0}
+			schema.Indexes[name] = index
+		}
+		part := column.String
+		if !column.Valid {
+			part = "(expression)"
+		}
+		if subPart.Valid {
+			part = fmt.Sprintf("%s(%d)", part, subPart.Int64)
+		}
+		index.Columns = append(index.Columns, part)
+	}
+	return schema, rows.Err()
+}
+
+// CheckSchema 对比数据库中的表结构与期望结构，tables 为空时检查 SchemaCheckedTables
+func CheckSchema(ctx context.Context, db *sql.DB, tables ...string) (*SchemaReport, error) {
+	if len(tables) == 0 {
+		tables = SchemaCheckedTables
+	}
+
+	expected, err := ExpectedSchema()
+	if err != nil {
+		return nil, fmt.Errorf("could not parse expected schema: %w", err)
+	}
+
+	report := &SchemaReport{Tables: tables, Diffs: []SchemaDiff{}}
+	if err := db.QueryRowContext(ctx, "select database()").Scan(&report.Database); err != nil {
+		return nil, err
+	}
+
+	for _, table := range tables {
+		want, ok := expected[table]
+		if !ok {
+			return nil, fmt.Errorf("table %s is not defined in the expected schema", table)
+		}
+		actual, err := InspectSchema(ctx, db, table)
+		if err != nil {
+			return nil, fmt.Errorf("could not inspect table %s: %w", table, err)
+		}
+		report.Diffs = append(report.Diffs, DiffSchema(want, actual)...)
+	}
+	report.Drifted = len(report.Diffs) > 0
+	return report, nil
+}
+
+// DiffSchema 对比期望结构与实际结构，actual 为 nil 表示表不存在
+func DiffSchema(expected, actual *TableSchema) []SchemaDiff {
+	var diffs []SchemaDiff
+	if actual == nil {
+		return append(diffs, SchemaDiff{Table: expected.Name, Object: "table", Name: expected.Name, Kind: SchemaDiffMissing})
+	}
+	diff := func(object, name, kind, want, got string) {
+		diffs = append(diffs, SchemaDiff{Table: expected.Name, Object: object, Name: name, Kind: kind, Expected: want, Actual: got})
+	}
+
+	for _, name := range sortedKeys(expected.Columns, actual.Columns) {
+		want, got := expected.Columns[name], actual.Columns[name]
+		switch {
+		case got == nil:
+			diff("column", name, SchemaDiffMissing, want.Type, "")
+		case want == nil:
+			diff("column", name, SchemaDiffUnexpected, "", got.Type)
+		default:
+			if want.Type != got.Type {
+				diff("column", name, SchemaDiffType, want.Type, got.Type)
+			}
+			if want.Nullable != got.Nullable {
+				diff("column", name, SchemaDiffNullable, nullability(want.Nullable), nullability(got.Nullable))
+			}
+			if defaultString(want.Default) != defaultString(got.Default) {
+				diff("column", name, SchemaDiffDefault, defaultString(want.Default), defaultString(got.Default))
+			}
+			if want.Extra != got.Extra {
+				diff("column", name, SchemaDiffExtra, want.Extra, got.Extra)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(expected.Indexes, actual.Indexes) {
+		want, got := expected.Indexes[name], actual.Indexes[name]
+		switch {
+		case got == nil:
+			diff("index", name, SchemaDiffMissing, want.String(), "")
+		case want == nil:
+			diff("index", name, SchemaDiffUnexpected, "", got.String())
+		default:
+			if strings.Join(want.Columns, ",") != strings.Join(got.Columns, ",") {
+				diff("index", name, SchemaDiffColumns, want.String(), got.String())
+			}
+			if want.Unique != got.Unique {
+				diff("index", name, SchemaDiffUnique, want.String(), got.String())
+			}
+		}
+	}
+	return diffs
+}
+
+// String 索引定义，如 UNIQUE (business_id, business_unique_id)
+func (i *IndexSchema) String() string {
+	prefix := ""
+	if i.Unique {
+		prefix = "UNIQUE "
+	}
+	return prefix + "(" + strings.Join(i.Columns, ", ") + ")"
+}
+
+// sortedKeys 返回两个 map 键的并集，按名称排序
+func sortedKeys[T any](a, b map[string]T) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var keys []string
+	for _, m := range []map[string]T{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// normalizeType 统一列类型写法，MySQL 8.0.19 起整数类型不再显示宽度
+func normalizeType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	return intWidthRe.ReplaceAllString(t, "$1")
+}
+
+// normalizeDefault 统一默认值写法：去掉引号，current_timestamp() 与 CURRENT_TIMESTAMP 视为相同，NULL 视为没有默认值
+func normalizeDefault(def *string) *string {
+	v := strings.TrimSpace(*def)
+	if strings.EqualFold(v, "NULL") {
+		return nil
+	}
+	if len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'' {
+		v = strings.ReplaceAll(v[1:len(v)-1], "''", "'")
+	}
+	if strings.EqualFold(strings.TrimSuffix(v, "()"), "CURRENT_TIMESTAMP") {
+		v = "CURRENT_TIMESTAMP"
+	}
+	return &v
+}
+
+// normalizeExtra 统一 EXTRA 写法，忽略 MySQL 8 的 DEFAULT_GENERATED 标记
+func normalizeExtra(extra string) string {
+	extra = strings.ToLower(extra)
+	extra = strings.ReplaceAll(extra, "default_generated", "")
+	extra = strings.ReplaceAll(extra, "current_timestamp()", "current_timestamp")
+	return strings.Join(strings.Fields(extra), " ")
+}
+
+// defaultString 默认值的显示形式
+func defaultString(def *string) string {
+	if def == nil {
+		return "NULL"
+	}
+	if *def == "CURRENT_TIMESTAMP" {
+		return *def
+	}
+	return "'" + *def + "'"
+}
+
+// nullability 是否允许 NULL 的显示形式
+func nullability(nullable bool) string {
+	if nullable {
+		return "NULL"
+	}
+	return "NOT NULL"
+}
@@ -0,0 +1,198 @@
+package database
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+
+	"task-center/model"
+)
+
+const testSchemaDDL = "-- 测试用表结构\n" +
+	"SET NAMES utf8mb4;\n\n" +
+	"CREATE TABLE IF NOT EXISTS `jobs` (\n" +
+	"  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT 'ID',\n" +
+	"  `name` varchar(64) NOT NULL DEFAULT '' COMMENT 'it''s, (the) name',\n" +
+	"  `status` tinyint(4) NOT NULL DEFAULT '1',\n" +
+	"  `note` text,\n" +
+	"  `run_at` datetime DEFAULT NULL,\n" +
+	"  `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  UNIQUE KEY `uk_name` (`name`),\n" +
+	"  KEY `idx_status_run_at` (`status`, `run_at`)\n" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n"
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestParseSchema(t *testing.T) {
+	tables, err := ParseSchema(testSchemaDDL)
+	if err != nil {
+		t.Fatalf("ParseSchema failed: %v", err)
+	}
+	table := tables["jobs"]
+	if len(tables) != 1 || table == nil {
+		t.Fatalf("tables = %v, want only jobs", tables)
+	}
+
+	columns := map[string]*ColumnSchema{
+		"id":         {Name: "id", Type: "bigint unsigned", Extra: "auto_increment"},
+		"name":       {Name: "name", Type: "varchar(64)", Default: strPtr("")},
+		"status":     {Name: "status", Type: "tinyint", Default: strPtr("1")},
+		"note":       {Name: "note", Type: "text", Nullable: true},
+		"run_at":     {Name: "run_at", Type: "datetime", Nullable: true},
+		"updated_at": {Name: "updated_at", Type: "timestamp", Default: strPtr("CURRENT_TIMESTAMP"), Extra: "on update current_timestamp"},
+	}
+	if !reflect.DeepEqual(table.Columns, columns) {
+		for name, want := range columns {
+			if got := table.Columns[name]; !reflect.DeepEqual(got, want) {
+				t.Errorf("column %s = %+v, want %+v", name, got, want)
+			}
+		}
+		t.Errorf("parsed %d columns, want %d", len(table.Columns), len(columns))
+	}
+
+	indexes := map[string]*IndexSchema{
+		"PRIMARY":           {Name: "PRIMARY", Unique: true, Columns: []string{"id"}},
+		"uk_name":           {Name: "uk_name", Unique: true, Columns: []string{"name"}},
+		"idx_status_run_at": {Name: "idx_status_run_at", Columns: []string{"status", "run_at"}},
+	}
+	if !reflect.DeepEqual(table.Indexes, indexes) {
+		t.Errorf("indexes = %+v, want %+v", table.Indexes, indexes)
+	}
+}
+
+func TestParseSchema_InvalidLine(t *testing.T) {
+	ddl := "CREATE TABLE `jobs` (\n  `id` bigint NOT NULL,\n  ???\n) ENGINE=InnoDB;"
+	if _, err := ParseSchema(ddl); err == nil {
+		t.Error("expected error for unparsable column line")
+	}
+}
+
+func TestExpectedSchema(t *testing.T) {
+	tables, err := ExpectedSchema()
+	if err != nil {
+		t.Fatalf("ExpectedSchema failed: %v", err)
+	}
+	for _, name := range SchemaCheckedTables {
+		if tables[name] == nil {
+			t.Errorf("expected schema has no table %s", name)
+		}
+	}
+}
+
+func TestExpectedSchema_TagLength(t *testing.T) {
+	tables, err := ExpectedSchema()
+	if err != nil {
+		t.Fatalf("ExpectedSchema failed: %v", err)
+	}
+	want := fmt.Sprintf("varchar(%d)", model.MaxTaskTagLength)
+	if got := tables["task_tags"].Columns["tag"].Type; got != want {
+		t.Errorf("task_tags.tag = %s, want %s to match MaxTaskTagLength", got, want)
+	}
+
+	// 回填时跳过超长标签，长度上限必须与列宽一致
+	migration, err := fs.ReadFile(MigrationsFS(), "000006_create_task_tags_table.up.sql")
+	if err != nil {
+		t.Fatalf("read migration: %v", err)
+	}
+	for _, expect := range []string{"tag " + want, fmt.Sprintf("BETWEEN 1 AND %d", model.MaxTaskTagLength)} {
+		if !strings.Contains(string(migration), expect) {
+			t.Errorf("task_tags migration does not contain %q", expect)
+		}
+	}
+}
+
+func TestDiffSchema(t *testing.T) {
+	expected := func() *TableSchema {
+		tables, err := ParseSchema(testSchemaDDL)
+		if err != nil {
+			t.Fatalf("ParseSchema failed: %v", err)
+		}
+		return tables["jobs"]
+	}
+
+	tests := []struct {
+		name   string
+		modify func(actual *TableSchema)
+		want   []SchemaDiff
+	}{
+		{
+			name:   "identical",
+			modify: func(*TableSchema) {},
+		},
+		{
+			name: "column drift",
+			modify: func(actual *TableSchema) {
+				actual.Columns["status"].Type = "int"
+				actual.Columns["note"].Nullable = false
+				actual.Columns["name"].Default = nil
+				actual.Columns["updated_at"].Extra = ""
+			},
+			want: []SchemaDiff{
+				{Table: "jobs", Object: "column", Name: "name", Kind: SchemaDiffDefault, Expected: "''", Actual: "NULL"},
+				{Table: "jobs", Object: "column", Name: "note", Kind: SchemaDiffNullable, Expected: "NULL", Actual: "NOT NULL"},
+				{Table: "jobs", Object: "column", Name: "status", Kind: SchemaDiffType, Expected: "tinyint", Actual: "int"},
+				{Table: "jobs", Object: "column", Name: "updated_at", Kind: SchemaDiffExtra, Expected: "on update current_timestamp"},
+			},
+		},
+		{
+			name: "missing and unexpected columns",
+			modify: func(actual *TableSchema) {
+				delete(actual.Columns, "run_at")
+				actual.Columns["extra"] = &ColumnSchema{Name: "extra", Type: "int", Nullable: true}
+			},
+			want: []SchemaDiff{
+				{Table: "jobs", Object: "column", Name: "extra", Kind: SchemaDiffUnexpected, Actual: "int"},
+				{Table: "jobs", Object: "column", Name: "run_at", Kind: SchemaDiffMissing, Expected: "datetime"},
+			},
+		},
+		{
+			name: "index drift",
+			modify: func(actual *TableSchema) {
+				delete(actual.Indexes, "uk_name")
+				actual.Indexes["idx_status_run_at"].Columns = []string{"run_at", "status"}
+				actual.Indexes["idx_manual"] = &IndexSchema{Name: "idx_manual", Unique: true, Columns: []string{"note"}}
+			},
+			want: []SchemaDiff{
+				{Table: "jobs", Object: "index", Name: "idx_manual", Kind: SchemaDiffUnexpected, Actual: "UNIQUE (note)"},
+				{Table: "jobs", Object: "index", Name: "idx_status_run_at", Kind: SchemaDiffColumns, Expected: "(status, run_at)", Actual: "(run_at, status)"},
+				{Table: "jobs", Object: "index", Name: "uk_name", Kind: SchemaDiffMissing, Expected: "UNIQUE (name)"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := expected()
+			tt.modify(actual)
+			got := DiffSchema(expected(), actual)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffSchema() = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+
+	got := DiffSchema(expected(), nil)
+	if len(got) != 1 || got[0].Object != "table" || got[0].Kind != SchemaDiffMissing {
+		t.Errorf("missing table diff = %+v", got)
+	}
+}
+
+func TestNormalizeDefault(t *testing.T) {
+	tests := map[string]*string{
+		"NULL":                nil,
+		"'abc'":               strPtr("abc"),
+		"'it''s'":             strPtr("it's"),
+		"current_timestamp()": strPtr("CURRENT_TIMESTAMP"),
+		"0":                   strPtr("0"),
+	}
+	for in, want := range tests {
+		got := normalizeDefault(strPtr(in))
+		if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+			t.Errorf("normalizeDefault(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
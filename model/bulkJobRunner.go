@@ -0,0 +1,171 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// BulkJobRunnerConfig 批量任务执行器配置
+type BulkJobRunnerConfig struct {
+	NodeId       string        // 节点ID，用于区分租约持有者
+	BatchSize    int           // 每批处理的任务数，每批之后保存进度并检查取消
+	LeaseTimeout time.Duration // 租约时长，节点宕机后超过该时长由其他节点接管
+	PollInterval time.Duration // 没有待执行任务时的轮询间隔
+}
+
+// DefaultBulkJobRunnerConfig 默认批量任务执行器配置
+func DefaultBulkJobRunnerConfig() *BulkJobRunnerConfig {
+	hostname, _ := os.Hostname()
+	return &BulkJobRunnerConfig{
+		NodeId:       fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		BatchSize:    200,
+		LeaseTimeout: time.Minute,
+		PollInterval: 5 * time.Second,
+	}
+}
+
+// BulkJobRunner 批量任务执行器，多个节点可同时运行，通过租约保证同一任务只由一个节点执行
+type BulkJobRunner struct {
+	jobs   BulkJobsModel
+	tasks  TasksModel
+	config *BulkJobRunnerConfig
+}
+
+// NewBulkJobRunner 创建批量任务执行器
+func NewBulkJobRunner(jobs BulkJobsModel, tasks TasksModel, config *BulkJobRunnerConfig) *BulkJobRunner {
+	if config == nil {
+		config = DefaultBulkJobRunnerConfig()
+	}
+	return &BulkJobRunner{
+		jobs:   jobs,
+		tasks:  tasks,
+		config: config,
+	}
+}
+
+// Run 持续领取并执行批量任务，直到 ctx 结束
+func (r *BulkJobRunner) Run(ctx context.Context) error {
+	for {
+		ran, err := r.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			// 单个任务出错不影响后续任务，等待下一轮
+			ran = false
+		}
+		if ran {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+}
+
+// RunOnce 领取并执行一个批量任务，没有可领取的任务时返回 false
+func (r *BulkJobRunner) RunOnce(ctx context.Context) (bool, error) {
+	job, err := r.jobs.ClaimNext(ctx, r.config.NodeId, r.config.LeaseTimeout)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	err = r.execute(ctx, job)
+	if errors.Is(err, ErrBulkJobLeaseLost) {
+		// 其他节点已接管，从保存的进度继续执行
+		return true, nil
+	}
+	return true, err
+}
+
+// execute 按游标分批处理匹配的任务，每批之后保存进度、续约并检查取消请求
+// 接管的任务从上次保存的游标继续，最后一批会重新处理：状态条件保证取消、重试、删除不会重复执行，
+// 但按时长顺延不是幂等的，节点在处理完一批、保存进度前宕机时，该批任务可能被再次顺延
+func (r *BulkJobRunner) execute(ctx context.Context, job *BulkJobs) error {
+	if job.CancelRequested == 1 {
+		return r.jobs.Finish(ctx, job, BulkJobStatusCancelled, "")
+	}
+
+	var filter BulkJobFilter
+	if err := json.Unmarshal([]byte(job.Filter), &filter); err != nil {
+		return r.jobs.Finish(ctx, job, BulkJobStatusFailed, fmt.Sprintf("invalid filter: %v", err))
+	}
+	listFilter, err := filter.ListFilter(job.BusinessId, job.Action)
+	if err != nil {
+		return r.jobs.Finish(ctx, job, BulkJobStatusFailed, err.Error())
+	}
+	if listFilter == nil {
+		return r.jobs.Finish(ctx, job, BulkJobStatusCompleted, "")
+	}
+
+	// 首次执行时统计总数用于计算进度，接管的任务沿用已保存的总数
+	if job.Processed == 0 && job.LastCursor == "" {
+		total, err := r.tasks.CountByFilter(ctx, listFilter)
+		if err != nil {
+			return err
+		}
+		job.Total = total
+	}
+	if job.DryRun == 1 {
+		return r.jobs.Finish(ctx, job, BulkJobStatusCompleted, "")
+	}
+
+	var reschedule *TaskReschedule
+	if job.Params.Valid {
+		reschedule = &TaskReschedule{}
+		if err := json.Unmarshal([]byte(job.Params.String), reschedule); err != nil {
+			return r.jobs.Finish(ctx, job, BulkJobStatusFailed, fmt.Sprintf("invalid params: %v", err))
+		}
+	}
+
+	ctx = WithTaskActor(ctx, "bulk", fmt.Sprintf("bulk %s job %d", job.Action, job.Id))
+	for {
+		page, err := r.tasks.ListByCursor(ctx, listFilter, &TaskPageRequest{Cursor: job.LastCursor, Limit: r.config.BatchSize})
+		if err != nil {
+			return err
+		}
+
+		if n := int64(len(page.Tasks)); n > 0 {
+			applied, err := r.tasks.ApplyBulkAction(ctx, job.Action, page.Tasks, reschedule)
+			if err != nil {
+				job.Failed += n
+				job.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
+			} else {
+				job.Succeeded += int64(len(applied))
+				job.Skipped += n - int64(len(applied))
+			}
+			job.Processed += n
+		}
+		if page.HasMore {
+			job.LastCursor = page.NextCursor
+		}
+		// 执行期间新建的匹配任务也会被处理
+		if job.Processed > job.Total {
+			job.Total = job.Processed
+		}
+
+		cancelled, err := r.jobs.SaveProgress(ctx, job, r.config.LeaseTimeout)
+		if err != nil {
+			return err
+		}
+		if cancelled {
+			return r.jobs.Finish(ctx, job, BulkJobStatusCancelled, "")
+		}
+		if !page.HasMore {
+			break
+		}
+	}
+
+	if job.Failed > 0 && job.Succeeded == 0 && job.Skipped == 0 {
+		return r.jobs.Finish(ctx, job, BulkJobStatusFailed, job.ErrorMessage.String)
+	}
+	return r.jobs.Finish(ctx, job, BulkJobStatusCompleted, "")
+}
@@ -0,0 +1,252 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeBulkJobs 依次返回待领取的批量任务，记录保存的进度和结束状态
+type fakeBulkJobs struct {
+	BulkJobsModel
+	queue       []*BulkJobs
+	claimedBy   string
+	lease       time.Duration
+	saves       []BulkJobs
+	cancelAfter int   // 第几次保存进度时返回已请求取消，0 表示不取消
+	saveErr     error // 保存进度返回的错误
+	finished    *BulkJobs
+	status      int64
+	errMsg      string
+}
+
+func (f *fakeBulkJobs) ClaimNext(_ context.Context, nodeId string, lease time.Duration) (*BulkJobs, error) {
+	if len(f.queue) == 0 {
+		return nil, ErrNotFound
+	}
+	job := f.queue[0]
+	f.queue = f.queue[1:]
+	f.claimedBy, f.lease = nodeId, lease
+	return job, nil
+}
+
+func (f *fakeBulkJobs) SaveProgress(_ context.Context, job *BulkJobs, _ time.Duration) (bool, error) {
+	if f.saveErr != nil {
+		return false, f.saveErr
+	}
+	f.saves = append(f.saves, *job)
+	return len(f.saves) == f.cancelAfter, nil
+}
+
+func (f *fakeBulkJobs) Finish(_ context.Context, job *BulkJobs, status int64, errMsg string) error {
+	f.finished, f.status, f.errMsg = job, status, errMsg
+	return nil
+}
+
+// fakeBulkTasks 按游标返回固定的分页，applied 为每页操作成功的任务数，applyErr 不为空时操作失败
+type fakeBulkTasks struct {
+	TasksModel
+	total    int64
+	pages    map[string]*TaskPage
+	applied  []int
+	applyErr error
+	cursors  []string
+	batches  int
+}
+
+func (f *fakeBulkTasks) CountByFilter(context.Context, *TaskListFilter) (int64, error) {
+	return f.total, nil
+}
+
+func (f *fakeBulkTasks) ListByCursor(_ context.Context, _ *TaskListFilter, page *TaskPageRequest) (*TaskPage, error) {
+	f.cursors = append(f.cursors, page.Cursor)
+	return f.pages[page.Cursor], nil
+}
+
+func (f *fakeBulkTasks) ApplyBulkAction(_ context.Context, _ string, tasks []*Tasks, _ *TaskReschedule) ([]int64, error) {
+	defer func() { f.batches++ }()
+	if f.applyErr != nil {
+		return nil, f.applyErr
+	}
+	var ids []int64
+	for _, task := range tasks[:f.applied[f.batches]] {
+		ids = append(ids, task.Id)
+	}
+	return ids, nil
+}
+
+// twoPages 返回两页共 5 个任务
+func twoPages() map[string]*TaskPage {
+	return map[string]*TaskPage{
+		"":   {Tasks: []*Tasks{{Id: 1}, {Id: 2}, {Id: 3}}, NextCursor: "c1", HasMore: true},
+		"c1": {Tasks: []*Tasks{{Id: 4}, {Id: 5}}},
+	}
+}
+
+func newBulkJob() *BulkJobs {
+	return &BulkJobs{Id: 1, JobId: "job-1", BusinessId: 7, Action: BulkActionCancel, Filter: "{}"}
+}
+
+func newTestBulkJobRunner(jobs *fakeBulkJobs, tasks *fakeBulkTasks) *BulkJobRunner {
+	return NewBulkJobRunner(jobs, tasks, &BulkJobRunnerConfig{NodeId: "node-a", BatchSize: 3, LeaseTimeout: 2 * time.Minute})
+}
+
+func TestBulkJobRunner_NoJob(t *testing.T) {
+	r := newTestBulkJobRunner(&fakeBulkJobs{}, &fakeBulkTasks{})
+	if ran, err := r.RunOnce(context.Background()); ran || err != nil {
+		t.Errorf("RunOnce() = %v, %v, want false, nil", ran, err)
+	}
+}
+
+func TestBulkJobRunner_Progress(t *testing.T) {
+	jobs := &fakeBulkJobs{queue: []*BulkJobs{newBulkJob()}}
+	tasks := &fakeBulkTasks{total: 5, pages: twoPages(), applied: []int{3, 1}}
+	r := newTestBulkJobRunner(jobs, tasks)
+
+	if ran, err := r.RunOnce(context.Background()); !ran || err != nil {
+		t.Fatalf("RunOnce() = %v, %v, want true, nil", ran, err)
+	}
+	if jobs.claimedBy != "node-a" || jobs.lease != 2*time.Minute {
+		t.Errorf("claimed by %q with lease %v", jobs.claimedBy, jobs.lease)
+	}
+
+	// 每批之后保存进度，下一批从保存的游标继续
+	if len(tasks.cursors) != 2 || tasks.cursors[1] != "c1" {
+		t.Errorf("cursors = %q, want [\"\" \"c1\"]", tasks.cursors)
+	}
+	if len(jobs.saves) != 2 {
+		t.Fatalf("saved progress %d times, want 2", len(jobs.saves))
+	}
+	if first := jobs.saves[0]; first.Processed != 3 || first.Succeeded != 3 || first.LastCursor != "c1" {
+		t.Errorf("first save = %+v", first)
+	}
+
+	job := jobs.finished
+	if jobs.status != BulkJobStatusCompleted {
+		t.Errorf("status = %d, want completed", jobs.status)
+	}
+	if job.Total != 5 || job.Processed != 5 || job.Succeeded != 4 || job.Skipped != 1 || job.Failed != 0 {
+		t.Errorf("counts = total %d processed %d succeeded %d skipped %d failed %d, want 5/5/4/1/0",
+			job.Total, job.Processed, job.Succeeded, job.Skipped, job.Failed)
+	}
+}
+
+func TestBulkJobRunner_ResumesTakenOverJob(t *testing.T) {
+	job := newBulkJob()
+	job.Total, job.Processed, job.Succeeded, job.LastCursor = 5, 3, 3, "c1"
+	jobs := &fakeBulkJobs{queue: []*BulkJobs{job}}
+	tasks := &fakeBulkTasks{total: 99, pages: twoPages(), applied: []int{2}}
+
+	if _, err := newTestBulkJobRunner(jobs, tasks).RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	// 接管的任务沿用已保存的总数，从游标继续
+	if len(tasks.cursors) != 1 || tasks.cursors[0] != "c1" {
+		t.Errorf("cursors = %q, want [\"c1\"]", tasks.cursors)
+	}
+	if job.Total != 5 || job.Processed != 5 || job.Succeeded != 5 {
+		t.Errorf("total %d processed %d succeeded %d, want 5/5/5", job.Total, job.Processed, job.Succeeded)
+	}
+}
+
+func TestBulkJobRunner_Cancel(t *testing.T) {
+	t.Run("requested before start", func(t *testing.T) {
+		job := newBulkJob()
+		job.CancelRequested = 1
+		jobs := &fakeBulkJobs{queue: []*BulkJobs{job}}
+		tasks := &fakeBulkTasks{}
+
+		if _, err := newTestBulkJobRunner(jobs, tasks).RunOnce(context.Background()); err != nil {
+			t.Fatalf("RunOnce failed: %v", err)
+		}
+		if jobs.status != BulkJobStatusCancelled || len(tasks.cursors) != 0 {
+			t.Errorf("status = %d after listing %d pages, want cancelled without listing", jobs.status, len(tasks.cursors))
+		}
+	})
+
+	t.Run("requested while running", func(t *testing.T) {
+		jobs := &fakeBulkJobs{queue: []*BulkJobs{newBulkJob()}, cancelAfter: 1}
+		tasks := &fakeBulkTasks{total: 5, pages: twoPages(), applied: []int{3, 2}}
+
+		if _, err := newTestBulkJobRunner(jobs, tasks).RunOnce(context.Background()); err != nil {
+			t.Fatalf("RunOnce failed: %v", err)
+		}
+		// 保存第一批进度时发现取消请求，不再处理第二批
+		if jobs.status != BulkJobStatusCancelled || tasks.batches != 1 || jobs.finished.Processed != 3 {
+			t.Errorf("status = %d after %d batches, processed %d, want cancelled after 1 batch of 3",
+				jobs.status, tasks.batches, jobs.finished.Processed)
+		}
+	})
+}
+
+func TestBulkJobRunner_DryRun(t *testing.T) {
+	job := newBulkJob()
+	job.DryRun = 1
+	jobs := &fakeBulkJobs{queue: []*BulkJobs{job}}
+	tasks := &fakeBulkTasks{total: 42, pages: twoPages()}
+
+	if _, err := newTestBulkJobRunner(jobs, tasks).RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	// 只统计匹配的任务数，不处理任务
+	if jobs.status != BulkJobStatusCompleted || job.Total != 42 || job.Processed != 0 {
+		t.Errorf("status = %d, total = %d, processed = %d, want completed with total 42", jobs.status, job.Total, job.Processed)
+	}
+	if len(tasks.cursors) != 0 || tasks.batches != 0 {
+		t.Errorf("dry run listed %d pages and applied %d batches", len(tasks.cursors), tasks.batches)
+	}
+}
+
+func TestBulkJobRunner_Failures(t *testing.T) {
+	t.Run("every batch failed", func(t *testing.T) {
+		jobs := &fakeBulkJobs{queue: []*BulkJobs{newBulkJob()}}
+		tasks := &fakeBulkTasks{total: 5, pages: twoPages(), applyErr: errors.New("db down")}
+
+		if _, err := newTestBulkJobRunner(jobs, tasks).RunOnce(context.Background()); err != nil {
+			t.Fatalf("RunOnce failed: %v", err)
+		}
+		if jobs.status != BulkJobStatusFailed || jobs.errMsg != "db down" || jobs.finished.Failed != 5 {
+			t.Errorf("status = %d, error = %q, failed = %d, want failed with 5 failures", jobs.status, jobs.errMsg, jobs.finished.Failed)
+		}
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		job := newBulkJob()
+		job.Filter = "not json"
+		jobs := &fakeBulkJobs{queue: []*BulkJobs{job}}
+
+		if _, err := newTestBulkJobRunner(jobs, &fakeBulkTasks{}).RunOnce(context.Background()); err != nil {
+			t.Fatalf("RunOnce failed: %v", err)
+		}
+		if jobs.status != BulkJobStatusFailed {
+			t.Errorf("status = %d, want failed", jobs.status)
+		}
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		job := newBulkJob()
+		job.Action = BulkActionReschedule
+		job.Params = sql.NullString{String: "not json", Valid: true}
+		jobs := &fakeBulkJobs{queue: []*BulkJobs{job}}
+
+		if _, err := newTestBulkJobRunner(jobs, &fakeBulkTasks{total: 5}).RunOnce(context.Background()); err != nil {
+			t.Fatalf("RunOnce failed: %v", err)
+		}
+		if jobs.status != BulkJobStatusFailed {
+			t.Errorf("status = %d, want failed", jobs.status)
+		}
+	})
+
+	t.Run("lease lost", func(t *testing.T) {
+		jobs := &fakeBulkJobs{queue: []*BulkJobs{newBulkJob()}, saveErr: ErrBulkJobLeaseLost}
+		tasks := &fakeBulkTasks{total: 5, pages: twoPages(), applied: []int{3, 2}}
+
+		// 其他节点已接管，不结束任务也不返回错误
+		ran, err := newTestBulkJobRunner(jobs, tasks).RunOnce(context.Background())
+		if !ran || err != nil || jobs.finished != nil {
+			t.Errorf("RunOnce() = %v, %v, finished = %v, want true, nil without finishing", ran, err, jobs.finished)
+		}
+	})
+}
@@ -0,0 +1,339 @@
+package model
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+
+	"task-center/pkg/search"
+)
+
+var _ BulkJobsModel = (*customBulkJobsModel)(nil)
+
+// 批量操作类型，取值与 sdk.BulkAction 一致
+const (
+	BulkActionCancel = "cancel" // 取消待执行的任务
+	BulkActionRetry  = "retry"  // 重新执行失败或过期的任务
+	BulkActionDelete = "delete" // 删除不在执行中的任务
+
+	BulkActionReschedule = "reschedule" // 调整待执行任务的执行时间
+)
+
+// 批量任务状态
+const (
+	BulkJobStatusPending   = 0 // 待执行
+	BulkJobStatusRunning   = 1 // 执行中
+	BulkJobStatusCompleted = 2 // 已完成
+	BulkJobStatusFailed    = 3 // 失败
+	BulkJobStatusCancelled = 4 // 已取消
+)
+
+// bulkJobStatusNames 批量任务状态对应的 sdk.BulkJobStatus 取值
+var bulkJobStatusNames = map[int64]string{
+	BulkJobStatusPending:   "pending",
+	BulkJobStatusRunning:   "running",
+	BulkJobStatusCompleted: "completed",
+	BulkJobStatusFailed:    "failed",
+	BulkJobStatusCancelled: "cancelled",
+}
+
+// bulkActionStatuses 每种批量操作可以处理的任务状态
+var bulkActionStatuses = map[string][]int64{
+	BulkActionCancel: {TaskStatusPending},
+	BulkActionRetry:  {TaskStatusFailed, TaskStatusExpired},
+	BulkActionDelete: {TaskStatusPending, TaskStatusSucceeded, TaskStatusFailed, TaskStatusCancelled, TaskStatusExpired},
+
+	BulkActionReschedule: {TaskStatusPending},
+}
+
+// ErrBulkJobLeaseLost 批量任务的执行租约已过期并被其他节点接管
+var ErrBulkJobLeaseLost = errors.New("bulk job lease lost")
+
+// BulkJobStatusName 返回批量任务状态对应的 sdk.BulkJobStatus 取值
+func BulkJobStatusName(status int64) string {
+	return bulkJobStatusNames[status]
+}
+
+// BulkJobFilter 批量任务保存的过滤条件，字段与 sdk.ListTasksRequest 的过滤字段对应
+type BulkJobFilter struct {
+	Status      []int64    `json:"status,omitempty"`
+	Priority    *int64     `json:"priority,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	TagMatch    string     `json:"tag_match,omitempty"`
+	CreatedFrom *time.Time `json:"created_from,omitempty"`
+	CreatedTo   *time.Time `json:"created_to,omitempty"`
+	Search      string     `json:"search,omitempty"` // 搜索语法，见 pkg/search
+}
+
+// IsEmpty 判断过滤条件是否为空，空条件会匹配业务系统下的全部任务
+func (f *BulkJobFilter) IsEmpty() bool {
+	return len(f.Status) == 0 && f.Priority == nil && len(dedupeTags(f.Tags)) == 0 &&
+		f.CreatedFrom == nil && f.CreatedTo == nil && f.Search == ""
+}
+
+// ListFilter 转换为任务列表过滤条件，状态只保留 action 可以处理的部分
+// 过滤的状态与 action 没有交集时返回 nil，表示不会匹配任何任务
+func (f *BulkJobFilter) ListFilter(businessId int64, action string) (*TaskListFilter, error) {
+	eligible, ok := bulkActionStatuses[action]
+	if !ok {
+		return nil, fmt.Errorf("invalid bulk action: %s", action)
+	}
+	if f.TagMatch != "" && f.TagMatch != TagMatchAll && f.TagMatch != TagMatchAny {
+		return nil, fmt.Errorf("invalid tag match: %s", f.TagMatch)
+	}
+
+	statuses := eligible
+	if len(f.Status) > 0 {
+		allowed := make(map[int64]bool, len(eligible))
+		for _, status := range eligible {
+			allowed[status] = true
+		}
+		statuses = nil
+		for _, status := range f.Status {
+			if allowed[status] {
+				statuses = append(statuses, status)
+				allowed[status] = false
+			}
+		}
+		if len(statuses) == 0 {
+			return nil, nil
+		}
+	}
+
+	filter := &TaskListFilter{
+		BusinessId:  businessId,
+		Status:      statuses,
+		Priority:    f.Priority,
+		Tags:        f.Tags,
+		TagMatch:    f.TagMatch,
+		CreatedFrom: f.CreatedFrom,
+		CreatedTo:   f.CreatedTo,
+	}
+	if f.Search != "" {
+		q, err := search.Parse(f.Search)
+		if err != nil {
+			return nil, err
+		}
+		filter.Search = q
+	}
+	return filter, nil
+}
+
+type (
+	// BulkJobsModel is an interface to be customized, add more methods here,
+	// and implement the added methods in customBulkJobsModel.
+	BulkJobsModel interface {
+		bulkJobsModel
+		Create(ctx context.Context, businessId int64, action string, filter *BulkJobFilter, reschedule *TaskReschedule, dryRun bool) (*BulkJobs, error)
+		ClaimNext(ctx context.Context, nodeId string, lease time.Duration) (*BulkJobs, error)
+		SaveProgress(ctx context.Context, job *BulkJobs, lease time.Duration) (bool, error)
+		Finish(ctx context.Context, job *BulkJobs, status int64, errMsg string) error
+		RequestCancel(ctx context.Context, jobId string) (*BulkJobs, error)
+	}
+
+	customBulkJobsModel struct {
+		*defaultBulkJobsModel
+	}
+)
+
+// NewBulkJobsModel returns a model for the database table.
+func NewBulkJobsModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) BulkJobsModel {
+	return &customBulkJobsModel{
+		defaultBulkJobsModel: newBulkJobsModel(conn, c, opts...),
+	}
+}
+
+// Create 创建待执行的批量任务，由 BulkJobRunner 异步执行
+// 空过滤条件会匹配业务系统下的全部任务，为避免误操作直接拒绝
+// reschedule 操作需要改期参数，试运行时可以为空
+func (m *customBulkJobsModel) Create(ctx context.Context, businessId int64, action string, filter *BulkJobFilter, reschedule *TaskReschedule, dryRun bool) (*BulkJobs, error) {
+	if businessId <= 0 {
+		return nil, fmt.Errorf("business id is required")
+	}
+	if filter == nil || filter.IsEmpty() {
+		return nil, fmt.Errorf("bulk job filter cannot be empty")
+	}
+	if _, err := filter.ListFilter(businessId, action); err != nil {
+		return nil, err
+	}
+
+	var params sql.NullString
+	if action == BulkActionReschedule && reschedule == nil && !dryRun {
+		return nil, fmt.Errorf("reschedule is required for the reschedule action")
+	}
+	if reschedule != nil {
+		if action != BulkActionReschedule {
+			return nil, fmt.Errorf("reschedule is only allowed for the reschedule action")
+		}
+		if err := reschedule.Validate(); err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(reschedule)
+		if err != nil {
+			return nil, err
+		}
+		params = sql.NullString{String: string(data), Valid: true}
+	}
+
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &BulkJobs{
+		JobId:      newBulkJobId(),
+		BusinessId: businessId,
+		Action:     action,
+		Filter:     string(data),
+		Params:     params,
+		Status:     BulkJobStatusPending,
+	}
+	if dryRun {
+		job.DryRun = 1
+	}
+	if _, err := m.Insert(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// 清理按 job_id 缓存的“不存在”占位
+	if err := m.DelCacheCtx(ctx, fmt.Sprintf("%s%v", cacheBulkJobsJobIdPrefix, job.JobId)); err != nil {
+		return nil, err
+	}
+	return m.FindOneByJobId(ctx, job.JobId)
+}
+
+// ClaimNext 领取一个待执行或租约已过期的批量任务，没有可领取的任务时返回 ErrNotFound
+func (m *customBulkJobsModel) ClaimNext(ctx context.Context, nodeId string, lease time.Duration) (*BulkJobs, error) {
+	now := time.Now()
+
+	var candidates []int64
+	query := fmt.Sprintf("select `id` from %s where `status` in (?, ?) and (`locked_until` is null or `locked_until` < ?) order by `id` limit 10", m.table)
+	if err := m.QueryRowsNoCacheCtx(ctx, &candidates, query, BulkJobStatusPending, BulkJobStatusRunning, now); err != nil {
+		return nil, err
+	}
+
+	for _, id := range candidates {
+		job, err := m.FindOne(ctx, id)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		// 多个节点竞争同一任务时，只有条件更新成功的节点取得租约
+		result, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+			query := fmt.Sprintf("update %s set `status` = ?, `locked_by` = ?, `locked_until` = ?, `started_at` = coalesce(`started_at`, ?) "+
+				"where `id` = ? and `status` in (?, ?) and (`locked_until` is null or `locked_until` < ?)", m.table)
+			return conn.ExecCtx(ctx, query, BulkJobStatusRunning, nodeId, now.Add(lease), now,
+				id, BulkJobStatusPending, BulkJobStatusRunning, now)
+		}, m.jobCacheKeys(job)...)
+		if err != nil {
+			return nil, err
+		}
+		if affected, err := result.RowsAffected(); err != nil {
+			return nil, err
+		} else if affected == 1 {
+			return m.FindOne(ctx, id)
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// SaveProgress 保存执行进度并续约，返回是否已请求取消
+// 租约已被其他节点接管时返回 ErrBulkJobLeaseLost，调用方应停止执行
+func (m *customBulkJobsModel) SaveProgress(ctx context.Context, job *BulkJobs, lease time.Duration) (bool, error) {
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		query := fmt.Sprintf("update %s set `total` = ?, `processed` = ?, `succeeded` = ?, `skipped` = ?, `failed` = ?, "+
+			"`last_cursor` = ?, `error_message` = ?, `locked_until` = ? where `id` = ? and `locked_by` = ? and `status` = ?", m.table)
+		return conn.ExecCtx(ctx, query, job.Total, job.Processed, job.Succeeded, job.Skipped, job.Failed,
+			job.LastCursor, job.ErrorMessage, time.Now().Add(lease), job.Id, job.LockedBy, BulkJobStatusRunning)
+	}, m.jobCacheKeys(job)...)
+	if err != nil {
+		return false, err
+	}
+
+	// 进度和租约时间可能与上次完全相同，不能依赖影响行数判断是否仍持有租约
+	var current struct {
+		Status          int64          `db:"status"`
+		LockedBy        sql.NullString `db:"locked_by"`
+		CancelRequested int64          `db:"cancel_requested"`
+	}
+	query := fmt.Sprintf("select `status`, `locked_by`, `cancel_requested` from %s where `id` = ? limit 1", m.table)
+	if err := m.QueryRowNoCacheCtx(ctx, &current, query, job.Id); err != nil {
+		return false, err
+	}
+	if current.Status != BulkJobStatusRunning || current.LockedBy != job.LockedBy {
+		return false, ErrBulkJobLeaseLost
+	}
+	return current.CancelRequested == 1, nil
+}
+
+// Finish 保存最终进度并结束批量任务，释放租约
+func (m *customBulkJobsModel) Finish(ctx context.Context, job *BulkJobs, status int64, errMsg string) error {
+	if errMsg != "" {
+		job.ErrorMessage = sql.NullString{String: errMsg, Valid: true}
+	}
+
+	result, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		query := fmt.Sprintf("update %s set `status` = ?, `total` = ?, `processed` = ?, `succeeded` = ?, `skipped` = ?, `failed` = ?, "+
+			"`last_cursor` = ?, `error_message` = ?, `locked_by` = null, `locked_until` = null, `completed_at` = ? "+
+			"where `id` = ? and `locked_by` = ? and `status` = ?", m.table)
+		return conn.ExecCtx(ctx, query, status, job.Total, job.Processed, job.Succeeded, job.Skipped, job.Failed,
+			job.LastCursor, job.ErrorMessage, time.Now(), job.Id, job.LockedBy, BulkJobStatusRunning)
+	}, m.jobCacheKeys(job)...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrBulkJobLeaseLost
+	}
+	return nil
+}
+
+// RequestCancel 请求取消批量任务，待执行的任务直接取消，执行中的任务在下一批之前停止
+// 已结束的任务不做修改，返回当前状态
+func (m *customBulkJobsModel) RequestCancel(ctx context.Context, jobId string) (*BulkJobs, error) {
+	job, err := m.FindOneByJobId(ctx, jobId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		// completed_at 必须在 status 之前赋值，MySQL 按顺序计算 set 子句
+		query := fmt.Sprintf("update %s set `cancel_requested` = 1, `completed_at` = if(`status` = ?, ?, `completed_at`), "+
+			"`status` = if(`status` = ?, ?, `status`) where `id` = ? and `status` in (?, ?)", m.table)
+		return conn.ExecCtx(ctx, query, BulkJobStatusPending, time.Now(), BulkJobStatusPending, BulkJobStatusCancelled,
+			job.Id, BulkJobStatusPending, BulkJobStatusRunning)
+	}, m.jobCacheKeys(job)...)
+	if err != nil {
+		return nil, err
+	}
+	return m.FindOne(ctx, job.Id)
+}
+
+// jobCacheKeys 返回批量任务的缓存键
+func (m *customBulkJobsModel) jobCacheKeys(job *BulkJobs) []string {
+	return []string{
+		fmt.Sprintf("%s%v", cacheBulkJobsIdPrefix, job.Id),
+		fmt.Sprintf("%s%v", cacheBulkJobsJobIdPrefix, job.JobId),
+	}
+}
+
+// newBulkJobId 生成对外暴露的批量任务ID
+func newBulkJobId() string {
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+	return "bulk_" + hex.EncodeToString(buf)
+}
@@ -1,12 +1,26 @@
 package model
 
 import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
+
+	"task-center/pkg/signing"
 )
 
 var _ BusinessSystemsModel = (*customBusinessSystemsModel)(nil)
 
+// 业务系统状态
+const (
+	BusinessStatusDisabled    = 0 // 禁用
+	BusinessStatusEnabled     = 1 // 启用
+	BusinessStatusMaintenance = 2 // 维护中
+)
+
 type (
 	// BusinessSystemsModel is an interface to be customized, add more methods here,
 	// and implement the added methods in customBusinessSystemsModel.
@@ -25,3 +39,49 @@ func NewBusinessSystemsModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache
 		defaultBusinessSystemsModel: newBusinessSystemsModel(conn, c, opts...),
 	}
 }
+
+// BusinessResolver 确定请求所属的业务系统ID，必须由已认证的凭证得出，不能直接使用客户端传入的 X-Business-ID
+type BusinessResolver func(r *http.Request) (int64, error)
+
+// BusinessFromAPIKey 按 Authorization 中的 API Key 确定请求所属的业务系统，校验规则与 BusinessSecretLookup 一致
+func BusinessFromAPIKey(m BusinessSystemsModel) BusinessResolver {
+	return func(r *http.Request) (int64, error) {
+		business, err := businessByAPIKey(m, r)
+		if err != nil {
+			return 0, err
+		}
+		return business.Id, nil
+	}
+}
+
+// BusinessSecretLookup 按 Authorization 中的 API Key 查找业务系统的 api_secret，供 signing.SignatureVerifier 校验请求签名
+// 业务系统未启用，或 X-Business-ID 与 API Key 所属业务系统不一致时拒绝请求
+func BusinessSecretLookup(m BusinessSystemsModel) signing.SecretLookup {
+	return func(r *http.Request) (string, error) {
+		business, err := businessByAPIKey(m, r)
+		if err != nil {
+			return "", err
+		}
+		return business.ApiSecret, nil
+	}
+}
+
+// businessByAPIKey 查找 API Key 所属的已启用业务系统，X-Business-ID 与之不一致时返回错误
+func businessByAPIKey(m BusinessSystemsModel, r *http.Request) (*BusinessSystems, error) {
+	apiKey, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || apiKey == "" {
+		return nil, fmt.Errorf("missing API key")
+	}
+
+	business, err := m.FindOneByApiKey(r.Context(), apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("unknown API key")
+	}
+	if business.Status != BusinessStatusEnabled {
+		return nil, fmt.Errorf("business system is not enabled")
+	}
+	if id := r.Header.Get("X-Business-ID"); id != "" && id != strconv.FormatInt(business.Id, 10) {
+		return nil, fmt.Errorf("business ID does not match API key")
+	}
+	return business, nil
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+
+	"task-center/pkg/event"
+)
+
+var _ EventSubscriptionsModel = (*customEventSubscriptionsModel)(nil)
+
+// 事件订阅状态
+const (
+	EventSubscriptionStatusDisabled = 0 // 停用
+	EventSubscriptionStatusEnabled  = 1 // 启用
+)
+
+type (
+	// EventSubscriptionsModel is an interface to be customized, add more methods here,
+	// and implement the added methods in customEventSubscriptionsModel.
+	EventSubscriptionsModel interface {
+		eventSubscriptionsModel
+		ListEnabledByBusiness(ctx context.Context, businessId int64) ([]*EventSubscriptions, error)
+	}
+
+	customEventSubscriptionsModel struct {
+		*defaultEventSubscriptionsModel
+	}
+)
+
+// NewEventSubscriptionsModel returns a model for the database table.
+func NewEventSubscriptionsModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) EventSubscriptionsModel {
+	return &customEventSubscriptionsModel{
+		defaultEventSubscriptionsModel: newEventSubscriptionsModel(conn, c, opts...),
+	}
+}
+
+// ListEnabledByBusiness 返回业务系统下启用的事件订阅
+func (m *customEventSubscriptionsModel) ListEnabledByBusiness(ctx context.Context, businessId int64) ([]*EventSubscriptions, error) {
+	var subscriptions []*EventSubscriptions
+	query := fmt.Sprintf("select %s from %s where `business_id` = ? and `status` = ? order by `id`", eventSubscriptionsRows, m.table)
+	if err := m.QueryRowsNoCacheCtx(ctx, &subscriptions, query, businessId, EventSubscriptionStatusEnabled); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// EncodeEventTypes 校验并编码订阅的事件类型，只接受 event.AllTypes 中的类型
+func EncodeEventTypes(eventTypes []string) (string, error) {
+	if len(eventTypes) == 0 {
+		return "", fmt.Errorf("event types must not be empty")
+	}
+
+	seen := make(map[string]bool, len(eventTypes))
+	types := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !event.IsValidType(eventType) {
+			return "", fmt.Errorf("unknown event type: %s", eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			types = append(types, eventType)
+		}
+	}
+
+	b, err := json.Marshal(types)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// EventTypeList 解析订阅的事件类型，格式错误时返回空
+func (s *EventSubscriptions) EventTypeList() []string {
+	var types []string
+	if err := json.Unmarshal([]byte(s.EventTypes), &types); err != nil {
+		return nil
+	}
+	return types
+}
+
+// Subscribes 判断订阅是否启用且包含指定事件类型
+func (s *EventSubscriptions) Subscribes(eventType string) bool {
+	if s.Status != EventSubscriptionStatusEnabled {
+		return false
+	}
+	for _, t := range s.EventTypeList() {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,295 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 幂等相关请求头，HeaderIdempotencyKey 与 sdk.HeaderIdempotencyKey 一致
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// CodeIdempotencyInProgress 同一幂等键的原请求仍在处理，与 sdk.CodeIdempotencyInProgress 一致
+// 使用 503 返回，SDK 按 Retry-After 等待后重试；不使用 CONFLICT_ERROR，避免被当作业务唯一ID冲突
+const CodeIdempotencyInProgress = "IDEMPOTENCY_IN_PROGRESS"
+
+// HeaderCreateOutcome 创建任务的结果响应头，与 sdk.CreateOutcomeHeader 一致
+const HeaderCreateOutcome = "X-TaskCenter-Create-Outcome"
+
+// IdempotencyConfig 幂等键配置
+type IdempotencyConfig struct {
+	TTL               time.Duration // 原始响应的保留时长，过期后同一幂等键视为新请求
+	ProcessingTimeout time.Duration // 处理中状态的租约时长，处理期间每 1/3 租约续期一次，超过后未续期视为请求已中断，允许重新占用
+	MaxKeyLength      int           // 幂等键最大长度
+	MaxBodySize       int64         // 参与请求摘要的最大请求体大小
+	MaxResponseSize   int           // 可保存的最大响应体大小，超过时不保存响应
+	ReplayHeaders     []string      // 除 Content-Type 外随响应保存并重放的响应头
+}
+
+// DefaultIdempotencyConfig 默认幂等键配置
+func DefaultIdempotencyConfig() *IdempotencyConfig {
+	return &IdempotencyConfig{
+		TTL:               24 * time.Hour,
+		ProcessingTimeout: time.Minute,
+		MaxKeyLength:      128,
+		MaxBodySize:       1024 * 1024, // 1MB
+		MaxResponseSize:   1024 * 1024, // 1MB
+		ReplayHeaders:     []string{HeaderCreateOutcome, "Location"},
+	}
+}
+
+// IdempotencyMiddleware 为带 Idempotency-Key 的写请求保存原始响应，重复请求直接返回该响应
+// 用于创建、批量创建、取消、重试和更新等接口，避免客户端超时重试导致重复执行
+type IdempotencyMiddleware struct {
+	keys     IdempotencyKeysModel
+	business BusinessResolver
+	config   *IdempotencyConfig
+}
+
+// NewIdempotencyMiddleware 创建幂等键中间件，幂等键按 business 确定的业务系统隔离，
+// 通常为 BusinessFromAPIKey，避免一个业务系统通过 X-Business-ID 读取另一个业务系统保存的响应
+func NewIdempotencyMiddleware(keys IdempotencyKeysModel, business BusinessResolver, config *IdempotencyConfig) *IdempotencyMiddleware {
+	if config == nil {
+		config = DefaultIdempotencyConfig()
+	}
+	return &IdempotencyMiddleware{keys: keys, business: business, config: config}
+}
+
+// Handle 幂等键中间件，签名与 go-zero 的 rest.Middleware 一致，应放在认证之后
+// 同一幂等键的请求在处理中时返回 503 CodeIdempotencyInProgress，请求内容不同时返回 422；
+// 5xx 响应不保存，客户端可以使用同一幂等键重试
+func (m *IdempotencyMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(HeaderIdempotencyKey)
+		if key == "" || !isMutatingMethod(r.Method) {
+			next(w, r)
+			return
+		}
+		if len(key) > m.config.MaxKeyLength {
+			writeIdempotencyError(w, http.StatusBadRequest, "VALIDATION_ERROR",
+				fmt.Sprintf("%s must not exceed %d characters", HeaderIdempotencyKey, m.config.MaxKeyLength))
+			return
+		}
+		businessId, err := m.business(r)
+		if err != nil {
+			writeIdempotencyError(w, http.StatusUnauthorized, "AUTHENTICATION_ERROR", err.Error())
+			return
+		}
+
+		hash, err := m.requestHash(r)
+		if err != nil {
+			writeIdempotencyError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+
+		ctx := r.Context()
+		record := &IdempotencyKeys{
+			BusinessId:     businessId,
+			IdempotencyKey: key,
+			RequestHash:    hash,
+			ExpiresAt:      time.Now().Add(m.config.ProcessingTimeout),
+		}
+		existing, err := m.keys.Claim(ctx, record)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("claim idempotency key %q: %v", key, err)
+			writeIdempotencyError(w, http.StatusInternalServerError, "SERVER_ERROR", "failed to check idempotency key")
+			return
+		}
+		if existing != nil {
+			m.replay(w, existing, hash)
+			return
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK, limit: m.config.MaxResponseSize}
+		completed := false
+		defer func() {
+			// 客户端断开连接不影响保存结果
+			ctx := context.WithoutCancel(ctx)
+			if completed {
+				return
+			}
+			if err := m.keys.Release(ctx, record); err != nil {
+				logx.WithContext(ctx).Errorf("release idempotency key %q: %v", key, err)
+			}
+		}()
+
+		stopRenew := m.renew(ctx, *record)
+		next(recorder, r)
+		stopRenew()
+
+		if recorder.status >= http.StatusInternalServerError || recorder.overflow {
+			return
+		}
+		record.StatusCode = int64(recorder.status)
+		record.ContentType = recorder.Header().Get("Content-Type")
+		record.ResponseHeaders = m.replayHeaders(recorder.Header())
+		record.ResponseBody = sql.NullString{String: recorder.body.String(), Valid: true}
+		record.ExpiresAt = time.Now().Add(m.config.TTL)
+		if err := m.keys.Complete(context.WithoutCancel(ctx), record); err != nil {
+			logx.WithContext(ctx).Errorf("complete idempotency key %q: %v", key, err)
+			return
+		}
+		completed = true
+	}
+}
+
+// renew 在请求处理期间定期续期处理中的幂等键，返回的函数停止续期并等待续期协程退出
+func (m *IdempotencyMiddleware) renew(ctx context.Context, record IdempotencyKeys) func() {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(m.config.ProcessingTimeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				record.ExpiresAt = time.Now().Add(m.config.ProcessingTimeout)
+				if err := m.keys.Renew(ctx, &record); err != nil && ctx.Err() == nil {
+					logx.WithContext(ctx).Errorf("renew idempotency key %q: %v", record.IdempotencyKey, err)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// replay 返回已保存的响应和 ReplayHeaders 中的响应头，请求内容不同或原请求仍在处理时返回错误
+func (m *IdempotencyMiddleware) replay(w http.ResponseWriter, existing *IdempotencyKeys, hash string) {
+	if existing.RequestHash != hash {
+		writeIdempotencyError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR",
+			fmt.Sprintf("%s was already used with a different request", HeaderIdempotencyKey))
+		return
+	}
+	if existing.Status != IdempotencyKeyStatusCompleted {
+		w.Header().Set("Retry-After", "1")
+		writeIdempotencyError(w, http.StatusServiceUnavailable, CodeIdempotencyInProgress,
+			fmt.Sprintf("a request with the same %s is still being processed", HeaderIdempotencyKey))
+		return
+	}
+
+	if existing.ContentType != "" {
+		w.Header().Set("Content-Type", existing.ContentType)
+	}
+	if existing.ResponseHeaders.Valid {
+		var header http.Header
+		if err := json.Unmarshal([]byte(existing.ResponseHeaders.String), &header); err == nil {
+			for name, values := range header {
+				w.Header()[http.CanonicalHeaderKey(name)] = values
+			}
+		}
+	}
+	w.Header().Set(HeaderIdempotentReplayed, "true")
+	w.WriteHeader(int(existing.StatusCode))
+	io.WriteString(w, existing.ResponseBody.String)
+}
+
+// replayHeaders 返回需要随响应保存的响应头，编码为 JSON 对象，没有时为 NULL
+func (m *IdempotencyMiddleware) replayHeaders(header http.Header) sql.NullString {
+	saved := make(http.Header)
+	for _, name := range m.config.ReplayHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			saved[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	if len(saved) == 0 {
+		return sql.NullString{}
+	}
+	data, _ := json.Marshal(saved)
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+// requestHash 计算请求摘要：方法、路径、排序后的查询参数和请求体，读取后恢复 r.Body
+func (m *IdempotencyMiddleware) requestHash(r *http.Request) (string, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(r.Body, m.config.MaxBodySize+1))
+		r.Body.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read request body: %w", err)
+		}
+		if int64(len(body)) > m.config.MaxBodySize {
+			return "", fmt.Errorf("request body exceeds %d bytes", m.config.MaxBodySize)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	query := r.URL.RawQuery
+	if values, err := url.ParseQuery(query); err == nil {
+		query = values.Encode()
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", r.Method, r.URL.EscapedPath(), query)
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// isMutatingMethod 只有写请求需要幂等保护
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// writeIdempotencyError 按 API 错误格式返回错误
+func writeIdempotencyError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"message": message,
+		"code":    code,
+	})
+}
+
+// idempotencyRecorder 在写出响应的同时记录状态码和响应体
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	limit       int
+	overflow    bool
+}
+
+func (r *idempotencyRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *idempotencyRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	if !r.overflow {
+		if r.body.Len()+len(p) > r.limit {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(p)
+		}
+	}
+	return r.ResponseWriter.Write(p)
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+var _ IdempotencyKeysModel = (*customIdempotencyKeysModel)(nil)
+
+// 幂等键状态
+const (
+	IdempotencyKeyStatusProcessing = 0 // 处理中
+	IdempotencyKeyStatusCompleted  = 1 // 已完成，保存了原始响应
+)
+
+type (
+	// IdempotencyKeysModel is an interface to be customized, add more methods here,
+	// and implement the added methods in customIdempotencyKeysModel.
+	IdempotencyKeysModel interface {
+		idempotencyKeysModel
+		Claim(ctx context.Context, data *IdempotencyKeys) (*IdempotencyKeys, error)
+		Renew(ctx context.Context, data *IdempotencyKeys) error
+		Complete(ctx context.Context, data *IdempotencyKeys) error
+		Release(ctx context.Context, data *IdempotencyKeys) error
+		DeleteExpired(ctx context.Context, before time.Time, limit int) (int64, error)
+	}
+
+	customIdempotencyKeysModel struct {
+		*defaultIdempotencyKeysModel
+	}
+)
+
+// NewIdempotencyKeysModel returns a model for the database table.
+func NewIdempotencyKeysModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) IdempotencyKeysModel {
+	return &customIdempotencyKeysModel{
+		defaultIdempotencyKeysModel: newIdempotencyKeysModel(conn, c, opts...),
+	}
+}
+
+// Claim 以处理中状态占用幂等键，成功时回填 data.Id 并返回 nil, nil
+// 幂等键已被占用时返回已有记录；已有记录过期时删除后重新占用
+func (m *customIdempotencyKeysModel) Claim(ctx context.Context, data *IdempotencyKeys) (*IdempotencyKeys, error) {
+	data.Status = IdempotencyKeyStatusProcessing
+	for i := 0; i < 2; i++ {
+		result, err := m.Insert(ctx, data)
+		if err == nil {
+			data.Id, err = result.LastInsertId()
+			return nil, err
+		}
+		if !isDuplicateEntry(err) {
+			return nil, err
+		}
+
+		// 记录状态会变化，不读缓存
+		var existing IdempotencyKeys
+		query := fmt.Sprintf("select %s from %s where `business_id` = ? and `idempotency_key` = ? limit 1", idempotencyKeysRows, m.table)
+		switch err := m.QueryRowNoCacheCtx(ctx, &existing, query, data.BusinessId, data.IdempotencyKey); err {
+		case nil:
+		case sqlx.ErrNotFound:
+			// 已有记录在查询前被释放，重新占用
+			continue
+		default:
+			return nil, err
+		}
+		if existing.ExpiresAt.After(time.Now()) {
+			return &existing, nil
+		}
+		if err := m.deleteIfExpired(ctx, &existing); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("idempotency key %q is being claimed concurrently", data.IdempotencyKey)
+}
+
+// Renew 延长处理中幂等键的 expires_at，请求处理期间定期调用，避免慢请求未结束时被其他请求重新占用
+func (m *customIdempotencyKeysModel) Renew(ctx context.Context, data *IdempotencyKeys) error {
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		query := fmt.Sprintf("update %s set `expires_at` = ? where `id` = ? and `status` = ?", m.table)
+		return conn.ExecCtx(ctx, query, data.ExpiresAt, data.Id, IdempotencyKeyStatusProcessing)
+	}, m.cacheKeys(data)...)
+	return err
+}
+
+// Complete 保存原始响应并标记为已完成，expires_at 更新为响应的保留期限
+func (m *customIdempotencyKeysModel) Complete(ctx context.Context, data *IdempotencyKeys) error {
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		query := fmt.Sprintf("update %s set `status` = ?, `status_code` = ?, `content_type` = ?, `response_headers` = ?, `response_body` = ?, `expires_at` = ? "+
+			"where `id` = ? and `status` = ?", m.table)
+		return conn.ExecCtx(ctx, query, IdempotencyKeyStatusCompleted, data.StatusCode, data.ContentType, data.ResponseHeaders, data.ResponseBody, data.ExpiresAt,
+			data.Id, IdempotencyKeyStatusProcessing)
+	}, m.cacheKeys(data)...)
+	if err == nil {
+		data.Status = IdempotencyKeyStatusCompleted
+	}
+	return err
+}
+
+// Release 删除处理中的幂等键，请求未产生可重放的响应时调用，客户端可以使用同一幂等键重试
+func (m *customIdempotencyKeysModel) Release(ctx context.Context, data *IdempotencyKeys) error {
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		query := fmt.Sprintf("delete from %s where `id` = ? and `status` = ?", m.table)
+		return conn.ExecCtx(ctx, query, data.Id, IdempotencyKeyStatusProcessing)
+	}, m.cacheKeys(data)...)
+	return err
+}
+
+// DeleteExpired 删除 before 之前过期的幂等键，每次最多删除 limit 条，返回删除的条数
+func (m *customIdempotencyKeysModel) DeleteExpired(ctx context.Context, before time.Time, limit int) (int64, error) {
+	query := fmt.Sprintf("delete from %s where `expires_at` <= ? limit ?", m.table)
+	result, err := m.ExecNoCacheCtx(ctx, query, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// deleteIfExpired 删除已过期的记录，过期时间已被其他请求更新时不删除
+func (m *customIdempotencyKeysModel) deleteIfExpired(ctx context.Context, data *IdempotencyKeys) error {
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		query := fmt.Sprintf("delete from %s where `id` = ? and `expires_at` <= ?", m.table)
+		return conn.ExecCtx(ctx, query, data.Id, time.Now())
+	}, m.cacheKeys(data)...)
+	return err
+}
+
+// cacheKeys 记录对应的缓存键
+func (m *customIdempotencyKeysModel) cacheKeys(data *IdempotencyKeys) []string {
+	return []string{
+		fmt.Sprintf("%s%v:%v", cacheIdempotencyKeysBusinessIdIdempotencyKeyPrefix, data.BusinessId, data.IdempotencyKey),
+		fmt.Sprintf("%s%v", cacheIdempotencyKeysIdPrefix, data.Id),
+	}
+}
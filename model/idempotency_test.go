@@ -0,0 +1,217 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeIdempotencyKeys 内存中的幂等键，只实现中间件用到的方法
+type fakeIdempotencyKeys struct {
+	IdempotencyKeysModel
+
+	mu      sync.Mutex
+	nextId  int64
+	records map[string]*IdempotencyKeys
+	renewed chan struct{}
+}
+
+func newFakeIdempotencyKeys() *fakeIdempotencyKeys {
+	return &fakeIdempotencyKeys{records: make(map[string]*IdempotencyKeys), renewed: make(chan struct{}, 16)}
+}
+
+func (f *fakeIdempotencyKeys) key(data *IdempotencyKeys) string {
+	return fmt.Sprintf("%d:%s", data.BusinessId, data.IdempotencyKey)
+}
+
+func (f *fakeIdempotencyKeys) Claim(_ context.Context, data *IdempotencyKeys) (*IdempotencyKeys, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.records[f.key(data)]; ok && existing.ExpiresAt.After(time.Now()) {
+		copied := *existing
+		return &copied, nil
+	}
+	f.nextId++
+	data.Id = f.nextId
+	data.Status = IdempotencyKeyStatusProcessing
+	copied := *data
+	f.records[f.key(data)] = &copied
+	return nil, nil
+}
+
+func (f *fakeIdempotencyKeys) Renew(_ context.Context, data *IdempotencyKeys) error {
+	f.mu.Lock()
+	if existing, ok := f.records[f.key(data)]; ok && existing.Id == data.Id && existing.Status == IdempotencyKeyStatusProcessing {
+		existing.ExpiresAt = data.ExpiresAt
+	}
+	f.mu.Unlock()
+	select {
+	case f.renewed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (f *fakeIdempotencyKeys) Complete(_ context.Context, data *IdempotencyKeys) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data.Status = IdempotencyKeyStatusCompleted
+	copied := *data
+	f.records[f.key(data)] = &copied
+	return nil
+}
+
+func (f *fakeIdempotencyKeys) Release(_ context.Context, data *IdempotencyKeys) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.records[f.key(data)]; ok && existing.Id == data.Id {
+		delete(f.records, f.key(data))
+	}
+	return nil
+}
+
+// fakeBusinessSystems 按 API Key 查找业务系统
+type fakeBusinessSystems struct {
+	BusinessSystemsModel
+	byKey map[string]*BusinessSystems
+}
+
+func (f *fakeBusinessSystems) FindOneByApiKey(_ context.Context, apiKey string) (*BusinessSystems, error) {
+	if b, ok := f.byKey[apiKey]; ok {
+		return b, nil
+	}
+	return nil, ErrNotFound
+}
+
+func newTestBusinessResolver() BusinessResolver {
+	return BusinessFromAPIKey(&fakeBusinessSystems{byKey: map[string]*BusinessSystems{
+		"key-1": {Id: 1, Status: BusinessStatusEnabled},
+		"key-2": {Id: 2, Status: BusinessStatusEnabled},
+	}})
+}
+
+func newIdempotentRequest(apiKey, businessId, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+apiKey)
+	if businessId != "" {
+		r.Header.Set("X-Business-ID", businessId)
+	}
+	r.Header.Set(HeaderIdempotencyKey, "create-1")
+	return r
+}
+
+func TestIdempotencyMiddleware_ScopedByAPIKey(t *testing.T) {
+	calls := 0
+	handler := NewIdempotencyMiddleware(newFakeIdempotencyKeys(), newTestBusinessResolver(), nil).Handle(
+		func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			body, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, "%s-%d", body, calls)
+		})
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		businessId string
+		wantStatus int
+		wantBody   string
+		replayed   bool
+	}{
+		{name: "first request runs the handler", apiKey: "key-1", wantStatus: http.StatusOK, wantBody: "a-1"},
+		{name: "same business replays", apiKey: "key-1", businessId: "1", wantStatus: http.StatusOK, wantBody: "a-1", replayed: true},
+		{name: "forged business id is rejected", apiKey: "key-2", businessId: "1", wantStatus: http.StatusUnauthorized},
+		{name: "unknown API key is rejected", apiKey: "key-3", wantStatus: http.StatusUnauthorized},
+		{name: "other business has its own scope", apiKey: "key-2", wantStatus: http.StatusOK, wantBody: "a-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler(w, newIdempotentRequest(tt.apiKey, tt.businessId, "a"))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if got := w.Header().Get(HeaderIdempotentReplayed) == "true"; got != tt.replayed {
+				t.Errorf("replayed = %v, want %v", got, tt.replayed)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_RenewsWhileProcessing(t *testing.T) {
+	keys := newFakeIdempotencyKeys()
+	config := DefaultIdempotencyConfig()
+	config.ProcessingTimeout = 30 * time.Millisecond
+	release := make(chan struct{})
+	handler := NewIdempotencyMiddleware(keys, newTestBusinessResolver(), config).Handle(
+		func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			io.WriteString(w, "done")
+		})
+
+	first := httptest.NewRecorder()
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		handler(first, newIdempotentRequest("key-1", "", "a"))
+	}()
+
+	// 续期多次后已超过最初的租约，处理中的幂等键仍不能被重新占用
+	for i := 0; i < 3; i++ {
+		select {
+		case <-keys.renewed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for lease renewal")
+		}
+	}
+	second := httptest.NewRecorder()
+	handler(second, newIdempotentRequest("key-1", "", "a"))
+	if second.Code != http.StatusServiceUnavailable || second.Header().Get("Retry-After") == "" {
+		t.Errorf("status = %d, want %d with Retry-After", second.Code, http.StatusServiceUnavailable)
+	}
+	if !strings.Contains(second.Body.String(), CodeIdempotencyInProgress) {
+		t.Errorf("body = %s, want code %s", second.Body.String(), CodeIdempotencyInProgress)
+	}
+
+	close(release)
+	<-finished
+	if first.Code != http.StatusOK || first.Body.String() != "done" {
+		t.Errorf("unexpected first response: %d %q", first.Code, first.Body.String())
+	}
+}
+
+func TestIdempotencyMiddleware_ReplaysAllowedHeaders(t *testing.T) {
+	handler := NewIdempotencyMiddleware(newFakeIdempotencyKeys(), newTestBusinessResolver(), nil).Handle(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(HeaderCreateOutcome, "replaced")
+			w.Header().Set("X-Request-Id", "req-1")
+			io.WriteString(w, "{}")
+		})
+
+	handler(httptest.NewRecorder(), newIdempotentRequest("key-1", "", "a"))
+	replayed := httptest.NewRecorder()
+	handler(replayed, newIdempotentRequest("key-1", "", "a"))
+
+	if replayed.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Fatal("expected a replayed response")
+	}
+	// 创建结果随响应重放，SDK 才能区分 replaced 与 existing；不在白名单中的响应头不保存
+	if got := replayed.Header().Get(HeaderCreateOutcome); got != "replaced" {
+		t.Errorf("%s = %q, want replaced", HeaderCreateOutcome, got)
+	}
+	if got := replayed.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := replayed.Header().Get("X-Request-Id"); got != "" {
+		t.Errorf("X-Request-Id = %q, want it not to be replayed", got)
+	}
+}
@@ -1,21 +1,44 @@
 package model
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 )
 
 var _ MigrationLocksModel = (*customMigrationLocksModel)(nil)
 
+// migrationLocksTableDDL 迁移锁表结构，与 core_tables_no_fk.sql 保持一致
+// 迁移锁必须在执行迁移之前可用，所以该表不在迁移文件中创建
+const migrationLocksTableDDL = "CREATE TABLE IF NOT EXISTS `migration_locks` (" +
+	"`id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID'," +
+	"`lock_name` varchar(64) NOT NULL COMMENT '锁名称'," +
+	"`locked_by` varchar(128) NOT NULL COMMENT '持有锁的进程标识'," +
+	"`locked_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '加锁时间'," +
+	"`expires_at` timestamp NOT NULL COMMENT '锁过期时间'," +
+	"PRIMARY KEY (`id`)," +
+	"UNIQUE KEY `uk_lock_name` (`lock_name`)," +
+	"KEY `idx_expires_at` (`expires_at`)" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci " +
+	"COMMENT='迁移执行锁表，防止并发执行迁移导致数据不一致'"
+
 type (
 	// MigrationLocksModel is an interface to be customized, add more methods here,
 	// and implement the added methods in customMigrationLocksModel.
 	MigrationLocksModel interface {
 		migrationLocksModel
+		EnsureTable(ctx context.Context) error
+		Acquire(ctx context.Context, lockName, lockedBy string, lease time.Duration) (bool, error)
+		Renew(ctx context.Context, lockName, lockedBy string, lease time.Duration) (bool, error)
+		Release(ctx context.Context, lockName, lockedBy string) error
 	}
 
 	customMigrationLocksModel struct {
 		*defaultMigrationLocksModel
+		store *MigrationLockStore
 	}
 )
 
@@ -23,5 +46,43 @@ type (
 func NewMigrationLocksModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) MigrationLocksModel {
 	return &customMigrationLocksModel{
 		defaultMigrationLocksModel: newMigrationLocksModel(conn, c, opts...),
+		store:                      NewMigrationLockStore(conn),
+	}
+}
+
+// EnsureTable 创建迁移锁表（如果不存在）
+func (m *customMigrationLocksModel) EnsureTable(ctx context.Context) error {
+	return m.store.EnsureTable(ctx)
+}
+
+// Acquire 尝试获取锁，规则见 MigrationLockStore.Acquire
+func (m *customMigrationLocksModel) Acquire(ctx context.Context, lockName, lockedBy string, lease time.Duration) (bool, error) {
+	defer m.delLockCache(ctx, lockName)
+	return m.store.Acquire(ctx, lockName, lockedBy, lease)
+}
+
+// Renew 延长 lockedBy 持有的锁，锁已被其他节点接管时返回 false
+func (m *customMigrationLocksModel) Renew(ctx context.Context, lockName, lockedBy string, lease time.Duration) (bool, error) {
+	defer m.delLockCache(ctx, lockName)
+	return m.store.Renew(ctx, lockName, lockedBy, lease)
+}
+
+// Release 释放 lockedBy 持有的锁，锁已被其他节点接管时不做任何操作
+func (m *customMigrationLocksModel) Release(ctx context.Context, lockName, lockedBy string) error {
+	defer m.delLockCache(ctx, lockName)
+	return m.store.Release(ctx, lockName, lockedBy)
+}
+
+// delLockCache 清除按锁名称缓存的记录，锁的状态只从数据库读取
+func (m *customMigrationLocksModel) delLockCache(ctx context.Context, lockName string) {
+	_ = m.DelCacheCtx(ctx, fmt.Sprintf("%s%v", cacheMigrationLocksLockNamePrefix, lockName))
+}
+
+// leaseSeconds 将租约时长转换为秒，不足一秒按一秒计算
+func leaseSeconds(lease time.Duration) int64 {
+	seconds := int64((lease + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
 	}
+	return seconds
 }
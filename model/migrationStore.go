@@ -0,0 +1,121 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+// MigrationLockStore 不使用缓存的迁移锁，直接读写 migration_locks 表
+// 迁移锁的状态只从数据库读取，命令行和服务实例都可以在没有 Redis 时使用，满足 database.MigrationLocker
+type MigrationLockStore struct {
+	conn  sqlx.SqlConn
+	table string
+}
+
+// NewMigrationLockStore 创建不使用缓存的迁移锁
+func NewMigrationLockStore(conn sqlx.SqlConn) *MigrationLockStore {
+	return &MigrationLockStore{conn: conn, table: "`migration_locks`"}
+}
+
+// EnsureTable 创建迁移锁表（如果不存在）
+func (s *MigrationLockStore) EnsureTable(ctx context.Context) error {
+	_, err := s.conn.ExecCtx(ctx, migrationLocksTableDDL)
+	return err
+}
+
+// Acquire 尝试获取锁，锁已过期或已由 lockedBy 持有时获取成功
+// 过期时间使用数据库时间计算，不受各节点时钟偏差影响
+func (s *MigrationLockStore) Acquire(ctx context.Context, lockName, lockedBy string, lease time.Duration) (bool, error) {
+	// 不放在事务中：唯一索引保证并发插入只有一个成功，事务反而会因间隙锁产生死锁
+	query := fmt.Sprintf("delete from %s where `lock_name` = ? and (`expires_at` <= now() or `locked_by` = ?)", s.table)
+	if _, err := s.conn.ExecCtx(ctx, query, lockName, lockedBy); err != nil {
+		return false, err
+	}
+
+	query = fmt.Sprintf("insert ignore into %s (`lock_name`, `locked_by`, `locked_at`, `expires_at`) values (?, ?, now(), now() + interval ? second)", s.table)
+	result, err := s.conn.ExecCtx(ctx, query, lockName, lockedBy, leaseSeconds(lease))
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// Renew 延长 lockedBy 持有的锁，锁已被其他节点接管时返回 false
+func (s *MigrationLockStore) Renew(ctx context.Context, lockName, lockedBy string, lease time.Duration) (bool, error) {
+	query := fmt.Sprintf("update %s set `expires_at` = now() + interval ? second where `lock_name` = ? and `locked_by` = ?", s.table)
+	if _, err := s.conn.ExecCtx(ctx, query, leaseSeconds(lease), lockName, lockedBy); err != nil {
+		return false, err
+	}
+
+	// 同一秒内续约时 expires_at 不变，影响行数为 0，需要重新查询持有者
+	var held int64
+	query = fmt.Sprintf("select count(*) from %s where `lock_name` = ? and `locked_by` = ?", s.table)
+	if err := s.conn.QueryRowCtx(ctx, &held, query, lockName, lockedBy); err != nil {
+		return false, err
+	}
+	return held > 0, nil
+}
+
+// Release 释放 lockedBy 持有的锁，锁已被其他节点接管时不做任何操作
+func (s *MigrationLockStore) Release(ctx context.Context, lockName, lockedBy string) error {
+	query := fmt.Sprintf("delete from %s where `lock_name` = ? and `locked_by` = ?", s.table)
+	_, err := s.conn.ExecCtx(ctx, query, lockName, lockedBy)
+	return err
+}
+
+// MigrationHistoryStore 不使用缓存的迁移记录，直接读写 migrations 表，满足 database.MigrationHistory
+type MigrationHistoryStore struct {
+	conn  sqlx.SqlConn
+	table string
+}
+
+// NewMigrationHistoryStore 创建不使用缓存的迁移记录
+func NewMigrationHistoryStore(conn sqlx.SqlConn) *MigrationHistoryStore {
+	return &MigrationHistoryStore{conn: conn, table: "`migrations`"}
+}
+
+// EnsureTable 创建迁移记录表（如果不存在）
+func (s *MigrationHistoryStore) EnsureTable(ctx context.Context) error {
+	_, err := s.conn.ExecCtx(ctx, migrationsTableDDL)
+	return err
+}
+
+// FindAll 按版本顺序返回所有迁移记录
+func (s *MigrationHistoryStore) FindAll(ctx context.Context) ([]*Migrations, error) {
+	var resp []*Migrations
+	query := fmt.Sprintf("select %s from %s order by `version`", migrationsRows, s.table)
+	if err := s.conn.QueryRowsCtx(ctx, &resp, query); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Save 按版本写入迁移记录，已存在时覆盖，applied_at 为零值时使用数据库当前时间
+func (s *MigrationHistoryStore) Save(ctx context.Context, data *Migrations) error {
+	var appliedAt any = data.AppliedAt
+	if data.AppliedAt.IsZero() {
+		appliedAt = nil
+	}
+	query := fmt.Sprintf("insert into %s (`version`, `name`, `filename`, `checksum`, `applied_at`, `execution_time`, `status`, `error_message`, `rollback_available`) "+
+		"values (?, ?, ?, ?, coalesce(?, now()), ?, ?, ?, ?) on duplicate key update "+
+		"`name` = values(`name`), `filename` = values(`filename`), `checksum` = values(`checksum`), `applied_at` = values(`applied_at`), "+
+		"`execution_time` = values(`execution_time`), `status` = values(`status`), `error_message` = values(`error_message`), "+
+		"`rollback_available` = values(`rollback_available`)", s.table)
+	_, err := s.conn.ExecCtx(ctx, query, data.Version, data.Name, data.Filename, data.Checksum, appliedAt,
+		data.ExecutionTime, data.Status, data.ErrorMessage, data.RollbackAvailable)
+	return err
+}
+
+// DeleteByVersion 删除指定版本的迁移记录，记录不存在时不做任何操作
+func (s *MigrationHistoryStore) DeleteByVersion(ctx context.Context, version string) error {
+	query := fmt.Sprintf("delete from %s where `version` = ?", s.table)
+	_, err := s.conn.ExecCtx(ctx, query, version)
+	return err
+}
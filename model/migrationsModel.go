@@ -1,21 +1,60 @@
 package model
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlc"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 )
 
 var _ MigrationsModel = (*customMigrationsModel)(nil)
 
+// 迁移执行状态，对应 migrations.status
+const (
+	MigrationStatusSuccess = "SUCCESS"
+	MigrationStatusFailed  = "FAILED"
+	MigrationStatusRunning = "RUNNING"
+)
+
+// migrationsTableDDL 迁移记录表结构，与 core_tables_no_fk.sql 保持一致
+// 迁移记录在执行迁移文件之前写入，所以该表不在迁移文件中创建
+const migrationsTableDDL = "CREATE TABLE IF NOT EXISTS `migrations` (" +
+	"`id` bigint(20) NOT NULL AUTO_INCREMENT COMMENT '主键ID，自增'," +
+	"`version` varchar(20) NOT NULL COMMENT '迁移版本号，格式：001、002、003'," +
+	"`name` varchar(255) NOT NULL COMMENT '迁移名称，描述本次迁移的内容'," +
+	"`filename` varchar(255) NOT NULL COMMENT '迁移文件名'," +
+	"`checksum` varchar(64) NOT NULL COMMENT '迁移文件内容的SHA256校验和'," +
+	"`applied_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '迁移执行时间'," +
+	"`execution_time` int(11) DEFAULT NULL COMMENT '迁移执行耗时，单位毫秒'," +
+	"`status` enum('SUCCESS','FAILED','RUNNING') NOT NULL DEFAULT 'SUCCESS' COMMENT '迁移状态'," +
+	"`error_message` text COMMENT '错误信息（如果迁移失败）'," +
+	"`rollback_available` tinyint(1) NOT NULL DEFAULT 1 COMMENT '是否支持回滚：1-是，0-否'," +
+	"`created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间'," +
+	"`updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录更新时间'," +
+	"PRIMARY KEY (`id`)," +
+	"UNIQUE KEY `uk_version` (`version`)," +
+	"UNIQUE KEY `uk_filename` (`filename`)," +
+	"KEY `idx_applied_at` (`applied_at`)," +
+	"KEY `idx_status` (`status`)" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci " +
+	"COMMENT='数据库迁移版本跟踪表，记录每次迁移的执行状态和详情'"
+
 type (
 	// MigrationsModel is an interface to be customized, add more methods here,
 	// and implement the added methods in customMigrationsModel.
 	MigrationsModel interface {
 		migrationsModel
+		EnsureTable(ctx context.Context) error
+		FindAll(ctx context.Context) ([]*Migrations, error)
+		Save(ctx context.Context, data *Migrations) error
+		DeleteByVersion(ctx context.Context, version string) error
 	}
 
 	customMigrationsModel struct {
 		*defaultMigrationsModel
+		store *MigrationHistoryStore
 	}
 )
 
@@ -23,5 +62,72 @@ type (
 func NewMigrationsModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) MigrationsModel {
 	return &customMigrationsModel{
 		defaultMigrationsModel: newMigrationsModel(conn, c, opts...),
+		store:                  NewMigrationHistoryStore(conn),
+	}
+}
+
+// EnsureTable 创建迁移记录表（如果不存在）
+func (m *customMigrationsModel) EnsureTable(ctx context.Context) error {
+	return m.store.EnsureTable(ctx)
+}
+
+// FindAll 按版本顺序返回所有迁移记录
+func (m *customMigrationsModel) FindAll(ctx context.Context) ([]*Migrations, error) {
+	return m.store.FindAll(ctx)
+}
+
+// Save 按版本写入迁移记录，已存在时覆盖，applied_at 为零值时使用数据库当前时间
+func (m *customMigrationsModel) Save(ctx context.Context, data *Migrations) error {
+	keys := []string{
+		fmt.Sprintf("%s%v", cacheMigrationsVersionPrefix, data.Version),
+		fmt.Sprintf("%s%v", cacheMigrationsFilenamePrefix, data.Filename),
+	}
+	// 覆盖已有记录时，旧记录的主键和文件名缓存也要清除
+	old, err := m.findByVersionNoCache(ctx, data.Version)
+	if err != nil {
+		return err
+	}
+	if old != nil {
+		keys = append(keys, m.cacheKeys(old)...)
+	}
+
+	if err := m.store.Save(ctx, data); err != nil {
+		return err
+	}
+	return m.DelCacheCtx(ctx, keys...)
+}
+
+// DeleteByVersion 删除指定版本的迁移记录，记录不存在时不做任何操作
+func (m *customMigrationsModel) DeleteByVersion(ctx context.Context, version string) error {
+	old, err := m.findByVersionNoCache(ctx, version)
+	if err != nil || old == nil {
+		return err
+	}
+	if err := m.store.DeleteByVersion(ctx, version); err != nil {
+		return err
+	}
+	return m.DelCacheCtx(ctx, m.cacheKeys(old)...)
+}
+
+// findByVersionNoCache 从数据库读取指定版本的迁移记录，不存在时返回 nil
+func (m *customMigrationsModel) findByVersionNoCache(ctx context.Context, version string) (*Migrations, error) {
+	var data Migrations
+	query := fmt.Sprintf("select %s from %s where `version` = ? limit 1", migrationsRows, m.table)
+	switch err := m.QueryRowNoCacheCtx(ctx, &data, query, version); err {
+	case nil:
+		return &data, nil
+	case sqlc.ErrNotFound:
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
+
+// cacheKeys 记录对应的缓存键
+func (m *customMigrationsModel) cacheKeys(data *Migrations) []string {
+	return []string{
+		fmt.Sprintf("%s%v", cacheMigrationsIdPrefix, data.Id),
+		fmt.Sprintf("%s%v", cacheMigrationsVersionPrefix, data.Version),
+		fmt.Sprintf("%s%v", cacheMigrationsFilenamePrefix, data.Filename),
 	}
 }
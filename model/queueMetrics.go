@@ -0,0 +1,261 @@
+package model
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// callbackHostOther 超过 MaxCallbackHosts 后新出现的回调主机使用的 host 标签
+const callbackHostOther = "other"
+
+// QueueMetricsConfig 队列指标配置
+type QueueMetricsConfig struct {
+	Namespace          string        // 指标命名空间
+	DepthQueryInterval time.Duration // 按数据库统计待执行任务数的最小间隔，间隔内的采集沿用上次的结果
+	DepthQueryTimeout  time.Duration // 统计待执行任务数的查询超时时间
+	MaxCallbackHosts   int           // host 标签最多保留的回调主机数，避免任意回调地址导致标签无限增长
+}
+
+// DefaultQueueMetricsConfig 默认队列指标配置
+func DefaultQueueMetricsConfig() *QueueMetricsConfig {
+	return &QueueMetricsConfig{
+		Namespace:          "taskcenter",
+		DepthQueryInterval: 30 * time.Second,
+		DepthQueryTimeout:  5 * time.Second,
+		MaxCallbackHosts:   200,
+	}
+}
+
+// TaskQueueDepthCounter 统计各业务系统、各优先级的待执行任务数，TasksModel 实现了该接口
+type TaskQueueDepthCounter interface {
+	CountPendingByPriority(ctx context.Context) ([]*TaskQueueDepth, error)
+}
+
+// QueueMetrics 任务队列的 Prometheus 指标，实现 prometheus.Collector
+// 领取延迟、重试、死信和回收由 Attach 注册的状态机钩子统计，是进程内递增的计数器，各节点分别上报，查询时求和；
+// 回调耗时和锁竞争在 storage/mysql 写入执行记录和获取任务锁时通过 ObserveCallback 和 InstrumentTaskLocks 上报；
+// 待执行任务数只来自数据库，采集时距上次查询超过 DepthQueryInterval 才用一条分组查询重新统计，
+// 各节点上报的都是同一个全局数量，查询时取 max 而不是求和
+type QueueMetrics struct {
+	depthCounter TaskQueueDepthCounter
+	config       *QueueMetricsConfig
+
+	pending          *prometheus.GaugeVec
+	dispatchLag      *prometheus.HistogramVec
+	callbackDuration *prometheus.HistogramVec
+	retries          *prometheus.CounterVec
+	deadLetters      *prometheus.CounterVec
+	lockContention   prometheus.Counter
+	reaperRecoveries prometheus.Counter
+
+	depthMu      sync.Mutex
+	depthQueried time.Time
+
+	hostsMu sync.Mutex
+	hosts   map[string]struct{}
+}
+
+// NewQueueMetrics 创建队列指标，depthCounter 为空时不上报待执行任务数
+func NewQueueMetrics(depthCounter TaskQueueDepthCounter, config *QueueMetricsConfig) *QueueMetrics {
+	if config == nil {
+		config = DefaultQueueMetricsConfig()
+	}
+	namespace := config.Namespace
+	return &QueueMetrics{
+		depthCounter: depthCounter,
+		config:       config,
+		pending: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "pending_tasks",
+			Help:      "Tasks waiting to be executed, by business and priority.",
+		}, []string{"business_id", "priority"}),
+		dispatchLag: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "dispatch_lag_seconds",
+			Help:      "Delay between a task becoming due and being claimed, by priority.",
+			Buckets:   []float64{0.1, 0.5, 1, 5, 15, 30, 60, 300, 900, 3600},
+		}, []string{"priority"}),
+		callbackDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "callback_duration_seconds",
+			Help:      "Task callback latency, by callback host and response status.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"host", "status"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "task_retries_total",
+			Help:      "Tasks moved back to pending for another attempt, by business.",
+		}, []string{"business_id"}),
+		deadLetters: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "task_dead_letters_total",
+			Help:      "Tasks dead-lettered after exhausting their retries, by business.",
+		}, []string{"business_id"}),
+		lockContention: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "lock_contention_total",
+			Help:      "Task lock acquisitions that failed because another node holds the lock.",
+		}),
+		reaperRecoveries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "reaper_recoveries_total",
+			Help:      "Stuck running tasks returned to pending by the reaper.",
+		}),
+		hosts: make(map[string]struct{}),
+	}
+}
+
+// Attach 在状态机上注册钩子，统计领取延迟、重试、死信和回收，返回取消注册的函数
+// 回到待执行的变更中，操作者为 TaskActorReaper 的记为回收，其余（包括手动重试）记为重试；
+// 待执行变为执行中即执行器领取任务；执行中变为失败且重试次数已用完即进入死信
+func (m *QueueMetrics) Attach(sm *TaskStateMachine) (remove func()) {
+	return sm.OnTransition(TaskStatusAny, TaskStatusAny, func(ctx context.Context, t *TaskTransition) {
+		m.observeTransition(t)
+	})
+}
+
+// observeTransition 按状态变更更新指标
+func (m *QueueMetrics) observeTransition(t *TaskTransition) {
+	if t.From == t.To {
+		return
+	}
+	switch {
+	case t.To == TaskStatusPending && t.Actor == TaskActorReaper:
+		m.reaperRecoveries.Inc()
+	case t.To == TaskStatusPending:
+		m.retries.WithLabelValues(strconv.FormatInt(t.BusinessId, 10)).Inc()
+	case t.From == TaskStatusPending && t.To == TaskStatusRunning && t.Task != nil:
+		m.observeDispatchLag(t.Task, t.At)
+	case t.DeadLettered():
+		m.deadLetters.WithLabelValues(strconv.FormatInt(t.BusinessId, 10)).Inc()
+	}
+}
+
+// observeDispatchLag 记录领取任务时距离到期时间的延迟
+// 到期时间为 next_execute_at，未设置时为 scheduled_at；提前领取的任务记为 0
+func (m *QueueMetrics) observeDispatchLag(task *Tasks, claimedAt time.Time) {
+	due := task.ScheduledAt
+	if task.NextExecuteAt.Valid {
+		due = task.NextExecuteAt.Time
+	}
+	lag := claimedAt.Sub(due)
+	if lag < 0 {
+		lag = 0
+	}
+	m.dispatchLag.WithLabelValues(strconv.FormatInt(task.Priority, 10)).Observe(lag.Seconds())
+}
+
+// ObserveCallback 记录一次回调的耗时，statusCode 为 0 表示没有收到响应，status 标签记为 error
+// storage/mysql 在执行器写入执行记录时按记录的耗时和状态码调用
+func (m *QueueMetrics) ObserveCallback(callbackUrl string, statusCode int, duration time.Duration) {
+	status := "error"
+	if statusCode > 0 {
+		status = strconv.Itoa(statusCode)
+	}
+	m.callbackDuration.WithLabelValues(m.callbackHost(callbackUrl), status).Observe(duration.Seconds())
+}
+
+// InstrumentTaskLocks 包装任务锁模型，锁被其他节点持有导致获取失败时累加锁竞争计数
+// storage/mysql 的 Store.WithQueueMetrics 用它包装存储使用的任务锁模型
+func (m *QueueMetrics) InstrumentTaskLocks(locks TaskLocksModel) TaskLocksModel {
+	return &instrumentedTaskLocks{TaskLocksModel: locks, metrics: m}
+}
+
+// Describe 实现 prometheus.Collector
+func (m *QueueMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.pending.Describe(ch)
+	m.dispatchLag.Describe(ch)
+	m.callbackDuration.Describe(ch)
+	m.retries.Describe(ch)
+	m.deadLetters.Describe(ch)
+	m.lockContention.Describe(ch)
+	m.reaperRecoveries.Describe(ch)
+}
+
+// Collect 实现 prometheus.Collector，首次采集或距上次查询超过 DepthQueryInterval 时按数据库重新统计待执行任务数
+func (m *QueueMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.refreshDepth()
+
+	m.pending.Collect(ch)
+	m.dispatchLag.Collect(ch)
+	m.callbackDuration.Collect(ch)
+	m.retries.Collect(ch)
+	m.deadLetters.Collect(ch)
+	m.lockContention.Collect(ch)
+	m.reaperRecoveries.Collect(ch)
+}
+
+// refreshDepth 按数据库重新统计待执行任务数，查询失败时保留上次的结果
+func (m *QueueMetrics) refreshDepth() {
+	if m.depthCounter == nil {
+		return
+	}
+
+	m.depthMu.Lock()
+	defer m.depthMu.Unlock()
+	if !m.depthQueried.IsZero() && time.Since(m.depthQueried) < m.config.DepthQueryInterval {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.config.DepthQueryTimeout)
+	defer cancel()
+	depths, err := m.depthCounter.CountPendingByPriority(ctx)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("count pending tasks for queue metrics: %v", err)
+		return
+	}
+
+	// 清空后重新设置，已清空的业务系统和优先级不再上报
+	m.pending.Reset()
+	for _, depth := range depths {
+		m.pending.WithLabelValues(strconv.FormatInt(depth.BusinessId, 10), strconv.FormatInt(depth.Priority, 10)).Set(float64(depth.Count))
+	}
+	m.depthQueried = time.Now()
+}
+
+// callbackHost 返回回调地址的主机作为 host 标签，已记录的主机数达到上限后新主机记为 other
+func (m *QueueMetrics) callbackHost(callbackUrl string) string {
+	u, err := url.Parse(callbackUrl)
+	if err != nil || u.Host == "" {
+		return callbackHostOther
+	}
+
+	m.hostsMu.Lock()
+	defer m.hostsMu.Unlock()
+	if _, ok := m.hosts[u.Host]; ok {
+		return u.Host
+	}
+	if len(m.hosts) >= m.config.MaxCallbackHosts {
+		return callbackHostOther
+	}
+	m.hosts[u.Host] = struct{}{}
+	return u.Host
+}
+
+// instrumentedTaskLocks 统计锁竞争的任务锁模型
+type instrumentedTaskLocks struct {
+	TaskLocksModel
+	metrics *QueueMetrics
+}
+
+// Acquire 获取任务锁，锁被其他节点持有时累加锁竞争计数
+func (l *instrumentedTaskLocks) Acquire(ctx context.Context, lockKey string, taskId int64, nodeId string, now, expiresAt time.Time) (bool, error) {
+	acquired, err := l.TaskLocksModel.Acquire(ctx, lockKey, taskId, nodeId, now, expiresAt)
+	if err == nil && !acquired {
+		l.metrics.lockContention.Inc()
+	}
+	return acquired, err
+}
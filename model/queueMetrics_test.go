@@ -0,0 +1,172 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// fakeDepthCounter 返回固定的待执行任务数并记录查询次数
+type fakeDepthCounter struct {
+	depths []*TaskQueueDepth
+	calls  int
+}
+
+func (f *fakeDepthCounter) CountPendingByPriority(context.Context) ([]*TaskQueueDepth, error) {
+	f.calls++
+	return f.depths, nil
+}
+
+// histogramCount 返回直方图的样本数
+func histogramCount(t *testing.T, observer prometheus.Observer) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := observer.(prometheus.Metric).Write(&m); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestQueueMetrics_Transitions(t *testing.T) {
+	sm := NewTaskStateMachine()
+	metrics := NewQueueMetrics(nil, nil)
+	defer metrics.Attach(sm)()
+
+	ctx := context.Background()
+	now := time.Now()
+	task := &Tasks{Id: 1, BusinessId: 7, Priority: 3, Status: TaskStatusPending, MaxRetries: 1,
+		ScheduledAt: now.Add(-time.Minute), NextExecuteAt: sql.NullTime{Time: now.Add(-10 * time.Second), Valid: true}}
+	transit := func(from, to int64, actor string) {
+		actorCtx := WithTaskActor(ctx, actor, "")
+		sm.Fire(actorCtx, NewTaskTransition(actorCtx, task, from, to, now))
+	}
+
+	tests := []struct {
+		name        string
+		from, to    int64
+		actor       string
+		retry       int64
+		retries     float64
+		recoveries  float64
+		deadLetters float64
+		lagSamples  uint64
+	}{
+		{name: "claimed", from: TaskStatusPending, to: TaskStatusRunning, lagSamples: 1},
+		{name: "retried", from: TaskStatusRunning, to: TaskStatusPending, retries: 1, lagSamples: 1},
+		{name: "claimed again", from: TaskStatusPending, to: TaskStatusRunning, retry: 1, retries: 1, lagSamples: 2},
+		{name: "reaped", from: TaskStatusRunning, to: TaskStatusPending, actor: TaskActorReaper, retry: 1, retries: 1, recoveries: 1, lagSamples: 2},
+		{name: "claimed by reaper retry", from: TaskStatusPending, to: TaskStatusRunning, retry: 1, retries: 1, recoveries: 1, lagSamples: 3},
+		{name: "retries exhausted", from: TaskStatusRunning, to: TaskStatusFailed, retry: 1, retries: 1, recoveries: 1, deadLetters: 1, lagSamples: 3},
+	}
+	for _, tt := range tests {
+		task.CurrentRetry = tt.retry
+		transit(tt.from, tt.to, tt.actor)
+		if got := testutil.ToFloat64(metrics.retries.WithLabelValues("7")); got != tt.retries {
+			t.Errorf("%s: retries = %v, want %v", tt.name, got, tt.retries)
+		}
+		if got := testutil.ToFloat64(metrics.reaperRecoveries); got != tt.recoveries {
+			t.Errorf("%s: recoveries = %v, want %v", tt.name, got, tt.recoveries)
+		}
+		if got := testutil.ToFloat64(metrics.deadLetters.WithLabelValues("7")); got != tt.deadLetters {
+			t.Errorf("%s: dead letters = %v, want %v", tt.name, got, tt.deadLetters)
+		}
+		if got := histogramCount(t, metrics.dispatchLag.WithLabelValues("3")); got != tt.lagSamples {
+			t.Errorf("%s: dispatch lag samples = %d, want %d", tt.name, got, tt.lagSamples)
+		}
+	}
+
+	// 待执行任务数只来自数据库，钩子不改变
+	if got := testutil.CollectAndCount(metrics.pending); got != 0 {
+		t.Errorf("pending series = %d without a depth counter, want 0", got)
+	}
+}
+
+func TestQueueMetrics_FailureBeforeRetriesExhaustedIsNotDeadLettered(t *testing.T) {
+	sm := NewTaskStateMachine()
+	metrics := NewQueueMetrics(nil, nil)
+	defer metrics.Attach(sm)()
+
+	task := &Tasks{Id: 1, BusinessId: 7, MaxRetries: 3, CurrentRetry: 1}
+	sm.Fire(context.Background(), NewTaskTransition(context.Background(), task, TaskStatusRunning, TaskStatusFailed, time.Now()))
+	if got := testutil.ToFloat64(metrics.deadLetters.WithLabelValues("7")); got != 0 {
+		t.Errorf("dead letters = %v, want 0", got)
+	}
+}
+
+func TestQueueMetrics_RefreshDepth(t *testing.T) {
+	counter := &fakeDepthCounter{depths: []*TaskQueueDepth{{BusinessId: 1, Priority: 5, Count: 10}}}
+	config := DefaultQueueMetricsConfig()
+	config.DepthQueryInterval = time.Hour
+	metrics := NewQueueMetrics(counter, config)
+	sm := NewTaskStateMachine()
+	defer metrics.Attach(sm)()
+
+	// 间隔内的采集和状态变更都不改变待执行任务数
+	testutil.CollectAndCount(metrics)
+	task := &Tasks{BusinessId: 1, Priority: 5}
+	sm.Fire(context.Background(), NewTaskTransition(context.Background(), task, TaskStatusFailed, TaskStatusPending, time.Now()))
+	testutil.CollectAndCount(metrics)
+	if got := testutil.ToFloat64(metrics.pending.WithLabelValues("1", "5")); got != 10 {
+		t.Errorf("pending = %v, want 10", got)
+	}
+	if counter.calls != 1 {
+		t.Errorf("CountPendingByPriority calls = %d, want 1", counter.calls)
+	}
+
+	// 超过间隔后重新查询，已清空的业务系统不再上报
+	counter.depths = []*TaskQueueDepth{{BusinessId: 2, Priority: 1, Count: 3}}
+	metrics.depthQueried = time.Now().Add(-2 * time.Hour)
+	testutil.CollectAndCount(metrics)
+	if got := testutil.ToFloat64(metrics.pending.WithLabelValues("2", "1")); got != 3 || counter.calls != 2 {
+		t.Errorf("pending = %v after %d queries, want 3 after 2", got, counter.calls)
+	}
+	if got := testutil.CollectAndCount(metrics.pending); got != 1 {
+		t.Errorf("pending series = %d, want 1", got)
+	}
+}
+
+// fakeTaskLocks 按 acquired 返回获取结果
+type fakeTaskLocks struct {
+	TaskLocksModel
+	acquired bool
+}
+
+func (f *fakeTaskLocks) Acquire(context.Context, string, int64, string, time.Time, time.Time) (bool, error) {
+	return f.acquired, nil
+}
+
+func TestQueueMetrics_InstrumentTaskLocks(t *testing.T) {
+	metrics := NewQueueMetrics(nil, nil)
+	locks := &fakeTaskLocks{acquired: true}
+	instrumented := metrics.InstrumentTaskLocks(locks)
+
+	now := time.Now()
+	instrumented.Acquire(context.Background(), "task:1", 1, "node-a", now, now.Add(time.Minute))
+	locks.acquired = false
+	instrumented.Acquire(context.Background(), "task:1", 1, "node-b", now, now.Add(time.Minute))
+	if got := testutil.ToFloat64(metrics.lockContention); got != 1 {
+		t.Errorf("lock contention = %v, want 1", got)
+	}
+}
+
+func TestQueueMetrics_CallbackHostLimit(t *testing.T) {
+	config := DefaultQueueMetricsConfig()
+	config.MaxCallbackHosts = 1
+	metrics := NewQueueMetrics(nil, config)
+
+	metrics.ObserveCallback("https://a.example.com/cb", 200, time.Second)
+	metrics.ObserveCallback("https://b.example.com/cb", 0, time.Second)
+	metrics.ObserveCallback("://bad", 500, time.Second)
+
+	if len(metrics.hosts) != 1 {
+		t.Errorf("tracked hosts = %v, want only a.example.com", metrics.hosts)
+	}
+	if got := testutil.CollectAndCount(metrics.callbackDuration); got != 3 {
+		t.Errorf("callback series = %d, want 3 (a/200, other/error, other/500)", got)
+	}
+}
@@ -0,0 +1,303 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"task-center/pkg/event"
+)
+
+// taskLifecycleEvents 状态变更对应的生命周期事件类型
+// task.created 由创建钩子发送，task.dead_lettered 在重试次数用完的失败变更时额外发送；
+// 任务暂不支持暂停，task.paused 只保留事件类型，不会发送
+var taskLifecycleEvents = map[[2]int64]string{
+	{TaskStatusPending, TaskStatusRunning}:   event.TypeTaskStarted,
+	{TaskStatusPending, TaskStatusCancelled}: event.TypeTaskCancelled,
+	{TaskStatusPending, TaskStatusExpired}:   event.TypeTaskExpired,
+	{TaskStatusRunning, TaskStatusSucceeded}: event.TypeTaskCompleted,
+	{TaskStatusRunning, TaskStatusFailed}:    event.TypeTaskFailed,
+	{TaskStatusRunning, TaskStatusPending}:   event.TypeTaskRetrying,
+	{TaskStatusFailed, TaskStatusPending}:    event.TypeTaskRetrying,
+	{TaskStatusExpired, TaskStatusPending}:   event.TypeTaskRetrying,
+}
+
+// TaskLifecycleEventType 返回状态变更对应的事件类型，没有对应事件时返回空
+func TaskLifecycleEventType(from, to int64) string {
+	return taskLifecycleEvents[[2]int64{from, to}]
+}
+
+// TaskEventDispatcherConfig 生命周期事件推送配置
+type TaskEventDispatcherConfig struct {
+	Workers      int           // 并发推送的协程数
+	QueueSize    int           // 待推送事件队列长度，队列满时丢弃新事件并计入丢弃数
+	Timeout      time.Duration // 单次推送的超时时间
+	MaxAttempts  int           // 每个订阅最多推送的次数，包括首次推送
+	RetryBackoff time.Duration // 首次重试的等待时间，之后每次翻倍
+}
+
+// DefaultTaskEventDispatcherConfig 默认生命周期事件推送配置
+func DefaultTaskEventDispatcherConfig() *TaskEventDispatcherConfig {
+	return &TaskEventDispatcherConfig{
+		Workers:      4,
+		QueueSize:    1024,
+		Timeout:      10 * time.Second,
+		MaxAttempts:  3,
+		RetryBackoff: 5 * time.Second,
+	}
+}
+
+// taskLifecycleEvent 待推送的事件，task 为空时推送前按 taskId 查询
+// 重试时 subscription 为推送失败的订阅，body 为首次推送的内容，保证每次重试的内容一致
+type taskLifecycleEvent struct {
+	eventType    string
+	taskId       int64
+	businessId   int64
+	task         *Tasks
+	at           time.Time
+	subscription *EventSubscriptions
+	body         []byte
+	attempt      int // 已推送的次数
+}
+
+// TaskEventDispatcher 将任务生命周期事件推送到业务系统订阅的通知地址
+// 推送在后台协程中进行，不阻塞状态变更。推送尽力而为：失败后按指数退避重试 MaxAttempts 次，
+// 次数用完后放弃并计入失败数；队列满时丢弃事件并计入丢弃数；进程退出时队列中的事件会丢失
+type TaskEventDispatcher struct {
+	tasks         TasksModel
+	subscriptions EventSubscriptionsModel
+	client        *http.Client
+	config        *TaskEventDispatcherConfig
+	queue         chan *taskLifecycleEvent
+	dropped       atomic.Int64
+	failed        atomic.Int64
+}
+
+// NewTaskEventDispatcher 创建生命周期事件推送器
+func NewTaskEventDispatcher(tasks TasksModel, subscriptions EventSubscriptionsModel, config *TaskEventDispatcherConfig) *TaskEventDispatcher {
+	if config == nil {
+		config = DefaultTaskEventDispatcherConfig()
+	}
+	return &TaskEventDispatcher{
+		tasks:         tasks,
+		subscriptions: subscriptions,
+		client:        &http.Client{Timeout: config.Timeout},
+		config:        config,
+		queue:         make(chan *taskLifecycleEvent, config.QueueSize),
+	}
+}
+
+// Attach 在状态机上注册钩子，任务创建和每次状态变更后推送对应的事件，返回取消注册的函数
+func (d *TaskEventDispatcher) Attach(sm *TaskStateMachine) (remove func()) {
+	removeCreated := sm.OnCreated(func(ctx context.Context, task *Tasks) {
+		d.enqueue(ctx, &taskLifecycleEvent{eventType: event.TypeTaskCreated, taskId: task.Id, businessId: task.BusinessId, task: task, at: task.CreatedAt})
+	})
+	removeTransition := sm.OnTransition(TaskStatusAny, TaskStatusAny, func(ctx context.Context, t *TaskTransition) {
+		if eventType := TaskLifecycleEventType(t.From, t.To); eventType != "" {
+			d.enqueue(ctx, &taskLifecycleEvent{eventType: eventType, taskId: t.TaskId, businessId: t.BusinessId, at: t.At})
+		}
+		if t.DeadLettered() {
+			d.enqueue(ctx, &taskLifecycleEvent{eventType: event.TypeTaskDeadLettered, taskId: t.TaskId, businessId: t.BusinessId, at: t.At})
+		}
+	})
+	return func() {
+		removeCreated()
+		removeTransition()
+	}
+}
+
+// Dropped 返回队列满时丢弃的事件数，包括丢弃的重试
+func (d *TaskEventDispatcher) Dropped() int64 {
+	return d.dropped.Load()
+}
+
+// Failed 返回重试次数用完仍推送失败的次数，每个订阅分别计数
+func (d *TaskEventDispatcher) Failed() int64 {
+	return d.failed.Load()
+}
+
+// enqueue 加入推送队列，队列满时丢弃事件，避免阻塞状态变更
+func (d *TaskEventDispatcher) enqueue(ctx context.Context, e *taskLifecycleEvent) {
+	select {
+	case d.queue <- e:
+	default:
+		d.dropped.Add(1)
+		logx.WithContext(ctx).Errorf("task event queue full, dropping %s for task %d", e.eventType, e.taskId)
+	}
+}
+
+// Run 启动推送协程，直到 ctx 结束
+func (d *TaskEventDispatcher) Run(ctx context.Context) error {
+	workers := d.config.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event := <-d.queue:
+					if err := d.deliver(ctx, event); err != nil && ctx.Err() == nil {
+						logx.WithContext(ctx).Errorf("deliver %s for task %d: %v", event.eventType, event.taskId, err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// deliver 将事件推送到业务系统下所有订阅了该事件类型的地址，重试时只推送到失败的订阅
+func (d *TaskEventDispatcher) deliver(ctx context.Context, e *taskLifecycleEvent) error {
+	targets := []*EventSubscriptions{e.subscription}
+	if e.subscription == nil {
+		subscriptions, err := d.subscriptions.ListEnabledByBusiness(ctx, e.businessId)
+		if err != nil {
+			d.retry(ctx, e, nil)
+			return err
+		}
+		targets = targets[:0]
+		for _, s := range subscriptions {
+			if s.Subscribes(e.eventType) {
+				targets = append(targets, s)
+			}
+		}
+		if len(targets) == 0 {
+			return nil
+		}
+	}
+
+	if e.body == nil {
+		if e.task == nil {
+			task, err := d.tasks.FindOne(ctx, e.taskId)
+			if err != nil {
+				d.retry(ctx, e, nil)
+				return err
+			}
+			e.task = task
+		}
+		body, err := json.Marshal(&event.Event{
+			EventType:  e.eventType,
+			EventTime:  e.at,
+			TaskID:     e.task.Id,
+			BusinessID: e.task.BusinessId,
+			Task:       callbackTask(e.task),
+		})
+		if err != nil {
+			return err
+		}
+		e.body = body
+	}
+
+	var errs []error
+	for _, s := range targets {
+		if err := d.post(ctx, s, e.task, e.eventType, e.body); err != nil {
+			errs = append(errs, fmt.Errorf("subscription %d attempt %d: %w", s.Id, e.attempt+1, err))
+			d.retry(ctx, e, s)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// retry 推送失败后按指数退避重新加入队列，subscription 为空时重新匹配订阅；次数用完后放弃并计入失败数
+func (d *TaskEventDispatcher) retry(ctx context.Context, e *taskLifecycleEvent, subscription *EventSubscriptions) {
+	attempt := e.attempt + 1
+	if attempt >= d.config.MaxAttempts {
+		d.failed.Add(1)
+		logx.WithContext(ctx).Errorf("giving up %s for task %d after %d attempts", e.eventType, e.taskId, attempt)
+		return
+	}
+
+	next := *e
+	next.subscription = subscription
+	next.attempt = attempt
+	time.AfterFunc(d.config.RetryBackoff<<(attempt-1), func() {
+		d.enqueue(ctx, &next)
+	})
+}
+
+// post 发送签名后的事件，签名方式与 sdk/callback 的校验一致；转发任务创建者的 traceparent
+func (d *TaskEventDispatcher) post(ctx context.Context, s *EventSubscriptions, task *Tasks, eventType string, body []byte) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write([]byte(timestamp + "." + string(body)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-TaskCenter-Event", eventType)
+	req.Header.Set("X-TaskCenter-Timestamp", timestamp)
+	req.Header.Set("X-TaskCenter-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	InjectTaskTraceContext(ContextWithTaskTrace(ctx, task), req.Header)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// callbackTask 转换为回调事件中的任务结构，JSON 字段解析失败时忽略该字段
+func callbackTask(t *Tasks) event.Task {
+	task := event.Task{
+		ID:               t.Id,
+		BusinessUniqueID: t.BusinessUniqueId,
+		CallbackURL:      t.CallbackUrl,
+		CallbackMethod:   t.CallbackMethod,
+		CallbackBody:     t.CallbackBody.String,
+		MaxRetries:       int(t.MaxRetries),
+		CurrentRetry:     int(t.CurrentRetry),
+		Status:           event.TaskStatus(t.Status),
+		Priority:         event.TaskPriority(t.Priority),
+		Timeout:          int(t.Timeout),
+		ScheduledAt:      t.ScheduledAt,
+		ErrorMessage:     t.ErrorMessage.String,
+		CreatedAt:        t.CreatedAt,
+		UpdatedAt:        t.UpdatedAt,
+	}
+	_ = json.Unmarshal([]byte(t.RetryIntervals), &task.RetryIntervals)
+	if t.CallbackHeaders.Valid {
+		_ = json.Unmarshal([]byte(t.CallbackHeaders.String), &task.CallbackHeaders)
+	}
+	if t.Tags.Valid {
+		_ = json.Unmarshal([]byte(t.Tags.String), &task.Tags)
+	}
+	if t.Metadata.Valid {
+		_ = json.Unmarshal([]byte(t.Metadata.String), &task.Metadata)
+	}
+	if t.NextExecuteAt.Valid {
+		task.NextExecuteAt = &t.NextExecuteAt.Time
+	}
+	if t.ExecutedAt.Valid {
+		task.ExecutedAt = &t.ExecutedAt.Time
+	}
+	if t.CompletedAt.Valid {
+		task.CompletedAt = &t.CompletedAt.Time
+	}
+	return task
+}
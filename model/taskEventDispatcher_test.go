@@ -0,0 +1,154 @@
+package model
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"task-center/pkg/event"
+)
+
+// fakeEventSubscriptions 返回固定的订阅
+type fakeEventSubscriptions struct {
+	EventSubscriptionsModel
+	subscriptions []*EventSubscriptions
+}
+
+func (f *fakeEventSubscriptions) ListEnabledByBusiness(context.Context, int64) ([]*EventSubscriptions, error) {
+	return f.subscriptions, nil
+}
+
+// fakeTaskFinder 按 ID 返回固定的任务
+type fakeTaskFinder struct {
+	TasksModel
+	task *Tasks
+}
+
+func (f *fakeTaskFinder) FindOne(context.Context, int64) (*Tasks, error) {
+	return f.task, nil
+}
+
+// eventRecorder 记录收到的事件类型，前 failures 次请求返回 500
+type eventRecorder struct {
+	mu       sync.Mutex
+	failures int
+	requests int
+	received []string
+}
+
+func (r *eventRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	if r.requests <= r.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	r.received = append(r.received, req.Header.Get("X-TaskCenter-Event"))
+}
+
+func (r *eventRecorder) snapshot() (requests int, received []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests, append([]string(nil), r.received...)
+}
+
+func newTestDispatcher(url string, task *Tasks, config *TaskEventDispatcherConfig) *TaskEventDispatcher {
+	subscriptions := &fakeEventSubscriptions{subscriptions: []*EventSubscriptions{{
+		Id:         1,
+		BusinessId: task.BusinessId,
+		Url:        url,
+		EventTypes: `["task.created","task.failed","task.dead_lettered"]`,
+		Secret:     "secret",
+		Status:     EventSubscriptionStatusEnabled,
+	}}}
+	return NewTaskEventDispatcher(&fakeTaskFinder{task: task}, subscriptions, config)
+}
+
+func TestTaskEventDispatcher_Attach(t *testing.T) {
+	recorder := &eventRecorder{failures: 1}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	task := &Tasks{Id: 1, BusinessId: 7, Status: TaskStatusPending, MaxRetries: 1, CurrentRetry: 1, CreatedAt: time.Now()}
+	d := newTestDispatcher(server.URL, task, &TaskEventDispatcherConfig{
+		Workers: 1, QueueSize: 16, Timeout: time.Second, MaxAttempts: 2, RetryBackoff: 10 * time.Millisecond,
+	})
+	sm := NewTaskStateMachine()
+	defer d.Attach(sm)()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	// 首次推送失败，task.created 重试后送达；重试次数用完的失败额外推送 task.dead_lettered
+	sm.FireCreated(ctx, task)
+	sm.Fire(ctx,
+		NewTaskTransition(ctx, task, TaskStatusPending, TaskStatusRunning, time.Now()),
+		NewTaskTransition(ctx, task, TaskStatusRunning, TaskStatusFailed, time.Now()),
+	)
+
+	want := map[string]bool{event.TypeTaskCreated: true, event.TypeTaskFailed: true, event.TypeTaskDeadLettered: true}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, received := recorder.snapshot()
+		if len(received) == len(want) {
+			for _, eventType := range received {
+				if !want[eventType] {
+					t.Errorf("unexpected event %s", eventType)
+				}
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("received = %v, want %v", received, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if d.Failed() != 0 || d.Dropped() != 0 {
+		t.Errorf("failed = %d, dropped = %d, want 0", d.Failed(), d.Dropped())
+	}
+}
+
+func TestTaskEventDispatcher_GivesUp(t *testing.T) {
+	recorder := &eventRecorder{failures: 100}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	task := &Tasks{Id: 1, BusinessId: 7}
+	d := newTestDispatcher(server.URL, task, &TaskEventDispatcherConfig{
+		Workers: 1, QueueSize: 16, Timeout: time.Second, MaxAttempts: 3, RetryBackoff: time.Millisecond,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	d.enqueue(ctx, &taskLifecycleEvent{eventType: event.TypeTaskFailed, taskId: task.Id, businessId: task.BusinessId, at: time.Now()})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for d.Failed() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("delivery was never given up")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if requests, _ := recorder.snapshot(); requests != 3 {
+		t.Errorf("requests = %d, want 3 attempts", requests)
+	}
+}
+
+func TestTaskEventDispatcher_Dropped(t *testing.T) {
+	task := &Tasks{Id: 1, BusinessId: 7}
+	d := newTestDispatcher("http://127.0.0.1:0", task, &TaskEventDispatcherConfig{QueueSize: 1})
+
+	// 未启动推送协程，第二个事件因队列已满被丢弃
+	sm := NewTaskStateMachine()
+	defer d.Attach(sm)()
+	sm.FireCreated(context.Background(), task, task)
+	if got := d.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+}
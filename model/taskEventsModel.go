@@ -0,0 +1,105 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+var _ TaskEventsModel = (*customTaskEventsModel)(nil)
+
+// taskEventsTable 任务事件表名，供 tasks 模型在状态变更的同一事务中写入事件
+const taskEventsTable = "`task_events`"
+
+type (
+	// TaskEventsModel is an interface to be customized, add more methods here,
+	// and implement the added methods in customTaskEventsModel.
+	TaskEventsModel interface {
+		taskEventsModel
+		ListByTask(ctx context.Context, taskId int64) ([]*TaskEvents, error)
+		ListAfter(ctx context.Context, afterId, businessId int64, taskIds []int64, limit int) ([]*TaskEvents, error)
+		MaxId(ctx context.Context) (int64, error)
+	}
+
+	customTaskEventsModel struct {
+		*defaultTaskEventsModel
+	}
+)
+
+// NewTaskEventsModel returns a model for the database table.
+func NewTaskEventsModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) TaskEventsModel {
+	return &customTaskEventsModel{
+		defaultTaskEventsModel: newTaskEventsModel(conn, c, opts...),
+	}
+}
+
+// ListByTask 按发生顺序返回任务的状态变更事件
+func (m *customTaskEventsModel) ListByTask(ctx context.Context, taskId int64) ([]*TaskEvents, error) {
+	var events []*TaskEvents
+	query := fmt.Sprintf("select %s from %s where `task_id` = ? order by `id`", taskEventsRows, m.table)
+	if err := m.QueryRowsNoCacheCtx(ctx, &events, query, taskId); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ListAfter 按 id 顺序返回 afterId 之后的事件，businessId 为 0 时不限业务系统，taskIds 为空时不限任务
+// 事件 id 全局递增，可作为订阅者断线重连时的续传位置
+func (m *customTaskEventsModel) ListAfter(ctx context.Context, afterId, businessId int64, taskIds []int64, limit int) ([]*TaskEvents, error) {
+	conditions := []string{"`id` > ?"}
+	args := []any{afterId}
+	if businessId > 0 {
+		conditions = append(conditions, "`business_id` = ?")
+		args = append(args, businessId)
+	}
+	if len(taskIds) > 0 {
+		conditions = append(conditions, fmt.Sprintf("`task_id` in (%s)", strings.TrimSuffix(strings.Repeat("?, ", len(taskIds)), ", ")))
+		for _, id := range taskIds {
+			args = append(args, id)
+		}
+	}
+	args = append(args, limit)
+
+	var events []*TaskEvents
+	query := fmt.Sprintf("select %s from %s where %s order by `id` limit ?", taskEventsRows, m.table, strings.Join(conditions, " and "))
+	if err := m.QueryRowsNoCacheCtx(ctx, &events, query, args...); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// MaxId 返回最新事件的 id，没有事件时返回 0
+func (m *customTaskEventsModel) MaxId(ctx context.Context) (int64, error) {
+	var id int64
+	query := fmt.Sprintf("select coalesce(max(`id`), 0) from %s", m.table)
+	if err := m.QueryRowNoCacheCtx(ctx, &id, query); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// insertTaskEvents 在事务中写入状态变更事件，created_at 使用变更发生的时间
+func insertTaskEvents(ctx context.Context, session sqlx.Session, transitions []*TaskTransition) error {
+	if len(transitions) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(transitions))
+	args := make([]any, 0, len(transitions)*7)
+	for _, t := range transitions {
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, t.TaskId, t.BusinessId, t.From, t.To, t.Actor, nullString(t.Reason), t.At)
+	}
+	query := fmt.Sprintf("insert into %s (%s, `created_at`) values %s", taskEventsTable, taskEventsRowsExpectAutoSet, strings.Join(values, ", "))
+	_, err := session.ExecCtx(ctx, query, args...)
+	return err
+}
+
+// nullString 空字符串写入 NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
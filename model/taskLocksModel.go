@@ -1,6 +1,11 @@
 package model
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 )
@@ -12,6 +17,9 @@ type (
 	// and implement the added methods in customTaskLocksModel.
 	TaskLocksModel interface {
 		taskLocksModel
+		Acquire(ctx context.Context, lockKey string, taskId int64, nodeId string, now, expiresAt time.Time) (bool, error)
+		Renew(ctx context.Context, lockKey, nodeId string, expiresAt time.Time) (bool, error)
+		Release(ctx context.Context, lockKey, nodeId string) error
 	}
 
 	customTaskLocksModel struct {
@@ -25,3 +33,83 @@ func NewTaskLocksModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Optio
 		defaultTaskLocksModel: newTaskLocksModel(conn, c, opts...),
 	}
 }
+
+// Acquire 获取任务锁，锁不存在、已在 now 之前过期或已由 nodeId 持有时成功，每次成功获取 version 加一
+// 时间由调用方传入而不是使用数据库时间，保证与其他存储实现的过期判断一致
+func (m *customTaskLocksModel) Acquire(ctx context.Context, lockKey string, taskId int64, nodeId string, now, expiresAt time.Time) (bool, error) {
+	keys, err := m.lockCacheKeys(ctx, lockKey)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		query := fmt.Sprintf("update %s set `task_id` = ?, `node_id` = ?, `locked_at` = ?, `expires_at` = ?, `version` = `version` + 1 "+
+			"where `lock_key` = ? and (`expires_at` <= ? or `node_id` = ?)", m.table)
+		return conn.ExecCtx(ctx, query, taskId, nodeId, now, expiresAt, lockKey, now, nodeId)
+	}, keys...)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 1 {
+		return affected == 1, err
+	}
+
+	// 锁不存在时插入，lock_key 唯一索引保证并发时只有一个节点成功
+	result, err = m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		query := fmt.Sprintf("insert ignore into %s (%s) values (?, ?, ?, ?, ?, 1)", m.table, taskLocksRowsExpectAutoSet)
+		return conn.ExecCtx(ctx, query, taskId, lockKey, nodeId, now, expiresAt)
+	}, keys...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// Renew 延长 nodeId 持有的锁，锁已被其他节点获取时返回 false
+func (m *customTaskLocksModel) Renew(ctx context.Context, lockKey, nodeId string, expiresAt time.Time) (bool, error) {
+	keys, err := m.lockCacheKeys(ctx, lockKey)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		query := fmt.Sprintf("update %s set `expires_at` = ?, `version` = `version` + 1 where `lock_key` = ? and `node_id` = ?", m.table)
+		return conn.ExecCtx(ctx, query, expiresAt, lockKey, nodeId)
+	}, keys...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// Release 释放 nodeId 持有的锁，锁已被其他节点获取时不做任何操作
+func (m *customTaskLocksModel) Release(ctx context.Context, lockKey, nodeId string) error {
+	keys, err := m.lockCacheKeys(ctx, lockKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (sql.Result, error) {
+		query := fmt.Sprintf("delete from %s where `lock_key` = ? and `node_id` = ?", m.table)
+		return conn.ExecCtx(ctx, query, lockKey, nodeId)
+	}, keys...)
+	return err
+}
+
+// lockCacheKeys 返回锁的缓存键，锁不存在时只有 lock_key 的缓存键，用于清理“不存在”占位
+func (m *customTaskLocksModel) lockCacheKeys(ctx context.Context, lockKey string) ([]string, error) {
+	keys := []string{fmt.Sprintf("%s%v", cacheTaskLocksLockKeyPrefix, lockKey)}
+
+	var id int64
+	query := fmt.Sprintf("select `id` from %s where `lock_key` = ? limit 1", m.table)
+	switch err := m.QueryRowNoCacheCtx(ctx, &id, query, lockKey); err {
+	case nil:
+		return append(keys, fmt.Sprintf("%s%v", cacheTaskLocksIdPrefix, id)), nil
+	case sqlx.ErrNotFound:
+		return keys, nil
+	default:
+		return nil, err
+	}
+}
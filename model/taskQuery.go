@@ -0,0 +1,220 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"task-center/pkg/search"
+)
+
+// 游标分页的排序键
+const (
+	TaskSortByCreatedAt     = "created_at"
+	TaskSortByNextExecuteAt = "next_execute_at"
+)
+
+// 游标分页单页上限
+const (
+	DefaultTaskPageSize = 20
+	MaxTaskPageSize     = 1000
+)
+
+// ErrInvalidCursor 游标无法解析或与当前排序不匹配
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TaskListFilter 任务列表过滤条件
+type TaskListFilter struct {
+	BusinessId  int64
+	Status      []int64
+	Priority    *int64
+	Tags        []string
+	TagMatch    string // TagMatchAll（默认）或 TagMatchAny
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Search      *search.Query // 搜索语法解析出的条件，见 pkg/search
+}
+
+// TaskCursor 游标分页位置，对调用方不透明
+type TaskCursor struct {
+	SortBy string    `json:"s"`
+	Desc   bool      `json:"d,omitempty"`
+	Value  time.Time `json:"v"`
+	Id     int64     `json:"i"`
+}
+
+// TaskPageRequest 游标分页请求
+type TaskPageRequest struct {
+	SortBy string // created_at 或 next_execute_at
+	Desc   bool   // 是否倒序
+	Cursor string // 上一页返回的游标，首页为空
+	Limit  int    // 每页条数
+}
+
+// TaskPage 游标分页结果
+type TaskPage struct {
+	Tasks      []*Tasks
+	NextCursor string // 没有更多数据时为空
+	HasMore    bool
+}
+
+// EncodeTaskCursor 编码游标
+func EncodeTaskCursor(c *TaskCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeTaskCursor 解码游标
+func DecodeTaskCursor(s string) (*TaskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c TaskCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Id <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	if c.SortBy != TaskSortByCreatedAt && c.SortBy != TaskSortByNextExecuteAt {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// where 构建过滤条件，返回不带 where 关键字的条件和参数
+func (f *TaskListFilter) where() (string, []any) {
+	conds := []string{"`business_id` = ?"}
+	args := []any{f.BusinessId}
+
+	if len(f.Status) > 0 {
+		conds = append(conds, "`status` in ("+placeholders(len(f.Status))+")")
+		for _, status := range f.Status {
+			args = append(args, status)
+		}
+	}
+	if f.Priority != nil {
+		conds = append(conds, "`priority` = ?")
+		args = append(args, *f.Priority)
+	}
+	if tags := dedupeTags(f.Tags); len(tags) > 0 {
+		// 通过 task_tags 的 (business_id, tag, task_id) 索引过滤，避免扫描 JSON 列
+		subquery := "select `task_id` from " + taskTagsTable + " where `business_id` = ? and `tag` in (" + placeholders(len(tags)) + ")"
+		args = append(args, f.BusinessId)
+		for _, tag := range tags {
+			args = append(args, tag)
+		}
+		if f.TagMatch != TagMatchAny {
+			subquery += " group by `task_id` having count(*) = ?"
+			args = append(args, len(tags))
+		}
+		conds = append(conds, "`id` in ("+subquery+")")
+	}
+	if f.CreatedFrom != nil {
+		conds = append(conds, "`created_at` >= ?")
+		args = append(args, *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		conds = append(conds, "`created_at` <= ?")
+		args = append(args, *f.CreatedTo)
+	}
+
+	searchConds, searchArgs := searchConditions(f.Search)
+	conds = append(conds, searchConds...)
+	args = append(args, searchArgs...)
+
+	return strings.Join(conds, " and "), args
+}
+
+// dedupeTags 去除空白和重复的过滤标签，大小写不同视为相同，与 task_tags 的排序规则一致
+func dedupeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// keysetQuery 构建游标分页查询，多取一条用于判断是否还有下一页
+func keysetQuery(table string, filter *TaskListFilter, page *TaskPageRequest) (string, []any, error) {
+	sortBy := page.SortBy
+	if sortBy == "" {
+		sortBy = TaskSortByCreatedAt
+	}
+	if sortBy != TaskSortByCreatedAt && sortBy != TaskSortByNextExecuteAt {
+		return "", nil, fmt.Errorf("unsupported sort key: %s", sortBy)
+	}
+
+	where, args := filter.where()
+	if sortBy == TaskSortByNextExecuteAt {
+		// 没有下次执行时间的任务不参与该排序
+		where += " and `next_execute_at` is not null"
+	}
+
+	if page.Cursor != "" {
+		cursor, err := DecodeTaskCursor(page.Cursor)
+		if err != nil {
+			return "", nil, err
+		}
+		if cursor.SortBy != sortBy || cursor.Desc != page.Desc {
+			return "", nil, ErrInvalidCursor
+		}
+
+		op := ">"
+		if page.Desc {
+			op = "<"
+		}
+		where += fmt.Sprintf(" and (`%s` %s ? or (`%s` = ? and `id` %s ?))", sortBy, op, sortBy, op)
+		args = append(args, cursor.Value, cursor.Value, cursor.Id)
+	}
+
+	order := "asc"
+	if page.Desc {
+		order = "desc"
+	}
+
+	query := fmt.Sprintf("select %s from %s where %s order by `%s` %s, `id` %s limit ?",
+		tasksRows, table, where, sortBy, order, order)
+	args = append(args, normalizeLimit(page.Limit)+1)
+
+	return query, args, nil
+}
+
+// nextTaskCursor 根据本页最后一条记录生成下一页游标
+func nextTaskCursor(last *Tasks, sortBy string, desc bool) string {
+	if sortBy == "" {
+		sortBy = TaskSortByCreatedAt
+	}
+
+	value := last.CreatedAt
+	if sortBy == TaskSortByNextExecuteAt {
+		value = last.NextExecuteAt.Time
+	}
+
+	return EncodeTaskCursor(&TaskCursor{SortBy: sortBy, Desc: desc, Value: value, Id: last.Id})
+}
+
+// normalizeLimit 规范化每页条数
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultTaskPageSize
+	}
+	if limit > MaxTaskPageSize {
+		return MaxTaskPageSize
+	}
+	return limit
+}
+
+// placeholders 生成 n 个以逗号分隔的占位符
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
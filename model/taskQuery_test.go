@@ -0,0 +1,169 @@
+package model
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskCursor_RoundTrip(t *testing.T) {
+	at := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	cursor := &TaskCursor{SortBy: TaskSortByNextExecuteAt, Desc: true, Value: at, Id: 42}
+
+	decoded, err := DecodeTaskCursor(EncodeTaskCursor(cursor))
+	if err != nil {
+		t.Fatalf("DecodeTaskCursor failed: %v", err)
+	}
+	if decoded.SortBy != cursor.SortBy || decoded.Desc != cursor.Desc || !decoded.Value.Equal(at) || decoded.Id != 42 {
+		t.Errorf("decoded cursor = %+v, want %+v", decoded, cursor)
+	}
+}
+
+func TestDecodeTaskCursor_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"not base64":       "%%%",
+		"not json":         rawCursor("nope"),
+		"missing id":       rawCursor(`{"s":"created_at","v":"2024-01-01T00:00:00Z"}`),
+		"unknown sort key": rawCursor(`{"s":"priority","v":"2024-01-01T00:00:00Z","i":1}`),
+	}
+	for name, cursor := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeTaskCursor(cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeTaskCursor(%q) error = %v, want ErrInvalidCursor", cursor, err)
+			}
+		})
+	}
+}
+
+func TestKeysetQuery(t *testing.T) {
+	at := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	ascCursor := EncodeTaskCursor(&TaskCursor{SortBy: TaskSortByCreatedAt, Value: at, Id: 7})
+	descCursor := EncodeTaskCursor(&TaskCursor{SortBy: TaskSortByNextExecuteAt, Desc: true, Value: at, Id: 7})
+
+	tests := []struct {
+		name     string
+		page     *TaskPageRequest
+		contains []string
+		args     []any
+		err      error
+	}{
+		{
+			name:     "first page defaults to created_at ascending",
+			page:     &TaskPageRequest{},
+			contains: []string{"where `business_id` = ? order by `created_at` asc, `id` asc limit ?"},
+			args:     []any{int64(1), DefaultTaskPageSize + 1},
+		},
+		{
+			name:     "ascending cursor",
+			page:     &TaskPageRequest{Cursor: ascCursor, Limit: 10},
+			contains: []string{"and (`created_at` > ? or (`created_at` = ? and `id` > ?))"},
+			args:     []any{int64(1), at, at, int64(7), 11},
+		},
+		{
+			name: "descending next_execute_at skips unscheduled tasks",
+			page: &TaskPageRequest{SortBy: TaskSortByNextExecuteAt, Desc: true, Cursor: descCursor, Limit: 5000},
+			contains: []string{
+				"and `next_execute_at` is not null",
+				"and (`next_execute_at` < ? or (`next_execute_at` = ? and `id` < ?))",
+				"order by `next_execute_at` desc, `id` desc",
+			},
+			args: []any{int64(1), at, at, int64(7), MaxTaskPageSize + 1},
+		},
+		{
+			name: "cursor from another sort order",
+			page: &TaskPageRequest{Desc: true, Cursor: ascCursor},
+			err:  ErrInvalidCursor,
+		},
+		{
+			name: "unsupported sort key",
+			page: &TaskPageRequest{SortBy: "priority"},
+			err:  errors.New("unsupported sort key: priority"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := keysetQuery("`tasks`", &TaskListFilter{BusinessId: 1}, tt.page)
+			if tt.err != nil {
+				if err == nil || err.Error() != tt.err.Error() {
+					t.Fatalf("error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("keysetQuery failed: %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(query, want) {
+					t.Errorf("query %q does not contain %q", query, want)
+				}
+			}
+			if len(args) != len(tt.args) {
+				t.Fatalf("args = %v, want %v", args, tt.args)
+			}
+			for i := range args {
+				if a, ok := args[i].(time.Time); ok {
+					if !a.Equal(tt.args[i].(time.Time)) {
+						t.Errorf("args[%d] = %v, want %v", i, a, tt.args[i])
+					}
+				} else if args[i] != tt.args[i] {
+					t.Errorf("args[%d] = %v (%T), want %v (%T)", i, args[i], args[i], tt.args[i], tt.args[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNextTaskCursor(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	next := created.Add(time.Hour)
+	task := &Tasks{Id: 3, CreatedAt: created, NextExecuteAt: sql.NullTime{Time: next, Valid: true}}
+
+	for sortBy, want := range map[string]time.Time{"": created, TaskSortByNextExecuteAt: next} {
+		cursor, err := DecodeTaskCursor(nextTaskCursor(task, sortBy, false))
+		if err != nil {
+			t.Fatalf("DecodeTaskCursor failed: %v", err)
+		}
+		if !cursor.Value.Equal(want) || cursor.Id != 3 {
+			t.Errorf("sortBy %q: cursor = %+v, want value %v", sortBy, cursor, want)
+		}
+	}
+}
+
+func TestTaskListFilter_Where(t *testing.T) {
+	priority := int64(2)
+	filter := &TaskListFilter{
+		BusinessId: 1,
+		Status:     []int64{TaskStatusPending, TaskStatusFailed},
+		Priority:   &priority,
+		Tags:       []string{" vip ", "VIP", "eu"},
+	}
+	where, args := filter.where()
+
+	for _, want := range []string{
+		"`business_id` = ?",
+		"`status` in (?,?)",
+		"`priority` = ?",
+		"`tag` in (?,?) group by `task_id` having count(*) = ?",
+	} {
+		if !strings.Contains(where, want) {
+			t.Errorf("where %q does not contain %q", where, want)
+		}
+	}
+	// 标签去重后为 vip、eu，全部匹配时按标签数过滤
+	if len(args) != 8 || args[5] != "vip" || args[6] != "eu" || args[7] != 2 {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	filter.TagMatch = TagMatchAny
+	if where, _ := filter.where(); strings.Contains(where, "having") {
+		t.Errorf("any-tag match must not require every tag: %q", where)
+	}
+}
+
+// rawCursor 按游标的编码方式编码任意内容，用于构造无效游标
+func rawCursor(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
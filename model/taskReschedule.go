@@ -0,0 +1,67 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxRescheduleTasks 单次按ID改期的最大任务数，与 sdk.MaxBatchRescheduleTasks 一致
+const MaxRescheduleTasks = 500
+
+// 单个任务的改期结果，取值与 sdk.RescheduleOutcome 一致
+const (
+	RescheduleOutcomeRescheduled = "rescheduled"
+	RescheduleOutcomeSkipped     = "skipped"
+	RescheduleOutcomeNotFound    = "not_found"
+)
+
+// taskStatusNames 任务状态名称，用于说明跳过原因
+var taskStatusNames = map[int64]string{
+	TaskStatusPending:   "pending",
+	TaskStatusRunning:   "running",
+	TaskStatusSucceeded: "succeeded",
+	TaskStatusFailed:    "failed",
+	TaskStatusCancelled: "cancelled",
+	TaskStatusExpired:   "expired",
+}
+
+// TaskReschedule 改期参数，DelaySeconds 与 At 必须且只能设置一个
+// DelaySeconds 在任务当前的执行时间上顺延（负数为提前），At 直接设置新的执行时间
+type TaskReschedule struct {
+	DelaySeconds int64      `json:"delay_seconds,omitempty"`
+	At           *time.Time `json:"at,omitempty"`
+}
+
+// TaskRescheduleResult 单个任务的改期结果
+type TaskRescheduleResult struct {
+	Id      int64
+	Outcome string
+	Reason  string
+	Task    *Tasks // 处理后的任务，不存在时为 nil
+}
+
+// Validate 校验改期参数
+func (r *TaskReschedule) Validate() error {
+	if r.DelaySeconds != 0 && r.At != nil {
+		return fmt.Errorf("delay_seconds and at are mutually exclusive")
+	}
+	if r.DelaySeconds == 0 && (r.At == nil || r.At.IsZero()) {
+		return fmt.Errorf("either delay_seconds or at is required")
+	}
+	return nil
+}
+
+// assignments 返回改期的 set 子句和参数
+// MySQL 按顺序计算 set 子句，next_execute_at 必须先于 scheduled_at 赋值，才能基于修改前的 scheduled_at 顺延
+func (r *TaskReschedule) assignments() (string, []any) {
+	if r.At != nil {
+		return "`next_execute_at` = ?, `scheduled_at` = ?, `version` = `version` + 1", []any{*r.At, *r.At}
+	}
+	return "`next_execute_at` = coalesce(`next_execute_at`, `scheduled_at`) + interval ? second, " +
+		"`scheduled_at` = `scheduled_at` + interval ? second, `version` = `version` + 1", []any{r.DelaySeconds, r.DelaySeconds}
+}
+
+// skipReason 返回任务不能改期的原因
+func skipReason(status int64) string {
+	return "task is " + taskStatusName(status)
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskReschedule_Validate(t *testing.T) {
+	at := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	zero := time.Time{}
+
+	tests := []struct {
+		name    string
+		r       TaskReschedule
+		wantErr string
+	}{
+		{name: "delay", r: TaskReschedule{DelaySeconds: 60}},
+		{name: "negative delay moves earlier", r: TaskReschedule{DelaySeconds: -60}},
+		{name: "absolute time", r: TaskReschedule{At: &at}},
+		{name: "both set", r: TaskReschedule{DelaySeconds: 60, At: &at}, wantErr: "mutually exclusive"},
+		{name: "neither set", r: TaskReschedule{}, wantErr: "is required"},
+		{name: "zero time", r: TaskReschedule{At: &zero}, wantErr: "is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.r.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTaskReschedule_Assignments(t *testing.T) {
+	at := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	set, args := (&TaskReschedule{At: &at}).assignments()
+	if !strings.HasPrefix(set, "`next_execute_at` = ?, `scheduled_at` = ?") || len(args) != 2 {
+		t.Errorf("absolute assignments = %q %v", set, args)
+	}
+
+	// next_execute_at 必须先于 scheduled_at 赋值，才能基于修改前的 scheduled_at 顺延
+	set, args = (&TaskReschedule{DelaySeconds: 30}).assignments()
+	if strings.Index(set, "`next_execute_at` =") > strings.Index(set, "`scheduled_at` =") {
+		t.Errorf("next_execute_at must be assigned before scheduled_at: %q", set)
+	}
+	if len(args) != 2 || args[0] != int64(30) || args[1] != int64(30) {
+		t.Errorf("delay args = %v, want [30 30]", args)
+	}
+	if !strings.HasSuffix(set, "`version` = `version` + 1") {
+		t.Errorf("reschedule must bump the version: %q", set)
+	}
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"sort"
+	"strings"
+
+	"task-center/pkg/search"
+)
+
+// callbackHostExpr 从 callback_url 中取出主机名（去掉协议、路径、查询串和端口）
+const callbackHostExpr = "lower(substring_index(substring_index(substring_index(substring_index(" +
+	"`callback_url`, '://', -1), '/', 1), '?', 1), ':', 1))"
+
+// searchTimeColumns 搜索时间字段对应的列
+var searchTimeColumns = map[string]string{
+	search.FieldScheduled: "`scheduled_at`",
+	search.FieldExecuted:  "`executed_at`",
+	search.FieldCompleted: "`completed_at`",
+	search.FieldCreated:   "`created_at`",
+}
+
+// searchConditions 将搜索条件转换为 SQL 条件和参数，调用方需保证 q 已通过 Validate
+func searchConditions(q *search.Query) ([]string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	if q.IsEmpty() {
+		return conds, args
+	}
+
+	// 按键排序，保证相同查询生成相同的SQL
+	keys := make([]string, 0, len(q.Metadata))
+	for key := range q.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		// metadata 是自由文本列，非 JSON 的行会让 json_extract 报错，使整个查询失败
+		conds = append(conds, "json_valid(`metadata`) and json_unquote(json_extract(`metadata`, ?)) = ?")
+		args = append(args, metadataPath(key), q.Metadata[key])
+	}
+	for _, host := range q.Hosts {
+		if strings.HasPrefix(host, "*.") {
+			conds = append(conds, callbackHostExpr+" like ?")
+			args = append(args, "%"+escapeLike(strings.ToLower(host[1:])))
+			continue
+		}
+		conds = append(conds, callbackHostExpr+" = ?")
+		args = append(args, strings.ToLower(host))
+	}
+	for _, text := range q.URLContains {
+		conds = append(conds, "`callback_url` like ?")
+		args = append(args, "%"+escapeLike(text)+"%")
+	}
+	for _, text := range q.ErrorContains {
+		conds = append(conds, "`error_message` like ?")
+		args = append(args, "%"+escapeLike(text)+"%")
+	}
+
+	timeRanges := []struct {
+		field string
+		r     *search.TimeRange
+	}{
+		{search.FieldScheduled, q.Scheduled},
+		{search.FieldExecuted, q.Executed},
+		{search.FieldCompleted, q.Completed},
+		{search.FieldCreated, q.Created},
+	}
+	for _, tr := range timeRanges {
+		if tr.r == nil {
+			continue
+		}
+		column := searchTimeColumns[tr.field]
+		if tr.r.From != nil {
+			op := " >= ?"
+			if tr.r.ExcludeFrom {
+				op = " > ?"
+			}
+			conds = append(conds, column+op)
+			args = append(args, *tr.r.From)
+		}
+		if tr.r.To != nil {
+			op := " <= ?"
+			if tr.r.ExcludeTo {
+				op = " < ?"
+			}
+			conds = append(conds, column+op)
+			args = append(args, *tr.r.To)
+		}
+	}
+
+	if r := q.Retries; r != nil {
+		if r.Min != nil {
+			conds = append(conds, "`current_retry` >= ?")
+			args = append(args, *r.Min)
+		}
+		if r.Max != nil {
+			conds = append(conds, "`current_retry` <= ?")
+			args = append(args, *r.Max)
+		}
+	}
+
+	for _, text := range q.Text {
+		conds = append(conds, "`business_unique_id` like ?")
+		args = append(args, "%"+escapeLike(text)+"%")
+	}
+
+	return conds, args
+}
+
+// metadataPath 将 a.b 形式的元数据键转换为 JSON 路径 $."a"."b"
+// 键已由 search 包校验，只包含字母、数字、下划线和中划线
+func metadataPath(key string) string {
+	parts := strings.Split(key, ".")
+	return `$."` + strings.Join(parts, `"."`) + `"`
+}
+
+// escapeLike 转义 like 通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
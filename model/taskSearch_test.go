@@ -0,0 +1,91 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"task-center/pkg/search"
+)
+
+func TestSearchConditions(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *search.Query
+		conds []string
+		args  []any
+	}{
+		{
+			name:  "empty",
+			query: search.NewQuery(),
+		},
+		{
+			name:  "metadata guarded by json_valid and sorted by key",
+			query: search.NewQuery().WithMetadata("region", "eu").WithMetadata("order.id", "42"),
+			conds: []string{
+				"json_valid(`metadata`) and json_unquote(json_extract(`metadata`, ?)) = ?",
+				"json_valid(`metadata`) and json_unquote(json_extract(`metadata`, ?)) = ?",
+			},
+			args: []any{`$."order"."id"`, "42", `$."region"`, "eu"},
+		},
+		{
+			name:  "exact host is lowercased",
+			query: search.NewQuery().WithHost("API.Example.com"),
+			conds: []string{callbackHostExpr + " = ?"},
+			args:  []any{"api.example.com"},
+		},
+		{
+			name:  "wildcard host matches subdomains",
+			query: search.NewQuery().WithHost("*.Example_1.com"),
+			conds: []string{callbackHostExpr + " like ?"},
+			args:  []any{`%.example\_1.com`},
+		},
+		{
+			name:  "like wildcards are escaped",
+			query: search.NewQuery().WithURLContains(`50%_off\`).WithErrorContains("timeout").WithText("order_1"),
+			conds: []string{"`callback_url` like ?", "`error_message` like ?", "`business_unique_id` like ?"},
+			args:  []any{`%50\%\_off\\%`, "%timeout%", `%order\_1%`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conds, args := searchConditions(tt.query)
+			if len(conds) != len(tt.conds) || (len(conds) > 0 && !reflect.DeepEqual(conds, tt.conds)) {
+				t.Errorf("conds = %q, want %q", conds, tt.conds)
+			}
+			if len(args) != len(tt.args) || (len(args) > 0 && !reflect.DeepEqual(args, tt.args)) {
+				t.Errorf("args = %v, want %v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestSearchConditions_Ranges(t *testing.T) {
+	q, err := search.Parse("scheduled:>2024-01-01T00:00:00Z retries:2..5")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	conds, args := searchConditions(q)
+	want := "`scheduled_at` > ? and `current_retry` >= ? and `current_retry` <= ?"
+	if got := strings.Join(conds, " and "); got != want {
+		t.Errorf("conds = %q, want %q", got, want)
+	}
+	if len(args) != 3 || args[1] != 2 || args[2] != 5 {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestMetadataPath(t *testing.T) {
+	tests := map[string]string{
+		"region":    `$."region"`,
+		"order.id":  `$."order"."id"`,
+		"a-b.c_d.e": `$."a-b"."c_d"."e"`,
+	}
+	for key, want := range tests {
+		if got := metadataPath(key); got != want {
+			t.Errorf("metadataPath(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
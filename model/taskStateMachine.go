@@ -0,0 +1,206 @@
+package model
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TaskStatusAny 注册钩子时匹配任意状态
+const TaskStatusAny int64 = -1
+
+// TaskActorSystem 未在 ctx 中指定操作者时事件记录的操作者
+const TaskActorSystem = "system"
+
+// TaskActorReaper 回收执行超时任务时使用的操作者，回收将执行中的任务放回待执行
+const TaskActorReaper = "reaper"
+
+// defaultTaskTransitions 默认允许的任务状态变更，状态不变总是允许的
+// 成功和取消是终态；执行中的任务可以回到待执行等待重试，失败和过期的任务可以重新进入待执行
+var defaultTaskTransitions = map[int64][]int64{
+	TaskStatusPending: {TaskStatusRunning, TaskStatusCancelled, TaskStatusExpired},
+	TaskStatusRunning: {TaskStatusSucceeded, TaskStatusFailed, TaskStatusPending},
+	TaskStatusFailed:  {TaskStatusPending},
+	TaskStatusExpired: {TaskStatusPending},
+}
+
+// DefaultTaskStateMachine 模型和存储实现使用的状态机，服务启动时在其上注册钩子
+var DefaultTaskStateMachine = NewTaskStateMachine()
+
+// TaskTransition 一次任务状态变更，对应 task_events 中的一条记录
+type TaskTransition struct {
+	TaskId     int64
+	BusinessId int64
+	From       int64
+	To         int64
+	Actor      string
+	Reason     string
+	At         time.Time
+	Task       *Tasks // 变更的任务，用于读取优先级、计划时间等不随状态变化的字段，钩子不得修改；状态以 From、To 为准
+}
+
+// TaskTransitionHook 状态变更提交后执行的钩子
+// 钩子不能阻止变更，按注册顺序同步执行，耗时操作应自行异步处理
+type TaskTransitionHook func(ctx context.Context, transition *TaskTransition)
+
+// TaskCreatedHook 任务创建提交后执行的钩子，创建不是状态变更，不写入 task_events
+type TaskCreatedHook func(ctx context.Context, task *Tasks)
+
+// TaskStateMachine 任务状态机，定义合法的状态变更以及变更提交后执行的钩子
+type TaskStateMachine struct {
+	mu          sync.RWMutex
+	transitions map[int64][]int64
+	hooks       []*taskTransitionHook
+	created     []*TaskCreatedHook
+}
+
+type taskTransitionHook struct {
+	from, to int64
+	fn       TaskTransitionHook
+}
+
+// NewTaskStateMachine 创建使用默认状态变更规则的状态机
+func NewTaskStateMachine() *TaskStateMachine {
+	transitions := make(map[int64][]int64, len(defaultTaskTransitions))
+	for from, to := range defaultTaskTransitions {
+		transitions[from] = append([]int64(nil), to...)
+	}
+	return &TaskStateMachine{transitions: transitions}
+}
+
+// Can 判断任务能否从 from 状态变更为 to 状态
+func (sm *TaskStateMachine) Can(from, to int64) bool {
+	if from == to {
+		return true
+	}
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	for _, next := range sm.transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 校验 current 能否变更为 to 状态，不允许时返回 *TaskTransitionError
+func (sm *TaskStateMachine) Validate(current *Tasks, to int64) error {
+	if !sm.Can(current.Status, to) {
+		return &TaskTransitionError{From: current.Status, To: to, Current: current}
+	}
+	return nil
+}
+
+// OnTransition 注册从 from 变更为 to 时执行的钩子，TaskStatusAny 匹配任意状态，返回取消注册的函数
+func (sm *TaskStateMachine) OnTransition(from, to int64, fn TaskTransitionHook) (remove func()) {
+	hook := &taskTransitionHook{from: from, to: to, fn: fn}
+
+	sm.mu.Lock()
+	sm.hooks = append(sm.hooks, hook)
+	sm.mu.Unlock()
+
+	return func() {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		for i, h := range sm.hooks {
+			if h == hook {
+				sm.hooks = append(sm.hooks[:i:i], sm.hooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// OnCreated 注册任务创建后执行的钩子，返回取消注册的函数
+func (sm *TaskStateMachine) OnCreated(fn TaskCreatedHook) (remove func()) {
+	hook := &fn
+
+	sm.mu.Lock()
+	sm.created = append(sm.created, hook)
+	sm.mu.Unlock()
+
+	return func() {
+		sm.mu.Lock()
+		defer sm.mu.Unlock()
+		for i, h := range sm.created {
+			if h == hook {
+				sm.created = append(sm.created[:i:i], sm.created[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// FireCreated 执行创建钩子，在任务创建提交之后调用
+func (sm *TaskStateMachine) FireCreated(ctx context.Context, tasks ...*Tasks) {
+	sm.mu.RLock()
+	hooks := append([]*TaskCreatedHook(nil), sm.created...)
+	sm.mu.RUnlock()
+
+	for _, task := range tasks {
+		for _, hook := range hooks {
+			(*hook)(ctx, task)
+		}
+	}
+}
+
+// Fire 执行匹配的钩子，在状态变更提交之后调用
+func (sm *TaskStateMachine) Fire(ctx context.Context, transitions ...*TaskTransition) {
+	sm.mu.RLock()
+	hooks := append([]*taskTransitionHook(nil), sm.hooks...)
+	sm.mu.RUnlock()
+
+	for _, transition := range transitions {
+		for _, hook := range hooks {
+			if (hook.from == TaskStatusAny || hook.from == transition.From) &&
+				(hook.to == TaskStatusAny || hook.to == transition.To) {
+				hook.fn(ctx, transition)
+			}
+		}
+	}
+}
+
+// CanTransitTask 按默认状态机判断任务能否从 from 状态变更为 to 状态
+func CanTransitTask(from, to int64) bool {
+	return DefaultTaskStateMachine.Can(from, to)
+}
+
+type taskActorKey struct{}
+
+type taskActor struct {
+	actor, reason string
+}
+
+// WithTaskActor 设置状态变更的操作者和原因，记录在 task_events 中
+func WithTaskActor(ctx context.Context, actor, reason string) context.Context {
+	return context.WithValue(ctx, taskActorKey{}, taskActor{actor: actor, reason: reason})
+}
+
+// TaskActorFromContext 返回 ctx 中的操作者和原因，未设置时操作者为 TaskActorSystem
+func TaskActorFromContext(ctx context.Context) (actor, reason string) {
+	if v, ok := ctx.Value(taskActorKey{}).(taskActor); ok && v.actor != "" {
+		return v.actor, v.reason
+	}
+	return TaskActorSystem, ""
+}
+
+// NewTaskTransition 根据 ctx 中的操作者创建状态变更记录
+func NewTaskTransition(ctx context.Context, task *Tasks, from, to int64, at time.Time) *TaskTransition {
+	actor, reason := TaskActorFromContext(ctx)
+	return &TaskTransition{
+		TaskId:     task.Id,
+		BusinessId: task.BusinessId,
+		From:       from,
+		To:         to,
+		Actor:      actor,
+		Reason:     reason,
+		At:         at,
+		Task:       task,
+	}
+}
+
+// DeadLettered 执行中变为失败且重试次数已用完，任务进入死信，不再自动重试
+func (t *TaskTransition) DeadLettered() bool {
+	return t.From == TaskStatusRunning && t.To == TaskStatusFailed && t.Task != nil && t.Task.CurrentRetry >= t.Task.MaxRetries
+}
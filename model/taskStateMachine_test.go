@@ -0,0 +1,104 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTaskStateMachine_Can(t *testing.T) {
+	statuses := []int64{
+		TaskStatusPending, TaskStatusRunning, TaskStatusSucceeded,
+		TaskStatusFailed, TaskStatusCancelled, TaskStatusExpired,
+	}
+	allowed := map[[2]int64]bool{
+		{TaskStatusPending, TaskStatusRunning}:   true,
+		{TaskStatusPending, TaskStatusCancelled}: true,
+		{TaskStatusPending, TaskStatusExpired}:   true,
+		{TaskStatusRunning, TaskStatusSucceeded}: true,
+		{TaskStatusRunning, TaskStatusFailed}:    true,
+		{TaskStatusRunning, TaskStatusPending}:   true,
+		{TaskStatusFailed, TaskStatusPending}:    true,
+		{TaskStatusExpired, TaskStatusPending}:   true,
+	}
+
+	sm := NewTaskStateMachine()
+	for _, from := range statuses {
+		for _, to := range statuses {
+			// 状态不变总是允许的
+			want := from == to || allowed[[2]int64{from, to}]
+			if got := sm.Can(from, to); got != want {
+				t.Errorf("Can(%s, %s) = %v, want %v", taskStatusName(from), taskStatusName(to), got, want)
+			}
+		}
+	}
+}
+
+func TestTaskStateMachine_Validate(t *testing.T) {
+	sm := NewTaskStateMachine()
+	current := &Tasks{Id: 1, Status: TaskStatusSucceeded}
+
+	err := sm.Validate(current, TaskStatusPending)
+	var transitionErr *TaskTransitionError
+	if !errors.As(err, &transitionErr) {
+		t.Fatalf("Validate() error = %v, want *TaskTransitionError", err)
+	}
+	if transitionErr.From != TaskStatusSucceeded || transitionErr.To != TaskStatusPending || transitionErr.Current != current {
+		t.Errorf("unexpected transition error: %+v", transitionErr)
+	}
+
+	if err := sm.Validate(&Tasks{Status: TaskStatusFailed}, TaskStatusPending); err != nil {
+		t.Errorf("Validate(failed -> pending) error = %v", err)
+	}
+}
+
+func TestTaskStateMachine_Fire(t *testing.T) {
+	sm := NewTaskStateMachine()
+	var fired []string
+	record := func(name string) TaskTransitionHook {
+		return func(context.Context, *TaskTransition) { fired = append(fired, name) }
+	}
+	sm.OnTransition(TaskStatusPending, TaskStatusRunning, record("claim"))
+	sm.OnTransition(TaskStatusAny, TaskStatusFailed, record("any->failed"))
+	removeAll := sm.OnTransition(TaskStatusAny, TaskStatusAny, record("any"))
+
+	ctx := WithTaskActor(context.Background(), "user-1", "manual")
+	task := &Tasks{Id: 1, BusinessId: 2}
+	sm.Fire(ctx,
+		NewTaskTransition(ctx, task, TaskStatusPending, TaskStatusRunning, time.Now()),
+		NewTaskTransition(ctx, task, TaskStatusRunning, TaskStatusFailed, time.Now()),
+	)
+	want := []string{"claim", "any", "any->failed", "any"}
+	if len(fired) != len(want) {
+		t.Fatalf("fired = %v, want %v", fired, want)
+	}
+	for i := range want {
+		if fired[i] != want[i] {
+			t.Fatalf("fired = %v, want %v", fired, want)
+		}
+	}
+
+	// 取消注册后不再执行
+	removeAll()
+	fired = nil
+	sm.Fire(ctx, NewTaskTransition(ctx, task, TaskStatusFailed, TaskStatusPending, time.Now()))
+	if len(fired) != 0 {
+		t.Errorf("fired after remove = %v", fired)
+	}
+}
+
+func TestNewTaskTransition_Actor(t *testing.T) {
+	task := &Tasks{Id: 1, BusinessId: 2}
+
+	transition := NewTaskTransition(context.Background(), task, TaskStatusPending, TaskStatusRunning, time.Now())
+	if transition.Actor != TaskActorSystem || transition.Task != task || transition.BusinessId != 2 {
+		t.Errorf("unexpected transition without actor: %+v", transition)
+	}
+
+	ctx := WithTaskActor(context.Background(), TaskActorReaper, "lease expired")
+	transition = NewTaskTransition(ctx, task, TaskStatusRunning, TaskStatusPending, time.Now())
+	if transition.Actor != TaskActorReaper || transition.Reason != "lease expired" {
+		t.Errorf("actor = %q, reason = %q", transition.Actor, transition.Reason)
+	}
+}
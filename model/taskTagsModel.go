@@ -0,0 +1,223 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+var _ TaskTagsModel = (*customTaskTagsModel)(nil)
+
+// 标签限制，取值与 sdk.MaxTags、sdk.MaxTagLength 一致
+// 10 个 32 字符的标签编码为 JSON 后仍能放入 tasks.tags 的 varchar(512)
+const (
+	MaxTaskTags      = 10
+	MaxTaskTagLength = 32
+)
+
+// 标签匹配方式，取值与 sdk.TagMatchMode 一致
+const (
+	TagMatchAll = "all" // 包含全部标签
+	TagMatchAny = "any" // 包含任一标签
+)
+
+// taskTagsTable 任务标签表名，供 tasks 模型在同一事务中维护标签
+const taskTagsTable = "`task_tags`"
+
+// ErrInvalidTags 标签数量、长度或内容不合法
+var ErrInvalidTags = errors.New("invalid tags")
+
+type (
+	// TaskTagsModel is an interface to be customized, add more methods here,
+	// and implement the added methods in customTaskTagsModel.
+	TaskTagsModel interface {
+		taskTagsModel
+		ListByTask(ctx context.Context, taskId int64) ([]string, error)
+		CountByBusiness(ctx context.Context, businessId int64) (map[string]int64, error)
+		ReplaceTaskTags(ctx context.Context, taskId, businessId int64, tags []string) error
+	}
+
+	customTaskTagsModel struct {
+		*defaultTaskTagsModel
+	}
+)
+
+// NewTaskTagsModel returns a model for the database table.
+func NewTaskTagsModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) TaskTagsModel {
+	return &customTaskTagsModel{
+		defaultTaskTagsModel: newTaskTagsModel(conn, c, opts...),
+	}
+}
+
+// ListByTask 查询任务的标签，按标签排序
+func (m *customTaskTagsModel) ListByTask(ctx context.Context, taskId int64) ([]string, error) {
+	var tags []string
+	query := fmt.Sprintf("select `tag` from %s where `task_id` = ? order by `tag`", m.table)
+	if err := m.QueryRowsNoCacheCtx(ctx, &tags, query, taskId); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// CountByBusiness 统计业务系统下每个标签的任务数，走 (business_id, tag, task_id) 索引
+func (m *customTaskTagsModel) CountByBusiness(ctx context.Context, businessId int64) (map[string]int64, error) {
+	var rows []struct {
+		Tag   string `db:"tag"`
+		Count int64  `db:"count"`
+	}
+	query := fmt.Sprintf("select `tag`, count(*) as `count` from %s where `business_id` = ? group by `tag`", m.table)
+	if err := m.QueryRowsNoCacheCtx(ctx, &rows, query, businessId); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Tag] = row.Count
+	}
+	return counts, nil
+}
+
+// ReplaceTaskTags 将任务的标签替换为 tags，只增删有变化的行
+// 只更新 task_tags，tasks.tags 需由调用方同步更新，通常应使用 TasksModel.UpdateTags
+func (m *customTaskTagsModel) ReplaceTaskTags(ctx context.Context, taskId, businessId int64, tags []string) error {
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+
+	var removed []*TaskTags
+	err = m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		removed, err = replaceTaskTags(ctx, session, m.table, taskId, businessId, tags)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return m.DelCacheCtx(ctx, taskTagsCacheKeys(removed)...)
+}
+
+// replaceTaskTags 在事务中替换任务标签，返回被删除的行用于清理缓存
+func replaceTaskTags(ctx context.Context, session sqlx.Session, table string, taskId, businessId int64, tags []string) ([]*TaskTags, error) {
+	var existing []*TaskTags
+	query := fmt.Sprintf("select %s from %s where `task_id` = ? for update", taskTagsRows, table)
+	if err := session.QueryRowsCtx(ctx, &existing, query, taskId); err != nil {
+		return nil, err
+	}
+
+	keep := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		keep[tag] = true
+	}
+
+	var removed []*TaskTags
+	present := make(map[string]bool, len(existing))
+	for _, row := range existing {
+		present[row.Tag] = true
+		if !keep[row.Tag] {
+			removed = append(removed, row)
+		}
+	}
+
+	if len(removed) > 0 {
+		args := make([]any, 0, len(removed))
+		for _, row := range removed {
+			args = append(args, row.Id)
+		}
+		query := fmt.Sprintf("delete from %s where `id` in (%s)", table, placeholders(len(removed)))
+		if _, err := session.ExecCtx(ctx, query, args...); err != nil {
+			return nil, err
+		}
+	}
+
+	var values []string
+	var args []any
+	for _, tag := range tags {
+		if present[tag] {
+			continue
+		}
+		values = append(values, "(?, ?, ?)")
+		args = append(args, taskId, businessId, tag)
+	}
+	if len(values) > 0 {
+		query := fmt.Sprintf("insert into %s (%s) values %s", table, taskTagsRowsExpectAutoSet, strings.Join(values, ", "))
+		if _, err := session.ExecCtx(ctx, query, args...); err != nil {
+			return nil, err
+		}
+	}
+
+	return removed, nil
+}
+
+// taskTagsCacheKeys 返回标签行的缓存键
+func taskTagsCacheKeys(rows []*TaskTags) []string {
+	keys := make([]string, 0, len(rows)*2)
+	for _, row := range rows {
+		keys = append(keys,
+			fmt.Sprintf("%s%v", cacheTaskTagsIdPrefix, row.Id),
+			fmt.Sprintf("%s%v:%v", cacheTaskTagsTaskIdTagPrefix, row.TaskId, row.Tag))
+	}
+	return keys
+}
+
+// NormalizeTags 去除首尾空白和重复标签，并校验数量、长度
+// 标签不能为空，也不能包含逗号（列表接口以逗号分隔标签）
+// 与 task_tags.tag 列的排序规则一致，只有大小写不同的标签视为重复，保留第一个
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		switch {
+		case tag == "":
+			return nil, fmt.Errorf("%w: empty tag", ErrInvalidTags)
+		case utf8.RuneCountInString(tag) > MaxTaskTagLength:
+			return nil, fmt.Errorf("%w: tag %q exceeds %d characters", ErrInvalidTags, tag, MaxTaskTagLength)
+		case strings.Contains(tag, ","):
+			return nil, fmt.Errorf("%w: tag %q contains a comma", ErrInvalidTags, tag)
+		}
+		key := strings.ToLower(tag)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxTaskTags {
+		return nil, fmt.Errorf("%w: %d tags exceeds the limit of %d", ErrInvalidTags, len(normalized), MaxTaskTags)
+	}
+	return normalized, nil
+}
+
+// ParseTags 解析 tasks.tags 中的 JSON 数组
+func ParseTags(tags sql.NullString) ([]string, error) {
+	if !tags.Valid || strings.TrimSpace(tags.String) == "" {
+		return nil, nil
+	}
+
+	var parsed []string
+	if err := json.Unmarshal([]byte(tags.String), &parsed); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTags, err)
+	}
+	return parsed, nil
+}
+
+// FormatTags 将标签编码为 tasks.tags 的 JSON 数组，没有标签时为 NULL
+func FormatTags(tags []string) sql.NullString {
+	if len(tags) == 0 {
+		return sql.NullString{}
+	}
+	// 不转义 HTML 字符，避免 < > & 被编码为 \u003c 等占用列长度
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(tags)
+	return sql.NullString{String: strings.TrimSuffix(buf.String(), "\n"), Valid: true}
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// taskTracerName 任务执行 span 使用的 tracer 名称
+const taskTracerName = "task-center/model"
+
+// traceContext 任务链路固定使用 W3C Trace Context，与全局 propagator 的配置无关
+var traceContext = propagation.TraceContext{}
+
+// TaskTraceParent 返回 ctx 中当前链路的 W3C traceparent，创建任务时写入 Tasks.TraceParent
+// ctx 中没有有效的 span 时返回无效值
+func TaskTraceParent(ctx context.Context) sql.NullString {
+	carrier := propagation.MapCarrier{}
+	traceContext.Inject(ctx, carrier)
+	traceParent := carrier.Get("traceparent")
+	return sql.NullString{String: traceParent, Valid: traceParent != ""}
+}
+
+// TaskSpanContext 解析任务保存的 traceparent，未保存或格式错误时返回无效的 SpanContext
+func TaskSpanContext(task *Tasks) trace.SpanContext {
+	if !task.TraceParent.Valid {
+		return trace.SpanContext{}
+	}
+	carrier := propagation.MapCarrier{"traceparent": task.TraceParent.String}
+	return trace.SpanContextFromContext(traceContext.Extract(context.Background(), carrier))
+}
+
+// ContextWithTaskTrace 以任务创建者的链路作为 ctx 的父 span，任务未保存 traceparent 时原样返回 ctx
+func ContextWithTaskTrace(ctx context.Context, task *Tasks) context.Context {
+	if parent := TaskSpanContext(task); parent.IsValid() {
+		return trace.ContextWithRemoteSpanContext(ctx, parent)
+	}
+	return ctx
+}
+
+// StartTaskAttemptSpan 为任务的一次执行尝试创建 span，attempt 从 1 开始
+// span 的父 span 是创建任务时的链路，使创建、调度、回调处于同一条链路；调度循环自身的 span 作为 link 关联
+// 调用方使用返回的 ctx 发送回调，并通过 InjectTaskTraceContext 转发 traceparent
+func StartTaskAttemptSpan(ctx context.Context, task *Tasks, attempt int) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.Int64("taskcenter.task_id", task.Id),
+			attribute.Int64("taskcenter.business_id", task.BusinessId),
+			attribute.Int("taskcenter.attempt", attempt),
+			attribute.String("http.request.method", task.CallbackMethod),
+			attribute.String("url.full", task.CallbackUrl),
+		),
+	}
+	if current := trace.SpanContextFromContext(ctx); current.IsValid() && TaskSpanContext(task).IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: current}))
+	}
+	return otel.Tracer(taskTracerName).Start(ContextWithTaskTrace(ctx, task), "TaskCenter callback "+task.CallbackMethod, opts...)
+}
+
+// EndTaskAttemptSpan 记录回调结果并结束 span，statusCode 为 0 表示没有收到响应
+func EndTaskAttemptSpan(span trace.Span, statusCode int, err error) {
+	if statusCode > 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+	}
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case statusCode >= http.StatusBadRequest:
+		span.SetStatus(codes.Error, http.StatusText(statusCode))
+	}
+	span.End()
+}
+
+// InjectTaskTraceContext 将 ctx 中的链路以 traceparent 请求头写入回调请求
+func InjectTaskTraceContext(ctx context.Context, header http.Header) {
+	traceContext.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// ExecutionTraceId 返回 ctx 中链路的 trace ID，写入 TaskExecutions.TraceId
+func ExecutionTraceId(ctx context.Context) sql.NullString {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: sc.TraceID().String(), Valid: true}
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/stringx"
+)
+
+// tasksRowsForVersionedUpdate 按版本号更新时写入的列，version 由更新语句自增
+var tasksRowsForVersionedUpdate = strings.Join(stringx.Remove(tasksFieldNames,
+	"`id`", "`created_at`", "`updated_at`", "`version`"), "=?,") + "=?"
+
+// taskStatusName 返回任务状态名称，未知状态返回 "status N"
+func taskStatusName(status int64) string {
+	if name, ok := taskStatusNames[status]; ok {
+		return name
+	}
+	return fmt.Sprintf("status %d", status)
+}
+
+// TaskVersionConflictError 任务在读取之后已被修改，Current 为最新的任务
+type TaskVersionConflictError struct {
+	Expected int64
+	Current  *Tasks
+}
+
+// Error 实现error接口
+func (e *TaskVersionConflictError) Error() string {
+	return fmt.Sprintf("task %d has been modified (expected version %d, current version %d)",
+		e.Current.Id, e.Expected, e.Current.Version)
+}
+
+// TaskTransitionError 不允许的任务状态变更，Current 为当前的任务
+type TaskTransitionError struct {
+	From    int64
+	To      int64
+	Current *Tasks
+}
+
+// Error 实现error接口
+func (e *TaskTransitionError) Error() string {
+	return fmt.Sprintf("task %d cannot transition from %s to %s", e.Current.Id, taskStatusName(e.From), taskStatusName(e.To))
+}
@@ -0,0 +1,651 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"task-center/pkg/event"
+)
+
+// 任务监听推送的 SSE 事件类型
+const (
+	TaskWatchEventStatusChanged = "task.status_changed" // 任务状态变更，id 为 task_events.id
+	TaskWatchEventSnapshot      = "task.snapshot"       // 连接建立时任务的当前状态，id 为连接时的事件位置
+)
+
+// TaskWatchConfig 任务状态推送配置
+type TaskWatchConfig struct {
+	PollInterval      time.Duration // 轮询 task_events 的间隔，本节点的状态变更会立即触发一次轮询
+	BatchSize         int           // 每次读取的最大事件数
+	HeartbeatInterval time.Duration // 没有事件时发送注释行的间隔，避免代理断开空闲连接
+	ReconnectDelay    time.Duration // 通过 retry 字段建议客户端的重连间隔
+	BufferSize        int           // 每个连接待发送的事件数上限，超过时断开连接，由客户端带 Last-Event-ID 重连补发
+	MaxTaskIds        int           // 单个连接最多监听的任务数
+	GapTimeout        time.Duration // 事件 id 出现空洞时等待的最长时间：id 较小的事务可能晚于较大的提交，超时后视为已回滚并跳过
+}
+
+// DefaultTaskWatchConfig 默认任务状态推送配置
+func DefaultTaskWatchConfig() *TaskWatchConfig {
+	return &TaskWatchConfig{
+		PollInterval:      time.Second,
+		BatchSize:         500,
+		HeartbeatInterval: 15 * time.Second,
+		ReconnectDelay:    3 * time.Second,
+		BufferSize:        256,
+		MaxTaskIds:        100,
+		GapTimeout:        10 * time.Second,
+	}
+}
+
+// TaskWatchHub 通过 SSE 推送任务状态变更，挂载在 GET /api/v1/tasks/watch
+// 事件来自 task_events：所有连接共用一个读取位置，每次轮询只执行一次查询，其他节点的状态变更同样能推送
+// 事件 id 即 task_events.id，客户端断线后带 Last-Event-ID 重连，从断开的位置补发
+type TaskWatchHub struct {
+	events   TaskEventsModel
+	tasks    TasksModel
+	business BusinessResolver
+	config   *TaskWatchConfig
+	wake     chan struct{}
+
+	// 读取位置后第一个空洞的出现时间，只由 poll 使用
+	gapAt    int64
+	gapSince time.Time
+
+	mu          sync.Mutex
+	started     bool
+	cursor      int64
+	subscribers map[*taskWatchSubscriber]struct{}
+}
+
+// taskWatchSubscriber 一个 SSE 连接，ch 被关闭表示连接需要断开
+type taskWatchSubscriber struct {
+	filter *taskWatchFilter
+	ch     chan *taskWatchEvent
+	closed bool
+}
+
+// taskWatchEvent 编码后的 SSE 事件
+type taskWatchEvent struct {
+	id        int64
+	eventType string
+	data      []byte
+}
+
+// taskWatchPayload SSE 事件的 data，task 为推送时任务的最新状态，任务已删除时为空
+type taskWatchPayload struct {
+	EventId    int64       `json:"event_id,omitempty"`
+	TaskId     int64       `json:"task_id"`
+	FromStatus int64       `json:"from_status"`
+	ToStatus   int64       `json:"to_status"`
+	Actor      string      `json:"actor,omitempty"`
+	Reason     string      `json:"reason,omitempty"`
+	At         time.Time   `json:"at"`
+	Task       *event.Task `json:"task,omitempty"`
+}
+
+// NewTaskWatchHub 创建任务状态推送，连接只能监听 business 确定的业务系统的任务，通常为 BusinessFromAPIKey
+func NewTaskWatchHub(events TaskEventsModel, tasks TasksModel, business BusinessResolver, config *TaskWatchConfig) *TaskWatchHub {
+	if config == nil {
+		config = DefaultTaskWatchConfig()
+	}
+	return &TaskWatchHub{
+		events:      events,
+		tasks:       tasks,
+		business:    business,
+		config:      config,
+		wake:        make(chan struct{}, 1),
+		subscribers: make(map[*taskWatchSubscriber]struct{}),
+	}
+}
+
+// Attach 在状态机上注册钩子，本节点的状态变更提交后立即读取新事件，不必等到下次轮询，返回取消注册的函数
+func (h *TaskWatchHub) Attach(sm *TaskStateMachine) (remove func()) {
+	return sm.OnTransition(TaskStatusAny, TaskStatusAny, func(ctx context.Context, t *TaskTransition) {
+		select {
+		case h.wake <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// Run 从最新的事件开始读取并推送，直到 ctx 结束；结束时断开所有连接
+func (h *TaskWatchHub) Run(ctx context.Context) error {
+	cursor, err := h.events.MaxId(ctx)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	h.cursor, h.started = cursor, true
+	h.mu.Unlock()
+	defer h.closeAll()
+
+	ticker := time.NewTicker(h.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-h.wake:
+		}
+		if err := h.poll(ctx); err != nil && ctx.Err() == nil {
+			logx.WithContext(ctx).Errorf("poll task events for watchers: %v", err)
+		}
+	}
+}
+
+// poll 读取新事件并分发给匹配的连接
+// 读取位置只推进到连续的事件为止，见 contiguous；查询任务在锁外完成，分发和推进读取位置在同一个锁内完成，
+// 新连接要么收到这批事件，要么从推进后的位置开始补发
+func (h *TaskWatchHub) poll(ctx context.Context) error {
+	for {
+		cursor := h.currentCursor()
+		events, err := h.events.ListAfter(ctx, cursor, 0, nil, h.config.BatchSize)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		full := len(events) == h.config.BatchSize
+		events, waiting := h.contiguous(cursor, events)
+		if len(events) == 0 {
+			return nil
+		}
+		h.dispatch(ctx, events)
+		if waiting || !full {
+			return nil
+		}
+	}
+}
+
+// contiguous 返回 cursor 之后 id 连续的事件，waiting 表示其后还有事件在等待空洞补齐
+// 自增 id 在插入时分配、在提交时可见，id 较小的事务可能晚于较大的提交，直接越过空洞会永久漏掉该事件；
+// 空洞持续超过 GapTimeout 时视为事务已回滚，跳过空洞
+func (h *TaskWatchHub) contiguous(cursor int64, events []*TaskEvents) ([]*TaskEvents, bool) {
+	next := cursor
+	for i, e := range events {
+		if e.Id == next+1 {
+			next = e.Id
+			continue
+		}
+		if h.gapAt != next {
+			h.gapAt, h.gapSince = next, time.Now()
+		}
+		if time.Since(h.gapSince) < h.config.GapTimeout {
+			return events[:i], true
+		}
+		next = e.Id
+	}
+	return events, false
+}
+
+// dispatch 将事件分发给匹配的连接并推进读取位置
+// 任务在锁外查询；查询期间新注册且匹配了未查询事件的连接，再查询一次，仍未完成时断开连接，由客户端续传
+func (h *TaskWatchHub) dispatch(ctx context.Context, events []*TaskEvents) {
+	built := make(map[int64]*taskWatchBuilt)
+	tasks := make(map[int64]*Tasks)
+	for attempt := 0; ; attempt++ {
+		h.buildEvents(ctx, events, h.currentSubscribers(), built, tasks)
+
+		h.mu.Lock()
+		if attempt < 2 && h.missingLocked(events, built) {
+			h.mu.Unlock()
+			continue
+		}
+		for _, e := range events {
+			b := built[e.Id]
+			for sub := range h.subscribers {
+				if !sub.filter.matchEvent(e) {
+					continue
+				}
+				switch {
+				case b == nil || b.event == nil:
+					// 查询任务失败，断开连接，由客户端重连后补发
+					h.disconnect(sub)
+				case sub.filter.matchTask(b.task):
+					h.send(sub, b.event)
+				}
+			}
+		}
+		h.cursor = events[len(events)-1].Id
+		h.mu.Unlock()
+		return
+	}
+}
+
+// taskWatchBuilt 编码后的事件和推送时任务的最新状态
+type taskWatchBuilt struct {
+	event *taskWatchEvent
+	task  *Tasks
+}
+
+// buildEvents 为至少匹配一个连接的事件查询任务并编码，结果写入 built，同一批事件的任务只查询一次
+func (h *TaskWatchHub) buildEvents(ctx context.Context, events []*TaskEvents, subs []*taskWatchSubscriber,
+	built map[int64]*taskWatchBuilt, tasks map[int64]*Tasks) {
+	for _, e := range events {
+		if _, ok := built[e.Id]; ok {
+			continue
+		}
+		if !slices.ContainsFunc(subs, func(sub *taskWatchSubscriber) bool { return sub.filter.matchEvent(e) }) {
+			continue
+		}
+		task, ok := tasks[e.TaskId]
+		if !ok {
+			var err error
+			task, err = h.tasks.FindOne(ctx, e.TaskId)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				logx.WithContext(ctx).Errorf("load task %d for watch event %d: %v", e.TaskId, e.Id, err)
+				built[e.Id] = &taskWatchBuilt{}
+				continue
+			}
+			tasks[e.TaskId] = task
+		}
+		built[e.Id] = &taskWatchBuilt{event: buildTaskWatchEvent(e, task), task: task}
+	}
+}
+
+// missingLocked 是否有连接匹配了尚未查询的事件，调用方持有 h.mu
+func (h *TaskWatchHub) missingLocked(events []*TaskEvents, built map[int64]*taskWatchBuilt) bool {
+	for _, e := range events {
+		if _, ok := built[e.Id]; ok {
+			continue
+		}
+		for sub := range h.subscribers {
+			if sub.filter.matchEvent(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// currentSubscribers 返回当前连接
+func (h *TaskWatchHub) currentSubscribers() []*taskWatchSubscriber {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subs := make([]*taskWatchSubscriber, 0, len(h.subscribers))
+	for sub := range h.subscribers {
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// currentCursor 返回当前读取位置
+func (h *TaskWatchHub) currentCursor() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.cursor
+}
+
+// send 将事件放入连接的队列，队列已满时断开连接而不是丢弃事件
+// 调用方持有 h.mu
+func (h *TaskWatchHub) send(sub *taskWatchSubscriber, event *taskWatchEvent) {
+	select {
+	case sub.ch <- event:
+	default:
+		h.disconnect(sub)
+	}
+}
+
+// disconnect 关闭连接的队列，连接随后断开，调用方持有 h.mu
+func (h *TaskWatchHub) disconnect(sub *taskWatchSubscriber) {
+	if !sub.closed {
+		sub.closed = true
+		close(sub.ch)
+		delete(h.subscribers, sub)
+	}
+}
+
+// subscribe 注册连接，返回注册时的读取位置；之后的事件由 poll 分发，之前的事件由连接自行补发
+func (h *TaskWatchHub) subscribe(filter *taskWatchFilter) (*taskWatchSubscriber, int64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.started {
+		return nil, 0, false
+	}
+	sub := &taskWatchSubscriber{filter: filter, ch: make(chan *taskWatchEvent, h.config.BufferSize)}
+	h.subscribers[sub] = struct{}{}
+	return sub, h.cursor, true
+}
+
+// unsubscribe 注销连接
+func (h *TaskWatchHub) unsubscribe(sub *taskWatchSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.disconnect(sub)
+}
+
+// closeAll 停止推送并断开所有连接
+func (h *TaskWatchHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers {
+		h.disconnect(sub)
+	}
+	h.started = false
+}
+
+// buildEvent 查询任务的最新状态并编码事件；任务已删除时 task 为空，查询失败时返回空事件
+func (h *TaskWatchHub) buildEvent(ctx context.Context, e *TaskEvents) (*taskWatchEvent, *Tasks) {
+	task, err := h.tasks.FindOne(ctx, e.TaskId)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		logx.WithContext(ctx).Errorf("load task %d for watch event %d: %v", e.TaskId, e.Id, err)
+		return nil, nil
+	}
+	return buildTaskWatchEvent(e, task), task
+}
+
+// buildTaskWatchEvent 编码状态变更事件，task 为空表示任务已删除，编码失败时返回空事件
+func buildTaskWatchEvent(e *TaskEvents, task *Tasks) *taskWatchEvent {
+	payload := &taskWatchPayload{
+		EventId:    e.Id,
+		TaskId:     e.TaskId,
+		FromStatus: e.FromStatus,
+		ToStatus:   e.ToStatus,
+		Actor:      e.Actor,
+		Reason:     e.Reason.String,
+		At:         e.CreatedAt,
+	}
+	if task != nil {
+		t := callbackTask(task)
+		payload.Task = &t
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil
+	}
+	return &taskWatchEvent{id: e.Id, eventType: TaskWatchEventStatusChanged, data: data}
+}
+
+// ServeHTTP 处理 GET /api/v1/tasks/watch，以 SSE 推送匹配的任务状态变更
+// 查询参数：task_id（可重复或逗号分隔）、status（变更后的状态）、priority、tags（须全部包含），都不传时监听业务系统的所有任务
+// 带 Last-Event-ID 请求头（或 last_event_id 参数）时先补发之后的事件；指定 task_id 且不续传，或 snapshot=true 时，先推送任务的当前状态
+func (h *TaskWatchHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeTaskWatchError(w, http.StatusMethodNotAllowed, "VALIDATION_ERROR", "method not allowed")
+		return
+	}
+	businessId, err := h.business(r)
+	if err != nil {
+		writeTaskWatchError(w, http.StatusUnauthorized, "AUTHENTICATION_ERROR", err.Error())
+		return
+	}
+	filter, err := parseTaskWatchFilter(r, businessId, h.config.MaxTaskIds)
+	if err != nil {
+		writeTaskWatchError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	lastEventId, err := parseLastEventId(r)
+	if err != nil {
+		writeTaskWatchError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeTaskWatchError(w, http.StatusInternalServerError, "SERVER_ERROR", "streaming is not supported")
+		return
+	}
+	sub, cursor, ok := h.subscribe(filter)
+	if !ok {
+		writeTaskWatchError(w, http.StatusServiceUnavailable, "SERVER_ERROR", "task watch is not running")
+		return
+	}
+	defer h.unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", h.config.ReconnectDelay.Milliseconds())
+	flusher.Flush()
+
+	ctx := r.Context()
+	sent := lastEventId
+	if lastEventId > 0 {
+		if sent, err = h.replay(ctx, w, filter, lastEventId, cursor); err != nil {
+			// 断开连接，客户端从已发送的位置重连
+			logx.WithContext(ctx).Errorf("replay task events after %d: %v", lastEventId, err)
+			return
+		}
+	}
+	sent = max(sent, cursor)
+	if len(filter.taskIds) > 0 && (lastEventId == 0 || r.URL.Query().Get("snapshot") == "true") {
+		if err := h.snapshot(ctx, w, filter, sent); err != nil {
+			logx.WithContext(ctx).Errorf("send task watch snapshot: %v", err)
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.config.HeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.ch:
+			if !ok {
+				return
+			}
+			if event.id <= sent {
+				continue
+			}
+			if err := writeTaskWatchEvent(w, event); err != nil {
+				return
+			}
+			sent = event.id
+		}
+		flusher.Flush()
+	}
+}
+
+// replay 补发 (after, until] 之间匹配的事件，返回最后处理的事件 id；until 之后的事件由 poll 分发
+func (h *TaskWatchHub) replay(ctx context.Context, w http.ResponseWriter, filter *taskWatchFilter, after, until int64) (int64, error) {
+	for after < until {
+		events, err := h.events.ListAfter(ctx, after, filter.businessId, filter.taskIds, h.config.BatchSize)
+		if err != nil {
+			return after, err
+		}
+		for _, e := range events {
+			if e.Id > until {
+				return after, nil
+			}
+			if filter.matchEvent(e) {
+				event, task := h.buildEvent(ctx, e)
+				if event == nil {
+					return after, fmt.Errorf("failed to build event %d", e.Id)
+				}
+				if filter.matchTask(task) {
+					if err := writeTaskWatchEvent(w, event); err != nil {
+						return after, err
+					}
+				}
+			}
+			after = e.Id
+		}
+		if len(events) < h.config.BatchSize {
+			break
+		}
+	}
+	return after, nil
+}
+
+// snapshot 推送监听任务的当前状态，事件 id 为 at，客户端从该位置续传
+func (h *TaskWatchHub) snapshot(ctx context.Context, w http.ResponseWriter, filter *taskWatchFilter, at int64) error {
+	for _, id := range filter.taskIds {
+		task, err := h.tasks.FindOne(ctx, id)
+		if errors.Is(err, ErrNotFound) || (err == nil && task.BusinessId != filter.businessId) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !filter.matchStatus(task.Status) || !filter.matchTask(task) {
+			continue
+		}
+
+		t := callbackTask(task)
+		data, err := json.Marshal(&taskWatchPayload{
+			TaskId:     task.Id,
+			FromStatus: task.Status,
+			ToStatus:   task.Status,
+			At:         task.UpdatedAt,
+			Task:       &t,
+		})
+		if err != nil {
+			return err
+		}
+		if err := writeTaskWatchEvent(w, &taskWatchEvent{id: at, eventType: TaskWatchEventSnapshot, data: data}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTaskWatchEvent 按 SSE 格式写出事件，JSON 编码的 data 不含换行
+func writeTaskWatchEvent(w http.ResponseWriter, event *taskWatchEvent) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.id, event.eventType, event.data)
+	return err
+}
+
+// writeTaskWatchError 返回与 API 一致的错误响应
+func writeTaskWatchError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"message": message,
+		"code":    code,
+	})
+}
+
+// taskWatchFilter 连接监听的任务范围
+type taskWatchFilter struct {
+	businessId int64
+	taskIds    []int64
+	statuses   []int64
+	priority   *int64
+	tags       []string
+}
+
+// parseTaskWatchFilter 从查询参数解析监听范围，businessId 为认证得到的业务系统
+func parseTaskWatchFilter(r *http.Request, businessId int64, maxTaskIds int) (*taskWatchFilter, error) {
+	filter := &taskWatchFilter{businessId: businessId}
+
+	query := r.URL.Query()
+	for _, value := range splitQueryValues(query["task_id"]) {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid task_id %q", value)
+		}
+		if !slices.Contains(filter.taskIds, id) {
+			filter.taskIds = append(filter.taskIds, id)
+		}
+	}
+	if len(filter.taskIds) > maxTaskIds {
+		return nil, fmt.Errorf("at most %d task_id values are allowed", maxTaskIds)
+	}
+	for _, value := range splitQueryValues(query["status"]) {
+		status, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || status < TaskStatusPending || status > TaskStatusExpired {
+			return nil, fmt.Errorf("invalid status %q", value)
+		}
+		filter.statuses = append(filter.statuses, status)
+	}
+	if value := query.Get("priority"); value != "" {
+		priority, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid priority %q", value)
+		}
+		filter.priority = &priority
+	}
+	if tags := splitQueryValues(query["tags"]); len(tags) > 0 {
+		var err error
+		if filter.tags, err = NormalizeTags(tags); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+// parseLastEventId 读取续传位置，EventSource 重连时使用 Last-Event-ID 请求头，无法设置请求头的客户端使用 last_event_id 参数
+func parseLastEventId(r *http.Request) (int64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("invalid Last-Event-ID %q", value)
+	}
+	return id, nil
+}
+
+// splitQueryValues 展开重复参数和逗号分隔的值
+func splitQueryValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+// matchEvent 按业务系统、任务ID和变更后的状态匹配事件，不需要查询任务
+func (f *taskWatchFilter) matchEvent(e *TaskEvents) bool {
+	if e.BusinessId != f.businessId {
+		return false
+	}
+	if len(f.taskIds) > 0 && !slices.Contains(f.taskIds, e.TaskId) {
+		return false
+	}
+	return f.matchStatus(e.ToStatus)
+}
+
+// matchTask 按优先级和标签匹配任务，没有这两个条件时总是匹配
+func (f *taskWatchFilter) matchTask(task *Tasks) bool {
+	if f.priority == nil && len(f.tags) == 0 {
+		return true
+	}
+	if task == nil {
+		return false
+	}
+	if f.priority != nil && task.Priority != *f.priority {
+		return false
+	}
+	if len(f.tags) > 0 {
+		tags, err := ParseTags(task.Tags)
+		if err != nil {
+			return false
+		}
+		for _, tag := range f.tags {
+			if !slices.Contains(tags, tag) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchStatus 按状态条件匹配，没有状态条件时总是匹配
+func (f *taskWatchFilter) matchStatus(status int64) bool {
+	return len(f.statuses) == 0 || slices.Contains(f.statuses, status)
+}
@@ -0,0 +1,203 @@
+package model
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTaskEvents 内存中的任务事件，只实现 TaskWatchHub 用到的方法
+type fakeTaskEvents struct {
+	TaskEventsModel
+
+	mu     sync.Mutex
+	events []*TaskEvents
+}
+
+func (f *fakeTaskEvents) add(ids ...int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, id := range ids {
+		f.events = append(f.events, &TaskEvents{Id: id, TaskId: 1, BusinessId: 1, ToStatus: TaskStatusRunning})
+	}
+}
+
+func (f *fakeTaskEvents) ListAfter(_ context.Context, afterId, businessId int64, taskIds []int64, limit int) ([]*TaskEvents, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []*TaskEvents
+	// 按 id 排序，与数据库查询一致
+	events := slices.Clone(f.events)
+	slices.SortFunc(events, func(a, b *TaskEvents) int { return cmp.Compare(a.Id, b.Id) })
+	for _, e := range events {
+		if e.Id > afterId && (businessId == 0 || e.BusinessId == businessId) && len(result) < limit {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeTaskEvents) MaxId(context.Context) (int64, error) {
+	return 0, nil
+}
+
+// fakeWatchTasks 记录 FindOne 调用时是否持有 hub 的锁
+type fakeWatchTasks struct {
+	TasksModel
+
+	hub     *TaskWatchHub
+	calls   int
+	blocked bool
+}
+
+func (f *fakeWatchTasks) FindOne(_ context.Context, id int64) (*Tasks, error) {
+	f.calls++
+	if f.hub.mu.TryLock() {
+		f.hub.mu.Unlock()
+	} else {
+		f.blocked = true
+	}
+	return &Tasks{Id: id, BusinessId: 1, Status: TaskStatusRunning}, nil
+}
+
+func newTestTaskWatchHub(config *TaskWatchConfig) (*TaskWatchHub, *fakeTaskEvents, *fakeWatchTasks) {
+	events := &fakeTaskEvents{}
+	tasks := &fakeWatchTasks{}
+	hub := NewTaskWatchHub(events, tasks, newTestBusinessResolver(), config)
+	tasks.hub = hub
+	hub.started = true
+	return hub, events, tasks
+}
+
+func receivedIds(sub *taskWatchSubscriber) []int64 {
+	var ids []int64
+	for {
+		select {
+		case e := <-sub.ch:
+			ids = append(ids, e.id)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestTaskWatchHub_PollWaitsForGaps(t *testing.T) {
+	config := DefaultTaskWatchConfig()
+	config.GapTimeout = time.Hour
+	hub, events, tasks := newTestTaskWatchHub(config)
+	sub, _, _ := hub.subscribe(&taskWatchFilter{businessId: 1})
+	ctx := context.Background()
+
+	// 事件 3 所在的事务尚未提交
+	events.add(1, 2, 4)
+	if err := hub.poll(ctx); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if hub.cursor != 2 {
+		t.Errorf("cursor = %d, want 2", hub.cursor)
+	}
+	if got := receivedIds(sub); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("received %v, want [1 2]", got)
+	}
+
+	events.add(3)
+	if err := hub.poll(ctx); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if hub.cursor != 4 {
+		t.Errorf("cursor = %d, want 4", hub.cursor)
+	}
+	if got := receivedIds(sub); len(got) != 2 || got[0] != 3 || got[1] != 4 {
+		t.Errorf("received %v, want [3 4]", got)
+	}
+
+	// 同一任务的事件只查询一次，且不在锁内查询
+	if tasks.calls != 2 || tasks.blocked {
+		t.Errorf("FindOne calls = %d, called under lock = %v", tasks.calls, tasks.blocked)
+	}
+}
+
+func TestTaskWatchHub_PollSkipsExpiredGaps(t *testing.T) {
+	config := DefaultTaskWatchConfig()
+	config.GapTimeout = 0
+	hub, events, _ := newTestTaskWatchHub(config)
+	sub, _, _ := hub.subscribe(&taskWatchFilter{businessId: 1})
+
+	// 事件 2 所在的事务已回滚
+	events.add(1, 3)
+	if err := hub.poll(context.Background()); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if hub.cursor != 3 {
+		t.Errorf("cursor = %d, want 3", hub.cursor)
+	}
+	if got := receivedIds(sub); len(got) != 2 {
+		t.Errorf("received %v, want [1 3]", got)
+	}
+}
+
+func TestTaskWatchHub_PollWithoutSubscribers(t *testing.T) {
+	hub, events, tasks := newTestTaskWatchHub(nil)
+	events.add(1, 2)
+	if err := hub.poll(context.Background()); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if hub.cursor != 2 || tasks.calls != 0 {
+		t.Errorf("cursor = %d, FindOne calls = %d, want 2 and 0", hub.cursor, tasks.calls)
+	}
+}
+
+func TestTaskWatchHub_BusinessFromAPIKey(t *testing.T) {
+	hub, _, _ := newTestTaskWatchHub(nil)
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		businessId string
+		wantStatus int
+	}{
+		{name: "missing API key", wantStatus: http.StatusUnauthorized},
+		{name: "business id of another tenant", apiKey: "key-2", businessId: "1", wantStatus: http.StatusUnauthorized},
+		{name: "invalid filter after auth", apiKey: "key-1", businessId: "1", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/watch?task_id=abc", nil)
+			if tt.apiKey != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.apiKey)
+			}
+			if tt.businessId != "" {
+				r.Header.Set("X-Business-ID", tt.businessId)
+			}
+			w := httptest.NewRecorder()
+			hub.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestParseTaskWatchFilter(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/watch?task_id=1,2&task_id=2&status=1&priority=2", nil)
+	r.Header.Set("X-Business-ID", "9")
+	filter, err := parseTaskWatchFilter(r, 1, 10)
+	if err != nil {
+		t.Fatalf("parseTaskWatchFilter failed: %v", err)
+	}
+	if filter.businessId != 1 {
+		t.Errorf("businessId = %d, want the authenticated business 1", filter.businessId)
+	}
+	if len(filter.taskIds) != 2 || len(filter.statuses) != 1 || filter.priority == nil || *filter.priority != 2 {
+		t.Errorf("unexpected filter: %+v", filter)
+	}
+
+	if _, err := parseTaskWatchFilter(r, 1, 1); err == nil {
+		t.Error("expected error for too many task_id values")
+	}
+}
@@ -1,17 +1,71 @@
 package model
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 )
 
 var _ TasksModel = (*customTasksModel)(nil)
 
+// 创建任务时 business_unique_id 冲突的处理策略，取值与 sdk.ConflictPolicy 一致
+const (
+	ConflictPolicyReject           = "reject"
+	ConflictPolicyReturnExisting   = "return_existing"
+	ConflictPolicyReplaceIfPending = "replace_if_pending"
+)
+
+// 创建任务的结果，取值与 sdk.CreateOutcome 一致
+const (
+	CreateOutcomeCreated  = "created"
+	CreateOutcomeExisting = "existing"
+	CreateOutcomeReplaced = "replaced"
+)
+
+// 任务状态，取值与 sdk.TaskStatus 一致
+const (
+	TaskStatusPending   = 0 // 待执行
+	TaskStatusRunning   = 1 // 执行中
+	TaskStatusSucceeded = 2 // 成功
+	TaskStatusFailed    = 3 // 失败
+	TaskStatusCancelled = 4 // 取消
+	TaskStatusExpired   = 5 // 过期
+)
+
+// mysqlErrDuplicateEntry 唯一键冲突错误码
+const mysqlErrDuplicateEntry = 1062
+
+// TaskConflictError 业务唯一ID冲突，Existing 为已存在的任务
+type TaskConflictError struct {
+	Existing *Tasks
+}
+
+// Error 实现error接口
+func (e *TaskConflictError) Error() string {
+	return fmt.Sprintf("task with business_unique_id %s already exists (id=%d, status=%d)",
+		e.Existing.BusinessUniqueId, e.Existing.Id, e.Existing.Status)
+}
+
 type (
 	// TasksModel is an interface to be customized, add more methods here,
 	// and implement the added methods in customTasksModel.
 	TasksModel interface {
 		tasksModel
+		InsertWithPolicy(ctx context.Context, data *Tasks, policy string) (*Tasks, string, error)
+		ReplacePending(ctx context.Context, data *Tasks) (bool, error)
+		ListByCursor(ctx context.Context, filter *TaskListFilter, page *TaskPageRequest) (*TaskPage, error)
+		UpdateTags(ctx context.Context, id int64, tags []string) error
+		CountByFilter(ctx context.Context, filter *TaskListFilter) (int64, error)
+		ApplyBulkAction(ctx context.Context, action string, tasks []*Tasks, reschedule *TaskReschedule) ([]int64, error)
+		Reschedule(ctx context.Context, businessId int64, ids []int64, reschedule *TaskReschedule) ([]*TaskRescheduleResult, error)
+		UpdateWithVersion(ctx context.Context, data *Tasks) error
+		CountPendingByPriority(ctx context.Context) ([]*TaskQueueDepth, error)
 	}
 
 	customTasksModel struct {
@@ -25,3 +79,655 @@ func NewTasksModel(conn sqlx.SqlConn, c cache.CacheConf, opts ...cache.Option) T
 		defaultTasksModel: newTasksModel(conn, c, opts...),
 	}
 }
+
+// InsertWithPolicy 创建任务，(business_id, business_unique_id) 已存在时按策略处理
+// 返回结果任务和创建结果；reject 以及已存在任务不再是待执行状态的 replace_if_pending 返回 *TaskConflictError
+// data.TraceParent 未设置时记录 ctx 中的链路，执行任务时据此串联创建者的链路
+func (m *customTasksModel) InsertWithPolicy(ctx context.Context, data *Tasks, policy string) (*Tasks, string, error) {
+	switch policy {
+	case "":
+		policy = ConflictPolicyReject
+	case ConflictPolicyReject, ConflictPolicyReturnExisting, ConflictPolicyReplaceIfPending:
+	default:
+		return nil, "", fmt.Errorf("invalid conflict policy: %s", policy)
+	}
+
+	tags, err := m.normalizeTaskTags(data)
+	if err != nil {
+		return nil, "", err
+	}
+	if !data.TraceParent.Valid {
+		data.TraceParent = TaskTraceParent(ctx)
+	}
+
+	created, err := m.create(ctx, data, tags)
+	if err == nil {
+		return created, CreateOutcomeCreated, nil
+	}
+	if !isDuplicateEntry(err) {
+		return nil, "", err
+	}
+
+	existing, err := m.FindOneByBusinessIdBusinessUniqueId(ctx, data.BusinessId, data.BusinessUniqueId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch policy {
+	case ConflictPolicyReturnExisting:
+		return existing, CreateOutcomeExisting, nil
+	case ConflictPolicyReplaceIfPending:
+		if existing.Status != TaskStatusPending {
+			return existing, "", &TaskConflictError{Existing: existing}
+		}
+
+		data.Id = existing.Id
+		replaced, err := m.ReplacePending(ctx, data)
+		if err != nil {
+			return nil, "", err
+		}
+		if !replaced {
+			// 替换前任务已被调度执行，返回最新状态
+			if current, err := m.FindOne(ctx, existing.Id); err == nil {
+				existing = current
+			}
+			return existing, "", &TaskConflictError{Existing: existing}
+		}
+
+		current, err := m.FindOne(ctx, existing.Id)
+		if err != nil {
+			return nil, "", err
+		}
+		return current, CreateOutcomeReplaced, nil
+	default:
+		return existing, "", &TaskConflictError{Existing: existing}
+	}
+}
+
+// ReplacePending 用新的任务定义覆盖仍处于待执行状态的任务
+// 通过 status 条件保证原子性：任务已被调度时不做修改并返回 false
+func (m *customTasksModel) ReplacePending(ctx context.Context, data *Tasks) (bool, error) {
+	existing, err := m.FindOne(ctx, data.Id)
+	if err != nil {
+		return false, err
+	}
+	tags, err := m.normalizeTaskTags(data)
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		affected int64
+		removed  []*TaskTags
+	)
+	err = m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		query := fmt.Sprintf("update %s set `callback_url` = ?, `callback_method` = ?, `callback_headers` = ?, `callback_body` = ?, "+
+			"`retry_intervals` = ?, `max_retries` = ?, `current_retry` = 0, `priority` = ?, `tags` = ?, `timeout` = ?, "+
+			"`scheduled_at` = ?, `next_execute_at` = ?, `executed_at` = null, `completed_at` = null, `error_message` = null, `metadata` = ?, "+
+			"`trace_parent` = ?, `version` = `version` + 1 where `id` = ? and `status` = ?", m.table)
+		result, err := session.ExecCtx(ctx, query, data.CallbackUrl, data.CallbackMethod, data.CallbackHeaders, data.CallbackBody,
+			data.RetryIntervals, data.MaxRetries, data.Priority, data.Tags, data.Timeout,
+			data.ScheduledAt, data.NextExecuteAt, data.Metadata, data.TraceParent, existing.Id, TaskStatusPending)
+		if err != nil {
+			return err
+		}
+		if affected, err = result.RowsAffected(); err != nil {
+			return err
+		}
+		if affected == 0 {
+			return nil
+		}
+		removed, err = replaceTaskTags(ctx, session, taskTagsTable, existing.Id, existing.BusinessId, tags)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	if err := m.DelCacheCtx(ctx, append(taskTagsCacheKeys(removed), m.taskCacheKeys(existing)...)...); err != nil {
+		return false, err
+	}
+	if affected == 1 {
+		return true, nil
+	}
+
+	// MySQL 默认只统计实际变化的行，新旧定义完全相同时 affected 为 0，需要再确认状态
+	current, err := m.FindOne(ctx, existing.Id)
+	if err != nil {
+		return false, err
+	}
+	return current.Status == TaskStatusPending, nil
+}
+
+// Insert 插入任务，与 InsertWithPolicy 创建任务的过程相同：同一事务中写入标签，提交后执行创建钩子
+// 新任务的版本号从 1 开始，不使用 data.Version；成功后回填 data.Id 和 data.Version
+func (m *customTasksModel) Insert(ctx context.Context, data *Tasks) (sql.Result, error) {
+	tags, err := m.normalizeTaskTags(data)
+	if err != nil {
+		return nil, err
+	}
+	if !data.TraceParent.Valid {
+		data.TraceParent = TaskTraceParent(ctx)
+	}
+
+	created, err := m.create(ctx, data, tags)
+	if err != nil {
+		return nil, err
+	}
+	data.Version = created.Version
+	return taskInsertResult(created.Id), nil
+}
+
+// Update 覆盖任务的全部字段，version 由更新语句自增，不写入 data.Version，
+// 使并发的 UpdateWithVersion 能够发现这次修改；不校验读取时的版本，需要乐观锁时使用 UpdateWithVersion
+// 状态变更同样经过 DefaultTaskStateMachine 校验，不允许时返回 *TaskTransitionError；
+// 状态变化时在同一事务中写入 task_events，提交后执行状态机钩子；task_tags 在同一事务中按 data.Tags 同步。
+// 为保证事件记录的原状态准确，只在状态仍为读取时的状态时更新，否则返回 *TaskVersionConflictError
+func (m *customTasksModel) Update(ctx context.Context, data *Tasks) error {
+	existing, err := m.FindOne(ctx, data.Id)
+	if err != nil {
+		return err
+	}
+	if err := DefaultTaskStateMachine.Validate(existing, data.Status); err != nil {
+		return err
+	}
+	tags, err := m.normalizeTaskTags(data)
+	if err != nil {
+		return err
+	}
+
+	var transitions []*TaskTransition
+	if existing.Status != data.Status {
+		transitions = append(transitions, NewTaskTransition(ctx, data, existing.Status, data.Status, time.Now()))
+	}
+
+	var (
+		affected int64
+		removed  []*TaskTags
+	)
+	err = m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		query := fmt.Sprintf("update %s set %s, `version` = `version` + 1 where `id` = ? and `status` = ?", m.table, tasksRowsForVersionedUpdate)
+		result, err := session.ExecCtx(ctx, query, data.BusinessId, data.BusinessUniqueId, data.CallbackUrl, data.CallbackMethod,
+			data.CallbackHeaders, data.CallbackBody, data.RetryIntervals, data.MaxRetries, data.CurrentRetry, data.Status,
+			data.Priority, data.Tags, data.Timeout, data.ScheduledAt, data.NextExecuteAt, data.ExecutedAt, data.CompletedAt,
+			data.ErrorMessage, data.Metadata, data.TraceParent, data.Id, existing.Status)
+		if err != nil {
+			return err
+		}
+		if affected, err = result.RowsAffected(); err != nil || affected == 0 {
+			return err
+		}
+		if err := insertTaskEvents(ctx, session, transitions); err != nil {
+			return err
+		}
+		removed, err = replaceTaskTags(ctx, session, taskTagsTable, data.Id, data.BusinessId, tags)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := append(taskTagsCacheKeys(removed), m.taskCacheKeys(existing)...)
+	if err := m.DelCacheCtx(ctx, append(keys, m.taskCacheKeys(data)...)...); err != nil {
+		return err
+	}
+	if affected == 0 {
+		// 读取之后状态已被其他节点修改，或任务已被删除
+		current, err := m.FindOne(ctx, data.Id)
+		if err != nil {
+			return err
+		}
+		return &TaskVersionConflictError{Expected: existing.Version, Current: current}
+	}
+
+	DefaultTaskStateMachine.Fire(ctx, transitions...)
+	return nil
+}
+
+// UpdateWithVersion 按版本号更新任务，data.Version 为读取任务时的版本，成功后 data.Version 加一
+// 任务在读取之后已被修改时返回 *TaskVersionConflictError，状态变更不被 DefaultTaskStateMachine 允许时返回 *TaskTransitionError
+// 与 Update 不同，不会覆盖其他节点在此期间的修改；状态变化时在同一事务中写入 task_events，提交后执行状态机钩子
+func (m *customTasksModel) UpdateWithVersion(ctx context.Context, data *Tasks) error {
+	existing, err := m.FindOne(ctx, data.Id)
+	if err != nil {
+		return err
+	}
+	if existing.Version != data.Version {
+		return &TaskVersionConflictError{Expected: data.Version, Current: existing}
+	}
+	if err := DefaultTaskStateMachine.Validate(existing, data.Status); err != nil {
+		return err
+	}
+	tags, err := m.normalizeTaskTags(data)
+	if err != nil {
+		return err
+	}
+
+	var transitions []*TaskTransition
+	if existing.Status != data.Status {
+		transitions = append(transitions, NewTaskTransition(ctx, data, existing.Status, data.Status, time.Now()))
+	}
+
+	var (
+		affected int64
+		removed  []*TaskTags
+	)
+	err = m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		query := fmt.Sprintf("update %s set %s, `version` = `version` + 1 where `id` = ? and `version` = ?", m.table, tasksRowsForVersionedUpdate)
+		result, err := session.ExecCtx(ctx, query, data.BusinessId, data.BusinessUniqueId, data.CallbackUrl, data.CallbackMethod,
+			data.CallbackHeaders, data.CallbackBody, data.RetryIntervals, data.MaxRetries, data.CurrentRetry, data.Status,
+			data.Priority, data.Tags, data.Timeout, data.ScheduledAt, data.NextExecuteAt, data.ExecutedAt, data.CompletedAt,
+			data.ErrorMessage, data.Metadata, data.TraceParent, data.Id, data.Version)
+		if err != nil {
+			return err
+		}
+		if affected, err = result.RowsAffected(); err != nil {
+			return err
+		}
+		if affected == 0 {
+			return nil
+		}
+		if err := insertTaskEvents(ctx, session, transitions); err != nil {
+			return err
+		}
+		removed, err = replaceTaskTags(ctx, session, taskTagsTable, data.Id, data.BusinessId, tags)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := append(taskTagsCacheKeys(removed), m.taskCacheKeys(existing)...)
+	if err := m.DelCacheCtx(ctx, append(keys, m.taskCacheKeys(data)...)...); err != nil {
+		return err
+	}
+	if affected == 0 {
+		// 读取之后被其他节点修改或删除
+		current, err := m.FindOne(ctx, data.Id)
+		if err != nil {
+			return err
+		}
+		return &TaskVersionConflictError{Expected: data.Version, Current: current}
+	}
+
+	data.Version++
+	DefaultTaskStateMachine.Fire(ctx, transitions...)
+	return nil
+}
+
+// ListByCursor 按 (排序键, id) 游标分页查询任务
+// 相比 offset 分页，翻页代价与页码无关，并发插入时也不会跳过或重复记录
+func (m *customTasksModel) ListByCursor(ctx context.Context, filter *TaskListFilter, page *TaskPageRequest) (*TaskPage, error) {
+	if filter == nil || filter.BusinessId <= 0 {
+		return nil, fmt.Errorf("business id is required")
+	}
+	if filter.TagMatch != "" && filter.TagMatch != TagMatchAll && filter.TagMatch != TagMatchAny {
+		return nil, fmt.Errorf("invalid tag match: %s", filter.TagMatch)
+	}
+	if page == nil {
+		page = &TaskPageRequest{}
+	}
+
+	query, args, err := keysetQuery(m.table, filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []*Tasks
+	if err := m.QueryRowsNoCacheCtx(ctx, &tasks, query, args...); err != nil {
+		return nil, err
+	}
+
+	result := &TaskPage{Tasks: tasks}
+	if limit := normalizeLimit(page.Limit); len(tasks) > limit {
+		result.Tasks = tasks[:limit]
+		result.HasMore = true
+		result.NextCursor = nextTaskCursor(result.Tasks[limit-1], page.SortBy, page.Desc)
+	}
+
+	return result, nil
+}
+
+// UpdateTags 更新任务标签，tasks.tags 与 task_tags 在同一事务中修改
+func (m *customTasksModel) UpdateTags(ctx context.Context, id int64, tags []string) error {
+	existing, err := m.FindOne(ctx, id)
+	if err != nil {
+		return err
+	}
+	tags, err = NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+
+	var removed []*TaskTags
+	err = m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		query := fmt.Sprintf("update %s set `tags` = ?, `version` = `version` + 1 where `id` = ?", m.table)
+		if _, err := session.ExecCtx(ctx, query, FormatTags(tags), id); err != nil {
+			return err
+		}
+		removed, err = replaceTaskTags(ctx, session, taskTagsTable, id, existing.BusinessId, tags)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return m.DelCacheCtx(ctx, append(taskTagsCacheKeys(removed), m.taskCacheKeys(existing)...)...)
+}
+
+// CountByFilter 统计匹配过滤条件的任务数
+func (m *customTasksModel) CountByFilter(ctx context.Context, filter *TaskListFilter) (int64, error) {
+	if filter == nil || filter.BusinessId <= 0 {
+		return 0, fmt.Errorf("business id is required")
+	}
+
+	where, args := filter.where()
+	var count int64
+	query := fmt.Sprintf("select count(*) from %s where %s", m.table, where)
+	if err := m.QueryRowNoCacheCtx(ctx, &count, query, args...); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// TaskQueueDepth 某个业务系统某个优先级下待执行的任务数
+type TaskQueueDepth struct {
+	BusinessId int64 `db:"business_id"`
+	Priority   int64 `db:"priority"`
+	Count      int64 `db:"count"`
+}
+
+// CountPendingByPriority 按业务系统和优先级统计待执行任务数，只扫描 idx_status 中的待执行部分
+// 用于队列深度指标，调用方应控制调用频率
+func (m *customTasksModel) CountPendingByPriority(ctx context.Context) ([]*TaskQueueDepth, error) {
+	var depths []*TaskQueueDepth
+	query := fmt.Sprintf("select `business_id`, `priority`, count(*) as `count` from %s where `status` = ? group by `business_id`, `priority`", m.table)
+	if err := m.QueryRowsNoCacheCtx(ctx, &depths, query, TaskStatusPending); err != nil {
+		return nil, err
+	}
+	return depths, nil
+}
+
+// ApplyBulkAction 对一批任务执行批量操作，返回实际处理的任务ID
+// 加锁后按 action 允许的状态重新过滤，执行期间状态已变化的任务被跳过，不会覆盖其他节点的修改
+// 取消和重试改变任务状态，在同一事务中写入 task_events，提交后执行状态机钩子
+// reschedule 只在 reschedule 操作中使用
+func (m *customTasksModel) ApplyBulkAction(ctx context.Context, action string, tasks []*Tasks, reschedule *TaskReschedule) ([]int64, error) {
+	statuses, ok := bulkActionStatuses[action]
+	if !ok {
+		return nil, fmt.Errorf("invalid bulk action: %s", action)
+	}
+	if action == BulkActionReschedule {
+		if reschedule == nil {
+			return nil, fmt.Errorf("reschedule is required for the reschedule action")
+		}
+		if err := reschedule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, 0, len(tasks)+len(statuses))
+	for _, task := range tasks {
+		args = append(args, task.Id)
+	}
+	for _, status := range statuses {
+		args = append(args, status)
+	}
+
+	var (
+		applied     []int64
+		removed     []*TaskTags
+		transitions []*TaskTransition
+	)
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		applied, transitions = nil, nil
+		var rows []*Tasks
+		query := fmt.Sprintf("select %s from %s where `id` in (%s) and `status` in (%s) for update",
+			tasksRows, m.table, placeholders(len(tasks)), placeholders(len(statuses)))
+		if err := session.QueryRowsCtx(ctx, &rows, query, args...); err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		ids := make([]any, 0, len(rows))
+		for _, row := range rows {
+			applied = append(applied, row.Id)
+			ids = append(ids, row.Id)
+		}
+		in := placeholders(len(ids))
+		now := time.Now()
+
+		// transit 记录批量操作引起的状态变更
+		transit := func(to int64) error {
+			for _, row := range rows {
+				transitions = append(transitions, NewTaskTransition(ctx, row, row.Status, to, now))
+			}
+			return insertTaskEvents(ctx, session, transitions)
+		}
+
+		switch action {
+		case BulkActionCancel:
+			query = fmt.Sprintf("update %s set `status` = ?, `next_execute_at` = null, `completed_at` = ?, `version` = `version` + 1 "+
+				"where `id` in (%s)", m.table, in)
+			if _, err := session.ExecCtx(ctx, query, append([]any{TaskStatusCancelled, now}, ids...)...); err != nil {
+				return err
+			}
+			return transit(TaskStatusCancelled)
+		case BulkActionReschedule:
+			set, setArgs := reschedule.assignments()
+			query = fmt.Sprintf("update %s set %s where `id` in (%s)", m.table, set, in)
+			_, err := session.ExecCtx(ctx, query, append(setArgs, ids...)...)
+			return err
+		case BulkActionRetry:
+			query = fmt.Sprintf("update %s set `status` = ?, `current_retry` = 0, `next_execute_at` = ?, `completed_at` = null, "+
+				"`error_message` = null, `version` = `version` + 1 where `id` in (%s)", m.table, in)
+			if _, err := session.ExecCtx(ctx, query, append([]any{TaskStatusPending, now}, ids...)...); err != nil {
+				return err
+			}
+			return transit(TaskStatusPending)
+		default:
+			// 没有外键的部署中 task_tags 不会级联删除，需要一并清理
+			query = fmt.Sprintf("select %s from %s where `task_id` in (%s) for update", taskTagsRows, taskTagsTable, in)
+			if err := session.QueryRowsCtx(ctx, &removed, query, ids...); err != nil {
+				return err
+			}
+			query = fmt.Sprintf("delete from %s where `task_id` in (%s)", taskTagsTable, in)
+			if _, err := session.ExecCtx(ctx, query, ids...); err != nil {
+				return err
+			}
+			query = fmt.Sprintf("delete from %s where `id` in (%s)", m.table, in)
+			_, err := session.ExecCtx(ctx, query, ids...)
+			return err
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(map[int64]bool, len(applied))
+	for _, id := range applied {
+		done[id] = true
+	}
+	keys := taskTagsCacheKeys(removed)
+	for _, task := range tasks {
+		if done[task.Id] {
+			keys = append(keys, m.taskCacheKeys(task)...)
+		}
+	}
+	if len(keys) > 0 {
+		if err := m.DelCacheCtx(ctx, keys...); err != nil {
+			return applied, err
+		}
+	}
+	DefaultTaskStateMachine.Fire(ctx, transitions...)
+	return applied, nil
+}
+
+// Reschedule 调整待执行任务的执行时间，scheduled_at 与 next_execute_at 在同一语句中更新
+// 返回与 ids 顺序一致的逐项结果：执行中或已结束的任务跳过，不属于该业务系统的任务视为不存在
+func (m *customTasksModel) Reschedule(ctx context.Context, businessId int64, ids []int64, reschedule *TaskReschedule) ([]*TaskRescheduleResult, error) {
+	if businessId <= 0 {
+		return nil, fmt.Errorf("business id is required")
+	}
+	if len(ids) == 0 || len(ids) > MaxRescheduleTasks {
+		return nil, fmt.Errorf("expected 1 to %d task ids, got %d", MaxRescheduleTasks, len(ids))
+	}
+	if reschedule == nil {
+		return nil, fmt.Errorf("reschedule is required")
+	}
+	if err := reschedule.Validate(); err != nil {
+		return nil, err
+	}
+
+	args := []any{businessId}
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			args = append(args, id)
+		}
+	}
+
+	current := make(map[int64]*Tasks, len(args)-1)
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		var rows []*Tasks
+		query := fmt.Sprintf("select %s from %s where `business_id` = ? and `id` in (%s) for update",
+			tasksRows, m.table, placeholders(len(args)-1))
+		if err := session.QueryRowsCtx(ctx, &rows, query, args...); err != nil {
+			return err
+		}
+
+		var pending []any
+		for _, row := range rows {
+			current[row.Id] = row
+			if row.Status == TaskStatusPending {
+				pending = append(pending, row.Id)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		set, setArgs := reschedule.assignments()
+		query = fmt.Sprintf("update %s set %s where `id` in (%s) and `status` = ?", m.table, set, placeholders(len(pending)))
+		updateArgs := append(append(setArgs, pending...), TaskStatusPending)
+		if _, err := session.ExecCtx(ctx, query, updateArgs...); err != nil {
+			return err
+		}
+
+		// 重新读取改期后的时间
+		rows = nil
+		query = fmt.Sprintf("select %s from %s where `id` in (%s)", tasksRows, m.table, placeholders(len(pending)))
+		if err := session.QueryRowsCtx(ctx, &rows, query, pending...); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			current[row.Id] = row
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	results := make([]*TaskRescheduleResult, 0, len(ids))
+	for _, id := range ids {
+		task, ok := current[id]
+		switch {
+		case !ok:
+			results = append(results, &TaskRescheduleResult{Id: id, Outcome: RescheduleOutcomeNotFound})
+		case task.Status != TaskStatusPending:
+			results = append(results, &TaskRescheduleResult{Id: id, Outcome: RescheduleOutcomeSkipped, Reason: skipReason(task.Status), Task: task})
+		default:
+			results = append(results, &TaskRescheduleResult{Id: id, Outcome: RescheduleOutcomeRescheduled, Task: task})
+			keys = append(keys, m.taskCacheKeys(task)...)
+		}
+	}
+	if len(keys) == 0 {
+		return results, nil
+	}
+	return results, m.DelCacheCtx(ctx, keys...)
+}
+
+// create 插入任务和标签，提交后执行创建钩子，返回创建后的任务
+func (m *customTasksModel) create(ctx context.Context, data *Tasks, tags []string) (*Tasks, error) {
+	id, err := m.insertWithTags(ctx, data, tags)
+	if err != nil {
+		return nil, err
+	}
+	created, err := m.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	DefaultTaskStateMachine.FireCreated(ctx, created)
+	return created, nil
+}
+
+// taskInsertResult Insert 的结果，只插入一行任务
+type taskInsertResult int64
+
+// LastInsertId 返回新任务ID
+func (r taskInsertResult) LastInsertId() (int64, error) {
+	return int64(r), nil
+}
+
+// RowsAffected 返回插入的任务数
+func (r taskInsertResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+// insertWithTags 在同一事务中插入任务和标签，返回新任务ID
+func (m *customTasksModel) insertWithTags(ctx context.Context, data *Tasks, tags []string) (int64, error) {
+	var id int64
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		// 新任务的版本号从 1 开始
+		query := fmt.Sprintf("insert into %s (%s) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1, ?)", m.table, tasksRowsExpectAutoSet)
+		result, err := session.ExecCtx(ctx, query, data.BusinessId, data.BusinessUniqueId, data.CallbackUrl, data.CallbackMethod, data.CallbackHeaders, data.CallbackBody, data.RetryIntervals, data.MaxRetries, data.CurrentRetry, data.Status, data.Priority, data.Tags, data.Timeout, data.ScheduledAt, data.NextExecuteAt, data.ExecutedAt, data.CompletedAt, data.ErrorMessage, data.Metadata, data.TraceParent)
+		if err != nil {
+			return err
+		}
+		if id, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		_, err = replaceTaskTags(ctx, session, taskTagsTable, id, data.BusinessId, tags)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// 清理按业务唯一ID缓存的“不存在”占位
+	data.Id = id
+	return id, m.DelCacheCtx(ctx, m.taskCacheKeys(data)...)
+}
+
+// normalizeTaskTags 校验并规范化 data.Tags，规范化后的 JSON 写回 data.Tags
+func (m *customTasksModel) normalizeTaskTags(data *Tasks) ([]string, error) {
+	tags, err := ParseTags(data.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if tags, err = NormalizeTags(tags); err != nil {
+		return nil, err
+	}
+	data.Tags = FormatTags(tags)
+	return tags, nil
+}
+
+// taskCacheKeys 返回任务的缓存键
+func (m *customTasksModel) taskCacheKeys(data *Tasks) []string {
+	return []string{
+		fmt.Sprintf("%s%v:%v", cacheTasksBusinessIdBusinessUniqueIdPrefix, data.BusinessId, data.BusinessUniqueId),
+		fmt.Sprintf("%s%v", cacheTasksIdPrefix, data.Id),
+	}
+}
+
+// isDuplicateEntry 判断是否为唯一键冲突
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
@@ -63,6 +63,8 @@ type (
 		CompletedAt      sql.NullTime   `db:"completed_at"`       // 完成时间
 		ErrorMessage     sql.NullString `db:"error_message"`      // 最新的错误信息
 		Metadata         sql.NullString `db:"metadata"`           // 扩展元数据，JSON格式存储
+		Version          int64          `db:"version"`            // 版本号，每次修改加一，用于乐观锁
+		TraceParent      sql.NullString `db:"trace_parent"`       // 创建任务时的 W3C traceparent，用于串联创建、调度和回调的链路
 		CreatedAt        time.Time      `db:"created_at"`         // 创建时间
 		UpdatedAt        time.Time      `db:"updated_at"`         // 更新时间
 	}
@@ -131,8 +133,8 @@ func (m *defaultTasksModel) Insert(ctx context.Context, data *Tasks) (sql.Result
 	tasksBusinessIdBusinessUniqueIdKey := fmt.Sprintf("%s%v:%v", cacheTasksBusinessIdBusinessUniqueIdPrefix, data.BusinessId, data.BusinessUniqueId)
 	tasksIdKey := fmt.Sprintf("%s%v", cacheTasksIdPrefix, data.Id)
 	ret, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
-		query := fmt.Sprintf("insert into %s (%s) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", m.table, tasksRowsExpectAutoSet)
-		return conn.ExecCtx(ctx, query, data.BusinessId, data.BusinessUniqueId, data.CallbackUrl, data.CallbackMethod, data.CallbackHeaders, data.CallbackBody, data.RetryIntervals, data.MaxRetries, data.CurrentRetry, data.Status, data.Priority, data.Tags, data.Timeout, data.ScheduledAt, data.NextExecuteAt, data.ExecutedAt, data.CompletedAt, data.ErrorMessage, data.Metadata)
+		query := fmt.Sprintf("insert into %s (%s) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", m.table, tasksRowsExpectAutoSet)
+		return conn.ExecCtx(ctx, query, data.BusinessId, data.BusinessUniqueId, data.CallbackUrl, data.CallbackMethod, data.CallbackHeaders, data.CallbackBody, data.RetryIntervals, data.MaxRetries, data.CurrentRetry, data.Status, data.Priority, data.Tags, data.Timeout, data.ScheduledAt, data.NextExecuteAt, data.ExecutedAt, data.CompletedAt, data.ErrorMessage, data.Metadata, data.Version, data.TraceParent)
 	}, tasksBusinessIdBusinessUniqueIdKey, tasksIdKey)
 	return ret, err
 }
@@ -147,7 +149,7 @@ func (m *defaultTasksModel) Update(ctx context.Context, newData *Tasks) error {
 	tasksIdKey := fmt.Sprintf("%s%v", cacheTasksIdPrefix, data.Id)
 	_, err = m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
 		query := fmt.Sprintf("update %s set %s where `id` = ?", m.table, tasksRowsWithPlaceHolder)
-		return conn.ExecCtx(ctx, query, newData.BusinessId, newData.BusinessUniqueId, newData.CallbackUrl, newData.CallbackMethod, newData.CallbackHeaders, newData.CallbackBody, newData.RetryIntervals, newData.MaxRetries, newData.CurrentRetry, newData.Status, newData.Priority, newData.Tags, newData.Timeout, newData.ScheduledAt, newData.NextExecuteAt, newData.ExecutedAt, newData.CompletedAt, newData.ErrorMessage, newData.Metadata, newData.Id)
+		return conn.ExecCtx(ctx, query, newData.BusinessId, newData.BusinessUniqueId, newData.CallbackUrl, newData.CallbackMethod, newData.CallbackHeaders, newData.CallbackBody, newData.RetryIntervals, newData.MaxRetries, newData.CurrentRetry, newData.Status, newData.Priority, newData.Tags, newData.Timeout, newData.ScheduledAt, newData.NextExecuteAt, newData.ExecutedAt, newData.CompletedAt, newData.ErrorMessage, newData.Metadata, newData.Version, newData.TraceParent, newData.Id)
 	}, tasksBusinessIdBusinessUniqueIdKey, tasksIdKey)
 	return err
 }
@@ -0,0 +1,150 @@
+// Package event 定义任务中心推送给业务系统的回调事件格式，服务端用于生成事件，sdk/callback 用于解析事件
+package event
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// 事件类型
+const (
+	TypeTaskCreated   = "task.created"
+	TypeTaskStarted   = "task.started"
+	TypeTaskCompleted = "task.completed"
+	TypeTaskFailed    = "task.failed"
+
+	// 生命周期事件，需要在业务系统的事件订阅中显式选择
+	TypeTaskRetrying     = "task.retrying"      // 失败或超时后重新进入待执行
+	TypeTaskCancelled    = "task.cancelled"     // 任务被取消
+	TypeTaskExpired      = "task.expired"       // 任务超过有效期未执行
+	TypeTaskDeadLettered = "task.dead_lettered" // 重试耗尽进入死信
+	TypeTaskPaused       = "task.paused"        // 任务被暂停
+)
+
+// AllTypes 所有支持的事件类型
+var AllTypes = []string{
+	TypeTaskCreated,
+	TypeTaskStarted,
+	TypeTaskCompleted,
+	TypeTaskFailed,
+	TypeTaskRetrying,
+	TypeTaskCancelled,
+	TypeTaskExpired,
+	TypeTaskDeadLettered,
+	TypeTaskPaused,
+}
+
+// IsValidType 检查是否为支持的事件类型
+func IsValidType(eventType string) bool {
+	return slices.Contains(AllTypes, eventType)
+}
+
+// TaskStatus 任务状态枚举
+type TaskStatus int
+
+const (
+	TaskStatusPending   TaskStatus = 0 // 待执行
+	TaskStatusRunning   TaskStatus = 1 // 执行中
+	TaskStatusSucceeded TaskStatus = 2 // 成功
+	TaskStatusFailed    TaskStatus = 3 // 失败
+	TaskStatusCancelled TaskStatus = 4 // 取消
+	TaskStatusExpired   TaskStatus = 5 // 过期
+)
+
+// String 返回任务状态的字符串表示
+func (s TaskStatus) String() string {
+	switch s {
+	case TaskStatusPending:
+		return "pending"
+	case TaskStatusRunning:
+		return "running"
+	case TaskStatusSucceeded:
+		return "succeeded"
+	case TaskStatusFailed:
+		return "failed"
+	case TaskStatusCancelled:
+		return "cancelled"
+	case TaskStatusExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// TaskPriority 任务优先级
+type TaskPriority int
+
+const (
+	TaskPriorityHighest TaskPriority = 1
+	TaskPriorityHigh    TaskPriority = 3
+	TaskPriorityNormal  TaskPriority = 5
+	TaskPriorityLow     TaskPriority = 7
+	TaskPriorityLowest  TaskPriority = 9
+)
+
+// Task 事件中的任务
+type Task struct {
+	ID               int64                  `json:"id,omitempty"`
+	BusinessUniqueID string                 `json:"business_unique_id"`
+	CallbackURL      string                 `json:"callback_url"`
+	CallbackMethod   string                 `json:"callback_method,omitempty"`
+	CallbackHeaders  map[string]string      `json:"callback_headers,omitempty"`
+	CallbackBody     string                 `json:"callback_body,omitempty"`
+	RetryIntervals   []int                  `json:"retry_intervals,omitempty"`
+	MaxRetries       int                    `json:"max_retries,omitempty"`
+	CurrentRetry     int                    `json:"current_retry,omitempty"`
+	Status           TaskStatus             `json:"status,omitempty"`
+	Priority         TaskPriority           `json:"priority,omitempty"`
+	Tags             []string               `json:"tags,omitempty"`
+	Timeout          int                    `json:"timeout,omitempty"`
+	ScheduledAt      time.Time              `json:"scheduled_at,omitempty"`
+	NextExecuteAt    *time.Time             `json:"next_execute_at,omitempty"`
+	ExecutedAt       *time.Time             `json:"executed_at,omitempty"`
+	CompletedAt      *time.Time             `json:"completed_at,omitempty"`
+	ErrorMessage     string                 `json:"error_message,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt        time.Time              `json:"created_at,omitempty"`
+	UpdatedAt        time.Time              `json:"updated_at,omitempty"`
+}
+
+// MarshalJSON 时间字段按 RFC3339 输出
+func (t *Task) MarshalJSON() ([]byte, error) {
+	type Alias Task
+	return json.Marshal(&struct {
+		*Alias
+		ScheduledAt   string  `json:"scheduled_at,omitempty"`
+		NextExecuteAt *string `json:"next_execute_at,omitempty"`
+		ExecutedAt    *string `json:"executed_at,omitempty"`
+		CompletedAt   *string `json:"completed_at,omitempty"`
+		CreatedAt     string  `json:"created_at,omitempty"`
+		UpdatedAt     string  `json:"updated_at,omitempty"`
+	}{
+		Alias:         (*Alias)(t),
+		ScheduledAt:   t.ScheduledAt.Format(time.RFC3339),
+		NextExecuteAt: formatTime(t.NextExecuteAt),
+		ExecutedAt:    formatTime(t.ExecutedAt),
+		CompletedAt:   formatTime(t.CompletedAt),
+		CreatedAt:     t.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     t.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+// formatTime 按 RFC3339 格式化可选时间
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
+// Event 回调事件，即推送请求的请求体
+type Event struct {
+	EventType  string    `json:"event_type"` // 取值见 AllTypes
+	EventTime  time.Time `json:"event_time"`
+	TaskID     int64     `json:"task_id"`
+	BusinessID int64     `json:"business_id"`
+	Task       Task      `json:"task"`
+	Signature  string    `json:"signature"` // 回调签名，用于验证
+}
@@ -0,0 +1,312 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse 解析搜索语法，返回的查询已通过校验
+func Parse(input string) (*Query, error) {
+	if len(input) > MaxQueryLength {
+		return nil, fmt.Errorf("search query too long: %d bytes (max %d)", len(input), MaxQueryLength)
+	}
+
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	q := NewQuery()
+	for _, tok := range tokens {
+		if tok.field == "" {
+			if tok.value != "" {
+				q.WithText(tok.value)
+			}
+			continue
+		}
+		if err := q.apply(tok.field, tok.value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// token 一个搜索条件，field 为空表示自由文本
+type token struct {
+	field string
+	value string
+}
+
+// tokenize 按空白切分条件，引号内的空白保留
+func tokenize(input string) ([]token, error) {
+	var (
+		tokens  []token
+		buf     strings.Builder
+		field   string
+		quoted  bool
+		inQuote bool
+		escaped bool
+	)
+
+	flush := func() {
+		if buf.Len() > 0 || field != "" || quoted {
+			tokens = append(tokens, token{field: field, value: buf.String()})
+		}
+		buf.Reset()
+		field = ""
+		quoted = false
+	}
+
+	for _, r := range input {
+		switch {
+		case escaped:
+			buf.WriteRune(r)
+			escaped = false
+		case inQuote && r == '\\':
+			escaped = true
+		case r == '"':
+			inQuote = !inQuote
+			quoted = true
+		case inQuote:
+			buf.WriteRune(r)
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			flush()
+		case r == ':' && field == "" && !quoted && buf.Len() > 0:
+			field = buf.String()
+			buf.Reset()
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote in search query")
+	}
+	flush()
+
+	return tokens, nil
+}
+
+// apply 将一个字段条件合并到查询，字段名不区分大小写，元数据键区分大小写
+func (q *Query) apply(field, value string) error {
+	prefix := FieldMetadata + "."
+	if len(field) > len(prefix) && strings.EqualFold(field[:len(prefix)], prefix) {
+		key := field[len(prefix):]
+		if _, exists := q.Metadata[key]; exists {
+			return fmt.Errorf("duplicate metadata key: %q", key)
+		}
+		q.WithMetadata(key, value)
+		return nil
+	}
+
+	if value == "" {
+		return fmt.Errorf("empty value for search field %q", field)
+	}
+
+	field = strings.ToLower(field)
+	switch field {
+	case FieldHost:
+		q.WithHost(strings.ToLower(value))
+	case FieldURL:
+		q.WithURLContains(value)
+	case FieldError:
+		q.WithErrorContains(value)
+	case FieldScheduled, FieldExecuted, FieldCompleted, FieldCreated:
+		r, err := parseTimeRange(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s range: %w", field, err)
+		}
+		target := q.timeRange(field)
+		if *target == nil {
+			*target = r
+		} else if !mergeTimeRange(*target, r) {
+			return fmt.Errorf("duplicate search field %q", field)
+		}
+	case FieldRetries:
+		r, err := parseIntRange(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s range: %w", field, err)
+		}
+		if q.Retries == nil {
+			q.Retries = r
+		} else if !mergeIntRange(q.Retries, r) {
+			return fmt.Errorf("duplicate search field %q", field)
+		}
+	default:
+		return fmt.Errorf("unknown search field %q", field)
+	}
+	return nil
+}
+
+// timeRange 返回字段对应的时间范围
+func (q *Query) timeRange(field string) **TimeRange {
+	switch field {
+	case FieldScheduled:
+		return &q.Scheduled
+	case FieldExecuted:
+		return &q.Executed
+	case FieldCompleted:
+		return &q.Completed
+	default:
+		return &q.Created
+	}
+}
+
+// mergeTimeRange 将只有一端的范围合并到另一端为空的范围，无法合并时返回 false
+func mergeTimeRange(dst, src *TimeRange) bool {
+	switch {
+	case dst.From == nil && src.From != nil && src.To == nil:
+		dst.From, dst.ExcludeFrom = src.From, src.ExcludeFrom
+	case dst.To == nil && src.To != nil && src.From == nil:
+		dst.To, dst.ExcludeTo = src.To, src.ExcludeTo
+	default:
+		return false
+	}
+	return true
+}
+
+// mergeIntRange 将只有一端的范围合并到另一端为空的范围，无法合并时返回 false
+func mergeIntRange(dst, src *IntRange) bool {
+	switch {
+	case dst.Min == nil && src.Min != nil && src.Max == nil:
+		dst.Min = src.Min
+	case dst.Max == nil && src.Max != nil && src.Min == nil:
+		dst.Max = src.Max
+	default:
+		return false
+	}
+	return true
+}
+
+// splitComparison 拆分比较运算符
+func splitComparison(value string) (string, string) {
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(value, op) {
+			return op, value[len(op):]
+		}
+	}
+	return "", value
+}
+
+// parseTimeRange 解析时间范围
+func parseTimeRange(value string) (*TimeRange, error) {
+	if from, to, ok := strings.Cut(value, ".."); ok {
+		r := &TimeRange{}
+		if from != "" {
+			t, _, err := parseTime(from)
+			if err != nil {
+				return nil, err
+			}
+			r.From = &t
+		}
+		if to != "" {
+			t, isDate, err := parseTime(to)
+			if err != nil {
+				return nil, err
+			}
+			// 只写日期的结束时间包含当天
+			if isDate {
+				t = t.AddDate(0, 0, 1)
+				r.ExcludeTo = true
+			}
+			r.To = &t
+		}
+		if r.From == nil && r.To == nil {
+			return nil, fmt.Errorf("empty range")
+		}
+		return r, nil
+	}
+
+	op, operand := splitComparison(value)
+	t, isDate, err := parseTime(operand)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case ">":
+		if isDate {
+			t = t.AddDate(0, 0, 1)
+			return &TimeRange{From: &t}, nil
+		}
+		return &TimeRange{From: &t, ExcludeFrom: true}, nil
+	case ">=":
+		return &TimeRange{From: &t}, nil
+	case "<":
+		return &TimeRange{To: &t, ExcludeTo: true}, nil
+	case "<=":
+		if isDate {
+			t = t.AddDate(0, 0, 1)
+			return &TimeRange{To: &t, ExcludeTo: true}, nil
+		}
+		return &TimeRange{To: &t}, nil
+	default:
+		if isDate {
+			end := t.AddDate(0, 0, 1)
+			return &TimeRange{From: &t, To: &end, ExcludeTo: true}, nil
+		}
+		return &TimeRange{From: &t, To: &t}, nil
+	}
+}
+
+// parseTime 解析 RFC3339 或日期，日期按 UTC 解释
+func parseTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.Parse(DateLayout, value); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("invalid time %q, expected RFC3339 or %s", value, DateLayout)
+}
+
+// parseIntRange 解析整数范围
+func parseIntRange(value string) (*IntRange, error) {
+	if from, to, ok := strings.Cut(value, ".."); ok {
+		r := &IntRange{}
+		if from != "" {
+			n, err := strconv.Atoi(from)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", from)
+			}
+			r.Min = &n
+		}
+		if to != "" {
+			n, err := strconv.Atoi(to)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", to)
+			}
+			r.Max = &n
+		}
+		if r.Min == nil && r.Max == nil {
+			return nil, fmt.Errorf("empty range")
+		}
+		return r, nil
+	}
+
+	op, operand := splitComparison(value)
+	n, err := strconv.Atoi(operand)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", operand)
+	}
+
+	switch op {
+	case ">":
+		n++
+		return &IntRange{Min: &n}, nil
+	case ">=":
+		return &IntRange{Min: &n}, nil
+	case "<":
+		n--
+		return &IntRange{Max: &n}, nil
+	case "<=":
+		return &IntRange{Max: &n}, nil
+	default:
+		return &IntRange{Min: &n, Max: &n}, nil
+	}
+}
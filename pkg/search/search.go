@@ -0,0 +1,366 @@
+// Package search 定义任务搜索语法，客户端用于构造查询，服务端用于解析查询
+//
+// 查询由空格分隔的条件组成，条件之间为 AND 关系：
+//
+//	metadata.<key>:<value>    元数据键值相等，key 可用 . 访问嵌套字段，如 metadata.order.id:42
+//	host:<host>               回调地址的主机名，支持 *.example.com 通配子域名
+//	url:<text>                回调地址包含 text
+//	error:<text>              error_message 包含 text
+//	scheduled:<range>         计划执行时间
+//	executed:<range>          实际执行时间
+//	completed:<range>         完成时间
+//	created:<range>           创建时间
+//	retries:<range>           当前重试次数
+//	<text>                    不带字段的词匹配 business_unique_id 包含 text
+//
+// 时间和数值范围支持 >v、>=v、<v、<=v、v（等于）以及 a..b（闭区间，任一端可省略），
+// 同一字段出现两次时合并为上下界，如 scheduled:>=2024-01-01 scheduled:<2024-02-01。
+// 时间格式为 RFC3339 或 2006-01-02，只写日期时按 UTC 的整天计算，如 completed:2024-01-01 表示当天内。
+// 值中包含空格时用双引号包裹，双引号内可用 \" 和 \\ 转义。
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 搜索字段
+const (
+	FieldMetadata  = "metadata"
+	FieldHost      = "host"
+	FieldURL       = "url"
+	FieldError     = "error"
+	FieldScheduled = "scheduled"
+	FieldExecuted  = "executed"
+	FieldCompleted = "completed"
+	FieldCreated   = "created"
+	FieldRetries   = "retries"
+)
+
+// 查询长度限制，防止构造过大的SQL
+const (
+	MaxQueryLength = 1024
+	MaxTerms       = 20
+)
+
+// DateLayout 只包含日期的时间格式
+const DateLayout = "2006-01-02"
+
+// metadataKeyPattern 元数据键只允许字母、数字、下划线和中划线，用 . 分隔嵌套字段
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// hostPattern 主机名，允许 *. 前缀
+var hostPattern = regexp.MustCompile(`^(\*\.)?[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*$`)
+
+// TimeRange 时间范围，为空的一端表示不限
+type TimeRange struct {
+	From        *time.Time
+	To          *time.Time
+	ExcludeFrom bool // From 为开区间
+	ExcludeTo   bool // To 为开区间
+}
+
+// IntRange 整数范围，为空的一端表示不限
+type IntRange struct {
+	Min *int
+	Max *int
+}
+
+// Query 解析后的搜索条件
+type Query struct {
+	Metadata      map[string]string // 元数据键值
+	Hosts         []string          // 回调主机名，多个之间为 AND（通常只有一个）
+	URLContains   []string          // 回调地址子串
+	ErrorContains []string          // 错误信息子串
+	Scheduled     *TimeRange
+	Executed      *TimeRange
+	Completed     *TimeRange
+	Created       *TimeRange
+	Retries       *IntRange
+	Text          []string // 匹配 business_unique_id 的自由文本
+}
+
+// NewQuery 创建空查询
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// IsEmpty 是否没有任何条件
+func (q *Query) IsEmpty() bool {
+	return q == nil || (len(q.Metadata) == 0 && len(q.Hosts) == 0 && len(q.URLContains) == 0 &&
+		len(q.ErrorContains) == 0 && q.Scheduled == nil && q.Executed == nil && q.Completed == nil &&
+		q.Created == nil && q.Retries == nil && len(q.Text) == 0)
+}
+
+// WithMetadata 添加元数据键值条件
+func (q *Query) WithMetadata(key, value string) *Query {
+	if q.Metadata == nil {
+		q.Metadata = make(map[string]string)
+	}
+	q.Metadata[key] = value
+	return q
+}
+
+// WithHost 添加回调主机名条件
+func (q *Query) WithHost(host string) *Query {
+	q.Hosts = append(q.Hosts, host)
+	return q
+}
+
+// WithURLContains 添加回调地址子串条件
+func (q *Query) WithURLContains(text string) *Query {
+	q.URLContains = append(q.URLContains, text)
+	return q
+}
+
+// WithErrorContains 添加错误信息子串条件
+func (q *Query) WithErrorContains(text string) *Query {
+	q.ErrorContains = append(q.ErrorContains, text)
+	return q
+}
+
+// WithScheduled 设置计划执行时间范围
+func (q *Query) WithScheduled(from, to *time.Time) *Query {
+	q.Scheduled = &TimeRange{From: from, To: to}
+	return q
+}
+
+// WithExecuted 设置实际执行时间范围
+func (q *Query) WithExecuted(from, to *time.Time) *Query {
+	q.Executed = &TimeRange{From: from, To: to}
+	return q
+}
+
+// WithCompleted 设置完成时间范围
+func (q *Query) WithCompleted(from, to *time.Time) *Query {
+	q.Completed = &TimeRange{From: from, To: to}
+	return q
+}
+
+// WithCreated 设置创建时间范围
+func (q *Query) WithCreated(from, to *time.Time) *Query {
+	q.Created = &TimeRange{From: from, To: to}
+	return q
+}
+
+// WithRetries 设置重试次数范围
+func (q *Query) WithRetries(min, max *int) *Query {
+	q.Retries = &IntRange{Min: min, Max: max}
+	return q
+}
+
+// WithText 添加自由文本条件
+func (q *Query) WithText(text string) *Query {
+	q.Text = append(q.Text, text)
+	return q
+}
+
+// Clone 深拷贝查询
+func (q *Query) Clone() *Query {
+	if q == nil {
+		return nil
+	}
+
+	c := &Query{
+		Hosts:         append([]string(nil), q.Hosts...),
+		URLContains:   append([]string(nil), q.URLContains...),
+		ErrorContains: append([]string(nil), q.ErrorContains...),
+		Scheduled:     q.Scheduled.clone(),
+		Executed:      q.Executed.clone(),
+		Completed:     q.Completed.clone(),
+		Created:       q.Created.clone(),
+		Text:          append([]string(nil), q.Text...),
+	}
+	if q.Metadata != nil {
+		c.Metadata = make(map[string]string, len(q.Metadata))
+		for key, value := range q.Metadata {
+			c.Metadata[key] = value
+		}
+	}
+	if q.Retries != nil {
+		c.Retries = &IntRange{}
+		if q.Retries.Min != nil {
+			min := *q.Retries.Min
+			c.Retries.Min = &min
+		}
+		if q.Retries.Max != nil {
+			max := *q.Retries.Max
+			c.Retries.Max = &max
+		}
+	}
+	return c
+}
+
+// clone 深拷贝时间范围
+func (r *TimeRange) clone() *TimeRange {
+	if r == nil {
+		return nil
+	}
+
+	c := &TimeRange{ExcludeFrom: r.ExcludeFrom, ExcludeTo: r.ExcludeTo}
+	if r.From != nil {
+		from := *r.From
+		c.From = &from
+	}
+	if r.To != nil {
+		to := *r.To
+		c.To = &to
+	}
+	return c
+}
+
+// Validate 校验查询条件
+func (q *Query) Validate() error {
+	if q == nil {
+		return nil
+	}
+	for key := range q.Metadata {
+		if !metadataKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid metadata key: %q", key)
+		}
+	}
+	for _, host := range q.Hosts {
+		if !hostPattern.MatchString(host) {
+			return fmt.Errorf("invalid host: %q", host)
+		}
+	}
+	for _, r := range []*TimeRange{q.Scheduled, q.Executed, q.Completed, q.Created} {
+		if r != nil && r.From != nil && r.To != nil && r.To.Before(*r.From) {
+			return fmt.Errorf("invalid time range: end is before start")
+		}
+	}
+	if r := q.Retries; r != nil {
+		if (r.Min != nil && *r.Min < 0) || (r.Max != nil && *r.Max < 0) {
+			return fmt.Errorf("invalid retries range: must not be negative")
+		}
+		if r.Min != nil && r.Max != nil && *r.Max < *r.Min {
+			return fmt.Errorf("invalid retries range: max is less than min")
+		}
+	}
+	if n := q.termCount(); n > MaxTerms {
+		return fmt.Errorf("too many search terms: %d (max %d)", n, MaxTerms)
+	}
+	return nil
+}
+
+// termCount 条件数量
+func (q *Query) termCount() int {
+	n := len(q.Metadata) + len(q.Hosts) + len(q.URLContains) + len(q.ErrorContains) + len(q.Text)
+	for _, r := range []*TimeRange{q.Scheduled, q.Executed, q.Completed, q.Created} {
+		if r != nil {
+			n++
+		}
+	}
+	if q.Retries != nil {
+		n++
+	}
+	return n
+}
+
+// String 编码为搜索语法，Parse(q.String()) 得到等价的查询
+func (q *Query) String() string {
+	if q == nil {
+		return ""
+	}
+
+	var terms []string
+
+	keys := make([]string, 0, len(q.Metadata))
+	for key := range q.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		terms = append(terms, FieldMetadata+"."+key+":"+quote(q.Metadata[key]))
+	}
+	for _, host := range q.Hosts {
+		terms = append(terms, FieldHost+":"+quote(host))
+	}
+	for _, text := range q.URLContains {
+		terms = append(terms, FieldURL+":"+quote(text))
+	}
+	for _, text := range q.ErrorContains {
+		terms = append(terms, FieldError+":"+quote(text))
+	}
+
+	timeRanges := []struct {
+		field string
+		r     *TimeRange
+	}{
+		{FieldScheduled, q.Scheduled},
+		{FieldExecuted, q.Executed},
+		{FieldCompleted, q.Completed},
+		{FieldCreated, q.Created},
+	}
+	for _, tr := range timeRanges {
+		if tr.r != nil {
+			terms = append(terms, tr.r.terms(tr.field)...)
+		}
+	}
+	if q.Retries != nil && (q.Retries.Min != nil || q.Retries.Max != nil) {
+		terms = append(terms, FieldRetries+":"+q.Retries.String())
+	}
+	for _, text := range q.Text {
+		terms = append(terms, quote(text))
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// terms 编码时间范围，两端都有且含开区间时拆成两个条件
+func (r *TimeRange) terms(field string) []string {
+	format := func(t *time.Time) string { return t.UTC().Format(time.RFC3339) }
+	lower := func() string {
+		if r.ExcludeFrom {
+			return field + ":>" + format(r.From)
+		}
+		return field + ":>=" + format(r.From)
+	}
+	upper := func() string {
+		if r.ExcludeTo {
+			return field + ":<" + format(r.To)
+		}
+		return field + ":<=" + format(r.To)
+	}
+
+	switch {
+	case r.From != nil && r.To != nil && !r.ExcludeFrom && !r.ExcludeTo:
+		return []string{field + ":" + format(r.From) + ".." + format(r.To)}
+	case r.From != nil && r.To != nil:
+		return []string{lower(), upper()}
+	case r.From != nil:
+		return []string{lower()}
+	case r.To != nil:
+		return []string{upper()}
+	default:
+		return nil
+	}
+}
+
+// String 编码整数范围
+func (r *IntRange) String() string {
+	switch {
+	case r.Min != nil && r.Max != nil && *r.Min == *r.Max:
+		return strconv.Itoa(*r.Min)
+	case r.Min != nil && r.Max != nil:
+		return strconv.Itoa(*r.Min) + ".." + strconv.Itoa(*r.Max)
+	case r.Min != nil:
+		return ">=" + strconv.Itoa(*r.Min)
+	default:
+		return "<=" + strconv.Itoa(*r.Max)
+	}
+}
+
+// quote 必要时为值加引号
+func quote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\"\\") {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
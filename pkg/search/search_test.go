@@ -0,0 +1,232 @@
+package search
+
+import (
+	"testing"
+	"time"
+)
+
+func intPtr(n int) *int { return &n }
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("invalid test time %q: %v", value, err)
+	}
+	return parsed
+}
+
+func TestParse_Fields(t *testing.T) {
+	q, err := Parse(`metadata.order.id:42 metadata.Region:"us east" HOST:API.example.com url:/hooks/ error:"connection refused" retries:2..5 order-123`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if q.Metadata["order.id"] != "42" || q.Metadata["Region"] != "us east" {
+		t.Errorf("Unexpected metadata: %v", q.Metadata)
+	}
+	if len(q.Hosts) != 1 || q.Hosts[0] != "api.example.com" {
+		t.Errorf("Unexpected hosts: %v", q.Hosts)
+	}
+	if len(q.URLContains) != 1 || q.URLContains[0] != "/hooks/" {
+		t.Errorf("Unexpected url terms: %v", q.URLContains)
+	}
+	if len(q.ErrorContains) != 1 || q.ErrorContains[0] != "connection refused" {
+		t.Errorf("Unexpected error terms: %v", q.ErrorContains)
+	}
+	if q.Retries == nil || *q.Retries.Min != 2 || *q.Retries.Max != 5 {
+		t.Errorf("Unexpected retries range: %+v", q.Retries)
+	}
+	if len(q.Text) != 1 || q.Text[0] != "order-123" {
+		t.Errorf("Unexpected text terms: %v", q.Text)
+	}
+}
+
+func TestParse_TimeRanges(t *testing.T) {
+	day := mustTime(t, "2024-01-01T00:00:00Z")
+	nextDay := day.AddDate(0, 0, 1)
+	exact := mustTime(t, "2024-01-01T08:30:00Z")
+
+	tests := []struct {
+		name  string
+		input string
+		want  TimeRange
+	}{
+		{"date equals whole day", "scheduled:2024-01-01", TimeRange{From: &day, To: &nextDay, ExcludeTo: true}},
+		{"after date", "scheduled:>2024-01-01", TimeRange{From: &nextDay}},
+		{"at or after time", "scheduled:>=2024-01-01T08:30:00Z", TimeRange{From: &exact}},
+		{"before time", "scheduled:<2024-01-01T08:30:00Z", TimeRange{To: &exact, ExcludeTo: true}},
+		{"up to date", "scheduled:<=2024-01-01", TimeRange{To: &nextDay, ExcludeTo: true}},
+		{"closed range", "scheduled:2024-01-01T00:00:00Z..2024-01-01T08:30:00Z", TimeRange{From: &day, To: &exact}},
+		{"open start", "scheduled:..2024-01-01", TimeRange{To: &nextDay, ExcludeTo: true}},
+		{"merged bounds", "scheduled:>=2024-01-01 scheduled:<2024-01-02", TimeRange{From: &day, To: &nextDay, ExcludeTo: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+			}
+			got := q.Scheduled
+			if got == nil {
+				t.Fatalf("Parse(%q) returned no scheduled range", tt.input)
+			}
+			if !timeEqual(got.From, tt.want.From) || !timeEqual(got.To, tt.want.To) ||
+				got.ExcludeFrom != tt.want.ExcludeFrom || got.ExcludeTo != tt.want.ExcludeTo {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func timeEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+func TestParse_RetryRanges(t *testing.T) {
+	tests := []struct {
+		input    string
+		min, max *int
+	}{
+		{"retries:3", intPtr(3), intPtr(3)},
+		{"retries:>3", intPtr(4), nil},
+		{"retries:>=3", intPtr(3), nil},
+		{"retries:<3", nil, intPtr(2)},
+		{"retries:<=3", nil, intPtr(3)},
+		{"retries:1..", intPtr(1), nil},
+		{"retries:>=1 retries:<=4", intPtr(1), intPtr(4)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			q, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+			}
+			if !intEqual(q.Retries.Min, tt.min) || !intEqual(q.Retries.Max, tt.max) {
+				t.Errorf("Parse(%q) = %v..%v", tt.input, q.Retries.Min, q.Retries.Max)
+			}
+		})
+	}
+}
+
+func intEqual(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []string{
+		`unknown:value`,
+		`metadata.bad key:1`,
+		`metadata.a$b:1`,
+		`host:bad_host`,
+		`url:`,
+		`error:"unterminated`,
+		`scheduled:yesterday`,
+		`scheduled:2024-02-01..2024-01-01`,
+		`scheduled:>=2024-01-01 scheduled:>=2024-01-02`,
+		`retries:-1`,
+		`retries:5..2`,
+		`retries:..`,
+		`metadata.a:1 metadata.a:2`,
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			if _, err := Parse(input); err == nil {
+				t.Errorf("Parse(%q) expected error", input)
+			}
+		})
+	}
+}
+
+func TestParse_Limits(t *testing.T) {
+	long := make([]byte, MaxQueryLength+1)
+	for i := range long {
+		long[i] = 'a'
+	}
+	if _, err := Parse(string(long)); err == nil {
+		t.Error("Expected error for query exceeding MaxQueryLength")
+	}
+
+	q := NewQuery()
+	for i := 0; i <= MaxTerms; i++ {
+		q.WithURLContains("x")
+	}
+	if _, err := Parse(q.String()); err == nil {
+		t.Error("Expected error for query exceeding MaxTerms")
+	}
+}
+
+func TestQuery_StringRoundTrip(t *testing.T) {
+	from := mustTime(t, "2024-01-01T00:00:00Z")
+	to := mustTime(t, "2024-01-31T00:00:00Z")
+
+	q := NewQuery().
+		WithMetadata("order.id", "42").
+		WithMetadata("note", `say "hi"`).
+		WithHost("*.example.com").
+		WithURLContains("/hooks").
+		WithErrorContains("timeout after 30s").
+		WithScheduled(&from, &to).
+		WithCompleted(&from, nil).
+		WithRetries(intPtr(1), nil).
+		WithText("order 42")
+
+	parsed, err := Parse(q.String())
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", q.String(), err)
+	}
+	if parsed.String() != q.String() {
+		t.Errorf("Round trip mismatch:\n got %s\nwant %s", parsed.String(), q.String())
+	}
+
+	// 日期解析出的半开区间也能往返
+	parsed, err = Parse("executed:2024-01-01")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	again, err := Parse(parsed.String())
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", parsed.String(), err)
+	}
+	if !timeEqual(again.Executed.From, parsed.Executed.From) || !timeEqual(again.Executed.To, parsed.Executed.To) ||
+		again.Executed.ExcludeTo != parsed.Executed.ExcludeTo {
+		t.Errorf("Half-open range round trip mismatch: %+v vs %+v", again.Executed, parsed.Executed)
+	}
+}
+
+func TestQuery_IsEmpty(t *testing.T) {
+	var nilQuery *Query
+	if !nilQuery.IsEmpty() || !NewQuery().IsEmpty() {
+		t.Error("Expected nil and new queries to be empty")
+	}
+	if NewQuery().WithErrorContains("x").IsEmpty() {
+		t.Error("Expected query with a term to be non-empty")
+	}
+	q, err := Parse("   ")
+	if err != nil || !q.IsEmpty() {
+		t.Errorf("Expected blank input to parse to an empty query, got %v, %v", q, err)
+	}
+}
+
+func TestQuery_Clone(t *testing.T) {
+	from := mustTime(t, "2024-01-01T00:00:00Z")
+	q := NewQuery().WithMetadata("k", "v").WithHost("a.com").WithScheduled(&from, nil).WithRetries(intPtr(1), intPtr(2))
+
+	c := q.Clone()
+	c.WithMetadata("k", "changed").WithHost("b.com")
+	*c.Scheduled.From = from.Add(time.Hour)
+	*c.Retries.Min = 5
+
+	if q.Metadata["k"] != "v" || len(q.Hosts) != 1 || !q.Scheduled.From.Equal(from) || *q.Retries.Min != 1 {
+		t.Errorf("Clone shares state with the original: %s", q.String())
+	}
+}
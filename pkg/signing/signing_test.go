@@ -0,0 +1,175 @@
+package signing
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSignedRequest(t *testing.T, signer *RequestSigner, method, target, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if err := signer.Sign(req, []byte(body)); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	return req
+}
+
+func staticSecret(secret string) SecretLookup {
+	return func(r *http.Request) (string, error) {
+		return secret, nil
+	}
+}
+
+func TestNewRequestSigner(t *testing.T) {
+	if _, err := NewRequestSigner(""); err == nil {
+		t.Error("NewRequestSigner() should reject an empty secret")
+	}
+}
+
+func TestSignatureVerifier_Verify(t *testing.T) {
+	signer, _ := NewRequestSigner("business-secret")
+
+	tests := []struct {
+		name    string
+		mutate  func(req *http.Request)
+		secret  string
+		wantErr error
+	}{
+		{
+			name:   "valid signature",
+			secret: "business-secret",
+		},
+		{
+			name:    "wrong secret",
+			secret:  "other-secret",
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name:    "missing nonce",
+			secret:  "business-secret",
+			mutate:  func(req *http.Request) { req.Header.Del(HeaderNonce) },
+			wantErr: ErrSignatureMissing,
+		},
+		{
+			name:   "tampered body",
+			secret: "business-secret",
+			mutate: func(req *http.Request) {
+				req.Body = io.NopCloser(strings.NewReader(`{"priority":1}`))
+			},
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name:    "tampered query",
+			secret:  "business-secret",
+			mutate:  func(req *http.Request) { req.URL.RawQuery = "status=1&status=2" },
+			wantErr: ErrSignatureInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newSignedRequest(t, signer, "POST", "/api/v1/tasks?status=1&limit=10", `{"priority":5}`)
+			if tt.mutate != nil {
+				tt.mutate(req)
+			}
+
+			err := NewSignatureVerifier(staticSecret(tt.secret)).Verify(req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignatureVerifier_QueryOrder(t *testing.T) {
+	signer, _ := NewRequestSigner("business-secret")
+	req := newSignedRequest(t, signer, "GET", "/api/v1/tasks?status=1&limit=10", "")
+	req.URL.RawQuery = "limit=10&status=1"
+
+	if err := NewSignatureVerifier(staticSecret("business-secret")).Verify(req); err != nil {
+		t.Errorf("Verify() should ignore query parameter order, got %v", err)
+	}
+}
+
+func TestSignatureVerifier_ClockSkew(t *testing.T) {
+	signer, _ := NewRequestSigner("business-secret")
+	signer.now = func() time.Time { return time.Now().Add(-10 * time.Minute) }
+	req := newSignedRequest(t, signer, "GET", "/api/v1/tasks", "")
+
+	err := NewSignatureVerifier(staticSecret("business-secret"), WithClockSkew(5*time.Minute)).Verify(req)
+	if !errors.Is(err, ErrSignatureExpired) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrSignatureExpired)
+	}
+}
+
+func TestSignatureVerifier_Replay(t *testing.T) {
+	signer, _ := NewRequestSigner("business-secret")
+	verifier := NewSignatureVerifier(staticSecret("business-secret"))
+
+	req := newSignedRequest(t, signer, "POST", "/api/v1/tasks", `{"priority":5}`)
+	replay := req.Clone(req.Context())
+	replay.Body = io.NopCloser(strings.NewReader(`{"priority":5}`))
+
+	if err := verifier.Verify(req); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != `{"priority":5}` {
+		t.Errorf("Verify() should restore the request body, got %q", body)
+	}
+
+	if err := verifier.Verify(replay); !errors.Is(err, ErrNonceReplayed) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrNonceReplayed)
+	}
+}
+
+func TestMemoryNonceCache_Expiry(t *testing.T) {
+	now := time.Now()
+	cache := NewMemoryNonceCache()
+	cache.now = func() time.Time { return now }
+
+	if !cache.Use("n1", time.Minute) {
+		t.Fatal("first use should succeed")
+	}
+	if cache.Use("n1", time.Minute) {
+		t.Error("second use within ttl should fail")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if !cache.Use("n1", time.Minute) {
+		t.Error("use after ttl should succeed")
+	}
+	if len(cache.nonces) != 1 {
+		t.Errorf("expired nonces should be swept, got %d entries", len(cache.nonces))
+	}
+}
+
+func TestSignatureVerifier_Middleware(t *testing.T) {
+	verifier := NewSignatureVerifier(staticSecret("business-secret"))
+	called := false
+	handler := verifier.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	})
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest("GET", "/api/v1/tasks", nil))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for unsigned request, got %d", recorder.Code)
+	}
+	if called {
+		t.Error("next handler should not be called for unsigned request")
+	}
+
+	signer, _ := NewRequestSigner("business-secret")
+	recorder = httptest.NewRecorder()
+	handler(recorder, newSignedRequest(t, signer, "GET", "/api/v1/tasks", ""))
+	if recorder.Code != http.StatusOK || !called {
+		t.Errorf("Expected signed request to pass, got status %d", recorder.Code)
+	}
+}
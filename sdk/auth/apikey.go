@@ -30,6 +30,11 @@ func (a *APIKeyAuth) Authenticate(req *http.Request) error {
 	return nil
 }
 
+// GetType 获取认证类型
+func (a *APIKeyAuth) GetType() AuthType {
+	return AuthTypeAPIKey
+}
+
 // ValidateAPIKey 验证API Key格式
 func (a *APIKeyAuth) ValidateAPIKey() error {
 	if a.apiKey == "" {
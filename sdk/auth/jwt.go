@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -71,6 +72,11 @@ func (j *JWTAuth) Authenticate(req *http.Request) error {
 	return nil
 }
 
+// GetType 获取认证类型
+func (j *JWTAuth) GetType() AuthType {
+	return AuthTypeJWT
+}
+
 // ValidateToken 验证JWT令牌
 func (j *JWTAuth) ValidateToken(token string) (*JWTPayload, error) {
 	if token == "" {
@@ -192,8 +198,9 @@ func (j *JWTAuth) Clone() *JWTAuth {
 	}
 }
 
-// JWTManager JWT令牌管理器
+// JWTManager JWT令牌管理器，可并发使用：客户端请求读取令牌的同时，自动刷新或 401 重试可能在更新令牌
 type JWTManager struct {
+	mu                sync.RWMutex
 	accessToken       string
 	refreshToken      string
 	secret            string
@@ -259,9 +266,9 @@ func NewJWTManager(config *JWTManagerConfig) (*JWTManager, error) {
 	return manager, nil
 }
 
-// parseTokenExpiry 解析令牌过期时间
+// parseTokenExpiry 解析令牌过期时间，调用方需持有写锁
 func (m *JWTManager) parseTokenExpiry() error {
-	auth := m.GetAuth()
+	auth := m.auth()
 
 	// 解析访问令牌过期时间
 	if m.accessToken != "" {
@@ -284,22 +291,32 @@ func (m *JWTManager) parseTokenExpiry() error {
 
 // GetAuth 获取认证器实例
 func (m *JWTManager) GetAuth() *JWTAuth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.auth()
+}
+
+// auth 使用当前令牌创建认证器，调用方需持有锁
+func (m *JWTManager) auth() *JWTAuth {
 	return NewJWTAuthWithConfig(m.accessToken, m.refreshToken, m.secret, m.issuer, m.audience)
 }
 
 // IsAccessTokenValid 检查访问令牌是否有效
 func (m *JWTManager) IsAccessTokenValid() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	if m.accessToken == "" {
 		return false
 	}
 
-	auth := m.GetAuth()
-	_, err := auth.ValidateToken(m.accessToken)
+	_, err := m.auth().ValidateToken(m.accessToken)
 	return err == nil
 }
 
 // IsAccessTokenExpired 检查访问令牌是否过期
 func (m *JWTManager) IsAccessTokenExpired() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	if m.accessTokenExpiry == nil {
 		return false
 	}
@@ -308,6 +325,8 @@ func (m *JWTManager) IsAccessTokenExpired() bool {
 
 // NeedsRefresh 检查是否需要刷新令牌（在过期前5分钟刷新）
 func (m *JWTManager) NeedsRefresh() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	if m.accessTokenExpiry == nil {
 		return false
 	}
@@ -318,17 +337,21 @@ func (m *JWTManager) NeedsRefresh() bool {
 
 // Refresh 刷新JWT令牌
 func (m *JWTManager) Refresh(ctx context.Context) error {
-	if m.refreshToken == "" {
+	m.mu.RLock()
+	refreshToken, refreshURL := m.refreshToken, m.refreshURL
+	m.mu.RUnlock()
+
+	if refreshToken == "" {
 		return fmt.Errorf("refresh token is empty")
 	}
 
-	if m.refreshURL == "" {
+	if refreshURL == "" {
 		return fmt.Errorf("refresh URL is not configured")
 	}
 
 	// 准备刷新请求
 	refreshRequest := map[string]string{
-		"refresh_token": m.refreshToken,
+		"refresh_token": refreshToken,
 	}
 
 	reqBody, err := json.Marshal(refreshRequest)
@@ -336,7 +359,7 @@ func (m *JWTManager) Refresh(ctx context.Context) error {
 		return fmt.Errorf("failed to marshal refresh request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", m.refreshURL, strings.NewReader(string(reqBody)))
+	req, err := http.NewRequestWithContext(ctx, "POST", refreshURL, strings.NewReader(string(reqBody)))
 	if err != nil {
 		return fmt.Errorf("failed to create refresh request: %w", err)
 	}
@@ -365,6 +388,8 @@ func (m *JWTManager) Refresh(ctx context.Context) error {
 	}
 
 	// 更新令牌
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.accessToken = refreshResponse.AccessToken
 	if refreshResponse.RefreshToken != "" {
 		m.refreshToken = refreshResponse.RefreshToken
@@ -381,6 +406,8 @@ func (m *JWTManager) UpdateTokens(accessToken, refreshToken string) error {
 		return fmt.Errorf("access token cannot be empty")
 	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.accessToken = strings.TrimSpace(accessToken)
 	if refreshToken != "" {
 		m.refreshToken = strings.TrimSpace(refreshToken)
@@ -397,16 +424,22 @@ func (m *JWTManager) GetCreatedAt() time.Time {
 
 // GetUpdatedAt 获取更新时间
 func (m *JWTManager) GetUpdatedAt() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.updatedAt
 }
 
 // GetAccessTokenExpiry 获取访问令牌过期时间
 func (m *JWTManager) GetAccessTokenExpiry() *time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.accessTokenExpiry
 }
 
 // GetRefreshTokenExpiry 获取刷新令牌过期时间
 func (m *JWTManager) GetRefreshTokenExpiry() *time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.refreshTokenExpiry
 }
 
@@ -425,6 +458,8 @@ type JWTCredentials struct {
 
 // ToCredentials 转换为凭证信息
 func (m *JWTManager) ToCredentials() *JWTCredentials {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return &JWTCredentials{
 		AccessToken:        m.accessToken,
 		RefreshToken:       m.refreshToken,
@@ -448,6 +483,8 @@ func (m *JWTManager) FromCredentials(creds *JWTCredentials) error {
 		return fmt.Errorf("access token cannot be empty")
 	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.accessToken = creds.AccessToken
 	m.refreshToken = creds.RefreshToken
 	m.secret = creds.Secret
@@ -6,29 +6,33 @@ import (
 	"sync"
 	"time"
 
+	"task-center/sdk"
 	"task-center/sdk/task"
 )
 
 // BatchClient 批量操作客户端
 type BatchClient struct {
-	client      *task.Client
-	concurrency int
-	timeout     time.Duration
+	client       *task.Client
+	concurrency  int
+	timeout      time.Duration
+	pollInterval time.Duration
 }
 
 // BatchConfig 批量操作配置
 type BatchConfig struct {
-	Concurrency int           // 并发数
-	Timeout     time.Duration // 操作超时时间
-	BatchSize   int           // 批次大小
+	Concurrency  int           // 并发数
+	Timeout      time.Duration // 操作超时时间
+	BatchSize    int           // 批次大小
+	PollInterval time.Duration // 等待服务端批量任务完成时的轮询间隔
 }
 
 // DefaultBatchConfig 默认批量操作配置
 func DefaultBatchConfig() *BatchConfig {
 	return &BatchConfig{
-		Concurrency: 10,
-		Timeout:     5 * time.Minute,
-		BatchSize:   100,
+		Concurrency:  10,
+		Timeout:      5 * time.Minute,
+		BatchSize:    100,
+		PollInterval: task.DefaultBulkJobPollInterval,
 	}
 }
 
@@ -39,9 +43,10 @@ func NewBatchClient(client *task.Client, config *BatchConfig) *BatchClient {
 	}
 
 	return &BatchClient{
-		client:      client,
-		concurrency: config.Concurrency,
-		timeout:     config.Timeout,
+		client:       client,
+		concurrency:  config.Concurrency,
+		timeout:      config.Timeout,
+		pollInterval: config.PollInterval,
 	}
 }
 
@@ -419,6 +424,88 @@ func (bc *BatchClient) RetryTasks(ctx context.Context, taskIDs []int64) (*BatchU
 	return result, nil
 }
 
+// BatchRescheduleResult 批量改期结果
+// Results 与任务ID顺序一致，请求失败的任务对应位置为 nil，并记录在 Failed 中
+type BatchRescheduleResult struct {
+	Results     []*task.RescheduleResult
+	Rescheduled int
+	Skipped     int // 不是待执行状态（如正在执行）而未修改的任务数
+	NotFound    int
+	Failed      []BatchError
+	Total       int
+}
+
+// RescheduleTasks 批量调整待执行任务的执行时间，按 sdk.MaxBatchRescheduleTasks 分批并发请求
+func (bc *BatchClient) RescheduleTasks(ctx context.Context, taskIDs []int64, req *sdk.RescheduleRequest) (*BatchRescheduleResult, error) {
+	if len(taskIDs) == 0 {
+		return &BatchRescheduleResult{Total: 0}, nil
+	}
+	if req == nil {
+		return nil, sdk.NewValidationError("reschedule request cannot be nil")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	result := &BatchRescheduleResult{
+		Results: make([]*task.RescheduleResult, len(taskIDs)),
+		Total:   len(taskIDs),
+	}
+
+	// 使用信号量控制并发
+	semaphore := make(chan struct{}, bc.concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for start := 0; start < len(taskIDs); start += sdk.MaxBatchRescheduleTasks {
+		end := min(start+sdk.MaxBatchRescheduleTasks, len(taskIDs))
+
+		wg.Add(1)
+		go func(offset int, ids []int64) {
+			defer wg.Done()
+
+			// 获取信号量
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			// 创建带超时的上下文
+			taskCtx, cancel := context.WithTimeout(ctx, bc.timeout)
+			defer cancel()
+
+			items, err := bc.client.RescheduleTasks(taskCtx, ids, req)
+			if err == nil && len(items) != len(ids) {
+				err = fmt.Errorf("expected %d reschedule results, got %d", len(ids), len(items))
+			}
+
+			// 保存结果
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				for i := range ids {
+					result.Failed = append(result.Failed, BatchError{Index: offset + i, Error: err})
+				}
+				return
+			}
+			for i, item := range items {
+				result.Results[offset+i] = item
+				switch item.Outcome {
+				case sdk.RescheduleOutcomeRescheduled:
+					result.Rescheduled++
+				case sdk.RescheduleOutcomeSkipped:
+					result.Skipped++
+				case sdk.RescheduleOutcomeNotFound:
+					result.NotFound++
+				}
+			}
+		}(start, taskIDs[start:end])
+	}
+
+	// 等待所有批次完成
+	wg.Wait()
+
+	return result, nil
+}
+
 // BatchProcessor 批处理器，用于处理大量数据
 type BatchProcessor struct {
 	client    *BatchClient
@@ -0,0 +1,80 @@
+package batch
+
+import (
+	"context"
+	"time"
+
+	"task-center/sdk"
+	"task-center/sdk/task"
+)
+
+// 按过滤条件的批量操作由服务端异步执行，不需要先查询任务ID再逐个调用：
+//
+//	job, err := bc.CancelByFilter(ctx, task.NewListRequest().WithStatus(task.StatusPending).WithTags("campaign-42"))
+//	job, err = bc.WaitForJob(ctx, job.ID, func(j *sdk.BulkJob) { log.Printf("%.0f%%", j.Progress()*100) })
+
+// StartJob 提交按过滤条件批量操作的任务，dryRun 为 true 时只统计会被处理的任务数
+func (bc *BatchClient) StartJob(ctx context.Context, action sdk.BulkAction, filter *task.ListRequest, dryRun bool) (*sdk.BulkJob, error) {
+	req := task.NewBulkJobRequest(action, filter)
+	req.DryRun = dryRun
+	return bc.client.StartBulkJob(ctx, req)
+}
+
+// CancelByFilter 取消所有匹配且待执行的任务
+func (bc *BatchClient) CancelByFilter(ctx context.Context, filter *task.ListRequest) (*sdk.BulkJob, error) {
+	return bc.StartJob(ctx, sdk.BulkActionCancel, filter, false)
+}
+
+// RetryByFilter 重新执行所有匹配且已失败或过期的任务
+func (bc *BatchClient) RetryByFilter(ctx context.Context, filter *task.ListRequest) (*sdk.BulkJob, error) {
+	return bc.StartJob(ctx, sdk.BulkActionRetry, filter, false)
+}
+
+// DeleteByFilter 删除所有匹配且不在执行中的任务
+func (bc *BatchClient) DeleteByFilter(ctx context.Context, filter *task.ListRequest) (*sdk.BulkJob, error) {
+	return bc.StartJob(ctx, sdk.BulkActionDelete, filter, false)
+}
+
+// CountByFilter 试运行批量操作，返回会被处理的任务数
+func (bc *BatchClient) CountByFilter(ctx context.Context, action sdk.BulkAction, filter *task.ListRequest) (int, error) {
+	job, err := bc.StartJob(ctx, action, filter, true)
+	if err != nil {
+		return 0, err
+	}
+	if !job.Status.IsTerminal() {
+		if job, err = bc.WaitForJob(ctx, job.ID, nil); err != nil {
+			return 0, err
+		}
+	}
+	if job.Status == sdk.BulkJobStatusFailed {
+		return 0, sdk.NewServerError(job.Error)
+	}
+	return job.Total, nil
+}
+
+// GetJob 查询批量操作任务的进度
+func (bc *BatchClient) GetJob(ctx context.Context, jobID string) (*sdk.BulkJob, error) {
+	return bc.client.GetBulkJob(ctx, jobID)
+}
+
+// CancelJob 取消批量操作任务，已处理的任务不会回滚
+func (bc *BatchClient) CancelJob(ctx context.Context, jobID string) (*sdk.BulkJob, error) {
+	return bc.client.CancelBulkJob(ctx, jobID)
+}
+
+// WaitForJob 等待批量操作任务结束，onProgress 不为空时每次轮询后回调
+func (bc *BatchClient) WaitForJob(ctx context.Context, jobID string, onProgress func(*sdk.BulkJob)) (*sdk.BulkJob, error) {
+	return bc.client.WaitBulkJob(ctx, jobID, bc.pollInterval, onProgress)
+}
+
+// RescheduleByFilter 调整所有匹配且待执行任务的执行时间，执行中的任务计入 Skipped
+func (bc *BatchClient) RescheduleByFilter(ctx context.Context, filter *task.ListRequest, req *sdk.RescheduleRequest) (*sdk.BulkJob, error) {
+	jobReq := task.NewBulkJobRequest(sdk.BulkActionReschedule, filter)
+	jobReq.Reschedule = req
+	return bc.client.StartBulkJob(ctx, jobReq)
+}
+
+// SnoozeByFilter 将所有匹配且待执行的任务顺延 d
+func (bc *BatchClient) SnoozeByFilter(ctx context.Context, filter *task.ListRequest, d time.Duration) (*sdk.BulkJob, error) {
+	return bc.RescheduleByFilter(ctx, filter, sdk.SnoozeFor(d))
+}
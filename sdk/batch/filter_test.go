@@ -0,0 +1,153 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"task-center/sdk"
+	"task-center/sdk/task"
+)
+
+func newFilterTestClient(t *testing.T, handler http.HandlerFunc) *BatchClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := task.NewClientWithConfig(&sdk.Config{
+		BaseURL:    server.URL,
+		APIKey:     "test-api-key",
+		BusinessID: 123,
+		Timeout:    5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create task client: %v", err)
+	}
+
+	config := DefaultBatchConfig()
+	config.PollInterval = time.Millisecond
+	return NewBatchClient(client, config)
+}
+
+func writeJob(w http.ResponseWriter, job sdk.BulkJob) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sdk.ApiResponse{Success: true, Data: job})
+}
+
+func TestBatchClient_CountByFilter(t *testing.T) {
+	var polls int32
+	bc := newFilterTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v1/tasks/bulk-jobs":
+			var req sdk.BulkJobRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if !req.DryRun || req.Action != sdk.BulkActionDelete || len(req.Filter.Tags) != 1 {
+				t.Errorf("Unexpected request: %+v", req)
+			}
+			writeJob(w, sdk.BulkJob{ID: "bulk_1", Action: req.Action, Status: sdk.BulkJobStatusPending, DryRun: true})
+		case r.Method == "GET" && r.URL.Path == "/api/v1/tasks/bulk-jobs/bulk_1":
+			status := sdk.BulkJobStatusRunning
+			if atomic.AddInt32(&polls, 1) > 1 {
+				status = sdk.BulkJobStatusCompleted
+			}
+			writeJob(w, sdk.BulkJob{ID: "bulk_1", Status: status, DryRun: true, Total: 17})
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	count, err := bc.CountByFilter(context.Background(), sdk.BulkActionDelete, task.NewListRequest().WithTags("campaign"))
+	if err != nil {
+		t.Fatalf("CountByFilter failed: %v", err)
+	}
+	if count != 17 {
+		t.Errorf("Expected 17 matching tasks, got %d", count)
+	}
+}
+
+func TestBatchClient_CancelByFilter(t *testing.T) {
+	bc := newFilterTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req sdk.BulkJobRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.DryRun || req.Action != sdk.BulkActionCancel || req.Filter.PageSize != 0 {
+			t.Errorf("Unexpected request: %+v", req)
+		}
+		writeJob(w, sdk.BulkJob{ID: "bulk_2", Action: req.Action, Status: sdk.BulkJobStatusPending})
+	})
+
+	filter := task.NewListRequest().WithStatus(task.StatusPending).WithPagination(1, 100)
+	job, err := bc.CancelByFilter(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("CancelByFilter failed: %v", err)
+	}
+	if job.ID != "bulk_2" || job.Status != sdk.BulkJobStatusPending {
+		t.Errorf("Unexpected job: %+v", job)
+	}
+
+	if _, err := bc.DeleteByFilter(context.Background(), task.NewListRequest()); err == nil {
+		t.Error("Expected an empty filter to be rejected")
+	}
+}
+
+func TestBatchClient_SnoozeByFilter(t *testing.T) {
+	bc := newFilterTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req sdk.BulkJobRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Action != sdk.BulkActionReschedule || req.Reschedule == nil || req.Reschedule.DelaySeconds != 7200 {
+			t.Errorf("Unexpected request: %+v", req)
+		}
+		writeJob(w, sdk.BulkJob{ID: "bulk_3", Action: req.Action, Status: sdk.BulkJobStatusPending})
+	})
+
+	job, err := bc.SnoozeByFilter(context.Background(), task.NewListRequest().WithTags("reminder"), 2*time.Hour)
+	if err != nil {
+		t.Fatalf("SnoozeByFilter failed: %v", err)
+	}
+	if job.Action != sdk.BulkActionReschedule {
+		t.Errorf("Unexpected job: %+v", job)
+	}
+}
+
+func TestBatchClient_RescheduleTasks(t *testing.T) {
+	var requests int32
+	bc := newFilterTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var req sdk.BatchRescheduleRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		results := make([]sdk.RescheduleResult, len(req.TaskIDs))
+		for i, id := range req.TaskIDs {
+			results[i] = sdk.RescheduleResult{TaskID: id, Outcome: sdk.RescheduleOutcomeRescheduled}
+			if id%2 == 0 {
+				results[i].Outcome = sdk.RescheduleOutcomeSkipped
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sdk.ApiResponse{Success: true, Data: sdk.BatchRescheduleResponse{Results: results}})
+	})
+
+	ids := make([]int64, sdk.MaxBatchRescheduleTasks+10)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+
+	result, err := bc.RescheduleTasks(context.Background(), ids, sdk.SnoozeFor(time.Hour))
+	if err != nil {
+		t.Fatalf("RescheduleTasks failed: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 chunked requests, got %d", requests)
+	}
+	if result.Total != len(ids) || result.Rescheduled+result.Skipped != len(ids) || len(result.Failed) != 0 {
+		t.Errorf("Unexpected result counts: %+v", result)
+	}
+	for i, item := range result.Results {
+		if item == nil || item.TaskID != ids[i] {
+			t.Fatalf("Result %d out of order: %+v", i, item)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"task-center/pkg/search"
 	"task-center/sdk/task"
 )
 
@@ -11,6 +12,7 @@ import (
 type QueryBuilder struct {
 	client  *task.Client
 	request *task.ListRequest
+	search  *search.Query // 元数据、回调地址、错误信息等搜索条件
 	context context.Context
 }
 
@@ -19,6 +21,7 @@ func NewQueryBuilder(client *task.Client) *QueryBuilder {
 	return &QueryBuilder{
 		client:  client,
 		request: task.NewListRequest(),
+		search:  search.NewQuery(),
 		context: context.Background(),
 	}
 }
@@ -76,6 +79,18 @@ func (q *QueryBuilder) WithTags(tags ...string) *QueryBuilder {
 	return q
 }
 
+// WithAnyTags 查询包含任一标签的任务
+func (q *QueryBuilder) WithAnyTags(tags ...string) *QueryBuilder {
+	q.request = q.request.WithAnyTags(tags...)
+	return q
+}
+
+// WithAllTags 查询包含全部标签的任务
+func (q *QueryBuilder) WithAllTags(tags ...string) *QueryBuilder {
+	q.request = q.request.WithAllTags(tags...)
+	return q
+}
+
 // WithTag 添加单个标签过滤条件
 func (q *QueryBuilder) WithTag(tag string) *QueryBuilder {
 	return q.WithTags(tag)
@@ -161,6 +176,60 @@ func (q *QueryBuilder) WithLastHour() *QueryBuilder {
 	return q.WithCreatedTimeRange(&pastHour, &now)
 }
 
+// WithMetadata 添加元数据键值过滤条件，key 可用 . 访问嵌套字段
+func (q *QueryBuilder) WithMetadata(key, value string) *QueryBuilder {
+	q.search.WithMetadata(key, value)
+	return q
+}
+
+// WithCallbackHost 按回调地址主机名过滤，支持 *.example.com 匹配子域名
+func (q *QueryBuilder) WithCallbackHost(host string) *QueryBuilder {
+	q.search.WithHost(host)
+	return q
+}
+
+// WithCallbackURLContains 查询回调地址包含指定内容的任务
+func (q *QueryBuilder) WithCallbackURLContains(text string) *QueryBuilder {
+	q.search.WithURLContains(text)
+	return q
+}
+
+// WithErrorContains 查询错误信息包含指定内容的任务
+func (q *QueryBuilder) WithErrorContains(text string) *QueryBuilder {
+	q.search.WithErrorContains(text)
+	return q
+}
+
+// WithScheduledTimeRange 添加计划执行时间范围过滤条件
+func (q *QueryBuilder) WithScheduledTimeRange(from, to *time.Time) *QueryBuilder {
+	q.search.WithScheduled(from, to)
+	return q
+}
+
+// WithExecutedTimeRange 添加实际执行时间范围过滤条件
+func (q *QueryBuilder) WithExecutedTimeRange(from, to *time.Time) *QueryBuilder {
+	q.search.WithExecuted(from, to)
+	return q
+}
+
+// WithCompletedTimeRange 添加完成时间范围过滤条件
+func (q *QueryBuilder) WithCompletedTimeRange(from, to *time.Time) *QueryBuilder {
+	q.search.WithCompleted(from, to)
+	return q
+}
+
+// WithRetryRange 添加重试次数范围过滤条件，为空的一端表示不限
+func (q *QueryBuilder) WithRetryRange(min, max *int) *QueryBuilder {
+	q.search.WithRetries(min, max)
+	return q
+}
+
+// WithRetried 查询至少重试过一次的任务
+func (q *QueryBuilder) WithRetried() *QueryBuilder {
+	min := 1
+	return q.WithRetryRange(&min, nil)
+}
+
 // WithPagination 设置分页参数
 func (q *QueryBuilder) WithPagination(page, pageSize int) *QueryBuilder {
 	q.request = q.request.WithPagination(page, pageSize)
@@ -206,9 +275,9 @@ func (q *QueryBuilder) PrevPage() *QueryBuilder {
 	return q
 }
 
-// Execute 执行查询
+// Execute 执行查询，设置了搜索条件时使用搜索接口
 func (q *QueryBuilder) Execute() (*task.ListResponse, error) {
-	return q.client.ListTasks(q.context, q.request)
+	return q.client.Search(q.context, q.search, q.request)
 }
 
 // Count 获取查询结果总数（执行查询并返回总数）
@@ -261,42 +330,14 @@ func (q *QueryBuilder) First() (*task.Task, error) {
 	return resp.Tasks[0], nil
 }
 
-// GetAll 获取所有匹配的任务（自动分页获取）
+// GetAll 获取所有匹配的任务（游标分页，不受并发写入影响）
 func (q *QueryBuilder) GetAll() ([]*task.Task, error) {
-	var allTasks []*task.Task
-	page := 1
-	pageSize := 100 // 每次获取100条记录
-
-	for {
-		// 保存原始分页设置
-		originalPage := q.request.Page
-		originalPageSize := q.request.PageSize
-
-		// 设置当前分页
-		q.request.Page = page
-		q.request.PageSize = pageSize
-
-		resp, err := q.Execute()
-
-		// 恢复原始分页设置
-		q.request.Page = originalPage
-		q.request.PageSize = originalPageSize
-
-		if err != nil {
-			return nil, err
-		}
-
-		allTasks = append(allTasks, resp.Tasks...)
-
-		// 如果已经获取完所有数据，退出循环
-		if len(resp.Tasks) < pageSize || len(allTasks) >= resp.Total {
-			break
-		}
-
-		page++
-	}
+	return q.Iterate().Collect()
+}
 
-	return allTasks, nil
+// Iterate 返回遍历所有匹配任务的迭代器
+func (q *QueryBuilder) Iterate() *task.Iterator {
+	return q.client.IterateSearch(q.context, q.search, q.request)
 }
 
 // Exists 检查是否存在匹配的任务
@@ -312,14 +353,18 @@ func (q *QueryBuilder) Exists() (bool, error) {
 func (q *QueryBuilder) Clone() *QueryBuilder {
 	newBuilder := &QueryBuilder{
 		client:  q.client,
+		search:  q.search.Clone(),
 		context: q.context,
 	}
 
 	// 深拷贝查询请求
-	newBuilder.request = &task.ListRequest{
-		Page:     q.request.Page,
-		PageSize: q.request.PageSize,
-	}
+	newBuilder.request = task.NewListRequest()
+	newBuilder.request.Page = q.request.Page
+	newBuilder.request.PageSize = q.request.PageSize
+	newBuilder.request.SortBy = q.request.SortBy
+	newBuilder.request.Descending = q.request.Descending
+	newBuilder.request.Cursor = q.request.Cursor
+	newBuilder.request.TagMatch = q.request.TagMatch
 
 	if q.request.Status != nil {
 		newBuilder.request.Status = make([]task.TaskStatus, len(q.request.Status))
@@ -352,6 +397,7 @@ func (q *QueryBuilder) Clone() *QueryBuilder {
 // Reset 重置查询构建器
 func (q *QueryBuilder) Reset() *QueryBuilder {
 	q.request = task.NewListRequest()
+	q.search = search.NewQuery()
 	q.context = context.Background()
 	return q
 }
@@ -361,7 +407,12 @@ func (q *QueryBuilder) GetRequest() *task.ListRequest {
 	return q.request
 }
 
-// Search 基于查询字符串搜索任务
+// GetSearch 获取构建的搜索条件（用于调试）
+func (q *QueryBuilder) GetSearch() *search.Query {
+	return q.search
+}
+
+// Search 基于搜索语法查询任务，语法见 pkg/search 包，不包含构建器中的搜索条件
 func (q *QueryBuilder) Search(query string) (*task.ListResponse, error) {
 	return q.client.SearchTasks(q.context, query, q.request)
 }
@@ -0,0 +1,104 @@
+package callback
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// 回调请求的处理结果，用作 outcome 标签
+const (
+	OutcomeSuccess          = "success"           // 处理成功
+	OutcomeSignatureFailure = "signature_failure" // 签名校验失败
+	OutcomeInvalidRequest   = "invalid_request"   // 请求方法、请求体或事件格式不合法
+	OutcomeHandlerError     = "handler_error"     // 处理函数返回错误
+	OutcomeRejected         = "rejected"          // 被前置中间件拒绝
+)
+
+// eventTypeUnknown 无法解析事件类型时使用的 event_type 标签
+const eventTypeUnknown = "unknown"
+
+// Metrics 回调接收端的 Prometheus 指标，实现 prometheus.Collector
+// 标签只取有限的取值（已知事件类型、处理结果、错误码），指标占用的内存不随请求数增长
+type Metrics struct {
+	requests          *prometheus.CounterVec
+	duration          *prometheus.HistogramVec
+	inFlight          prometheus.Gauge
+	signatureFailures *prometheus.CounterVec
+}
+
+// NewMetrics 创建回调指标，namespace 为空时使用 taskcenter
+func NewMetrics(namespace string) *Metrics {
+	if namespace == "" {
+		namespace = "taskcenter"
+	}
+	return &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "callback",
+			Name:      "requests_total",
+			Help:      "Callback requests received, by event type and outcome.",
+		}, []string{"event_type", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "callback",
+			Name:      "request_duration_seconds",
+			Help:      "Time spent handling callback requests, by event type.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"event_type"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "callback",
+			Name:      "requests_in_flight",
+			Help:      "Callback requests currently being handled.",
+		}),
+		signatureFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "callback",
+			Name:      "signature_failures_total",
+			Help:      "Callback requests rejected by signature validation, by error code.",
+		}, []string{"code"}),
+	}
+}
+
+// Describe 实现 prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.duration.Describe(ch)
+	m.inFlight.Describe(ch)
+	m.signatureFailures.Describe(ch)
+}
+
+// Collect 实现 prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.duration.Collect(ch)
+	m.inFlight.Collect(ch)
+	m.signatureFailures.Collect(ch)
+}
+
+// Handler 返回只包含回调指标的 /metrics 处理器；与应用其他指标一起暴露时，将 Metrics 注册到应用的 Registry
+func (m *Metrics) Handler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(m)
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// observe 记录一次回调请求的结果和耗时
+func (m *Metrics) observe(eventType, outcome string, seconds float64) {
+	if eventType == "" {
+		eventType = eventTypeUnknown
+	}
+	m.requests.WithLabelValues(eventType, outcome).Inc()
+	m.duration.WithLabelValues(eventType).Observe(seconds)
+}
+
+// signatureFailed 记录签名校验失败，code 为错误码
+func (m *Metrics) signatureFailed(err error) {
+	code := CodeUnknownError
+	if sdkErr, ok := err.(Error); ok {
+		code = sdkErr.Code()
+	}
+	m.signatureFailures.WithLabelValues(code).Inc()
+}
@@ -0,0 +1,93 @@
+package callback
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scrapeMetrics 请求服务器的 /metrics 路由
+func scrapeMetrics(t *testing.T, server *Server) string {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 from /metrics, got %d", recorder.Code)
+	}
+	body, _ := io.ReadAll(recorder.Body)
+	return string(body)
+}
+
+func TestServer_PrometheusMetrics(t *testing.T) {
+	registry := NewHandlerRegistry()
+	registry.On(EventTypeTaskStarted, func(event *CallbackEvent) error { return nil })
+	registry.On(EventTypeTaskFailed, func(event *CallbackEvent) error { return errors.New("downstream unavailable") })
+	server := NewServerWithRegistry("test-secret", registry, WithMetrics(true))
+
+	postTestEvent(t, server, EventTypeTaskStarted)
+	postTestEvent(t, server, EventTypeTaskStarted)
+	postTestEvent(t, server, EventTypeTaskFailed)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/webhook", strings.NewReader(`{}`))
+	req.Header.Set("X-TaskCenter-Signature", "sha256=bad")
+	req.Header.Set("X-TaskCenter-Timestamp", "1")
+	server.ServeHTTP(recorder, req)
+
+	body := scrapeMetrics(t, server)
+	for _, want := range []string{
+		`taskcenter_callback_requests_total{event_type="task.started",outcome="success"} 2`,
+		`taskcenter_callback_requests_total{event_type="task.failed",outcome="handler_error"} 1`,
+		`taskcenter_callback_requests_total{event_type="unknown",outcome="signature_failure"} 1`,
+		`taskcenter_callback_request_duration_seconds_count{event_type="task.started"} 2`,
+		`taskcenter_callback_signature_failures_total{code="VALIDATION_ERROR"} 1`,
+		`taskcenter_callback_requests_in_flight 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestServer_MetricsDisabled(t *testing.T) {
+	server := NewServerWithRegistry("test-secret", NewHandlerRegistry())
+	if server.Metrics() != nil {
+		t.Error("Metrics should be nil when EnableMetrics is off")
+	}
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected /metrics to be absent, got status %d", recorder.Code)
+	}
+
+	server = NewServerWithRegistry("test-secret", NewHandlerRegistry(), WithMetrics(true), WithMetricsPath(""))
+	if server.Metrics() == nil {
+		t.Error("Metrics should be collected without a route")
+	}
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected /metrics route to be disabled, got status %d", recorder.Code)
+	}
+}
+
+func TestDurationWindow_Bounded(t *testing.T) {
+	window := &durationWindow{}
+	for i := 1; i <= 250; i++ {
+		window.add(time.Duration(i) * time.Millisecond)
+	}
+
+	if window.count != durationWindowSize {
+		t.Errorf("Expected %d samples, got %d", durationWindowSize, window.count)
+	}
+	// 只保留最近 100 个记录：151ms..250ms
+	if want := time.Duration(151+250) * durationWindowSize / 2 * time.Millisecond; window.total != want {
+		t.Errorf("Expected total %v, got %v", want, window.total)
+	}
+}
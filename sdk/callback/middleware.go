@@ -236,7 +236,7 @@ func (m *LoggingMiddleware) After(w http.ResponseWriter, r *http.Request, event
 type MetricsMiddleware struct {
 	mu               sync.RWMutex
 	requestCount     map[string]int64
-	responseTime     map[string][]time.Duration
+	responseTime     map[string]*durationWindow
 	errorCount       map[string]int64
 	lastRequestTime  time.Time
 	requestInFlight  int64
@@ -251,7 +251,7 @@ type MetricsMiddleware struct {
 func NewMetricsMiddleware() *MetricsMiddleware {
 	return &MetricsMiddleware{
 		requestCount: make(map[string]int64),
-		responseTime: make(map[string][]time.Duration),
+		responseTime: make(map[string]*durationWindow),
 		errorCount:   make(map[string]int64),
 	}
 }
@@ -289,15 +289,12 @@ func (m *MetricsMiddleware) After(w http.ResponseWriter, r *http.Request, event
 	// 记录响应时间
 	if startTime, ok := r.Context().Value("metrics_start_time").(time.Time); ok {
 		duration := time.Since(startTime)
-		if m.responseTime[event.EventType] == nil {
-			m.responseTime[event.EventType] = make([]time.Duration, 0, 100)
-		}
-		m.responseTime[event.EventType] = append(m.responseTime[event.EventType], duration)
-
-		// 保持最近100个记录以计算平均值
-		if len(m.responseTime[event.EventType]) > 100 {
-			m.responseTime[event.EventType] = m.responseTime[event.EventType][1:]
+		window := m.responseTime[event.EventType]
+		if window == nil {
+			window = &durationWindow{}
+			m.responseTime[event.EventType] = window
 		}
+		window.add(duration)
 
 		if m.OnRequestComplete != nil {
 			m.OnRequestComplete(event.EventType, duration)
@@ -334,13 +331,9 @@ func (m *MetricsMiddleware) GetMetrics() map[string]interface{} {
 
 	// 计算平均响应时间
 	avgResponseTime := make(map[string]float64)
-	for eventType, durations := range m.responseTime {
-		if len(durations) > 0 {
-			var total time.Duration
-			for _, d := range durations {
-				total += d
-			}
-			avgResponseTime[eventType] = float64(total.Milliseconds()) / float64(len(durations))
+	for eventType, window := range m.responseTime {
+		if window.count > 0 {
+			avgResponseTime[eventType] = float64(window.total.Milliseconds()) / float64(window.count)
 		}
 	}
 	metrics["avg_response_time_ms"] = avgResponseTime
@@ -348,6 +341,29 @@ func (m *MetricsMiddleware) GetMetrics() map[string]interface{} {
 	return metrics
 }
 
+// durationWindowSize 计算平均响应时间使用的最近记录数
+const durationWindowSize = 100
+
+// durationWindow 固定大小的响应时间环形缓冲，内存占用不随请求数增长
+type durationWindow struct {
+	samples [durationWindowSize]time.Duration
+	next    int
+	count   int
+	total   time.Duration
+}
+
+// add 记录一次响应时间，缓冲已满时覆盖最早的记录
+func (w *durationWindow) add(d time.Duration) {
+	if w.count == durationWindowSize {
+		w.total -= w.samples[w.next]
+	} else {
+		w.count++
+	}
+	w.samples[w.next] = d
+	w.total += d
+	w.next = (w.next + 1) % durationWindowSize
+}
+
 // SecurityMiddleware 安全中间件
 type SecurityMiddleware struct {
 	AllowedIPs          []string
@@ -0,0 +1,107 @@
+package callback
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// EventHandlerFunc 单个事件类型的处理函数
+type EventHandlerFunc func(event *CallbackEvent) error
+
+// HandlerRegistry 按事件类型注册的处理器表
+// 任务中心新增的事件类型无需修改 Handler 接口，直接通过 On 注册即可
+type HandlerRegistry struct {
+	mu       sync.RWMutex
+	handlers map[string]EventHandlerFunc
+	fallback EventHandlerFunc
+}
+
+// NewHandlerRegistry 创建空的处理器表
+func NewHandlerRegistry() *HandlerRegistry {
+	return &HandlerRegistry{
+		handlers: make(map[string]EventHandlerFunc),
+	}
+}
+
+// RegistryFromHandler 将 Handler 接口适配为处理器表
+// 注册 task.created / task.started / task.completed / task.failed 四个事件
+func RegistryFromHandler(handler Handler) *HandlerRegistry {
+	registry := NewHandlerRegistry()
+	if handler == nil {
+		return registry
+	}
+
+	registry.On(EventTypeTaskCreated, handler.HandleTaskCreated)
+	registry.On(EventTypeTaskStarted, handler.HandleTaskStarted)
+	registry.On(EventTypeTaskCompleted, handler.HandleTaskCompleted)
+	registry.On(EventTypeTaskFailed, handler.HandleTaskFailed)
+	return registry
+}
+
+// On 注册事件处理函数，同一事件类型重复注册时后者覆盖前者
+// 事件类型不限于 AllEventTypes，注册后的自定义类型同样可以通过服务器的事件校验
+func (r *HandlerRegistry) On(eventType string, fn EventHandlerFunc) *HandlerRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fn == nil {
+		delete(r.handlers, eventType)
+	} else {
+		r.handlers[eventType] = fn
+	}
+	return r
+}
+
+// OnUnhandled 设置未注册事件类型的兜底处理函数
+// 未设置时，已知但未注册的事件直接确认，未知事件返回校验错误
+func (r *HandlerRegistry) OnUnhandled(fn EventHandlerFunc) *HandlerRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = fn
+	return r
+}
+
+// Handle 分发事件到对应的处理函数
+func (r *HandlerRegistry) Handle(event *CallbackEvent) error {
+	r.mu.RLock()
+	fn, ok := r.handlers[event.EventType]
+	fallback := r.fallback
+	r.mu.RUnlock()
+
+	switch {
+	case ok:
+		return fn(event)
+	case fallback != nil:
+		return fallback(event)
+	case IsValidEventType(event.EventType):
+		// 订阅了但没有关心的事件，确认收到即可，避免任务中心重复投递
+		return nil
+	default:
+		return NewValidationError(fmt.Sprintf("Unknown event type: %s", event.EventType))
+	}
+}
+
+// Known 判断事件类型是否可以被接收：预定义类型、已注册类型，或设置了兜底处理函数
+func (r *HandlerRegistry) Known(eventType string) bool {
+	if IsValidEventType(eventType) {
+		return true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.handlers[eventType]
+	return ok || r.fallback != nil
+}
+
+// EventTypes 返回已注册处理函数的事件类型，按字典序排列
+func (r *HandlerRegistry) EventTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.handlers))
+	for eventType := range r.handlers {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	return types
+}
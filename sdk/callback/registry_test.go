@@ -0,0 +1,140 @@
+package callback
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// postTestEvent 发送签名后的回调事件
+func postTestEvent(t *testing.T, server *Server, eventType string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	event := &CallbackEvent{
+		EventType:  eventType,
+		EventTime:  time.Now(),
+		TaskID:     123,
+		BusinessID: 456,
+		Task: Task{
+			ID:               123,
+			BusinessUniqueID: "test-task-123",
+			CallbackURL:      "http://example.com/callback",
+			Status:           TaskStatusPending,
+		},
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal("Failed to marshal event:", err)
+	}
+
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("X-TaskCenter-Signature", calculateTestSignature("test-secret", timestamp, body))
+	req.Header.Set("X-TaskCenter-Timestamp", timestamp)
+
+	recorder := httptest.NewRecorder()
+	server.handleWebhook(recorder, req)
+	return recorder
+}
+
+func TestRegistryFromHandler(t *testing.T) {
+	handler := &testHandler{}
+	registry := RegistryFromHandler(handler)
+
+	want := []string{EventTypeTaskCompleted, EventTypeTaskCreated, EventTypeTaskFailed, EventTypeTaskStarted}
+	got := registry.EventTypes()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+
+	if err := registry.Handle(&CallbackEvent{EventType: EventTypeTaskCompleted}); err != nil {
+		t.Fatal("Handle failed:", err)
+	}
+	if !handler.completedCalled {
+		t.Error("Handler HandleTaskCompleted not called")
+	}
+}
+
+func TestServer_LifecycleEvents(t *testing.T) {
+	handler := &testHandler{}
+	server := NewServer("test-secret", handler)
+
+	var got []string
+	for _, eventType := range []string{EventTypeTaskRetrying, EventTypeTaskCancelled, EventTypeTaskExpired} {
+		server.On(eventType, func(event *CallbackEvent) error {
+			got = append(got, event.EventType)
+			return nil
+		})
+	}
+
+	for _, eventType := range []string{EventTypeTaskRetrying, EventTypeTaskCancelled, EventTypeTaskExpired} {
+		if recorder := postTestEvent(t, server, eventType); recorder.Code != http.StatusOK {
+			t.Errorf("%s: expected status 200, got %d", eventType, recorder.Code)
+		}
+	}
+	if len(got) != 3 {
+		t.Errorf("Expected 3 lifecycle events handled, got %v", got)
+	}
+
+	// 已知但未注册处理函数的事件直接确认
+	if recorder := postTestEvent(t, server, EventTypeTaskDeadLettered); recorder.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for unhandled known event, got %d", recorder.Code)
+	}
+
+	// 原有的 Handler 仍然生效
+	if recorder := postTestEvent(t, server, EventTypeTaskStarted); recorder.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", recorder.Code)
+	}
+	if !handler.startedCalled {
+		t.Error("Handler HandleTaskStarted not called")
+	}
+}
+
+func TestServer_CustomEventType(t *testing.T) {
+	registry := NewHandlerRegistry()
+	server := NewServerWithRegistry("test-secret", registry)
+
+	// 未注册的自定义事件被拒绝
+	if recorder := postTestEvent(t, server, "billing.settled"); recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 before registration, got %d", recorder.Code)
+	}
+
+	called := false
+	registry.On("billing.settled", func(event *CallbackEvent) error {
+		called = true
+		return nil
+	})
+	if recorder := postTestEvent(t, server, "billing.settled"); recorder.Code != http.StatusOK {
+		t.Errorf("Expected status 200 after registration, got %d", recorder.Code)
+	}
+	if !called {
+		t.Error("Custom handler not called")
+	}
+}
+
+func TestHandlerRegistry_OnUnhandled(t *testing.T) {
+	var fallbackType string
+	registry := NewHandlerRegistry().OnUnhandled(func(event *CallbackEvent) error {
+		fallbackType = event.EventType
+		return nil
+	})
+
+	if !registry.Known("anything.happened") {
+		t.Error("Registry with fallback should accept any event type")
+	}
+	if err := registry.Handle(&CallbackEvent{EventType: "anything.happened"}); err != nil {
+		t.Fatal("Handle failed:", err)
+	}
+	if fallbackType != "anything.happened" {
+		t.Errorf("Expected fallback to receive 'anything.happened', got %q", fallbackType)
+	}
+}
@@ -3,25 +3,35 @@ package callback
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Version SDK版本信息
 const Version = "1.0.0"
 
+// tracerName 回调处理 span 使用的 tracer 名称
+const tracerName = "task-center/sdk/callback"
+
 // Server HTTP回调服务器
 type Server struct {
 	mu          sync.RWMutex
 	apiSecret   string
 	handler     Handler
+	registry    *HandlerRegistry
 	middlewares []Middleware
 	mux         *http.ServeMux
 	validator   *Validator
 	options     ServerOptions
+	metrics     *Metrics
 }
 
 // ServerOptions 服务器配置选项
@@ -29,6 +39,7 @@ type ServerOptions struct {
 	// 路由配置
 	WebhookPath string
 	HealthPath  string
+	MetricsPath string // EnableMetrics 开启时暴露 Prometheus 指标的路径，为空时不注册该路由
 
 	// 安全配置
 	EnableSignatureValidation bool
@@ -107,6 +118,13 @@ func WithMetrics(enabled bool) ServerOption {
 	}
 }
 
+// WithMetricsPath 设置指标路径，为空时只收集指标不注册路由，通过 Server.Metrics 注册到应用自己的 Registry
+func WithMetricsPath(path string) ServerOption {
+	return func(opts *ServerOptions) {
+		opts.MetricsPath = path
+	}
+}
+
 // WithLogging 启用日志记录
 func WithLogging(enabled bool) ServerOption {
 	return func(opts *ServerOptions) {
@@ -133,6 +151,7 @@ func defaultServerOptions() ServerOptions {
 	return ServerOptions{
 		WebhookPath:               "/webhook",
 		HealthPath:                "/health",
+		MetricsPath:               "/metrics",
 		EnableSignatureValidation: true,
 		TimestampToleranceSeconds: 300, // 5分钟
 		MaxRequestBodySize:        1024 * 1024, // 1MB
@@ -145,7 +164,19 @@ func defaultServerOptions() ServerOptions {
 }
 
 // NewServer 创建新的回调服务器
+// handler 处理四个基础事件，其他事件类型通过 On 注册
 func NewServer(apiSecret string, handler Handler, opts ...ServerOption) *Server {
+	server := NewServerWithRegistry(apiSecret, RegistryFromHandler(handler), opts...)
+	server.handler = handler
+	return server
+}
+
+// NewServerWithRegistry 使用处理器表创建回调服务器
+func NewServerWithRegistry(apiSecret string, registry *HandlerRegistry, opts ...ServerOption) *Server {
+	if registry == nil {
+		registry = NewHandlerRegistry()
+	}
+
 	options := defaultServerOptions()
 
 	// 应用配置选项
@@ -155,12 +186,15 @@ func NewServer(apiSecret string, handler Handler, opts ...ServerOption) *Server
 
 	server := &Server{
 		apiSecret:   apiSecret,
-		handler:     handler,
+		registry:    registry,
 		middlewares: []Middleware{},
 		mux:         http.NewServeMux(),
-		validator:   NewValidator(apiSecret),
+		validator:   NewValidator(apiSecret, WithKnownEventTypes(registry.Known)),
 		options:     options,
 	}
+	if options.EnableMetrics {
+		server.metrics = NewMetrics("")
+	}
 
 	// 注册路由
 	server.setupRoutes()
@@ -175,6 +209,22 @@ func (s *Server) AddMiddleware(middleware Middleware) {
 	s.middlewares = append(s.middlewares, middleware)
 }
 
+// On 注册指定事件类型的处理函数
+func (s *Server) On(eventType string, fn EventHandlerFunc) *Server {
+	s.registry.On(eventType, fn)
+	return s
+}
+
+// Metrics 获取服务器的 Prometheus 指标，未启用 EnableMetrics 时返回 nil
+func (s *Server) Metrics() *Metrics {
+	return s.metrics
+}
+
+// Registry 获取服务器使用的处理器表
+func (s *Server) Registry() *HandlerRegistry {
+	return s.registry
+}
+
 // setupRoutes 设置路由
 func (s *Server) setupRoutes() {
 	// 注册webhook处理器
@@ -184,6 +234,11 @@ func (s *Server) setupRoutes() {
 	if s.options.EnableHealthCheck {
 		s.mux.HandleFunc(s.options.HealthPath, s.handleHealth)
 	}
+
+	// 注册指标处理器
+	if s.metrics != nil && s.options.MetricsPath != "" {
+		s.mux.Handle(s.options.MetricsPath, s.metrics.Handler())
+	}
 }
 
 // ServeHTTP 实现 http.Handler 接口
@@ -198,12 +253,27 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.mux.ServeHTTP(w, r)
 }
 
-// handleWebhook 处理webhook回调
+// handleWebhook 处理webhook回调，启用指标时记录处理结果和耗时
 func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.metrics == nil {
+		s.serveWebhook(w, r)
+		return
+	}
+
+	s.metrics.inFlight.Inc()
+	defer s.metrics.inFlight.Dec()
+
+	start := time.Now()
+	eventType, outcome := s.serveWebhook(w, r)
+	s.metrics.observe(eventType, outcome, time.Since(start).Seconds())
+}
+
+// serveWebhook 处理webhook回调，返回事件类型（解析失败时为空）和处理结果
+func (s *Server) serveWebhook(w http.ResponseWriter, r *http.Request) (string, string) {
 	// 只接受POST请求
 	if r.Method != http.MethodPost {
 		s.handleError(w, r, NewMethodNotAllowedError("Only POST method is allowed"))
-		return
+		return "", OutcomeInvalidRequest
 	}
 
 	// 限制请求体大小
@@ -220,7 +290,7 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	for _, middleware := range middlewares {
 		if err := middleware.Before(w, r); err != nil {
 			s.handleError(w, r, err)
-			return
+			return "", OutcomeRejected
 		}
 	}
 
@@ -228,15 +298,18 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		s.handleError(w, r, NewValidationError("Failed to read request body: "+err.Error()))
-		return
+		return "", OutcomeInvalidRequest
 	}
 	defer r.Body.Close()
 
 	// 验证签名
 	if s.options.EnableSignatureValidation {
 		if err := s.validator.ValidateSignature(r, body); err != nil {
+			if s.metrics != nil {
+				s.metrics.signatureFailed(err)
+			}
 			s.handleError(w, r, err)
-			return
+			return "", OutcomeSignatureFailure
 		}
 	}
 
@@ -244,14 +317,21 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 	event, err := s.validator.ParseEvent(body)
 	if err != nil {
 		s.handleError(w, r, err)
-		return
+		return "", OutcomeInvalidRequest
 	}
 
+	// 从 traceparent 恢复任务中心的链路，处理函数通过 event.Context() 获取
+	ctx, span := startCallbackSpan(r, event)
+	defer span.End()
+	event.WithContext(ctx)
+
 	// 调用业务处理器
 	handlerErr := s.callEventHandler(event)
 	if handlerErr != nil {
+		span.RecordError(handlerErr)
+		span.SetStatus(codes.Error, handlerErr.Error())
 		s.handleError(w, r, handlerErr)
-		return
+		return event.EventType, OutcomeHandlerError
 	}
 
 	// 执行后置中间件
@@ -266,22 +346,24 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 
 	// 返回成功响应
 	s.sendSuccessResponse(w, event)
+	return event.EventType, OutcomeSuccess
+}
+
+// startCallbackSpan 以回调请求中的 W3C traceparent 为父 span 创建处理 span，请求不带 traceparent 时创建新链路
+func startCallbackSpan(r *http.Request, event *CallbackEvent) (context.Context, trace.Span) {
+	ctx := propagation.TraceContext{}.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	return otel.Tracer(tracerName).Start(ctx, "TaskCenter callback "+event.EventType,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("taskcenter.event_type", event.EventType),
+			attribute.Int64("taskcenter.task_id", event.TaskID),
+			attribute.Int64("taskcenter.business_id", event.BusinessID),
+		))
 }
 
 // callEventHandler 调用对应的事件处理器
 func (s *Server) callEventHandler(event *CallbackEvent) error {
-	switch event.EventType {
-	case "task.created":
-		return s.handler.HandleTaskCreated(event)
-	case "task.started":
-		return s.handler.HandleTaskStarted(event)
-	case "task.completed":
-		return s.handler.HandleTaskCompleted(event)
-	case "task.failed":
-		return s.handler.HandleTaskFailed(event)
-	default:
-		return NewValidationError(fmt.Sprintf("Unknown event type: %s", event.EventType))
-	}
+	return s.registry.Handle(event)
 }
 
 // handleHealth 健康检查处理
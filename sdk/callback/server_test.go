@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
 )
 
 // testHandler 测试用的回调处理器
@@ -555,4 +556,38 @@ func BenchmarkServer_HandleWebhook_NoValidation(b *testing.B) {
 		recorder := httptest.NewRecorder()
 		server.handleWebhook(recorder, req)
 	}
-}
\ No newline at end of file
+}
+
+func TestServer_HandleWebhook_TraceContext(t *testing.T) {
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	var got trace.SpanContext
+	registry := NewHandlerRegistry()
+	registry.On(EventTypeTaskCompleted, func(event *CallbackEvent) error {
+		got = trace.SpanContextFromContext(event.Context())
+		return nil
+	})
+	server := NewServerWithRegistry("test-secret", registry)
+
+	body, _ := json.Marshal(&CallbackEvent{
+		EventType:  EventTypeTaskCompleted,
+		EventTime:  time.Now(),
+		TaskID:     123,
+		BusinessID: 456,
+		Task:       Task{ID: 123, BusinessUniqueID: "test-task-123", CallbackURL: "http://example.com/callback", Status: TaskStatusSucceeded},
+	})
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+	req.Header.Set("X-TaskCenter-Signature", calculateTestSignature("test-secret", timestamp, body))
+	req.Header.Set("X-TaskCenter-Timestamp", timestamp)
+	req.Header.Set("traceparent", traceParent)
+
+	recorder := httptest.NewRecorder()
+	server.handleWebhook(recorder, req)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+	if got.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected handler context to continue the task trace, got %v", got.TraceID())
+	}
+}
@@ -0,0 +1,40 @@
+package callback
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"task-center/pkg/event"
+)
+
+func TestCallbackEvent_DecodesServerEvent(t *testing.T) {
+	at := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	body, err := json.Marshal(&event.Event{
+		EventType:  event.TypeTaskRetrying,
+		EventTime:  at,
+		TaskID:     42,
+		BusinessID: 7,
+		Task: event.Task{
+			ID:               42,
+			BusinessUniqueID: "order-1",
+			Status:           event.TaskStatusPending,
+			ScheduledAt:      at,
+		},
+		Signature: "sha256=abc",
+	})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var got CallbackEvent
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got.EventType != EventTypeTaskRetrying || !got.EventTime.Equal(at) || got.TaskID != 42 || got.BusinessID != 7 || got.Signature != "sha256=abc" {
+		t.Errorf("unexpected event: %+v", got)
+	}
+	if got.Task.BusinessUniqueID != "order-1" || got.Task.Status != TaskStatusPending || !got.Task.ScheduledAt.Equal(at) {
+		t.Errorf("unexpected task: %+v", got.Task)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"task-center/pkg/event"
 )
 
 // Validator 回调验证器
@@ -19,6 +20,7 @@ type Validator struct {
 	timestampTolerance int64
 	requiredHeaders   []string
 	customValidators  []CustomValidator
+	knownEventType    func(eventType string) bool
 }
 
 // CustomValidator 自定义验证器接口
@@ -50,12 +52,21 @@ func WithCustomValidator(validator CustomValidator) ValidatorOption {
 	}
 }
 
+// WithKnownEventTypes 设置事件类型校验函数，默认只接受 AllEventTypes
+// 回调服务器使用处理器表的 Known，使自定义注册的事件类型也能通过校验
+func WithKnownEventTypes(known func(eventType string) bool) ValidatorOption {
+	return func(v *Validator) {
+		v.knownEventType = known
+	}
+}
+
 // NewValidator 创建新的验证器
 func NewValidator(apiSecret string, opts ...ValidatorOption) *Validator {
 	validator := &Validator{
 		apiSecret:         apiSecret,
 		timestampTolerance: 300, // 默认5分钟容差
 		requiredHeaders:   []string{"X-TaskCenter-Signature", "X-TaskCenter-Timestamp"},
+		knownEventType:    IsValidEventType,
 	}
 
 	for _, opt := range opts {
@@ -173,17 +184,10 @@ func (v *Validator) validateEvent(event *CallbackEvent) error {
 		return NewValidationError("Missing event_type field")
 	}
 
-	validEventTypes := []string{
-		"task.created",
-		"task.started",
-		"task.completed",
-		"task.failed",
-	}
-
-	if !validatorContains(validEventTypes, event.EventType) {
+	if !v.knownEventType(event.EventType) {
 		return NewValidationError(fmt.Sprintf(
 			"Invalid event_type: %s. Valid types: %s",
-			event.EventType, strings.Join(validEventTypes, ", ")))
+			event.EventType, strings.Join(AllEventTypes, ", ")))
 	}
 
 	// 验证任务ID
@@ -418,25 +422,27 @@ func (v *BusinessValidator) Validate(r *http.Request, body []byte) error {
 
 // 工具函数和常量
 
-// 预定义的事件类型常量
+// 预定义的事件类型常量，与服务端推送的事件类型一致，见 pkg/event
 const (
-	EventTypeTaskCreated   = "task.created"
-	EventTypeTaskStarted   = "task.started"
-	EventTypeTaskCompleted = "task.completed"
-	EventTypeTaskFailed    = "task.failed"
+	EventTypeTaskCreated   = event.TypeTaskCreated
+	EventTypeTaskStarted   = event.TypeTaskStarted
+	EventTypeTaskCompleted = event.TypeTaskCompleted
+	EventTypeTaskFailed    = event.TypeTaskFailed
+
+	// 生命周期事件，需要在业务系统的事件订阅中显式选择
+	EventTypeTaskRetrying     = event.TypeTaskRetrying     // 失败或超时后重新进入待执行
+	EventTypeTaskCancelled    = event.TypeTaskCancelled    // 任务被取消
+	EventTypeTaskExpired      = event.TypeTaskExpired      // 任务超过有效期未执行
+	EventTypeTaskDeadLettered = event.TypeTaskDeadLettered // 重试耗尽进入死信
+	EventTypeTaskPaused       = event.TypeTaskPaused       // 任务被暂停
 )
 
 // AllEventTypes 所有支持的事件类型
-var AllEventTypes = []string{
-	EventTypeTaskCreated,
-	EventTypeTaskStarted,
-	EventTypeTaskCompleted,
-	EventTypeTaskFailed,
-}
+var AllEventTypes = event.AllTypes
 
 // IsValidEventType 检查是否为有效的事件类型
 func IsValidEventType(eventType string) bool {
-	return validatorContains(AllEventTypes, eventType)
+	return event.IsValidType(eventType)
 }
 
 // contains 检查切片是否包含指定元素
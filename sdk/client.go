@@ -3,32 +3,88 @@ package sdk
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+
+	"task-center/pkg/signing"
+	"task-center/sdk/auth"
+	"task-center/sdk/retry"
 )
 
+// HeaderIdempotencyKey 幂等键请求头，POST 等非幂等请求只有带该头时才会重试
+// 服务端在有效期内保存同一幂等键的原始响应，重复请求直接返回该响应
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+type idempotencyKeyCtxKey struct{}
+
+// WithIdempotencyKey 为使用该 ctx 发出的请求设置幂等键，服务端据此识别重复提交，客户端据此允许重试 POST 请求
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtxKey{}, key)
+}
+
+// IdempotencyKeyFromContext 返回 ctx 中的幂等键
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyCtxKey{}).(string)
+	return key
+}
+
+// newIdempotencyKey 生成随机幂等键，格式为 idem_ 加 128 位随机数的十六进制
+func newIdempotencyKey() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return "idem_" + hex.EncodeToString(buf)
+}
+
 // Client 是 TaskCenter 的 Go SDK 客户端
 type Client struct {
-	httpClient  *http.Client
-	baseURL     string
-	apiKey      string
-	businessID  int64
-	config      *Config
-	retryPolicy *RetryPolicy
+	httpClient   *http.Client
+	streamClient *http.Client // 长连接请求使用，不设置 Timeout
+	baseURL      string
+	apiKey       string
+	businessID   int64
+	config       *Config
+	retryPolicy  *RetryPolicy
+	retry        *retry.Policy
+
+	authenticator auth.Authenticator
+	signer        *signing.RequestSigner
+	refreshMu     sync.Mutex // 串行化 401 触发的凭证刷新，避免并发请求重复刷新
 }
 
 // Config 客户端配置选项
 type Config struct {
 	BaseURL     string        // TaskCenter 服务基础URL
-	APIKey      string        // API 密钥
+	APIKey      string        // API 密钥，未设置 Authenticator 时使用
 	BusinessID  int64         // 业务系统ID
 	Timeout     time.Duration // 请求超时时间
 	RetryPolicy *RetryPolicy  // 重试策略
 	UserAgent   string        // 用户代理字符串
+
+	// Authenticator 为每个请求添加认证信息，如 *auth.AuthManager
+	// 同时实现 auth.CredentialManager 时，收到 401 会刷新凭证并重试一次；实现 io.Closer 时随 Client.Close 关闭
+	Authenticator auth.Authenticator
+
+	// SigningSecret 业务系统的 api_secret，设置后每个请求都带 HMAC 签名、时间戳和 nonce，服务端使用 signing.SignatureVerifier 校验
+	SigningSecret string
+
+	// DisableIdempotencyKeys 关闭幂等键自动生成；默认每次写操作（POST、PUT、PATCH、DELETE）生成一个幂等键，
+	// 重试和 401 后的重发都使用同一个键。通过 WithIdempotencyKey 指定的键不受影响
+	DisableIdempotencyKeys bool
+
+	// Transport 自定义 HTTP 传输层，如配置代理或连接池的 *http.Transport，为空时使用 http.DefaultTransport
+	Transport http.RoundTripper
+
+	// Interceptors 按顺序执行的客户端拦截器，第一个在最外层；每次尝试都会经过整条拦截器链
+	Interceptors []Interceptor
 }
 
 // RetryPolicy 重试策略配置
@@ -38,6 +94,21 @@ type RetryPolicy struct {
 	MaxInterval     time.Duration // 最大重试间隔
 	Multiplier      float64       // 重试间隔倍数
 	RetryableErrors []int         // 可重试的HTTP状态码
+	MaxElapsedTime  time.Duration // 包括等待在内的最长重试时间，0 表示不限制
+	Jitter          bool          // 是否为重试间隔添加随机抖动
+}
+
+// toPolicy 转换为 retry.Policy，客户端的重试循环由 retry.Policy 执行
+func (p *RetryPolicy) toPolicy() *retry.Policy {
+	policy := retry.DefaultPolicy().
+		WithMaxAttempts(p.MaxRetries+1).
+		WithMaxElapsedTime(p.MaxElapsedTime).
+		WithDelay(p.InitialInterval, p.MaxInterval, p.Multiplier).
+		WithJitter(p.Jitter)
+	if p.RetryableErrors != nil {
+		policy.WithRetryableCodes(p.RetryableErrors...)
+	}
+	return policy
 }
 
 // DefaultConfig 返回默认配置
@@ -51,6 +122,8 @@ func DefaultConfig() *Config {
 			MaxInterval:     30 * time.Second,
 			Multiplier:      2.0,
 			RetryableErrors: []int{429, 500, 502, 503, 504},
+			MaxElapsedTime:  5 * time.Minute,
+			Jitter:          true,
 		},
 	}
 }
@@ -65,7 +138,7 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, fmt.Errorf("BaseURL is required")
 	}
 
-	if config.APIKey == "" {
+	if config.APIKey == "" && config.Authenticator == nil {
 		return nil, fmt.Errorf("APIKey is required")
 	}
 
@@ -78,15 +151,45 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, fmt.Errorf("invalid BaseURL: %w", err)
 	}
 
+	// 未设置的可选项使用默认值
+	defaults := DefaultConfig()
+	if config.RetryPolicy == nil {
+		config.RetryPolicy = defaults.RetryPolicy
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = defaults.UserAgent
+	}
+
+	authenticator := config.Authenticator
+	if authenticator == nil {
+		authenticator = auth.NewAPIKeyAuth(config.APIKey)
+	}
+
+	var signer *signing.RequestSigner
+	if config.SigningSecret != "" {
+		var err error
+		if signer, err = signing.NewRequestSigner(config.SigningSecret); err != nil {
+			return nil, err
+		}
+	}
+
 	client := &Client{
 		httpClient: &http.Client{
-			Timeout: config.Timeout,
+			Timeout:   config.Timeout,
+			Transport: config.Transport,
+		},
+		streamClient: &http.Client{
+			Transport: config.Transport,
 		},
 		baseURL:     config.BaseURL,
 		apiKey:      config.APIKey,
 		businessID:  config.BusinessID,
 		config:      config,
 		retryPolicy: config.RetryPolicy,
+		retry:       config.RetryPolicy.toPolicy(),
+
+		authenticator: authenticator,
+		signer:        signer,
 	}
 
 	return client, nil
@@ -114,6 +217,10 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 		reqBody = bytes.NewReader(jsonBody)
 	}
 
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	url := c.baseURL + path
 	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
@@ -122,59 +229,123 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 
 	// 设置请求头
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	req.Header.Set("User-Agent", c.config.UserAgent)
 	req.Header.Set("X-Business-ID", fmt.Sprintf("%d", c.businessID))
+	if key := c.idempotencyKey(ctx, method); key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	// 传递 ctx 中的链路（W3C traceparent），服务端将其保存到任务上，回调时转发
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if err := c.authenticator.Authenticate(req); err != nil {
+		return nil, NewAuthenticationError(fmt.Sprintf("failed to authenticate request: %v", err))
+	}
 
 	// 执行请求，包含重试逻辑
+	resp, err := c.executeWithRetry(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// 凭证可能已过期，刷新后重试一次；无法刷新时返回原始的 401 响应
+	rejected := req.Header.Get("Authorization")
+	if !c.refreshCredentials(ctx, rejected) {
+		return resp, nil
+	}
+	if err := c.authenticator.Authenticate(req); err != nil {
+		return resp, nil
+	}
+	resp.Body.Close()
 	return c.executeWithRetry(req)
 }
 
-// executeWithRetry 执行带重试的HTTP请求
-func (c *Client) executeWithRetry(req *http.Request) (*http.Response, error) {
-	var lastErr error
+// idempotencyKey 返回请求使用的幂等键：优先使用 ctx 中的键，写操作未指定时自动生成
+func (c *Client) idempotencyKey(ctx context.Context, method string) string {
+	if key := IdempotencyKeyFromContext(ctx); key != "" {
+		return key
+	}
+	if c.config.DisableIdempotencyKeys {
+		return ""
+	}
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return newIdempotencyKey()
+	}
+	return ""
+}
 
-	for attempt := 0; attempt <= c.retryPolicy.MaxRetries; attempt++ {
-		// 如果需要重试，等待一段时间
-		if attempt > 0 {
-			interval := c.calculateRetryInterval(attempt)
-			time.Sleep(interval)
-		}
+// refreshCredentials 刷新被服务端拒绝的凭证，返回是否可以使用新凭证重试
+// 其他请求已经刷新过时不再重复刷新，直接使用新凭证
+func (c *Client) refreshCredentials(ctx context.Context, rejected string) bool {
+	manager, ok := c.authenticator.(auth.CredentialManager)
+	if !ok || c.authenticator.GetType() == auth.AuthTypeAPIKey {
+		// API Key 是静态的，刷新后仍是同一个凭证
+		return false
+	}
 
-		// 克隆请求体（因为可能需要重试）
-		var bodyReader io.Reader
-		if req.Body != nil {
-			bodyBytes, err := io.ReadAll(req.Body)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read request body: %w", err)
-			}
-			req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-			bodyReader = bytes.NewReader(bodyBytes)
-		}
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
 
-		// 克隆请求
-		clonedReq := req.Clone(req.Context())
-		if bodyReader != nil {
-			clonedReq.Body = io.NopCloser(bodyReader)
-		}
+	probe := &http.Request{Header: make(http.Header)}
+	if err := c.authenticator.Authenticate(probe); err == nil && probe.Header.Get("Authorization") != rejected {
+		return true
+	}
+	return manager.Refresh(ctx) == nil
+}
 
-		resp, err := c.httpClient.Do(clonedReq)
+// executeWithRetry 执行带重试的HTTP请求，重试间隔和次数由 retry.Policy 决定
+// 等待期间响应 ctx 取消，429/503 按 Retry-After 等待；非幂等请求只有带幂等键时才重试
+func (c *Client) executeWithRetry(req *http.Request) (*http.Response, error) {
+	// 读取请求体，每次尝试使用新的副本
+	var bodyBytes []byte
+	if req.Body != nil {
+		var err error
+		bodyBytes, err = io.ReadAll(req.Body)
 		if err != nil {
-			lastErr = err
-			continue
+			return nil, fmt.Errorf("failed to read request body: %w", err)
 		}
+		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	}
 
-		// 检查是否需要重试
-		if c.shouldRetry(resp.StatusCode) && attempt < c.retryPolicy.MaxRetries {
-			resp.Body.Close()
-			lastErr = fmt.Errorf("HTTP %d: request failed", resp.StatusCode)
-			continue
+	policy := c.retry
+	if !isIdempotent(req) {
+		single := *c.retry
+		policy = single.WithMaxAttempts(1)
+	}
+
+	resp, err := policy.Do(req.Context(), func(ctx context.Context, attempt int) (*http.Response, error) {
+		clonedReq := req.Clone(ctx)
+		if req.Body != nil {
+			clonedReq.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 		}
 
-		return resp, nil
+		// 每次发送都重新签名，重试的请求使用新的时间戳和 nonce，不会被服务端当作重放
+		if c.signer != nil {
+			if err := c.signer.Sign(clonedReq, bodyBytes); err != nil {
+				return nil, err
+			}
+		}
+
+		return chainInterceptors(c.config.Interceptors, attempt, c.httpClient.Do)(clonedReq)
+	})
+	if err != nil && req.Context().Err() == nil {
+		return nil, fmt.Errorf("request failed after %d attempts: %w", policy.MaxAttempts, err)
+	}
+	if err == nil && policy.MaxAttempts > 1 && c.shouldRetry(resp.StatusCode) {
+		// 重试次数或时间预算耗尽，按最后一次响应返回对应的错误
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, ParseHTTPError(resp.StatusCode, body)
 	}
+	return resp, err
+}
 
-	return nil, fmt.Errorf("request failed after %d retries: %w", c.retryPolicy.MaxRetries, lastErr)
+// isIdempotent 判断请求能否安全重试：幂等方法，或带幂等键的请求
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(HeaderIdempotencyKey) != ""
 }
 
 // shouldRetry 检查是否应该重试请求
@@ -187,25 +358,63 @@ func (c *Client) shouldRetry(statusCode int) bool {
 	return false
 }
 
-// calculateRetryInterval 计算重试间隔
-func (c *Client) calculateRetryInterval(attempt int) time.Duration {
-	interval := time.Duration(float64(c.retryPolicy.InitialInterval) *
-		(c.retryPolicy.Multiplier * float64(attempt-1)))
+// DoRequest 公开的HTTP请求方法，供其他包使用
+func (c *Client) DoRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	return c.doRequest(ctx, method, path, body)
+}
 
-	if interval > c.retryPolicy.MaxInterval {
-		interval = c.retryPolicy.MaxInterval
+// DoStream 发送长连接的 GET 请求，如 SSE；不受 Config.Timeout 限制，由 ctx 控制连接的生命周期
+// 请求同样经过认证、签名和拦截器，收到 401 时刷新凭证后重发一次；不重试，断线重连由调用方负责
+func (c *Client) DoStream(ctx context.Context, path string, header http.Header) (*http.Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", c.config.UserAgent)
+	req.Header.Set("X-Business-ID", fmt.Sprintf("%d", c.businessID))
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if err := c.authenticator.Authenticate(req); err != nil {
+		return nil, NewAuthenticationError(fmt.Sprintf("failed to authenticate request: %v", err))
 	}
 
-	return interval
+	resp, err := c.sendStream(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	rejected := req.Header.Get("Authorization")
+	if !c.refreshCredentials(ctx, rejected) {
+		return resp, nil
+	}
+	if err := c.authenticator.Authenticate(req); err != nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+	return c.sendStream(req)
 }
 
-// DoRequest 公开的HTTP请求方法，供其他包使用
-func (c *Client) DoRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
-	return c.doRequest(ctx, method, path, body)
+// sendStream 签名并通过拦截器链发送一次长连接请求
+func (c *Client) sendStream(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if c.signer != nil {
+		if err := c.signer.Sign(req, nil); err != nil {
+			return nil, err
+		}
+	}
+	return chainInterceptors(c.config.Interceptors, 1, c.streamClient.Do)(req)
 }
 
-// Close 关闭客户端，清理资源
+// Close 关闭客户端，清理资源，Authenticator 实现 io.Closer 时一并关闭，如停止 AuthManager 的自动刷新
 func (c *Client) Close() error {
-	// 这里可以添加清理逻辑，比如关闭连接池等
+	c.httpClient.CloseIdleConnections()
+	c.streamClient.CloseIdleConnections()
+	if closer, ok := c.authenticator.(io.Closer); ok {
+		return closer.Close()
+	}
 	return nil
 }
\ No newline at end of file
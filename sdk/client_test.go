@@ -1,10 +1,17 @@
 package sdk
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"task-center/pkg/signing"
+	"task-center/sdk/auth"
 )
 
 func TestNewClient(t *testing.T) {
@@ -47,8 +54,8 @@ func TestNewClient(t *testing.T) {
 		{
 			name: "invalid BusinessID",
 			config: &Config{
-				BaseURL: "http://example.com",
-				APIKey:  "test-key",
+				BaseURL:    "http://example.com",
+				APIKey:     "test-key",
 				BusinessID: 0,
 			},
 			wantErr: true,
@@ -202,39 +209,6 @@ func TestClient_shouldRetry(t *testing.T) {
 	}
 }
 
-func TestClient_calculateRetryInterval(t *testing.T) {
-	config := DefaultConfig()
-	config.BaseURL = "http://example.com"
-	config.APIKey = "test-key"
-	config.BusinessID = 123
-
-	client, err := NewClient(config)
-	if err != nil {
-		t.Fatalf("Failed to create client: %v", err)
-	}
-	defer client.Close()
-
-	tests := []struct {
-		name    string
-		attempt int
-		want    time.Duration
-	}{
-		{"first retry", 1, 1 * time.Second},
-		{"second retry", 2, 2 * time.Second},
-		{"third retry", 3, 4 * time.Second},
-		{"max interval", 10, 30 * time.Second}, // 应该被限制在最大间隔
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := client.calculateRetryInterval(tt.attempt)
-			if got != tt.want {
-				t.Errorf("calculateRetryInterval() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestClient_doRequest(t *testing.T) {
 	// 创建测试服务器
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -358,4 +332,465 @@ func TestClient_doRequestMaxRetries(t *testing.T) {
 	if attempt != expectedAttempts {
 		t.Errorf("Expected %d attempts, got %d", expectedAttempts, attempt)
 	}
-}
\ No newline at end of file
+}
+
+// testTokenAuth 测试用的可刷新令牌认证器
+type testTokenAuth struct {
+	mu        sync.Mutex
+	token     string
+	refreshed int
+	closed    bool
+}
+
+func (a *testTokenAuth) Authenticate(req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+func (a *testTokenAuth) GetType() auth.AuthType { return auth.AuthTypeJWT }
+
+func (a *testTokenAuth) Refresh(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.refreshed++
+	a.token = "fresh-token"
+	return nil
+}
+
+func (a *testTokenAuth) IsValid() bool           { return true }
+func (a *testTokenAuth) GetCreatedAt() time.Time { return time.Time{} }
+func (a *testTokenAuth) GetUpdatedAt() time.Time { return time.Time{} }
+
+func (a *testTokenAuth) Close() error {
+	a.closed = true
+	return nil
+}
+
+func TestClient_AuthenticatorRefreshOn401(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")
+		seen = append(seen, token)
+		if token != "Bearer fresh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	authenticator := &testTokenAuth{token: "stale-token"}
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.BusinessID = 123
+	config.Authenticator = authenticator
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	resp, err := client.doRequest(context.Background(), "POST", "/test", map[string]string{"k": "v"})
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 after refresh, got %d", resp.StatusCode)
+	}
+	if authenticator.refreshed != 1 {
+		t.Errorf("Expected 1 refresh, got %d", authenticator.refreshed)
+	}
+	if len(seen) != 2 || seen[0] != "Bearer stale-token" || seen[1] != "Bearer fresh-token" {
+		t.Errorf("Unexpected Authorization headers: %v", seen)
+	}
+
+	client.Close()
+	if !authenticator.closed {
+		t.Error("Client.Close should close the authenticator")
+	}
+}
+
+func TestClient_AuthenticatorRetriesOnlyOnce(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	authenticator := &testTokenAuth{token: "stale-token"}
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.BusinessID = 123
+	config.Authenticator = authenticator
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	resp, err := client.doRequest(context.Background(), "GET", "/test", nil)
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", resp.StatusCode)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestClient_APIKeyNoRefreshOn401(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	manager, err := auth.NewAPIKeyAuthManager("test-api-key-0123456789")
+	if err != nil {
+		t.Fatalf("Failed to create auth manager: %v", err)
+	}
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.BusinessID = 123
+	config.Authenticator = manager
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	resp, err := client.doRequest(context.Background(), "GET", "/test", nil)
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if attempts != 1 {
+		t.Errorf("Expected API key to be sent once, got %d attempts", attempts)
+	}
+}
+
+func TestClient_SigningSecret(t *testing.T) {
+	verifier := signing.NewSignatureVerifier(func(r *http.Request) (string, error) {
+		return "business-secret", nil
+	})
+
+	attempt := 0
+	var nonces []string
+	server := httptest.NewServer(verifier.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		nonces = append(nonces, r.Header.Get(signing.HeaderNonce))
+		if attempt == 1 {
+			// 第一次返回服务器错误，重试的请求需要重新签名
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.APIKey = "test-key"
+	config.BusinessID = 123
+	config.SigningSecret = "business-secret"
+	config.RetryPolicy.InitialInterval = 10 * time.Millisecond
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := WithIdempotencyKey(context.Background(), "create-task-1")
+	resp, err := client.doRequest(ctx, "POST", "/api/v1/tasks?dry_run=true", map[string]int{"priority": 5})
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if len(nonces) != 2 || nonces[0] == nonces[1] {
+		t.Errorf("Expected a fresh nonce per attempt, got %v", nonces)
+	}
+}
+
+func TestClient_RetryAfter(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		if len(times) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.APIKey = "test-key"
+	config.BusinessID = 123
+	config.RetryPolicy.InitialInterval = 10 * time.Millisecond
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	resp, err := client.doRequest(context.Background(), "GET", "/test", nil)
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(times) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(times))
+	}
+	if wait := times[1].Sub(times[0]); wait < 900*time.Millisecond {
+		t.Errorf("Expected to wait for Retry-After, waited %v", wait)
+	}
+}
+
+func TestClient_RetryHonoursContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.APIKey = "test-key"
+	config.BusinessID = 123
+	config.RetryPolicy.InitialInterval = time.Minute
+	config.RetryPolicy.MaxInterval = time.Minute
+	config.RetryPolicy.MaxElapsedTime = 0
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.doRequest(ctx, "GET", "/test", nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Retry wait should stop when ctx is done, took %v", elapsed)
+	}
+}
+
+func TestClient_PostRetriesOnlyWithIdempotencyKey(t *testing.T) {
+	attempts := 0
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		keys = append(keys, r.Header.Get(HeaderIdempotencyKey))
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.APIKey = "test-key"
+	config.BusinessID = 123
+	config.RetryPolicy.InitialInterval = time.Millisecond
+	config.RetryPolicy.MaxRetries = 2
+	config.DisableIdempotencyKeys = true
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	resp, err := client.doRequest(context.Background(), "POST", "/api/v1/tasks", map[string]int{"priority": 5})
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+	if attempts != 1 {
+		t.Errorf("POST without idempotency key should not be retried, got %d attempts", attempts)
+	}
+
+	attempts = 0
+	keys = nil
+	ctx := WithIdempotencyKey(context.Background(), "create-task-1")
+	if _, err := client.doRequest(ctx, "POST", "/api/v1/tasks", map[string]int{"priority": 5}); err == nil {
+		t.Error("Expected error after retries are exhausted")
+	}
+	if attempts != 3 {
+		t.Errorf("POST with idempotency key should be retried, got %d attempts", attempts)
+	}
+	for _, key := range keys {
+		if key != "create-task-1" {
+			t.Errorf("Expected idempotency key on every attempt, got %v", keys)
+			break
+		}
+	}
+}
+
+func TestClient_RetriesIdempotencyInProgress(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			// 同一幂等键的原请求仍在处理
+			w.Header().Set("Retry-After", "1")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"success":false,"code":"IDEMPOTENCY_IN_PROGRESS","message":"still being processed"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.APIKey = "test-key"
+	config.BusinessID = 123
+	config.RetryPolicy.InitialInterval = time.Millisecond
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	resp, err := client.doRequest(context.Background(), "POST", "/api/v1/tasks", map[string]int{"priority": 5})
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+	if attempts != 2 || resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected the in-progress response to be retried, got %d attempts and status %d", attempts, resp.StatusCode)
+	}
+}
+
+func TestClient_AutoIdempotencyKey(t *testing.T) {
+	attempts := map[string]int{}
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(HeaderIdempotencyKey)
+		attempts[r.Method]++
+		if r.Method == "GET" {
+			if key != "" {
+				t.Errorf("GET should not carry an idempotency key, got %q", key)
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		keys = append(keys, key)
+		if attempts[r.Method] == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.APIKey = "test-key"
+	config.BusinessID = 123
+	config.RetryPolicy.InitialInterval = time.Millisecond
+	config.RetryPolicy.MaxRetries = 2
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	resp, err := client.doRequest(context.Background(), "POST", "/api/v1/tasks", map[string]int{"priority": 5})
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+	if attempts["POST"] != 2 {
+		t.Fatalf("POST with generated idempotency key should be retried, got %d attempts", attempts["POST"])
+	}
+	if keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("Expected the same generated key on every attempt, got %v", keys)
+	}
+
+	resp, err = client.doRequest(context.Background(), "POST", "/api/v1/tasks/1/cancel", nil)
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+	if keys[2] == keys[0] {
+		t.Error("Each logical call should generate a new idempotency key")
+	}
+
+	resp, err = client.doRequest(context.Background(), "GET", "/api/v1/tasks/1", nil)
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClient_PropagatesTraceContext(t *testing.T) {
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithDefaults(server.URL, "test-key", 123)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	resp, err := client.doRequest(ctx, "POST", "/api/v1/tasks", map[string]int{"priority": 5})
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+	if traceParent != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("Expected traceparent to be propagated, got %q", traceParent)
+	}
+
+	resp, err = client.doRequest(context.Background(), "GET", "/api/v1/tasks/1", nil)
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+	if traceParent != "" {
+		t.Errorf("Expected no traceparent without a span, got %q", traceParent)
+	}
+}
@@ -54,6 +54,9 @@ const (
 	CodeNetworkError        = "NETWORK_ERROR"
 	CodeTimeoutError        = "TIMEOUT_ERROR"
 	CodeUnknownError        = "UNKNOWN_ERROR"
+
+	// CodeIdempotencyInProgress 同一幂等键的原请求仍在处理，服务端返回 503 和 Retry-After，客户端会自动等待重试
+	CodeIdempotencyInProgress = "IDEMPOTENCY_IN_PROGRESS"
 )
 
 // NewValidationError 创建验证错误
@@ -111,6 +114,16 @@ func NewConflictError(message string) Error {
 	}
 }
 
+// NewConflictErrorWithDetails 创建带详情的冲突错误
+func NewConflictErrorWithDetails(message string, details interface{}) Error {
+	return &BaseError{
+		message:    message,
+		code:       CodeConflictError,
+		statusCode: http.StatusConflict,
+		details:    details,
+	}
+}
+
 // NewRateLimitError 创建速率限制错误
 func NewRateLimitError(message string) Error {
 	return &BaseError{
@@ -243,6 +256,40 @@ func IsConflictError(err error) bool {
 	return false
 }
 
+// IsIdempotencyInProgressError 检查是否为同一幂等键的原请求仍在处理，自动重试耗尽后返回该错误
+// 原请求的结果未知，稍后使用同一幂等键重试即可得到原请求的响应
+func IsIdempotencyInProgressError(err error) bool {
+	if sdkErr, ok := err.(Error); ok {
+		return sdkErr.Code() == CodeIdempotencyInProgress
+	}
+	return false
+}
+
+// ConflictingTask 从冲突错误的详情中取出已存在的任务
+// 创建任务因 business_unique_id 冲突被拒绝时，服务端会在 details 中返回已存在的任务；
+// 更新任务时版本已过期或状态变更不被允许，details 中是任务的当前状态
+func ConflictingTask(err error) (*Task, bool) {
+	sdkErr, ok := err.(Error)
+	if !ok || sdkErr.Code() != CodeConflictError || sdkErr.Details() == nil {
+		return nil, false
+	}
+
+	if task, ok := sdkErr.Details().(*Task); ok {
+		return task, true
+	}
+
+	// 从HTTP响应解析的详情是通用的 map 结构，重新编解码为 Task
+	data, err := json.Marshal(sdkErr.Details())
+	if err != nil {
+		return nil, false
+	}
+	var task Task
+	if err := json.Unmarshal(data, &task); err != nil || task.ID == 0 {
+		return nil, false
+	}
+	return &task, true
+}
+
 // IsRateLimitError 检查是否为速率限制错误
 func IsRateLimitError(err error) bool {
 	if sdkErr, ok := err.(Error); ok {
@@ -279,7 +326,7 @@ func IsTimeoutError(err error) bool {
 func IsRetryableError(err error) bool {
 	if sdkErr, ok := err.(Error); ok {
 		switch sdkErr.Code() {
-		case CodeRateLimitError, CodeServerError, CodeNetworkError, CodeTimeoutError:
+		case CodeRateLimitError, CodeServerError, CodeNetworkError, CodeTimeoutError, CodeIdempotencyInProgress:
 			return true
 		}
 	}
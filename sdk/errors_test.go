@@ -108,6 +108,38 @@ func TestNewValidationErrorWithDetails(t *testing.T) {
 	}
 }
 
+func TestConflictingTask(t *testing.T) {
+	// 直接构造的冲突错误
+	existing := &Task{ID: 42, BusinessUniqueID: "order-1", Status: TaskStatusRunning}
+	task, ok := ConflictingTask(NewConflictErrorWithDetails("task already exists", existing))
+	if !ok || task.ID != 42 {
+		t.Errorf("Expected existing task 42, got %v (ok=%v)", task, ok)
+	}
+
+	// 从HTTP响应解析的冲突错误
+	body, _ := json.Marshal(ErrorResponse{
+		Success: false,
+		Message: "task already exists",
+		Code:    CodeConflictError,
+		Details: existing,
+	})
+	task, ok = ConflictingTask(ParseHTTPError(http.StatusConflict, body))
+	if !ok {
+		t.Fatal("Expected existing task in parsed conflict error")
+	}
+	if task.ID != 42 || task.BusinessUniqueID != "order-1" || task.Status != TaskStatusRunning {
+		t.Errorf("Unexpected existing task: %+v", task)
+	}
+
+	// 没有详情或不是冲突错误
+	if _, ok := ConflictingTask(NewConflictError("conflict")); ok {
+		t.Error("Expected no task for conflict error without details")
+	}
+	if _, ok := ConflictingTask(NewValidationErrorWithDetails("invalid", existing)); ok {
+		t.Error("Expected no task for non-conflict error")
+	}
+}
+
 func TestParseHTTPError(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -309,6 +341,20 @@ func TestErrorTypeCheckers(t *testing.T) {
 				"IsRetryableError": true,
 			},
 		},
+		{
+			name:  "idempotency in progress",
+			error: ParseHTTPError(http.StatusServiceUnavailable, []byte(`{"code":"IDEMPOTENCY_IN_PROGRESS","message":"still being processed"}`)),
+			checkers: map[string]func(error) bool{
+				"IsIdempotencyInProgressError": IsIdempotencyInProgressError,
+				"IsConflictError":              IsConflictError,
+				"IsRetryableError":             IsRetryableError,
+			},
+			expected: map[string]bool{
+				"IsIdempotencyInProgressError": true,
+				"IsConflictError":              false,
+				"IsRetryableError":             true,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,243 @@
+package sdk
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HeaderRequestID 请求ID请求头，用于在客户端和服务端日志之间关联同一个请求
+const HeaderRequestID = "X-Request-ID"
+
+// RoundTripFunc 发送一次请求，拦截器通过它调用链上的下一个拦截器或最终的 Transport
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Interceptor 客户端拦截器，每次尝试（包括重试和 401 后的重发）都会调用，attempt 从 1 开始
+// 拦截器可以修改请求、检查 next 返回的响应和错误，或者不调用 next 直接返回
+type Interceptor interface {
+	Intercept(req *http.Request, attempt int, next RoundTripFunc) (*http.Response, error)
+}
+
+// InterceptorFunc 将函数适配为 Interceptor
+type InterceptorFunc func(req *http.Request, attempt int, next RoundTripFunc) (*http.Response, error)
+
+// Intercept 调用 f
+func (f InterceptorFunc) Intercept(req *http.Request, attempt int, next RoundTripFunc) (*http.Response, error) {
+	return f(req, attempt, next)
+}
+
+// chainInterceptors 按顺序组合拦截器，第一个拦截器在最外层
+func chainInterceptors(interceptors []Interceptor, attempt int, final RoundTripFunc) RoundTripFunc {
+	next := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := interceptors[i], next
+		next = func(req *http.Request) (*http.Response, error) {
+			return interceptor.Intercept(req, attempt, inner)
+		}
+	}
+	return next
+}
+
+type requestIDCtxKey struct{}
+
+// WithRequestID 为使用该 ctx 发出的请求指定请求ID，通常是服务端收到的上游请求ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, id)
+}
+
+// RequestIDFromContext 返回 ctx 中的请求ID
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+// RequestIDInterceptor 为请求设置 X-Request-ID：已有该头时保留，否则使用 ctx 中的请求ID，都没有时生成一个
+func RequestIDInterceptor() Interceptor {
+	return InterceptorFunc(func(req *http.Request, attempt int, next RoundTripFunc) (*http.Response, error) {
+		if req.Header.Get(HeaderRequestID) == "" {
+			id := RequestIDFromContext(req.Context())
+			if id == "" {
+				id = newRequestID()
+			}
+			req.Header.Set(HeaderRequestID, id)
+		}
+		return next(req)
+	})
+}
+
+// newRequestID 生成 128 位随机请求ID
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// LogFunc 结构化日志函数，与 LoggingMiddleware.Logger 的签名一致
+type LogFunc func(level string, message string, fields map[string]interface{})
+
+// DefaultRedactHeaders 日志中默认隐藏值的请求头
+var DefaultRedactHeaders = []string{"Authorization", "Cookie", "X-API-Key", "X-TaskCenter-Signature", "X-TaskCenter-Nonce"}
+
+// LoggingInterceptor 记录每次尝试的结构化日志，请求头中的凭证按 RedactHeaders 隐藏
+type LoggingInterceptor struct {
+	Logger        LogFunc
+	LogHeaders    bool     // 是否记录请求头
+	RedactHeaders []string // 记录请求头时隐藏值的请求头，不区分大小写
+}
+
+// NewLoggingInterceptor 创建日志拦截器，默认不记录请求头，记录时隐藏 DefaultRedactHeaders
+func NewLoggingInterceptor(logger LogFunc) *LoggingInterceptor {
+	return &LoggingInterceptor{
+		Logger:        logger,
+		RedactHeaders: DefaultRedactHeaders,
+	}
+}
+
+// Intercept 请求完成后记录方法、地址、尝试次数、状态码和耗时；失败或 5xx 时使用 error 级别
+func (l *LoggingInterceptor) Intercept(req *http.Request, attempt int, next RoundTripFunc) (*http.Response, error) {
+	start := time.Now()
+	resp, err := next(req)
+	if l.Logger == nil {
+		return resp, err
+	}
+
+	fields := map[string]interface{}{
+		"method":      req.Method,
+		"url":         req.URL.String(),
+		"attempt":     attempt,
+		"duration_ms": time.Since(start).Milliseconds(),
+	}
+	if id := req.Header.Get(HeaderRequestID); id != "" {
+		fields["request_id"] = id
+	}
+	if l.LogHeaders {
+		fields["headers"] = l.redact(req.Header)
+	}
+
+	level, message := "info", "TaskCenter request completed"
+	switch {
+	case err != nil:
+		level, message = "error", "TaskCenter request failed"
+		fields["error"] = err.Error()
+	case resp.StatusCode >= http.StatusInternalServerError:
+		level = "error"
+		fields["status"] = resp.StatusCode
+	case resp.StatusCode >= http.StatusBadRequest:
+		level = "warn"
+		fields["status"] = resp.StatusCode
+	default:
+		fields["status"] = resp.StatusCode
+	}
+	l.Logger(level, message, fields)
+	return resp, err
+}
+
+// redact 复制请求头并隐藏敏感值
+func (l *LoggingInterceptor) redact(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for key, values := range header {
+		value := strings.Join(values, ", ")
+		for _, name := range l.RedactHeaders {
+			if strings.EqualFold(key, name) {
+				value = "[REDACTED]"
+				break
+			}
+		}
+		headers[key] = value
+	}
+	return headers
+}
+
+// RequestTiming 一次尝试的各阶段耗时，复用连接时 DNS、Connect、TLSHandshake 为 0
+type RequestTiming struct {
+	DNS          time.Duration // DNS 解析
+	Connect      time.Duration // 建立 TCP 连接
+	TLSHandshake time.Duration // TLS 握手
+	FirstByte    time.Duration // 请求写完到收到响应首字节
+	Total        time.Duration // 开始到收到响应头
+	ReusedConn   bool          // 是否复用了空闲连接
+}
+
+// TimingInterceptor 使用 httptrace 统计每次尝试的各阶段耗时，请求结束后调用 report
+func TimingInterceptor(report func(req *http.Request, attempt int, timing *RequestTiming)) Interceptor {
+	return InterceptorFunc(func(req *http.Request, attempt int, next RoundTripFunc) (*http.Response, error) {
+		var (
+			mu                                          sync.Mutex
+			timing                                      RequestTiming
+			dnsStart, connectStart, tlsStart, wroteDone time.Time
+		)
+		start := time.Now()
+		since := func(t time.Time) time.Duration {
+			if t.IsZero() {
+				return 0
+			}
+			return time.Since(t)
+		}
+
+		trace := &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				mu.Lock()
+				timing.ReusedConn = info.Reused
+				mu.Unlock()
+			},
+			DNSStart: func(httptrace.DNSStartInfo) {
+				mu.Lock()
+				dnsStart = time.Now()
+				mu.Unlock()
+			},
+			DNSDone: func(httptrace.DNSDoneInfo) {
+				mu.Lock()
+				timing.DNS = since(dnsStart)
+				mu.Unlock()
+			},
+			ConnectStart: func(string, string) {
+				mu.Lock()
+				connectStart = time.Now()
+				mu.Unlock()
+			},
+			ConnectDone: func(string, string, error) {
+				mu.Lock()
+				timing.Connect = since(connectStart)
+				mu.Unlock()
+			},
+			TLSHandshakeStart: func() {
+				mu.Lock()
+				tlsStart = time.Now()
+				mu.Unlock()
+			},
+			TLSHandshakeDone: func(tls.ConnectionState, error) {
+				mu.Lock()
+				timing.TLSHandshake = since(tlsStart)
+				mu.Unlock()
+			},
+			WroteRequest: func(httptrace.WroteRequestInfo) {
+				mu.Lock()
+				wroteDone = time.Now()
+				mu.Unlock()
+			},
+			GotFirstResponseByte: func() {
+				mu.Lock()
+				timing.FirstByte = since(wroteDone)
+				mu.Unlock()
+			},
+		}
+
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+		resp, err := next(req)
+
+		mu.Lock()
+		timing.Total = time.Since(start)
+		result := timing
+		mu.Unlock()
+		if report != nil {
+			report(req, attempt, &result)
+		}
+		return resp, err
+	})
+}
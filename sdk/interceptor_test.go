@@ -0,0 +1,203 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newInterceptorTestClient 创建使用指定拦截器的测试客户端
+func newInterceptorTestClient(t *testing.T, serverURL string, interceptors ...Interceptor) *Client {
+	t.Helper()
+
+	config := DefaultConfig()
+	config.BaseURL = serverURL
+	config.APIKey = "test-api-key-0123456789"
+	config.BusinessID = 123
+	config.RetryPolicy.InitialInterval = time.Millisecond
+	config.RetryPolicy.MaxRetries = 2
+	config.Interceptors = interceptors
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// roundTripperFunc 将函数适配为 http.RoundTripper
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClient_InterceptorChain(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var calls []string
+	record := func(name string) Interceptor {
+		return InterceptorFunc(func(req *http.Request, attempt int, next RoundTripFunc) (*http.Response, error) {
+			calls = append(calls, name+" before")
+			resp, err := next(req)
+			status := 0
+			if resp != nil {
+				status = resp.StatusCode
+			}
+			calls = append(calls, fmt.Sprintf("%s after %d #%d", name, status, attempt))
+			return resp, err
+		})
+	}
+
+	client := newInterceptorTestClient(t, server.URL, record("outer"), record("inner"))
+	resp, err := client.doRequest(context.Background(), "GET", "/api/v1/tasks/1", nil)
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+
+	want := []string{
+		"outer before", "inner before", "inner after 503 #1", "outer after 503 #1",
+		"outer before", "inner before", "inner after 200 #2", "outer after 200 #2",
+	}
+	if strings.Join(calls, "|") != strings.Join(want, "|") {
+		t.Errorf("Unexpected interceptor calls:\n got %v\nwant %v", calls, want)
+	}
+}
+
+func TestClient_CustomTransport(t *testing.T) {
+	used := false
+	config := DefaultConfig()
+	config.BaseURL = "http://taskcenter.invalid"
+	config.APIKey = "test-api-key-0123456789"
+	config.BusinessID = 123
+	config.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		used = true
+		rec := httptest.NewRecorder()
+		rec.WriteHeader(http.StatusOK)
+		return rec.Result(), nil
+	})
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	resp, err := client.doRequest(context.Background(), "GET", "/health", nil)
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+	if !used {
+		t.Error("Expected custom transport to be used")
+	}
+}
+
+func TestRequestIDInterceptor(t *testing.T) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(HeaderRequestID))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := newInterceptorTestClient(t, server.URL, RequestIDInterceptor())
+
+	ctx := WithRequestID(context.Background(), "upstream-request-1")
+	resp, err := client.doRequest(ctx, "GET", "/api/v1/tasks/1", nil)
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = client.doRequest(context.Background(), "GET", "/api/v1/tasks/1", nil)
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if ids[0] != "upstream-request-1" {
+		t.Errorf("Expected request ID from context, got %q", ids[0])
+	}
+	if len(ids[1]) != 32 {
+		t.Errorf("Expected generated request ID, got %q", ids[1])
+	}
+}
+
+func TestLoggingInterceptor_RedactsHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var level string
+	var fields map[string]interface{}
+	logging := NewLoggingInterceptor(func(l, message string, f map[string]interface{}) {
+		level, fields = l, f
+	})
+	logging.LogHeaders = true
+
+	client := newInterceptorTestClient(t, server.URL, logging)
+	resp, err := client.doRequest(context.Background(), "GET", "/api/v1/tasks/1", nil)
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if level != "warn" || fields["status"] != http.StatusNotFound || fields["attempt"] != 1 {
+		t.Errorf("Unexpected log entry: level=%s fields=%v", level, fields)
+	}
+	headers := fields["headers"].(map[string]string)
+	if headers["Authorization"] != "[REDACTED]" {
+		t.Errorf("Authorization should be redacted, got %q", headers["Authorization"])
+	}
+	if headers["X-Business-Id"] != "123" {
+		t.Errorf("Non-sensitive headers should be logged, got %v", headers)
+	}
+}
+
+func TestTimingInterceptor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var timings []*RequestTiming
+	client := newInterceptorTestClient(t, server.URL, TimingInterceptor(func(req *http.Request, attempt int, timing *RequestTiming) {
+		timings = append(timings, timing)
+	}))
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.doRequest(context.Background(), "GET", "/api/v1/tasks/1", nil)
+		if err != nil {
+			t.Fatalf("doRequest() failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if len(timings) != 2 {
+		t.Fatalf("Expected 2 timings, got %d", len(timings))
+	}
+	if timings[0].FirstByte < 5*time.Millisecond || timings[0].Total < timings[0].FirstByte {
+		t.Errorf("Unexpected timing: %+v", timings[0])
+	}
+	if timings[0].ReusedConn || !timings[1].ReusedConn {
+		t.Errorf("Expected second request to reuse the connection: %+v, %+v", timings[0], timings[1])
+	}
+}
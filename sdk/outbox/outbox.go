@@ -0,0 +1,271 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"task-center/sdk"
+)
+
+// Dialect 数据库方言
+type Dialect string
+
+const (
+	DialectMySQL  Dialect = "mysql"  // MySQL 5.7+
+	DialectSQLite Dialect = "sqlite" // SQLite 3，主要用于本地测试
+)
+
+// DefaultTableName 默认发件箱表名
+const DefaultTableName = "task_outbox"
+
+// 发件箱记录状态
+const (
+	StatusPending   = 0 // 待投递
+	StatusDelivered = 1 // 已投递
+	StatusFailed    = 2 // 投递失败（不可重试）
+)
+
+// tableNamePattern 表名只允许字母、数字和下划线，防止SQL注入
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Config 发件箱配置
+type Config struct {
+	Dialect   Dialect // 数据库方言
+	TableName string  // 发件箱表名
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Dialect:   DialectMySQL,
+		TableName: DefaultTableName,
+	}
+}
+
+// Message 发件箱中的一条待投递任务
+type Message struct {
+	ID               int64
+	BusinessUniqueID string
+	Request          *sdk.CreateTaskRequest
+	Status           int
+	Attempts         int
+	TaskID           int64
+	LastError        string
+}
+
+// Outbox 事务性发件箱，将创建任务请求与业务数据写入同一个事务
+type Outbox struct {
+	dialect Dialect
+	table   string
+}
+
+// New 创建发件箱
+func New(config *Config) (*Outbox, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	dialect := config.Dialect
+	if dialect == "" {
+		dialect = DialectMySQL
+	}
+	if dialect != DialectMySQL && dialect != DialectSQLite {
+		return nil, fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	table := config.TableName
+	if table == "" {
+		table = DefaultTableName
+	}
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name: %s", table)
+	}
+
+	return &Outbox{
+		dialect: dialect,
+		table:   table,
+	}, nil
+}
+
+// Dialect 返回数据库方言
+func (o *Outbox) Dialect() Dialect {
+	return o.dialect
+}
+
+// TableName 返回发件箱表名
+func (o *Outbox) TableName() string {
+	return o.table
+}
+
+// SchemaSQL 返回创建发件箱表的DDL语句
+func (o *Outbox) SchemaSQL() []string {
+	if o.dialect == DialectSQLite {
+		return []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  business_unique_id TEXT NOT NULL,
+  payload TEXT NOT NULL,
+  status INTEGER NOT NULL DEFAULT 0,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  task_id INTEGER DEFAULT NULL,
+  last_error TEXT,
+  next_attempt_at DATETIME NOT NULL,
+  locked_by TEXT DEFAULT NULL,
+  locked_until DATETIME DEFAULT NULL,
+  created_at DATETIME NOT NULL,
+  delivered_at DATETIME DEFAULT NULL
+)`, o.table),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_status_next ON %s (status, next_attempt_at)", o.table, o.table),
+		}
+	}
+
+	return []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS `%s` (\n"+
+			"  id bigint(20) NOT NULL AUTO_INCREMENT,\n"+
+			"  business_unique_id varchar(128) NOT NULL,\n"+
+			"  payload text NOT NULL,\n"+
+			"  status tinyint(4) NOT NULL DEFAULT 0,\n"+
+			"  attempts int(11) NOT NULL DEFAULT 0,\n"+
+			"  task_id bigint(20) DEFAULT NULL,\n"+
+			"  last_error text,\n"+
+			"  next_attempt_at datetime NOT NULL,\n"+
+			"  locked_by varchar(64) DEFAULT NULL,\n"+
+			"  locked_until datetime DEFAULT NULL,\n"+
+			"  created_at datetime NOT NULL,\n"+
+			"  delivered_at datetime DEFAULT NULL,\n"+
+			"  PRIMARY KEY (id),\n"+
+			"  KEY idx_status_next_attempt_at (status, next_attempt_at)\n"+
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci", o.table),
+	}
+}
+
+// CreateTable 在业务库中创建发件箱表（幂等）
+func (o *Outbox) CreateTable(ctx context.Context, db *sql.DB) error {
+	for _, stmt := range o.SchemaSQL() {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create outbox table: %w", err)
+		}
+	}
+	return nil
+}
+
+// Enqueue 在调用方的事务中写入一条创建任务请求
+// 只有事务提交后，Relay 才会看到这条记录并投递到 TaskCenter
+func (o *Outbox) Enqueue(ctx context.Context, tx *sql.Tx, req *sdk.CreateTaskRequest) (int64, error) {
+	if tx == nil {
+		return 0, sdk.NewValidationError("transaction cannot be nil")
+	}
+	if req == nil {
+		return 0, sdk.NewValidationError("create request cannot be nil")
+	}
+	if err := req.Validate(); err != nil {
+		return 0, err
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal create request: %w", err)
+	}
+
+	now := dbTime(time.Now())
+	query := fmt.Sprintf("insert into %s (business_unique_id, payload, status, attempts, next_attempt_at, created_at) values (?, ?, ?, ?, ?, ?)", o.quotedTable())
+	result, err := tx.ExecContext(ctx, query, req.BusinessUniqueID, string(payload), StatusPending, 0, now, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert outbox message: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get outbox message id: %w", err)
+	}
+
+	return id, nil
+}
+
+// EnqueueBatch 在同一事务中写入多条创建任务请求
+func (o *Outbox) EnqueueBatch(ctx context.Context, tx *sql.Tx, reqs []*sdk.CreateTaskRequest) ([]int64, error) {
+	ids := make([]int64, 0, len(reqs))
+	for i, req := range reqs {
+		id, err := o.Enqueue(ctx, tx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to enqueue request at index %d: %w", i, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Get 根据ID获取发件箱记录
+func (o *Outbox) Get(ctx context.Context, db *sql.DB, id int64) (*Message, error) {
+	query := fmt.Sprintf("select id, business_unique_id, payload, status, attempts, task_id, last_error from %s where id = ?", o.quotedTable())
+	msg, err := scanMessage(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, sdk.NewNotFoundError("outbox message")
+	}
+	return msg, err
+}
+
+// PendingCount 返回尚未投递的记录数
+func (o *Outbox) PendingCount(ctx context.Context, db *sql.DB) (int64, error) {
+	var count int64
+	query := fmt.Sprintf("select count(*) from %s where status = ?", o.quotedTable())
+	if err := db.QueryRowContext(ctx, query, StatusPending).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count pending messages: %w", err)
+	}
+	return count, nil
+}
+
+// PurgeDelivered 删除指定时间之前已投递的记录
+func (o *Outbox) PurgeDelivered(ctx context.Context, db *sql.DB, before time.Time) (int64, error) {
+	query := fmt.Sprintf("delete from %s where status = ? and delivered_at < ?", o.quotedTable())
+	result, err := db.ExecContext(ctx, query, StatusDelivered, dbTime(before))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge delivered messages: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// quotedTable 返回带引号的表名
+func (o *Outbox) quotedTable() string {
+	if o.dialect == DialectMySQL {
+		return "`" + o.table + "`"
+	}
+	return `"` + o.table + `"`
+}
+
+// rowScanner 兼容 *sql.Row 和 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanMessage 扫描一条发件箱记录
+func scanMessage(row rowScanner) (*Message, error) {
+	var (
+		msg       Message
+		payload   string
+		taskID    sql.NullInt64
+		lastError sql.NullString
+	)
+	if err := row.Scan(&msg.ID, &msg.BusinessUniqueID, &payload, &msg.Status, &msg.Attempts, &taskID, &lastError); err != nil {
+		return nil, err
+	}
+
+	var req sdk.CreateTaskRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal outbox payload %d: %w", msg.ID, err)
+	}
+	msg.Request = &req
+	msg.TaskID = taskID.Int64
+	msg.LastError = lastError.String
+
+	return &msg, nil
+}
+
+// dbTime 统一使用UTC并截断到秒，保证 MySQL 和 SQLite 上的比较结果一致
+func dbTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
@@ -0,0 +1,197 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"task-center/sdk"
+)
+
+// newTestDB 创建基于 SQLite 的测试数据库和发件箱
+func newTestDB(t *testing.T) (*sql.DB, *Outbox) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "outbox.db"))
+	if err != nil {
+		t.Fatalf("Failed to open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	ob, err := New(&Config{Dialect: DialectSQLite})
+	if err != nil {
+		t.Fatalf("Failed to create outbox: %v", err)
+	}
+	if err := ob.CreateTable(context.Background(), db); err != nil {
+		t.Fatalf("Failed to create outbox table: %v", err)
+	}
+
+	return db, ob
+}
+
+func TestNew_Defaults(t *testing.T) {
+	ob, err := New(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if ob.Dialect() != DialectMySQL {
+		t.Errorf("Expected dialect mysql, got %s", ob.Dialect())
+	}
+	if ob.TableName() != DefaultTableName {
+		t.Errorf("Expected table %s, got %s", DefaultTableName, ob.TableName())
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+	}{
+		{"unsupported dialect", &Config{Dialect: "postgres"}},
+		{"invalid table name", &Config{TableName: "outbox; drop table tasks"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.config); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestOutbox_SchemaSQL(t *testing.T) {
+	mysqlOutbox, _ := New(&Config{Dialect: DialectMySQL, TableName: "my_outbox"})
+	schema := strings.Join(mysqlOutbox.SchemaSQL(), "\n")
+	if !strings.Contains(schema, "`my_outbox`") || !strings.Contains(schema, "AUTO_INCREMENT") {
+		t.Errorf("Unexpected MySQL schema: %s", schema)
+	}
+
+	sqliteOutbox, _ := New(&Config{Dialect: DialectSQLite, TableName: "my_outbox"})
+	schema = strings.Join(sqliteOutbox.SchemaSQL(), "\n")
+	if !strings.Contains(schema, "AUTOINCREMENT") {
+		t.Errorf("Unexpected SQLite schema: %s", schema)
+	}
+}
+
+func TestOutbox_EnqueueCommitted(t *testing.T) {
+	db, ob := newTestDB(t)
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to begin tx: %v", err)
+	}
+
+	req := sdk.NewTask("order-1", "https://example.com/callback").WithTags("order")
+	id, err := ob.Enqueue(ctx, tx, req)
+	if err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	msg, err := ob.Get(ctx, db, id)
+	if err != nil {
+		t.Fatalf("Failed to get message: %v", err)
+	}
+	if msg.BusinessUniqueID != "order-1" {
+		t.Errorf("Expected business unique id order-1, got %s", msg.BusinessUniqueID)
+	}
+	if msg.Status != StatusPending {
+		t.Errorf("Expected status pending, got %d", msg.Status)
+	}
+	if msg.Request.CallbackURL != "https://example.com/callback" || len(msg.Request.Tags) != 1 {
+		t.Errorf("Request not round-tripped: %+v", msg.Request)
+	}
+}
+
+func TestOutbox_EnqueueRolledBack(t *testing.T) {
+	db, ob := newTestDB(t)
+	ctx := context.Background()
+
+	tx, _ := db.BeginTx(ctx, nil)
+	if _, err := ob.Enqueue(ctx, tx, sdk.NewTask("order-2", "https://example.com/callback")); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	tx.Rollback()
+
+	count, err := ob.PendingCount(ctx, db)
+	if err != nil {
+		t.Fatalf("Failed to count: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected rolled back message to disappear, got %d pending", count)
+	}
+}
+
+func TestOutbox_EnqueueValidation(t *testing.T) {
+	db, ob := newTestDB(t)
+	ctx := context.Background()
+
+	tx, _ := db.BeginTx(ctx, nil)
+	defer tx.Rollback()
+
+	if _, err := ob.Enqueue(ctx, tx, nil); !sdk.IsValidationError(err) {
+		t.Errorf("Expected validation error for nil request, got %v", err)
+	}
+	if _, err := ob.Enqueue(ctx, tx, &sdk.CreateTaskRequest{CallbackURL: "https://example.com"}); !sdk.IsValidationError(err) {
+		t.Errorf("Expected validation error for missing business unique id, got %v", err)
+	}
+	if _, err := ob.Enqueue(ctx, nil, sdk.NewTask("order-3", "https://example.com")); !sdk.IsValidationError(err) {
+		t.Errorf("Expected validation error for nil tx, got %v", err)
+	}
+}
+
+func TestOutbox_EnqueueBatch(t *testing.T) {
+	db, ob := newTestDB(t)
+	ctx := context.Background()
+
+	tx, _ := db.BeginTx(ctx, nil)
+	ids, err := ob.EnqueueBatch(ctx, tx, []*sdk.CreateTaskRequest{
+		sdk.NewTask("batch-1", "https://example.com"),
+		sdk.NewTask("batch-2", "https://example.com"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to enqueue batch: %v", err)
+	}
+	tx.Commit()
+
+	if len(ids) != 2 {
+		t.Fatalf("Expected 2 ids, got %d", len(ids))
+	}
+	count, _ := ob.PendingCount(ctx, db)
+	if count != 2 {
+		t.Errorf("Expected 2 pending, got %d", count)
+	}
+}
+
+func TestOutbox_PurgeDelivered(t *testing.T) {
+	db, ob := newTestDB(t)
+	ctx := context.Background()
+
+	tx, _ := db.BeginTx(ctx, nil)
+	id, _ := ob.Enqueue(ctx, tx, sdk.NewTask("purge-1", "https://example.com"))
+	tx.Commit()
+
+	past := dbTime(time.Now().Add(-48 * time.Hour))
+	if _, err := db.Exec(`update task_outbox set status = ?, delivered_at = ? where id = ?`, StatusDelivered, past, id); err != nil {
+		t.Fatalf("Failed to mark delivered: %v", err)
+	}
+
+	purged, err := ob.PurgeDelivered(ctx, db, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("Expected 1 purged, got %d", purged)
+	}
+}
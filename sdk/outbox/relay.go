@@ -0,0 +1,299 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"task-center/sdk"
+	"task-center/sdk/retry"
+)
+
+// TaskCreator 投递任务所需的最小接口，sdk.TaskService 已实现该接口
+type TaskCreator interface {
+	Create(ctx context.Context, req *sdk.CreateTaskRequest) (*sdk.Task, error)
+	GetByBusinessUniqueID(ctx context.Context, businessUniqueID string) (*sdk.Task, error)
+}
+
+// RelayConfig 投递器配置
+type RelayConfig struct {
+	WorkerID      string              // 投递器标识，用于租约，默认 hostname:pid
+	BatchSize     int                 // 每轮最多投递的记录数
+	PollInterval  time.Duration       // 轮询间隔
+	LeaseDuration time.Duration       // 单条记录的租约时长，超时后其他投递器可以接管
+	MaxAttempts   int                 // 最大投递次数，超过后标记为失败
+	Backoff       retry.BackoffConfig // 投递失败后的退避配置
+}
+
+// DefaultRelayConfig 返回默认投递器配置
+func DefaultRelayConfig() *RelayConfig {
+	return &RelayConfig{
+		BatchSize:     100,
+		PollInterval:  time.Second,
+		LeaseDuration: 30 * time.Second,
+		MaxAttempts:   10,
+		Backoff: retry.BackoffConfig{
+			BaseDelay:  time.Second,
+			MaxDelay:   5 * time.Minute,
+			Multiplier: 2.0,
+			Jitter:     true,
+		},
+	}
+}
+
+// errLeaseLost 更新投递结果时租约已过期并被其他投递器接管，记录的状态以接管者为准
+var errLeaseLost = errors.New("outbox lease lost")
+
+// RelayResult 单轮投递结果
+type RelayResult struct {
+	Claimed   int // 本轮认领的记录数
+	Delivered int // 投递成功（含服务端已存在）的记录数
+	Retried   int // 等待重试的记录数
+	Failed    int // 标记为失败的记录数
+	LeaseLost int // 更新结果时租约已丢失的记录数，由接管的投递器重新投递
+}
+
+// Relay 发件箱投递器，将已提交的记录投递到 TaskCenter
+// 依赖 (business_id, business_unique_id) 唯一键实现恰好一次创建：
+// 如果服务端返回冲突，说明任务已经创建过，直接标记为已投递
+type Relay struct {
+	db      *sql.DB
+	outbox  *Outbox
+	tasks   TaskCreator
+	config  *RelayConfig
+	backoff retry.BackoffStrategy
+	now     func() time.Time
+}
+
+// NewRelay 创建发件箱投递器
+func NewRelay(db *sql.DB, outbox *Outbox, tasks TaskCreator, config *RelayConfig) (*Relay, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db is required")
+	}
+	if outbox == nil {
+		return nil, fmt.Errorf("outbox is required")
+	}
+	if tasks == nil {
+		return nil, fmt.Errorf("task creator is required")
+	}
+
+	defaults := DefaultRelayConfig()
+	if config == nil {
+		config = defaults
+	}
+	if config.WorkerID == "" {
+		hostname, _ := os.Hostname()
+		config.WorkerID = hostname + ":" + strconv.Itoa(os.Getpid())
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaults.PollInterval
+	}
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = defaults.LeaseDuration
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.Backoff.BaseDelay <= 0 {
+		config.Backoff = defaults.Backoff
+	}
+
+	return &Relay{
+		db:      db,
+		outbox:  outbox,
+		tasks:   tasks,
+		config:  config,
+		backoff: &retry.ExponentialBackoff{},
+		now:     time.Now,
+	}, nil
+}
+
+// Run 持续轮询并投递，直到上下文取消
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		result, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			// 数据库暂时不可用时等待下一轮
+			result = nil
+		}
+
+		// 本轮认领满批次时说明还有积压，立即继续
+		if result != nil && result.Claimed >= r.config.BatchSize {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce 认领一批到期的记录并逐条投递
+func (r *Relay) RelayOnce(ctx context.Context) (*RelayResult, error) {
+	ids, err := r.claim(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RelayResult{Claimed: len(ids)}
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		msg, err := r.outbox.Get(ctx, r.db, id)
+		if err != nil {
+			return result, err
+		}
+
+		status, err := r.deliver(ctx, msg)
+		if errors.Is(err, errLeaseLost) {
+			result.LeaseLost++
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+
+		switch status {
+		case StatusDelivered:
+			result.Delivered++
+		case StatusFailed:
+			result.Failed++
+		default:
+			result.Retried++
+		}
+	}
+
+	return result, nil
+}
+
+// claim 通过租约认领到期的记录，多个投递器并发运行时互不重复
+func (r *Relay) claim(ctx context.Context) ([]int64, error) {
+	now := dbTime(r.now())
+	table := r.outbox.quotedTable()
+
+	query := fmt.Sprintf("select id from %s where status = ? and next_attempt_at <= ? and (locked_until is null or locked_until < ?) order by id limit ?", table)
+	rows, err := r.db.QueryContext(ctx, query, StatusPending, now, now, r.config.BatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+
+	var candidates []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan outbox id: %w", err)
+		}
+		candidates = append(candidates, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate outbox: %w", err)
+	}
+
+	lockedUntil := dbTime(now.Add(r.config.LeaseDuration))
+	update := fmt.Sprintf("update %s set locked_by = ?, locked_until = ? where id = ? and status = ? and (locked_until is null or locked_until < ?)", table)
+
+	claimed := make([]int64, 0, len(candidates))
+	for _, id := range candidates {
+		res, err := r.db.ExecContext(ctx, update, r.config.WorkerID, lockedUntil, id, StatusPending, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim outbox message %d: %w", id, err)
+		}
+		if affected, _ := res.RowsAffected(); affected == 1 {
+			claimed = append(claimed, id)
+		}
+	}
+
+	return claimed, nil
+}
+
+// deliver 投递单条记录，返回投递后的状态
+// 更新状态失败时返回错误，租约已丢失时返回 errLeaseLost
+func (r *Relay) deliver(ctx context.Context, msg *Message) (int, error) {
+	created, err := r.tasks.Create(ctx, msg.Request)
+	if err == nil {
+		return r.markDelivered(ctx, msg, created.ID)
+	}
+
+	// 唯一键冲突说明任务已经创建成功（例如上次投递后未能标记），视为已投递
+	if sdk.IsConflictError(err) {
+		existing, getErr := r.tasks.GetByBusinessUniqueID(ctx, msg.BusinessUniqueID)
+		if getErr == nil {
+			return r.markDelivered(ctx, msg, existing.ID)
+		}
+		err = getErr
+	}
+
+	if !isRetryable(err) || msg.Attempts+1 >= r.config.MaxAttempts {
+		return r.markFailed(ctx, msg, err)
+	}
+
+	return r.markRetry(ctx, msg, err)
+}
+
+// markDelivered 标记为已投递
+// 标记失败不影响正确性：租约到期后记录会被重新认领，再次投递时由唯一键冲突识别为已投递
+func (r *Relay) markDelivered(ctx context.Context, msg *Message, taskID int64) (int, error) {
+	query := fmt.Sprintf("update %s set status = ?, task_id = ?, attempts = attempts + 1, last_error = null, delivered_at = ?, locked_by = null, locked_until = null where id = ? and locked_by = ?", r.outbox.quotedTable())
+	return StatusDelivered, r.update(ctx, msg, query, StatusDelivered, taskID, dbTime(r.now()), msg.ID, r.config.WorkerID)
+}
+
+// markRetry 记录错误并按退避策略安排下次投递
+func (r *Relay) markRetry(ctx context.Context, msg *Message, cause error) (int, error) {
+	delay := r.backoff.Calculate(msg.Attempts+1, r.config.Backoff)
+	nextAttemptAt := dbTime(r.now().Add(delay))
+
+	query := fmt.Sprintf("update %s set attempts = attempts + 1, last_error = ?, next_attempt_at = ?, locked_by = null, locked_until = null where id = ? and locked_by = ?", r.outbox.quotedTable())
+	return StatusPending, r.update(ctx, msg, query, cause.Error(), nextAttemptAt, msg.ID, r.config.WorkerID)
+}
+
+// markFailed 标记为失败，不再投递
+func (r *Relay) markFailed(ctx context.Context, msg *Message, cause error) (int, error) {
+	query := fmt.Sprintf("update %s set status = ?, attempts = attempts + 1, last_error = ?, locked_by = null, locked_until = null where id = ? and locked_by = ?", r.outbox.quotedTable())
+	return StatusFailed, r.update(ctx, msg, query, StatusFailed, cause.Error(), msg.ID, r.config.WorkerID)
+}
+
+// update 在仍持有租约时更新记录的投递结果，没有更新到记录时说明租约已被其他投递器接管
+func (r *Relay) update(ctx context.Context, msg *Message, query string, args ...interface{}) error {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update outbox message %d: %w", msg.ID, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update outbox message %d: %w", msg.ID, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("outbox message %d: %w", msg.ID, errLeaseLost)
+	}
+	return nil
+}
+
+// isRetryable 判断投递错误是否可以重试
+// 校验、认证和授权错误重试也不会成功，其余错误（包括网络错误）都重试
+func isRetryable(err error) bool {
+	switch {
+	case sdk.IsValidationError(err), sdk.IsAuthenticationError(err), sdk.IsAuthorizationError(err):
+		return false
+	default:
+		return true
+	}
+}
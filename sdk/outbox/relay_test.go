@@ -0,0 +1,346 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"task-center/sdk"
+)
+
+// mockTaskCreator 模拟 TaskCenter，按 business_unique_id 保证唯一
+type mockTaskCreator struct {
+	mu        sync.Mutex
+	tasks     map[string]*sdk.Task
+	nextID    int64
+	createErr error
+	calls     int
+	onCreate  func() // 创建任务时调用，用于模拟投递期间租约被接管等情况
+}
+
+func newMockTaskCreator() *mockTaskCreator {
+	return &mockTaskCreator{tasks: make(map[string]*sdk.Task)}
+}
+
+func (m *mockTaskCreator) Create(ctx context.Context, req *sdk.CreateTaskRequest) (*sdk.Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls++
+	if m.onCreate != nil {
+		m.onCreate()
+	}
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	if _, exists := m.tasks[req.BusinessUniqueID]; exists {
+		return nil, sdk.NewConflictError("task already exists")
+	}
+
+	m.nextID++
+	task := &sdk.Task{ID: m.nextID, BusinessUniqueID: req.BusinessUniqueID, CallbackURL: req.CallbackURL}
+	m.tasks[req.BusinessUniqueID] = task
+	return task, nil
+}
+
+func (m *mockTaskCreator) GetByBusinessUniqueID(ctx context.Context, businessUniqueID string) (*sdk.Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, exists := m.tasks[businessUniqueID]
+	if !exists {
+		return nil, sdk.NewNotFoundError("task")
+	}
+	return task, nil
+}
+
+// enqueue 在独立事务中写入一条记录
+func enqueue(t *testing.T, db *sql.DB, ob *Outbox, businessUniqueID string) int64 {
+	t.Helper()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin tx: %v", err)
+	}
+	id, err := ob.Enqueue(context.Background(), tx, sdk.NewTask(businessUniqueID, "https://example.com/callback"))
+	if err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	return id
+}
+
+func TestNewRelay_Validation(t *testing.T) {
+	db, ob := newTestDB(t)
+	creator := newMockTaskCreator()
+
+	if _, err := NewRelay(nil, ob, creator, nil); err == nil {
+		t.Error("Expected error for nil db")
+	}
+	if _, err := NewRelay(db, nil, creator, nil); err == nil {
+		t.Error("Expected error for nil outbox")
+	}
+	if _, err := NewRelay(db, ob, nil, nil); err == nil {
+		t.Error("Expected error for nil task creator")
+	}
+
+	relay, err := NewRelay(db, ob, creator, &RelayConfig{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if relay.config.WorkerID == "" || relay.config.BatchSize != 100 || relay.config.MaxAttempts != 10 {
+		t.Errorf("Defaults not applied: %+v", relay.config)
+	}
+}
+
+func TestRelay_DeliversPendingMessages(t *testing.T) {
+	db, ob := newTestDB(t)
+	creator := newMockTaskCreator()
+	ctx := context.Background()
+
+	id1 := enqueue(t, db, ob, "order-1")
+	id2 := enqueue(t, db, ob, "order-2")
+
+	relay, _ := NewRelay(db, ob, creator, nil)
+	result, err := relay.RelayOnce(ctx)
+	if err != nil {
+		t.Fatalf("RelayOnce failed: %v", err)
+	}
+	if result.Claimed != 2 || result.Delivered != 2 {
+		t.Errorf("Expected 2 claimed and delivered, got %+v", result)
+	}
+
+	for _, id := range []int64{id1, id2} {
+		msg, _ := ob.Get(ctx, db, id)
+		if msg.Status != StatusDelivered || msg.TaskID == 0 {
+			t.Errorf("Message %d not marked delivered: %+v", id, msg)
+		}
+	}
+
+	// 再次运行不应重复投递
+	result, _ = relay.RelayOnce(ctx)
+	if result.Claimed != 0 || creator.calls != 2 {
+		t.Errorf("Expected no redelivery, got %+v with %d calls", result, creator.calls)
+	}
+}
+
+func TestRelay_ConflictTreatedAsDelivered(t *testing.T) {
+	db, ob := newTestDB(t)
+	creator := newMockTaskCreator()
+	ctx := context.Background()
+
+	// 模拟上次投递成功但未能标记：服务端已存在该任务
+	existing, _ := creator.Create(ctx, sdk.NewTask("order-1", "https://example.com/callback"))
+	id := enqueue(t, db, ob, "order-1")
+
+	relay, _ := NewRelay(db, ob, creator, nil)
+	result, err := relay.RelayOnce(ctx)
+	if err != nil {
+		t.Fatalf("RelayOnce failed: %v", err)
+	}
+	if result.Delivered != 1 {
+		t.Errorf("Expected conflict to count as delivered, got %+v", result)
+	}
+
+	msg, _ := ob.Get(ctx, db, id)
+	if msg.Status != StatusDelivered || msg.TaskID != existing.ID {
+		t.Errorf("Expected delivered with task id %d, got %+v", existing.ID, msg)
+	}
+}
+
+func TestRelay_RetriesTransientErrors(t *testing.T) {
+	db, ob := newTestDB(t)
+	creator := newMockTaskCreator()
+	creator.createErr = sdk.NewServerError("service unavailable")
+	ctx := context.Background()
+
+	id := enqueue(t, db, ob, "order-1")
+
+	now := time.Now()
+	relay, _ := NewRelay(db, ob, creator, nil)
+	relay.now = func() time.Time { return now }
+
+	result, _ := relay.RelayOnce(ctx)
+	if result.Retried != 1 {
+		t.Fatalf("Expected 1 retried, got %+v", result)
+	}
+
+	msg, _ := ob.Get(ctx, db, id)
+	if msg.Status != StatusPending || msg.Attempts != 1 || msg.LastError == "" {
+		t.Errorf("Expected pending with 1 attempt and error, got %+v", msg)
+	}
+
+	// 退避期内不会再次认领
+	result, _ = relay.RelayOnce(ctx)
+	if result.Claimed != 0 {
+		t.Errorf("Expected message to be backing off, got %+v", result)
+	}
+
+	// 退避结束后恢复投递
+	creator.createErr = nil
+	now = now.Add(10 * time.Minute)
+	result, _ = relay.RelayOnce(ctx)
+	if result.Delivered != 1 {
+		t.Errorf("Expected delivery after backoff, got %+v", result)
+	}
+}
+
+func TestRelay_IdempotencyInProgressRetried(t *testing.T) {
+	db, ob := newTestDB(t)
+	creator := newMockTaskCreator()
+	// 上一次投递仍在服务端处理，自动重试耗尽后返回处理中错误
+	creator.createErr = sdk.ParseHTTPError(503, []byte(`{"code":"IDEMPOTENCY_IN_PROGRESS","message":"still being processed"}`))
+	ctx := context.Background()
+
+	id := enqueue(t, db, ob, "order-1")
+
+	relay, _ := NewRelay(db, ob, creator, nil)
+	result, _ := relay.RelayOnce(ctx)
+	if result.Retried != 1 || result.Delivered != 0 {
+		t.Fatalf("Expected in-progress request to be retried rather than delivered, got %+v", result)
+	}
+
+	msg, _ := ob.Get(ctx, db, id)
+	if msg.Status != StatusPending || msg.TaskID != 0 {
+		t.Errorf("Expected pending without task id, got %+v", msg)
+	}
+}
+
+func TestRelay_FailsPermanentErrors(t *testing.T) {
+	db, ob := newTestDB(t)
+	creator := newMockTaskCreator()
+	creator.createErr = sdk.NewValidationError("callback_url is invalid")
+	ctx := context.Background()
+
+	id := enqueue(t, db, ob, "order-1")
+
+	relay, _ := NewRelay(db, ob, creator, nil)
+	result, _ := relay.RelayOnce(ctx)
+	if result.Failed != 1 {
+		t.Errorf("Expected 1 failed, got %+v", result)
+	}
+
+	msg, _ := ob.Get(ctx, db, id)
+	if msg.Status != StatusFailed {
+		t.Errorf("Expected failed status, got %d", msg.Status)
+	}
+}
+
+func TestRelay_MaxAttempts(t *testing.T) {
+	db, ob := newTestDB(t)
+	creator := newMockTaskCreator()
+	creator.createErr = errors.New("connection refused")
+	ctx := context.Background()
+
+	id := enqueue(t, db, ob, "order-1")
+
+	now := time.Now()
+	relay, _ := NewRelay(db, ob, creator, &RelayConfig{MaxAttempts: 2})
+	relay.now = func() time.Time { return now }
+
+	relay.RelayOnce(ctx)
+	now = now.Add(time.Hour)
+	relay.RelayOnce(ctx)
+
+	msg, _ := ob.Get(ctx, db, id)
+	if msg.Status != StatusFailed || msg.Attempts != 2 {
+		t.Errorf("Expected failed after 2 attempts, got %+v", msg)
+	}
+}
+
+func TestRelay_LeasePreventsDoubleClaim(t *testing.T) {
+	db, ob := newTestDB(t)
+	ctx := context.Background()
+
+	enqueue(t, db, ob, "order-1")
+
+	relayA, _ := NewRelay(db, ob, newMockTaskCreator(), &RelayConfig{WorkerID: "a"})
+	relayB, _ := NewRelay(db, ob, newMockTaskCreator(), &RelayConfig{WorkerID: "b"})
+
+	claimedA, err := relayA.claim(ctx)
+	if err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+	claimedB, _ := relayB.claim(ctx)
+
+	if len(claimedA) != 1 || len(claimedB) != 0 {
+		t.Errorf("Expected only relay a to claim, got a=%v b=%v", claimedA, claimedB)
+	}
+}
+
+func TestRelay_LeaseLostCountedSeparately(t *testing.T) {
+	db, ob := newTestDB(t)
+	creator := newMockTaskCreator()
+	ctx := context.Background()
+
+	id := enqueue(t, db, ob, "order-1")
+
+	// 投递期间租约过期并被另一个投递器接管
+	creator.onCreate = func() {
+		if _, err := db.Exec("update "+ob.quotedTable()+" set locked_by = ? where id = ?", "other", id); err != nil {
+			t.Errorf("Failed to steal lease: %v", err)
+		}
+	}
+
+	relay, _ := NewRelay(db, ob, creator, &RelayConfig{WorkerID: "a"})
+	result, err := relay.RelayOnce(ctx)
+	if err != nil {
+		t.Fatalf("RelayOnce failed: %v", err)
+	}
+	if result.LeaseLost != 1 || result.Delivered != 0 {
+		t.Errorf("Expected 1 lease lost and none delivered, got %+v", result)
+	}
+
+	msg, _ := ob.Get(ctx, db, id)
+	if msg.Status != StatusPending || msg.Attempts != 0 {
+		t.Errorf("Expected message left to the other relay, got %+v", msg)
+	}
+}
+
+func TestRelay_UpdateErrorReturned(t *testing.T) {
+	db, ob := newTestDB(t)
+	creator := newMockTaskCreator()
+
+	enqueue(t, db, ob, "order-1")
+
+	// 投递后无法更新记录
+	creator.onCreate = func() {
+		if _, err := db.Exec("drop table " + ob.quotedTable()); err != nil {
+			t.Errorf("Failed to drop table: %v", err)
+		}
+	}
+
+	relay, _ := NewRelay(db, ob, creator, nil)
+	result, err := relay.RelayOnce(context.Background())
+	if err == nil {
+		t.Fatal("Expected error when the status update fails")
+	}
+	if result.Delivered != 0 {
+		t.Errorf("Expected no message counted as delivered, got %+v", result)
+	}
+}
+
+func TestRelay_RunStopsOnContextCancel(t *testing.T) {
+	db, ob := newTestDB(t)
+	creator := newMockTaskCreator()
+	enqueue(t, db, ob, "order-1")
+
+	relay, _ := NewRelay(db, ob, creator, &RelayConfig{PollInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := relay.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+
+	count, _ := ob.PendingCount(context.Background(), db)
+	if count != 0 {
+		t.Errorf("Expected all messages delivered, got %d pending", count)
+	}
+}
@@ -0,0 +1,105 @@
+package retry
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AttemptFunc 执行一次请求，attempt 从 1 开始
+type AttemptFunc func(ctx context.Context, attempt int) (*http.Response, error)
+
+// Do 按策略执行请求直到成功、不可重试或预算耗尽
+// 等待期间响应 ctx 取消；429/503 响应带 Retry-After 时按服务端要求的时间等待，
+// 等待时间超出 MaxElapsedTime 剩余预算时不再重试，直接返回该响应
+// 被重试的响应会关闭 Body，最终返回的响应由调用方关闭
+func (p *Policy) Do(ctx context.Context, fn AttemptFunc) (*http.Response, error) {
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		resp, err := fn(ctx, attempt)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, ctxErr
+		}
+
+		elapsed := time.Since(start)
+		if !p.ShouldRetry(attempt, err, resp, elapsed) {
+			if attempt > 1 {
+				p.ExecuteAfterRetry(attempt-1, err, resp, elapsed)
+			}
+			return resp, err
+		}
+
+		backoff := p.CalculateBackoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := RetryAfter(resp, time.Now()); ok {
+				backoff = retryAfter
+			}
+		}
+		if p.MaxElapsedTime > 0 && elapsed+backoff > p.MaxElapsedTime {
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+		p.ExecuteBeforeRetry(attempt, err, backoff)
+
+		if err := Sleep(ctx, backoff); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Sleep 等待 d 或 ctx 结束，ctx 结束时返回 ctx.Err()
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RetryAfter 读取 429/503 响应的 Retry-After，其他状态码忽略该头
+func RetryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	return ParseRetryAfter(resp.Header.Get("Retry-After"), now)
+}
+
+// ParseRetryAfter 解析 Retry-After，支持秒数和 HTTP 日期两种格式，日期早于 now 时返回 0
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := at.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
@@ -0,0 +1,126 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newResponse(status int, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(""))}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 9, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"seconds", "120", 2 * time.Minute, true},
+		{"zero seconds", "0", 0, true},
+		{"http date", now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{"past http date", now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"empty", "", 0, false},
+		{"negative", "-5", 0, false},
+		{"garbage", "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseRetryAfter(tt.value, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ParseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRetryAfterOnlyFor429And503(t *testing.T) {
+	header := http.Header{"Retry-After": []string{"5"}}
+	if _, ok := RetryAfter(newResponse(http.StatusInternalServerError, header), time.Now()); ok {
+		t.Error("Retry-After should be ignored for 500")
+	}
+	if d, ok := RetryAfter(newResponse(http.StatusTooManyRequests, header), time.Now()); !ok || d != 5*time.Second {
+		t.Errorf("RetryAfter() = %v, %v, want 5s, true", d, ok)
+	}
+}
+
+func TestPolicyDo(t *testing.T) {
+	policy := DefaultPolicy().WithDelay(time.Millisecond, 10*time.Millisecond, 2).WithJitter(false)
+
+	attempts := 0
+	resp, err := policy.Do(context.Background(), func(ctx context.Context, attempt int) (*http.Response, error) {
+		attempts++
+		if attempt != attempts {
+			t.Errorf("attempt = %d, want %d", attempt, attempts)
+		}
+		if attempt < 3 {
+			return newResponse(http.StatusBadGateway, nil), nil
+		}
+		return newResponse(http.StatusOK, nil), nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || attempts != 3 {
+		t.Errorf("Do() status = %d after %d attempts, want 200 after 3", resp.StatusCode, attempts)
+	}
+}
+
+func TestPolicyDo_NonRetryable(t *testing.T) {
+	attempts := 0
+	resp, _ := DefaultPolicy().Do(context.Background(), func(ctx context.Context, attempt int) (*http.Response, error) {
+		attempts++
+		return newResponse(http.StatusBadRequest, nil), nil
+	})
+	if resp.StatusCode != http.StatusBadRequest || attempts != 1 {
+		t.Errorf("Do() status = %d after %d attempts, want 400 after 1", resp.StatusCode, attempts)
+	}
+}
+
+func TestPolicyDo_ContextCancelled(t *testing.T) {
+	policy := DefaultPolicy().WithDelay(time.Minute, time.Minute, 2)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := policy.Do(ctx, func(ctx context.Context, attempt int) (*http.Response, error) {
+		return newResponse(http.StatusServiceUnavailable, nil), nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Do() should stop waiting when ctx is done")
+	}
+}
+
+func TestPolicyDo_RetryAfterExceedsBudget(t *testing.T) {
+	policy := DefaultPolicy().WithMaxElapsedTime(time.Second)
+
+	attempts := 0
+	start := time.Now()
+	resp, err := policy.Do(context.Background(), func(ctx context.Context, attempt int) (*http.Response, error) {
+		attempts++
+		return newResponse(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"60"}}), nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests || attempts != 1 {
+		t.Errorf("Do() status = %d after %d attempts, want 429 after 1", resp.StatusCode, attempts)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Do() should not wait when Retry-After exceeds the budget")
+	}
+}
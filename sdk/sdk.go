@@ -97,6 +97,12 @@ func (req *CreateTaskRequest) WithSchedule(scheduledAt time.Time) *CreateTaskReq
 	return req
 }
 
+// WithConflictPolicy 设置 business_unique_id 冲突时的处理策略
+func (req *CreateTaskRequest) WithConflictPolicy(policy ConflictPolicy) *CreateTaskRequest {
+	req.ConflictPolicy = policy
+	return req
+}
+
 // 便捷的查询构造器
 
 // NewListTasksRequest 创建任务列表查询请求
@@ -0,0 +1,142 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"task-center/sdk"
+)
+
+// DefaultBulkJobPollInterval 等待批量任务完成时的默认轮询间隔
+const DefaultBulkJobPollInterval = time.Second
+
+// NewBulkJobRequest 使用列表过滤条件创建批量操作请求
+func NewBulkJobRequest(action sdk.BulkAction, filter *ListRequest) *sdk.BulkJobRequest {
+	req := &sdk.BulkJobRequest{Action: action}
+	if filter != nil && filter.ListTasksRequest != nil {
+		req.Filter = *cloneListRequest(filter).ListTasksRequest
+		req.Filter.Page = 0
+		req.Filter.PageSize = 0
+		req.Filter.SortBy = ""
+		req.Filter.Descending = false
+		req.Filter.Cursor = ""
+	}
+	return req
+}
+
+// StartBulkJob 提交按过滤条件批量操作的任务，服务端异步执行
+// DryRun 时服务端只统计匹配的任务数，返回的任务通常已完成
+func (c *Client) StartBulkJob(ctx context.Context, req *sdk.BulkJobRequest) (*sdk.BulkJob, error) {
+	if req == nil {
+		return nil, sdk.NewValidationError("bulk job request cannot be nil")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.sdkClient.DoRequest(ctx, "POST", "/api/v1/tasks/bulk-jobs", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return c.parseBulkJobResponse(resp)
+}
+
+// GetBulkJob 查询批量操作任务的进度
+func (c *Client) GetBulkJob(ctx context.Context, jobID string) (*sdk.BulkJob, error) {
+	if jobID == "" {
+		return nil, sdk.NewValidationError("bulk job ID cannot be empty")
+	}
+
+	resp, err := c.sdkClient.DoRequest(ctx, "GET", "/api/v1/tasks/bulk-jobs/"+url.PathEscape(jobID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return c.parseBulkJobResponse(resp)
+}
+
+// CancelBulkJob 取消批量操作任务，已处理的任务不会回滚
+func (c *Client) CancelBulkJob(ctx context.Context, jobID string) (*sdk.BulkJob, error) {
+	if jobID == "" {
+		return nil, sdk.NewValidationError("bulk job ID cannot be empty")
+	}
+
+	resp, err := c.sdkClient.DoRequest(ctx, "POST", "/api/v1/tasks/bulk-jobs/"+url.PathEscape(jobID)+"/cancel", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return c.parseBulkJobResponse(resp)
+}
+
+// WaitBulkJob 轮询直到批量任务结束，onProgress 不为空时每次查询后回调
+// interval 小于等于 0 时使用 DefaultBulkJobPollInterval
+func (c *Client) WaitBulkJob(ctx context.Context, jobID string, interval time.Duration, onProgress func(*sdk.BulkJob)) (*sdk.BulkJob, error) {
+	if interval <= 0 {
+		interval = DefaultBulkJobPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.GetBulkJob(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if onProgress != nil {
+			onProgress(job)
+		}
+		if job.Status.IsTerminal() {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// 内部方法：解析批量任务响应
+func (c *Client) parseBulkJobResponse(resp *http.Response) (*sdk.BulkJob, error) {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, c.parseErrorResponse(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var apiResp sdk.ApiResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if !apiResp.Success {
+		return nil, sdk.NewServerError(apiResp.Message)
+	}
+
+	jobData, err := json.Marshal(apiResp.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bulk job data: %w", err)
+	}
+
+	var job sdk.BulkJob
+	if err := json.Unmarshal(jobData, &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal bulk job: %w", err)
+	}
+
+	return &job, nil
+}
@@ -0,0 +1,171 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"task-center/sdk"
+)
+
+func writeBulkJob(t *testing.T, w http.ResponseWriter, job sdk.BulkJob) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sdk.ApiResponse{Success: true, Data: job}); err != nil {
+		t.Fatalf("Failed to encode response: %v", err)
+	}
+}
+
+func TestNewBulkJobRequest(t *testing.T) {
+	filter := NewListRequest().WithStatus(StatusPending).WithTags("campaign").WithPagination(3, 50).WithSort("created_at", true)
+	req := NewBulkJobRequest(sdk.BulkActionCancel, filter)
+
+	if req.Action != sdk.BulkActionCancel {
+		t.Errorf("Expected action cancel, got %s", req.Action)
+	}
+	if req.Filter.Page != 0 || req.Filter.PageSize != 0 || req.Filter.SortBy != "" || req.Filter.Descending {
+		t.Errorf("Expected pagination and sort to be cleared, got %+v", req.Filter)
+	}
+	if len(req.Filter.Tags) != 1 || len(req.Filter.Status) != 1 {
+		t.Errorf("Expected filter fields to be kept, got %+v", req.Filter)
+	}
+
+	// 原过滤条件不受影响
+	req.Filter.Tags[0] = "changed"
+	if filter.Tags[0] != "campaign" || filter.Page != 3 {
+		t.Errorf("NewBulkJobRequest modified the original filter: %+v", filter.ListTasksRequest)
+	}
+}
+
+func TestClient_StartBulkJob(t *testing.T) {
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/tasks/bulk-jobs" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var req sdk.BulkJobRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if req.Action != sdk.BulkActionRetry || !req.DryRun || req.Search != "error:timeout" {
+			t.Errorf("Unexpected request body: %+v", req)
+		}
+
+		writeBulkJob(t, w, sdk.BulkJob{ID: "bulk_1", Action: req.Action, Status: sdk.BulkJobStatusCompleted, DryRun: true, Total: 42})
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	job, err := client.StartBulkJob(context.Background(), &sdk.BulkJobRequest{
+		Action: sdk.BulkActionRetry,
+		Search: "error:timeout",
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("StartBulkJob failed: %v", err)
+	}
+	if job.ID != "bulk_1" || job.Total != 42 || job.Progress() != 1 {
+		t.Errorf("Unexpected job: %+v", job)
+	}
+}
+
+func TestClient_StartBulkJobValidation(t *testing.T) {
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Invalid requests should not reach the server")
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+
+	tests := []*sdk.BulkJobRequest{
+		nil,
+		{Action: "archive", Search: "error:x"},
+		{Action: sdk.BulkActionDelete},
+		{Action: sdk.BulkActionDelete, Filter: sdk.ListTasksRequest{Tags: []string{"a"}, TagMatch: "some"}},
+	}
+	for _, req := range tests {
+		if _, err := client.StartBulkJob(context.Background(), req); err == nil {
+			t.Errorf("Expected validation error for %+v", req)
+		}
+	}
+}
+
+func TestClient_WaitBulkJob(t *testing.T) {
+	var calls int32
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/api/v1/tasks/bulk-jobs/bulk_1" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		n := atomic.AddInt32(&calls, 1)
+		job := sdk.BulkJob{ID: "bulk_1", Action: sdk.BulkActionCancel, Status: sdk.BulkJobStatusRunning, Total: 10, Processed: int(n) * 4}
+		if n == 3 {
+			job.Status = sdk.BulkJobStatusCompleted
+			job.Processed = 10
+			job.Succeeded = 9
+			job.Skipped = 1
+		}
+		writeBulkJob(t, w, job)
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	var progress []float64
+	job, err := client.WaitBulkJob(context.Background(), "bulk_1", time.Millisecond, func(j *sdk.BulkJob) {
+		progress = append(progress, j.Progress())
+	})
+	if err != nil {
+		t.Fatalf("WaitBulkJob failed: %v", err)
+	}
+	if job.Status != sdk.BulkJobStatusCompleted || job.Succeeded != 9 || job.Skipped != 1 {
+		t.Errorf("Unexpected final job: %+v", job)
+	}
+	if len(progress) != 3 || progress[0] != 0.4 || progress[2] != 1 {
+		t.Errorf("Unexpected progress callbacks: %v", progress)
+	}
+}
+
+func TestClient_WaitBulkJobContextCancelled(t *testing.T) {
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeBulkJob(t, w, sdk.BulkJob{ID: "bulk_1", Status: sdk.BulkJobStatusRunning})
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	job, err := client.WaitBulkJob(ctx, "bulk_1", time.Hour, func(*sdk.BulkJob) { cancel() })
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if job == nil || job.Status != sdk.BulkJobStatusRunning {
+		t.Errorf("Expected the last observed job state, got %+v", job)
+	}
+}
+
+func TestClient_CancelBulkJob(t *testing.T) {
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/tasks/bulk-jobs/bulk_1/cancel" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		writeBulkJob(t, w, sdk.BulkJob{ID: "bulk_1", Status: sdk.BulkJobStatusCancelled, Total: 10, Processed: 4})
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	job, err := client.CancelBulkJob(context.Background(), "bulk_1")
+	if err != nil {
+		t.Fatalf("CancelBulkJob failed: %v", err)
+	}
+	if job.Status != sdk.BulkJobStatusCancelled || job.Progress() != 0.4 {
+		t.Errorf("Unexpected job: %+v", job)
+	}
+
+	if _, err := client.CancelBulkJob(context.Background(), ""); err == nil {
+		t.Error("Expected validation error for empty job ID")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 
+	"task-center/pkg/search"
 	"task-center/sdk"
 )
 
@@ -96,6 +97,9 @@ func (c *Client) UpdateTask(ctx context.Context, taskID int64, req *UpdateReques
 	if req == nil {
 		return nil, sdk.NewValidationError("update request cannot be nil")
 	}
+	if err := sdk.ValidateTags(req.Tags); err != nil {
+		return nil, err
+	}
 
 	path := fmt.Sprintf("/api/v1/tasks/%d", taskID)
 	resp, err := c.sdkClient.DoRequest(ctx, "PUT", path, req.UpdateTaskRequest)
@@ -164,6 +168,9 @@ func (c *Client) ListTasks(ctx context.Context, req *ListRequest) (*ListResponse
 	if req == nil {
 		req = NewListRequest()
 	}
+	if !req.TagMatch.IsValid() {
+		return nil, sdk.NewValidationError("invalid tag_match: " + string(req.TagMatch))
+	}
 
 	// 构建查询参数
 	query := c.buildListQuery(req)
@@ -192,11 +199,14 @@ func (c *Client) GetTaskStats(ctx context.Context) (*StatsResponse, error) {
 	return c.parseStatsResponse(resp)
 }
 
-// SearchTasks 搜索任务
+// SearchTasks 按搜索语法查询任务，语法见 pkg/search 包
 func (c *Client) SearchTasks(ctx context.Context, query string, filters *ListRequest) (*ListResponse, error) {
-	if query == "" {
+	if strings.TrimSpace(query) == "" {
 		return nil, sdk.NewValidationError("search query cannot be empty")
 	}
+	if _, err := search.Parse(query); err != nil {
+		return nil, sdk.NewValidationError(err.Error())
+	}
 
 	if filters == nil {
 		filters = NewListRequest()
@@ -228,6 +238,17 @@ func (c *Client) SearchTasks(ctx context.Context, query string, filters *ListReq
 	return c.parseListResponse(resp)
 }
 
+// Search 按结构化搜索条件查询任务，条件为空时等同于 ListTasks
+func (c *Client) Search(ctx context.Context, query *search.Query, filters *ListRequest) (*ListResponse, error) {
+	if query.IsEmpty() {
+		return c.ListTasks(ctx, filters)
+	}
+	if err := query.Validate(); err != nil {
+		return nil, sdk.NewValidationError(err.Error())
+	}
+	return c.SearchTasks(ctx, query.String(), filters)
+}
+
 // GetTaskHistory 获取任务执行历史
 func (c *Client) GetTaskHistory(ctx context.Context, taskID int64) ([]*Task, error) {
 	if taskID <= 0 {
@@ -340,6 +361,9 @@ func (c *Client) buildListQuery(req *ListRequest) string {
 	// 标签过滤
 	if len(req.Tags) > 0 {
 		params.Set("tags", strings.Join(req.Tags, ","))
+		if req.TagMatch != "" {
+			params.Set("tag_match", string(req.TagMatch))
+		}
 	}
 
 	// 优先级过滤
@@ -355,8 +379,10 @@ func (c *Client) buildListQuery(req *ListRequest) string {
 		params.Set("created_to", req.CreatedTo.Format("2006-01-02T15:04:05Z"))
 	}
 
-	// 分页参数
-	if req.Page > 0 {
+	// 分页参数，游标分页时忽略页码
+	if req.UsesCursor() {
+		req.EncodeCursorParams(params)
+	} else if req.Page > 0 {
 		params.Set("page", strconv.Itoa(req.Page))
 	}
 	if req.PageSize > 0 {
@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"task-center/pkg/search"
 	"task-center/sdk"
 )
 
@@ -638,4 +640,86 @@ func TestClient_SearchTasks(t *testing.T) {
 	if resp.Tasks[0].BusinessUniqueID != "search-result-1" {
 		t.Errorf("Expected business ID search-result-1, got %s", resp.Tasks[0].BusinessUniqueID)
 	}
-}
\ No newline at end of file
+}
+
+func TestClient_SearchTasksInvalidQuery(t *testing.T) {
+	var requests int32
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	defer client.Close()
+
+	_, err := client.SearchTasks(context.Background(), "retries:abc", nil)
+	if !sdk.IsValidationError(err) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+	if atomic.LoadInt32(&requests) != 0 {
+		t.Errorf("Expected invalid query to be rejected before sending, got %d requests", requests)
+	}
+}
+
+func TestClient_Search(t *testing.T) {
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/api/v1/tasks/search" {
+			t.Errorf("Expected search path, got %s", r.URL.Path)
+		}
+		if want := "metadata.order.id:42 host:api.example.com error:timeout retries:>=1"; query.Get("q") != want {
+			t.Errorf("Expected q=%q, got %q", want, query.Get("q"))
+		}
+		if query.Get("status") != "3" {
+			t.Errorf("Expected status filter to be kept, got %q", query.Get("status"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sdk.ApiResponse{Success: true, Data: sdk.ListTasksResponse{Tasks: []sdk.Task{{ID: 7}}}})
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	defer client.Close()
+
+	min := 1
+	query := search.NewQuery().
+		WithMetadata("order.id", "42").
+		WithHost("api.example.com").
+		WithErrorContains("timeout").
+		WithRetries(&min, nil)
+
+	resp, err := client.Search(context.Background(), query, NewListRequest().WithStatus(StatusFailed))
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(resp.Tasks) != 1 || resp.Tasks[0].ID != 7 {
+		t.Errorf("Unexpected search result: %+v", resp.Tasks)
+	}
+}
+
+func TestClient_ListTasksTagMatch(t *testing.T) {
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("tags") != "billing,urgent" || query.Get("tag_match") != "any" {
+			t.Errorf("Expected tags=billing,urgent&tag_match=any, got %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sdk.ApiResponse{Success: true, Data: sdk.ListTasksResponse{}})
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	defer client.Close()
+
+	if _, err := client.ListTasks(context.Background(), NewListRequest().WithAnyTags("billing", "urgent")); err != nil {
+		t.Fatalf("ListTasks() error = %v", err)
+	}
+
+	req := NewListRequest().WithTags("billing")
+	req.TagMatch = "some"
+	if _, err := client.ListTasks(context.Background(), req); !sdk.IsValidationError(err) {
+		t.Errorf("Expected validation error for invalid tag_match, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package task
+
+import (
+	"context"
+
+	"task-center/pkg/search"
+	"task-center/sdk"
+)
+
+// DefaultIteratePageSize 迭代时每次请求的条数
+const DefaultIteratePageSize = 100
+
+// Iterator 基于游标分页的任务迭代器，逐条返回所有匹配的任务
+//
+//	it := client.Iterate(ctx, filter)
+//	for it.Next() {
+//		task := it.Task()
+//	}
+//	if err := it.Err(); err != nil {
+//		// 处理错误
+//	}
+type Iterator struct {
+	ctx     context.Context
+	client  *Client
+	request *ListRequest
+	query   string // 搜索语法，为空时使用列表接口
+	page    []*Task
+	index   int
+	current *Task
+	done    bool
+	err     error
+}
+
+// Iterate 返回遍历所有匹配任务的迭代器
+// filter 为空时遍历全部任务；未指定排序时按 (created_at, id) 升序，已设置的页码和游标会被忽略
+func (c *Client) Iterate(ctx context.Context, filter *ListRequest) *Iterator {
+	req := cloneListRequest(filter)
+	if req.SortBy == "" {
+		req.SortBy = sdk.ListSortByCreatedAt
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = DefaultIteratePageSize
+	}
+	req.Page = 0
+	req.Cursor = ""
+
+	return &Iterator{
+		ctx:     ctx,
+		client:  c,
+		request: req,
+	}
+}
+
+// IterateSearch 返回遍历所有搜索结果的迭代器，query 为空时等同于 Iterate
+func (c *Client) IterateSearch(ctx context.Context, query *search.Query, filter *ListRequest) *Iterator {
+	it := c.Iterate(ctx, filter)
+	if query.IsEmpty() {
+		return it
+	}
+	if err := query.Validate(); err != nil {
+		it.err = sdk.NewValidationError(err.Error())
+		return it
+	}
+	it.query = query.String()
+	return it
+}
+
+// Next 前进到下一个任务，没有更多任务或出错时返回 false
+func (it *Iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	for it.index >= len(it.page) {
+		if it.done {
+			it.current = nil
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			it.current = nil
+			return false
+		}
+	}
+
+	it.current = it.page[it.index]
+	it.index++
+	return true
+}
+
+// Task 返回当前任务
+func (it *Iterator) Task() *Task {
+	return it.current
+}
+
+// Err 返回迭代过程中的错误
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Collect 读取剩余的所有任务
+func (it *Iterator) Collect() ([]*Task, error) {
+	var tasks []*Task
+	for it.Next() {
+		tasks = append(tasks, it.Task())
+	}
+	return tasks, it.Err()
+}
+
+// fetch 获取下一页
+func (it *Iterator) fetch() error {
+	if err := it.ctx.Err(); err != nil {
+		return err
+	}
+
+	var (
+		resp *ListResponse
+		err  error
+	)
+	if it.query != "" {
+		resp, err = it.client.SearchTasks(it.ctx, it.query, it.request)
+	} else {
+		resp, err = it.client.ListTasks(it.ctx, it.request)
+	}
+	if err != nil {
+		return err
+	}
+
+	it.page = resp.Tasks
+	it.index = 0
+
+	// 服务端返回空游标或游标未前进时结束，避免死循环
+	if !resp.HasMore || resp.NextCursor == "" || resp.NextCursor == it.request.Cursor {
+		it.done = true
+	}
+	it.request.Cursor = resp.NextCursor
+
+	return nil
+}
+
+// cloneListRequest 复制查询条件，避免迭代过程修改调用方的请求
+func cloneListRequest(filter *ListRequest) *ListRequest {
+	req := NewListRequest()
+	if filter == nil || filter.ListTasksRequest == nil {
+		return req
+	}
+
+	copied := *filter.ListTasksRequest
+	if filter.Status != nil {
+		copied.Status = append([]TaskStatus(nil), filter.Status...)
+	}
+	if filter.Tags != nil {
+		copied.Tags = append([]string(nil), filter.Tags...)
+	}
+	req.ListTasksRequest = &copied
+	return req
+}
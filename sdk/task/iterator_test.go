@@ -0,0 +1,157 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"task-center/pkg/search"
+	"task-center/sdk"
+)
+
+// cursorServer 模拟游标分页接口，游标为上一页最后一条任务的ID
+func cursorServer(t *testing.T, total int, requests *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		query := r.URL.Query()
+
+		if query.Get("sort_by") != "created_at" {
+			t.Errorf("Expected sort_by created_at, got %q", query.Get("sort_by"))
+		}
+		if query.Get("page") != "" {
+			t.Errorf("Expected no page parameter in cursor mode, got %q", query.Get("page"))
+		}
+		if query.Get("status") != "0" {
+			t.Errorf("Expected status filter to be kept, got %q", query.Get("status"))
+		}
+
+		pageSize, _ := strconv.Atoi(query.Get("page_size"))
+		after, _ := strconv.Atoi(query.Get("cursor"))
+
+		resp := sdk.ListTasksResponse{PageSize: pageSize}
+		for id := after + 1; id <= total && len(resp.Tasks) < pageSize; id++ {
+			resp.Tasks = append(resp.Tasks, sdk.Task{ID: int64(id), BusinessUniqueID: "task-" + strconv.Itoa(id)})
+		}
+		if len(resp.Tasks) > 0 && int(resp.Tasks[len(resp.Tasks)-1].ID) < total {
+			resp.HasMore = true
+			resp.NextCursor = strconv.FormatInt(resp.Tasks[len(resp.Tasks)-1].ID, 10)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sdk.ApiResponse{Success: true, Data: resp})
+	}
+}
+
+func TestClient_Iterate(t *testing.T) {
+	var requests int32
+	server := mockServer(t, cursorServer(t, 5, &requests))
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	filter := NewListRequest().WithStatus(StatusPending).WithPagination(3, 2)
+
+	it := client.Iterate(context.Background(), filter)
+	var ids []int64
+	for it.Next() {
+		ids = append(ids, it.Task().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iterate failed: %v", err)
+	}
+
+	if len(ids) != 5 {
+		t.Fatalf("Expected 5 tasks, got %d", len(ids))
+	}
+	for i, id := range ids {
+		if id != int64(i+1) {
+			t.Errorf("Expected task %d at position %d, got %d", i+1, i, id)
+		}
+	}
+	if atomic.LoadInt32(&requests) != 3 {
+		t.Errorf("Expected 3 page requests, got %d", requests)
+	}
+
+	// 调用方的请求不应被修改
+	if filter.Page != 3 || filter.Cursor != "" || filter.SortBy != "" {
+		t.Errorf("Expected filter to be unchanged, got %+v", filter.ListTasksRequest)
+	}
+}
+
+func TestClient_IterateEmpty(t *testing.T) {
+	var requests int32
+	server := mockServer(t, cursorServer(t, 0, &requests))
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	tasks, err := client.Iterate(context.Background(), NewListRequest().WithStatus(StatusPending)).Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(tasks) != 0 || atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("Expected no tasks after 1 request, got %d tasks after %d requests", len(tasks), requests)
+	}
+}
+
+func TestClient_IterateError(t *testing.T) {
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(sdk.ErrorResponse{Message: "invalid cursor", Code: sdk.CodeValidationError})
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	it := client.Iterate(context.Background(), nil)
+	if it.Next() {
+		t.Error("Expected Next to return false on error")
+	}
+	if !sdk.IsValidationError(it.Err()) {
+		t.Errorf("Expected validation error, got %v", it.Err())
+	}
+}
+
+func TestClient_IterateContextCancelled(t *testing.T) {
+	var requests int32
+	server := mockServer(t, cursorServer(t, 5, &requests))
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	it := client.Iterate(ctx, NewListRequest().WithStatus(StatusPending))
+	if it.Next() {
+		t.Error("Expected Next to return false for cancelled context")
+	}
+	if it.Err() != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", it.Err())
+	}
+	if atomic.LoadInt32(&requests) != 0 {
+		t.Errorf("Expected no requests, got %d", requests)
+	}
+}
+
+func TestClient_IterateSearch(t *testing.T) {
+	var requests int32
+	list := cursorServer(t, 3, &requests)
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/tasks/search" || r.URL.Query().Get("q") != "url:/hooks" {
+			t.Errorf("Expected search request, got %s", r.URL.String())
+		}
+		list(w, r)
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	query := search.NewQuery().WithURLContains("/hooks")
+	tasks, err := client.IterateSearch(context.Background(), query, NewListRequest().WithStatus(StatusPending).WithPagination(1, 2)).Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(tasks) != 3 || atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("Expected 3 tasks in 2 requests, got %d tasks in %d requests", len(tasks), requests)
+	}
+}
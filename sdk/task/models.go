@@ -127,6 +127,12 @@ func (r *CreateRequest) WithMetadata(metadata map[string]interface{}) *CreateReq
 	return r
 }
 
+// WithConflictPolicy 设置 business_unique_id 冲突时的处理策略
+func (r *CreateRequest) WithConflictPolicy(policy sdk.ConflictPolicy) *CreateRequest {
+	r.ConflictPolicy = policy
+	return r
+}
+
 // UpdateRequest 更新任务请求结构
 type UpdateRequest struct {
 	*sdk.UpdateTaskRequest
@@ -235,6 +241,20 @@ func (r *ListRequest) WithTags(tags ...string) *ListRequest {
 	return r
 }
 
+// WithAnyTags 查询包含任一标签的任务
+func (r *ListRequest) WithAnyTags(tags ...string) *ListRequest {
+	r.Tags = tags
+	r.TagMatch = sdk.TagMatchAny
+	return r
+}
+
+// WithAllTags 查询包含全部标签的任务
+func (r *ListRequest) WithAllTags(tags ...string) *ListRequest {
+	r.Tags = tags
+	r.TagMatch = sdk.TagMatchAll
+	return r
+}
+
 // WithPriority 过滤优先级
 func (r *ListRequest) WithPriority(priority TaskPriority) *ListRequest {
 	r.Priority = &priority
@@ -248,6 +268,13 @@ func (r *ListRequest) WithTimeRange(from, to time.Time) *ListRequest {
 	return r
 }
 
+// WithCreatedTimeRange 设置创建时间范围，为空的一端表示不限
+func (r *ListRequest) WithCreatedTimeRange(from, to *time.Time) *ListRequest {
+	r.CreatedFrom = from
+	r.CreatedTo = to
+	return r
+}
+
 // WithCreatedFrom 设置创建时间起始
 func (r *ListRequest) WithCreatedFrom(from time.Time) *ListRequest {
 	r.CreatedFrom = &from
@@ -267,6 +294,19 @@ func (r *ListRequest) WithPagination(page, pageSize int) *ListRequest {
 	return r
 }
 
+// WithSort 使用游标分页并设置排序键
+func (r *ListRequest) WithSort(sortBy sdk.ListSortBy, descending bool) *ListRequest {
+	r.SortBy = sortBy
+	r.Descending = descending
+	return r
+}
+
+// WithCursor 设置游标，从上一页响应的 NextCursor 继续
+func (r *ListRequest) WithCursor(cursor string) *ListRequest {
+	r.Cursor = cursor
+	return r
+}
+
 // ListResponse 任务列表响应
 type ListResponse struct {
 	*sdk.ListTasksResponse
@@ -280,9 +320,9 @@ func NewListResponseFromSDK(sdkResp *sdk.ListTasksResponse) *ListResponse {
 		Tasks:             make([]*Task, len(sdkResp.Tasks)),
 	}
 
-	// 转换任务列表
-	for i, sdkTask := range sdkResp.Tasks {
-		resp.Tasks[i] = NewTaskFromSDK(&sdkTask)
+	// 转换任务列表，取切片元素地址避免共用循环变量
+	for i := range sdkResp.Tasks {
+		resp.Tasks[i] = NewTaskFromSDK(&sdkResp.Tasks[i])
 	}
 
 	return resp
@@ -326,6 +366,12 @@ func (r *BatchCreateRequest) AddTasks(reqs ...*CreateRequest) *BatchCreateReques
 	return r
 }
 
+// WithConflictPolicy 设置批量创建的默认冲突策略
+func (r *BatchCreateRequest) WithConflictPolicy(policy sdk.ConflictPolicy) *BatchCreateRequest {
+	r.BatchCreateTasksRequest.ConflictPolicy = policy
+	return r
+}
+
 // ToSDK 转换为 SDK 批量创建请求
 func (r *BatchCreateRequest) ToSDK() *sdk.BatchCreateTasksRequest {
 	sdkTasks := make([]sdk.CreateTaskRequest, len(r.Requests))
@@ -333,7 +379,8 @@ func (r *BatchCreateRequest) ToSDK() *sdk.BatchCreateTasksRequest {
 		sdkTasks[i] = *req.CreateTaskRequest
 	}
 	return &sdk.BatchCreateTasksRequest{
-		Tasks: sdkTasks,
+		Tasks:          sdkTasks,
+		ConflictPolicy: r.BatchCreateTasksRequest.ConflictPolicy,
 	}
 }
 
@@ -297,110 +297,6 @@ func (cop *ConcurrentOperations) ConcurrentUpdate(ctx context.Context, updates m
 	return results
 }
 
-// TaskWatcher 任务监控器
-type TaskWatcher struct {
-	client   *Client
-	interval time.Duration
-	stopChan chan struct{}
-	mu       sync.RWMutex
-	watchers map[int64]chan *Task
-}
-
-// NewTaskWatcher 创建任务监控器
-func NewTaskWatcher(client *Client, interval time.Duration) *TaskWatcher {
-	if interval <= 0 {
-		interval = 5 * time.Second // 默认5秒检查一次
-	}
-
-	return &TaskWatcher{
-		client:   client,
-		interval: interval,
-		stopChan: make(chan struct{}),
-		watchers: make(map[int64]chan *Task),
-	}
-}
-
-// WatchTask 监控任务状态变化
-func (tw *TaskWatcher) WatchTask(ctx context.Context, taskID int64) <-chan *Task {
-	tw.mu.Lock()
-	defer tw.mu.Unlock()
-
-	taskChan := make(chan *Task, 10)
-	tw.watchers[taskID] = taskChan
-
-	return taskChan
-}
-
-// StopWatching 停止监控任务
-func (tw *TaskWatcher) StopWatching(taskID int64) {
-	tw.mu.Lock()
-	defer tw.mu.Unlock()
-
-	if taskChan, exists := tw.watchers[taskID]; exists {
-		close(taskChan)
-		delete(tw.watchers, taskID)
-	}
-}
-
-// Start 启动监控
-func (tw *TaskWatcher) Start(ctx context.Context) {
-	ticker := time.NewTicker(tw.interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-tw.stopChan:
-			return
-		case <-ticker.C:
-			tw.checkTasks(ctx)
-		}
-	}
-}
-
-// Stop 停止监控
-func (tw *TaskWatcher) Stop() {
-	close(tw.stopChan)
-
-	tw.mu.Lock()
-	defer tw.mu.Unlock()
-
-	for _, taskChan := range tw.watchers {
-		close(taskChan)
-	}
-	tw.watchers = make(map[int64]chan *Task)
-}
-
-// checkTasks 检查任务状态
-func (tw *TaskWatcher) checkTasks(ctx context.Context) {
-	tw.mu.RLock()
-	taskIDs := make([]int64, 0, len(tw.watchers))
-	for taskID := range tw.watchers {
-		taskIDs = append(taskIDs, taskID)
-	}
-	tw.mu.RUnlock()
-
-	for _, taskID := range taskIDs {
-		task, err := tw.client.GetTask(ctx, taskID)
-		if err != nil {
-			continue
-		}
-
-		tw.mu.RLock()
-		taskChan, exists := tw.watchers[taskID]
-		tw.mu.RUnlock()
-
-		if exists {
-			select {
-			case taskChan <- task:
-			default:
-				// 如果通道已满，跳过这次更新
-			}
-		}
-	}
-}
-
 // TaskScheduler 任务调度器
 type TaskScheduler struct {
 	client *Client
@@ -635,29 +531,12 @@ func (tq *TaskQuery) First(ctx context.Context) (*Task, error) {
 	return resp.Tasks[0], nil
 }
 
-// All 获取所有匹配的任务
+// All 获取所有匹配的任务（游标分页，不受并发写入影响）
 func (tq *TaskQuery) All(ctx context.Context) ([]*Task, error) {
-	var allTasks []*Task
-	page := 1
-	pageSize := 100
-
-	for {
-		req := tq.filter.Build()
-		req.WithPagination(page, pageSize)
-
-		resp, err := tq.client.ListTasks(ctx, req)
-		if err != nil {
-			return nil, err
-		}
-
-		allTasks = append(allTasks, resp.Tasks...)
-
-		if len(resp.Tasks) < pageSize {
-			break
-		}
-
-		page++
-	}
+	return tq.client.Iterate(ctx, tq.filter.Build()).Collect()
+}
 
-	return allTasks, nil
+// Iterate 返回遍历所有匹配任务的迭代器
+func (tq *TaskQuery) Iterate(ctx context.Context) *Iterator {
+	return tq.client.Iterate(ctx, tq.filter.Build())
 }
\ No newline at end of file
@@ -337,6 +337,11 @@ func TestTaskWatcher(t *testing.T) {
 	callCount := 0
 
 	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// 服务端不支持推送，监控器回退到轮询
+		if r.URL.Path == "/api/v1/tasks/watch" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
 		callCount++
 
 		if r.Method != "GET" {
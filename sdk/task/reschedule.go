@@ -0,0 +1,127 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"task-center/sdk"
+)
+
+// RescheduleResult 单个任务的改期结果
+type RescheduleResult struct {
+	TaskID  int64
+	Outcome sdk.RescheduleOutcome
+	Reason  string // 跳过的原因，如任务正在执行
+	Task    *Task  // 处理后的任务，任务不存在时为 nil
+}
+
+// Rescheduled 任务是否已改期
+func (r *RescheduleResult) Rescheduled() bool {
+	return r.Outcome == sdk.RescheduleOutcomeRescheduled
+}
+
+// newRescheduleResult 转换服务端返回的改期结果
+func newRescheduleResult(r *sdk.RescheduleResult) *RescheduleResult {
+	result := &RescheduleResult{
+		TaskID:  r.TaskID,
+		Outcome: r.Outcome,
+		Reason:  r.Reason,
+	}
+	if r.Task != nil {
+		result.Task = NewTaskFromSDK(r.Task)
+	}
+	return result
+}
+
+// RescheduleTask 调整单个待执行任务的执行时间，任务不是待执行状态时结果为 skipped
+func (c *Client) RescheduleTask(ctx context.Context, taskID int64, req *sdk.RescheduleRequest) (*RescheduleResult, error) {
+	if taskID <= 0 {
+		return nil, sdk.NewValidationError("task ID must be greater than 0")
+	}
+	if req == nil {
+		return nil, sdk.NewValidationError("reschedule request cannot be nil")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/api/v1/tasks/%d/reschedule", taskID)
+	resp, err := c.sdkClient.DoRequest(ctx, "POST", path, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result sdk.RescheduleResult
+	if err := c.parseDataResponse(resp, &result); err != nil {
+		return nil, err
+	}
+	return newRescheduleResult(&result), nil
+}
+
+// SnoozeTask 将待执行任务顺延 d
+func (c *Client) SnoozeTask(ctx context.Context, taskID int64, d time.Duration) (*RescheduleResult, error) {
+	return c.RescheduleTask(ctx, taskID, sdk.SnoozeFor(d))
+}
+
+// RescheduleTasks 按任务ID批量改期，每个任务单独判断状态，结果与 taskIDs 顺序一致
+func (c *Client) RescheduleTasks(ctx context.Context, taskIDs []int64, req *sdk.RescheduleRequest) ([]*RescheduleResult, error) {
+	if req == nil {
+		return nil, sdk.NewValidationError("reschedule request cannot be nil")
+	}
+	batchReq := &sdk.BatchRescheduleRequest{TaskIDs: taskIDs, RescheduleRequest: *req}
+	if err := batchReq.Validate(); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.sdkClient.DoRequest(ctx, "POST", "/api/v1/tasks/reschedule", batchReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var batchResp sdk.BatchRescheduleResponse
+	if err := c.parseDataResponse(resp, &batchResp); err != nil {
+		return nil, err
+	}
+
+	results := make([]*RescheduleResult, 0, len(batchResp.Results))
+	for i := range batchResp.Results {
+		results = append(results, newRescheduleResult(&batchResp.Results[i]))
+	}
+	return results, nil
+}
+
+// 内部方法：解析响应中的 data 字段到 v
+func (c *Client) parseDataResponse(resp *http.Response, v any) error {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return c.parseErrorResponse(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var apiResp sdk.ApiResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if !apiResp.Success {
+		return sdk.NewServerError(apiResp.Message)
+	}
+
+	data, err := json.Marshal(apiResp.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response data: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"task-center/sdk"
+)
+
+func TestClient_SnoozeTask(t *testing.T) {
+	next := time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC)
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/tasks/7/reschedule" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var req sdk.RescheduleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if req.DelaySeconds != 7200 || req.At != nil {
+			t.Errorf("Unexpected request body: %+v", req)
+		}
+
+		json.NewEncoder(w).Encode(sdk.ApiResponse{Success: true, Data: sdk.RescheduleResult{
+			TaskID:  7,
+			Outcome: sdk.RescheduleOutcomeRescheduled,
+			Task:    &sdk.Task{ID: 7, Status: sdk.TaskStatusPending, NextExecuteAt: &next},
+		}})
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	result, err := client.SnoozeTask(context.Background(), 7, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("SnoozeTask failed: %v", err)
+	}
+	if !result.Rescheduled() || result.Task == nil || !result.Task.NextExecuteAt.Equal(next) {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestClient_RescheduleTasks(t *testing.T) {
+	at := time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/tasks/reschedule" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var req sdk.BatchRescheduleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if len(req.TaskIDs) != 3 || req.At == nil || !req.At.Equal(at) {
+			t.Errorf("Unexpected request body: %+v", req)
+		}
+
+		json.NewEncoder(w).Encode(sdk.ApiResponse{Success: true, Data: sdk.BatchRescheduleResponse{Results: []sdk.RescheduleResult{
+			{TaskID: 1, Outcome: sdk.RescheduleOutcomeRescheduled, Task: &sdk.Task{ID: 1, ScheduledAt: at}},
+			{TaskID: 2, Outcome: sdk.RescheduleOutcomeSkipped, Reason: "task is running", Task: &sdk.Task{ID: 2, Status: sdk.TaskStatusRunning}},
+			{TaskID: 3, Outcome: sdk.RescheduleOutcomeNotFound},
+		}}})
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	results, err := client.RescheduleTasks(context.Background(), []int64{1, 2, 3}, sdk.RescheduleAt(at))
+	if err != nil {
+		t.Fatalf("RescheduleTasks failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if !results[0].Rescheduled() || results[1].Outcome != sdk.RescheduleOutcomeSkipped || results[1].Reason == "" ||
+		results[2].Outcome != sdk.RescheduleOutcomeNotFound || results[2].Task != nil {
+		t.Errorf("Unexpected results: %+v, %+v, %+v", results[0], results[1], results[2])
+	}
+}
+
+func TestClient_RescheduleValidation(t *testing.T) {
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Invalid requests should not reach the server")
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	ctx := context.Background()
+
+	if _, err := client.RescheduleTask(ctx, 0, sdk.SnoozeFor(time.Hour)); err == nil {
+		t.Error("Expected error for invalid task ID")
+	}
+	if _, err := client.RescheduleTask(ctx, 1, &sdk.RescheduleRequest{}); err == nil {
+		t.Error("Expected error for empty reschedule request")
+	}
+	if _, err := client.RescheduleTasks(ctx, nil, sdk.SnoozeFor(time.Hour)); err == nil {
+		t.Error("Expected error for empty task IDs")
+	}
+	if _, err := client.RescheduleTasks(ctx, make([]int64, sdk.MaxBatchRescheduleTasks+1), sdk.SnoozeFor(time.Hour)); err == nil {
+		t.Error("Expected error for too many task IDs")
+	}
+}
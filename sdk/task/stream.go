@@ -0,0 +1,230 @@
+package task
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-center/sdk"
+)
+
+// 任务监听事件类型，与服务端 GET /api/v1/tasks/watch 推送的 SSE 事件一致
+const (
+	WatchEventStatusChanged = "task.status_changed" // 任务状态变更
+	WatchEventSnapshot      = "task.snapshot"       // 连接建立时任务的当前状态
+)
+
+// ErrStreamUnsupported 服务端不支持任务状态推送（接口不存在或不是 SSE 响应），调用方应改为轮询
+var ErrStreamUnsupported = errors.New("task watch stream is not supported by the server")
+
+// WatchRequest 任务监听范围，TaskIDs 为空时按其余条件监听业务系统的所有任务
+type WatchRequest struct {
+	TaskIDs  []int64
+	Statuses []TaskStatus // 变更后的状态
+	Priority *TaskPriority
+	Tags     []string // 任务须包含全部标签
+	Snapshot bool     // 续传时也先推送 TaskIDs 的当前状态；不续传时服务端总是推送
+}
+
+// WatchEvent 服务端推送的任务事件
+type WatchEvent struct {
+	ID         string // SSE 事件 id，断线重连时作为 Last-Event-ID
+	Type       string
+	TaskID     int64
+	FromStatus TaskStatus
+	ToStatus   TaskStatus
+	Actor      string
+	Reason     string
+	At         time.Time
+	Task       *Task // 推送时任务的最新状态，任务已删除时为 nil
+}
+
+// watchPayload SSE 事件的 data
+type watchPayload struct {
+	TaskID     int64      `json:"task_id"`
+	FromStatus TaskStatus `json:"from_status"`
+	ToStatus   TaskStatus `json:"to_status"`
+	Actor      string     `json:"actor"`
+	Reason     string     `json:"reason"`
+	At         time.Time  `json:"at"`
+	Task       *sdk.Task  `json:"task"`
+}
+
+// TaskStream 任务状态推送连接，不是并发安全的
+type TaskStream struct {
+	body        io.ReadCloser
+	reader      *bufio.Reader
+	lastEventID string
+	retry       time.Duration
+}
+
+// StreamTasks 建立任务状态推送连接，lastEventID 非空时从该事件之后续传
+// 服务端不支持推送时返回 ErrStreamUnsupported；连接由 ctx 或 Close 关闭
+func (c *Client) StreamTasks(ctx context.Context, req *WatchRequest, lastEventID string) (*TaskStream, error) {
+	if req == nil {
+		req = &WatchRequest{}
+	}
+
+	header := http.Header{}
+	header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		header.Set("Last-Event-ID", lastEventID)
+	}
+	path := "/api/v1/tasks/watch"
+	if query := buildWatchQuery(req); query != "" {
+		path += "?" + query
+	}
+
+	resp, err := c.sdkClient.DoStream(ctx, path, header)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented:
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("%w: %v", ErrStreamUnsupported, c.parseErrorResponse(resp))
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		defer resp.Body.Close()
+		return nil, c.parseErrorResponse(resp)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: unexpected content type %q", ErrStreamUnsupported, resp.Header.Get("Content-Type"))
+	}
+
+	return &TaskStream{
+		body:        resp.Body,
+		reader:      bufio.NewReader(resp.Body),
+		lastEventID: lastEventID,
+	}, nil
+}
+
+// buildWatchQuery 构建监听范围的查询参数
+func buildWatchQuery(req *WatchRequest) string {
+	params := url.Values{}
+	if len(req.TaskIDs) > 0 {
+		ids := make([]string, len(req.TaskIDs))
+		for i, id := range req.TaskIDs {
+			ids[i] = strconv.FormatInt(id, 10)
+		}
+		params.Set("task_id", strings.Join(ids, ","))
+	}
+	if len(req.Statuses) > 0 {
+		statuses := make([]string, len(req.Statuses))
+		for i, status := range req.Statuses {
+			statuses[i] = strconv.Itoa(int(status))
+		}
+		params.Set("status", strings.Join(statuses, ","))
+	}
+	if req.Priority != nil {
+		params.Set("priority", strconv.Itoa(int(*req.Priority)))
+	}
+	if len(req.Tags) > 0 {
+		params.Set("tags", strings.Join(req.Tags, ","))
+	}
+	if req.Snapshot {
+		params.Set("snapshot", "true")
+	}
+	return params.Encode()
+}
+
+// Next 阻塞直到收到下一个任务事件；服务端关闭连接时返回 io.EOF，未知类型的事件被跳过
+func (s *TaskStream) Next() (*WatchEvent, error) {
+	var (
+		eventType string
+		data      strings.Builder
+		hasData   bool
+	)
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) && line == "" {
+				return nil, io.EOF
+			}
+			if !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			// 空行结束一个事件
+			if hasData && (eventType == WatchEventStatusChanged || eventType == WatchEventSnapshot) {
+				return s.decode(eventType, data.String())
+			}
+			eventType, hasData = "", false
+			data.Reset()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// 注释行，服务端用作心跳
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			s.lastEventID = value
+		case "event":
+			eventType = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms > 0 {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// decode 解析事件的 data
+func (s *TaskStream) decode(eventType, data string) (*WatchEvent, error) {
+	var payload watchPayload
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal watch event: %w", err)
+	}
+
+	event := &WatchEvent{
+		ID:         s.lastEventID,
+		Type:       eventType,
+		TaskID:     payload.TaskID,
+		FromStatus: payload.FromStatus,
+		ToStatus:   payload.ToStatus,
+		Actor:      payload.Actor,
+		Reason:     payload.Reason,
+		At:         payload.At,
+	}
+	if payload.Task != nil {
+		event.Task = NewTaskFromSDK(payload.Task)
+	}
+	return event, nil
+}
+
+// LastEventID 返回最近收到的事件 id，重连时传给 StreamTasks 续传
+func (s *TaskStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// RetryDelay 返回服务端建议的重连间隔，未建议时为 0
+func (s *TaskStream) RetryDelay() time.Duration {
+	return s.retry
+}
+
+// Close 关闭连接
+func (s *TaskStream) Close() error {
+	return s.body.Close()
+}
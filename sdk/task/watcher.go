@@ -0,0 +1,415 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// WatcherConfig 任务监控器配置
+type WatcherConfig struct {
+	PollInterval        time.Duration // 轮询间隔，服务端不支持推送或关闭推送时使用
+	DisableStream       bool          // 不使用服务端推送，始终轮询
+	ReconnectDelay      time.Duration // 推送连接断开后的初始重连间隔，服务端建议的间隔优先
+	MaxReconnectDelay   time.Duration // 连续重连失败时的最大重连间隔
+	StreamProbeInterval time.Duration // 回退到轮询后重新尝试推送的间隔
+	BufferSize          int           // 每个任务通道的缓冲区大小，消费者跟不上时更新在监控器内排队
+	QueueSize           int           // 每个任务排队的更新数上限，超过时丢弃排队的中间状态，只保留最新状态
+}
+
+// DefaultWatcherConfig 默认任务监控器配置
+func DefaultWatcherConfig() *WatcherConfig {
+	return &WatcherConfig{
+		PollInterval:        5 * time.Second,
+		ReconnectDelay:      time.Second,
+		MaxReconnectDelay:   30 * time.Second,
+		StreamProbeInterval: 5 * time.Minute,
+		BufferSize:          10,
+		QueueSize:           100,
+	}
+}
+
+// TaskWatcher 任务监控器
+// 优先通过服务端推送（SSE）接收状态变更，断线后带 Last-Event-ID 自动重连续传；服务端不支持推送时回退到轮询
+// 同一任务状态不变时不重复通知
+type TaskWatcher struct {
+	client *Client
+	config *WatcherConfig
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+
+	mu          sync.Mutex
+	watches     map[int64]*taskWatch
+	running     bool
+	stopped     bool
+	added       bool   // 上次建立推送连接后是否新增了监控的任务，新增时重连需要推送当前状态
+	lastEventID string // 最近收到的推送事件 id，只由监控协程读写
+	changed     chan struct{}
+}
+
+// taskWatch 单个任务的监控，更新先进入队列，由转发协程按顺序写入 ch
+type taskWatch struct {
+	ch     chan *Task
+	notify chan struct{}
+	done   chan struct{}
+	limit  int
+
+	mu    sync.Mutex
+	queue []*Task
+	last  *Task
+}
+
+// NewTaskWatcher 创建任务监控器
+func NewTaskWatcher(client *Client, interval time.Duration) *TaskWatcher {
+	config := DefaultWatcherConfig()
+	if interval > 0 {
+		config.PollInterval = interval
+	}
+	return NewTaskWatcherWithConfig(client, config)
+}
+
+// NewTaskWatcherWithConfig 使用配置创建任务监控器
+func NewTaskWatcherWithConfig(client *Client, config *WatcherConfig) *TaskWatcher {
+	if config == nil {
+		config = DefaultWatcherConfig()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TaskWatcher{
+		client:  client,
+		config:  config,
+		ctx:     ctx,
+		cancel:  cancel,
+		watches: make(map[int64]*taskWatch),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// WatchTask 监控任务状态变化，首次调用时自动启动监控；ctx 结束或调用 StopWatching 后通道关闭
+// 重复监控同一任务时返回同一个通道
+func (tw *TaskWatcher) WatchTask(ctx context.Context, taskID int64) <-chan *Task {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if w, exists := tw.watches[taskID]; exists {
+		return w.ch
+	}
+	w := &taskWatch{
+		ch:     make(chan *Task, tw.config.BufferSize),
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		limit:  tw.config.QueueSize,
+	}
+	if tw.stopped {
+		close(w.ch)
+		return w.ch
+	}
+	tw.watches[taskID] = w
+	tw.added = true
+	go w.forward()
+	tw.notifyChanged()
+	tw.startLocked(tw.ctx)
+
+	if ctx != nil && ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				tw.StopWatching(taskID)
+			case <-w.done:
+			}
+		}()
+	}
+	return w.ch
+}
+
+// StopWatching 停止监控任务
+func (tw *TaskWatcher) StopWatching(taskID int64) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if w, exists := tw.watches[taskID]; exists {
+		close(w.done)
+		delete(tw.watches, taskID)
+		tw.notifyChanged()
+	}
+}
+
+// Start 启动监控并阻塞，直到 ctx 结束或调用 Stop；WatchTask 会自动启动监控，不必调用 Start
+func (tw *TaskWatcher) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(tw.ctx, cancel)
+	defer stop()
+
+	tw.mu.Lock()
+	if tw.stopped || tw.running {
+		tw.mu.Unlock()
+		<-ctx.Done()
+		return
+	}
+	tw.running = true
+	tw.mu.Unlock()
+	tw.run(ctx)
+}
+
+// Stop 停止监控并关闭所有任务通道
+func (tw *TaskWatcher) Stop() {
+	tw.once.Do(tw.cancel)
+
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.stopped = true
+	for taskID, w := range tw.watches {
+		close(w.done)
+		delete(tw.watches, taskID)
+	}
+}
+
+// startLocked 监控协程未运行时启动，调用方持有 tw.mu
+func (tw *TaskWatcher) startLocked(ctx context.Context) {
+	if tw.running || tw.stopped {
+		return
+	}
+	tw.running = true
+	go tw.run(ctx)
+}
+
+// notifyChanged 通知监控协程监控的任务有变化，调用方持有 tw.mu
+func (tw *TaskWatcher) notifyChanged() {
+	select {
+	case tw.changed <- struct{}{}:
+	default:
+	}
+}
+
+// run 监控循环：有任务时建立推送连接，连接断开后按退避间隔重连，服务端不支持推送时轮询
+func (tw *TaskWatcher) run(ctx context.Context) {
+	defer func() {
+		tw.mu.Lock()
+		tw.running = false
+		tw.mu.Unlock()
+	}()
+
+	delay := tw.config.ReconnectDelay
+	var fallbackUntil time.Time
+	for ctx.Err() == nil {
+		if !tw.hasWatches() {
+			select {
+			case <-ctx.Done():
+			case <-tw.changed:
+			}
+			continue
+		}
+		if tw.config.DisableStream || time.Now().Before(fallbackUntil) {
+			tw.poll(ctx, fallbackUntil)
+			continue
+		}
+
+		received, retry, err := tw.stream(ctx)
+		switch {
+		case errors.Is(err, ErrStreamUnsupported):
+			fallbackUntil = time.Now().Add(tw.config.StreamProbeInterval)
+			continue
+		case err == nil:
+			// 监控的任务有变化，立即重连
+			continue
+		case received:
+			delay = tw.config.ReconnectDelay
+		}
+		if retry > 0 {
+			delay = retry
+		}
+		if !sleepContext(ctx, delay) {
+			return
+		}
+		delay = min(delay*2, tw.config.MaxReconnectDelay)
+	}
+}
+
+// stream 建立推送连接并分发事件，直到连接断开或监控的任务有变化
+// received 表示本次连接是否收到过事件，retry 为服务端建议的重连间隔；因任务变化而断开时返回 nil
+func (tw *TaskWatcher) stream(ctx context.Context) (received bool, retry time.Duration, err error) {
+	// 之前的变化已包含在本次连接中
+	select {
+	case <-tw.changed:
+	default:
+	}
+
+	tw.mu.Lock()
+	ids := make([]int64, 0, len(tw.watches))
+	for taskID := range tw.watches {
+		ids = append(ids, taskID)
+	}
+	snapshot := tw.added
+	tw.added = false
+	tw.mu.Unlock()
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s, err := tw.client.StreamTasks(streamCtx, &WatchRequest{TaskIDs: ids, Snapshot: snapshot}, tw.lastEventID)
+	if err != nil {
+		if snapshot {
+			tw.mu.Lock()
+			tw.added = true
+			tw.mu.Unlock()
+		}
+		return false, 0, err
+	}
+	defer s.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-tw.changed:
+			cancel()
+		case <-done:
+		}
+	}()
+
+	for {
+		event, err := s.Next()
+		tw.lastEventID = s.LastEventID()
+		if err != nil {
+			if streamCtx.Err() != nil && ctx.Err() == nil {
+				return received, 0, nil
+			}
+			return received, s.RetryDelay(), err
+		}
+		received = true
+		if event.Task != nil {
+			tw.deliver(event.Task)
+		}
+	}
+}
+
+// poll 轮询监控的任务，直到 ctx 结束或到达 until（为零时一直轮询）；监控的任务有变化时立即查询一次
+func (tw *TaskWatcher) poll(ctx context.Context, until time.Time) {
+	ticker := time.NewTicker(tw.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-tw.changed:
+		}
+		if !tw.hasWatches() {
+			return
+		}
+		tw.checkTasks(ctx)
+		if !until.IsZero() && time.Now().After(until) {
+			return
+		}
+	}
+}
+
+// checkTasks 查询监控任务的当前状态，查询失败的任务等待下一轮
+func (tw *TaskWatcher) checkTasks(ctx context.Context) {
+	tw.mu.Lock()
+	taskIDs := make([]int64, 0, len(tw.watches))
+	for taskID := range tw.watches {
+		taskIDs = append(taskIDs, taskID)
+	}
+	tw.mu.Unlock()
+
+	for _, taskID := range taskIDs {
+		task, err := tw.client.GetTask(ctx, taskID)
+		if err != nil {
+			continue
+		}
+		tw.deliver(task)
+	}
+}
+
+// deliver 将任务状态交给对应的监控
+func (tw *TaskWatcher) deliver(task *Task) {
+	if task.Task == nil {
+		return
+	}
+	tw.mu.Lock()
+	w := tw.watches[task.ID]
+	tw.mu.Unlock()
+	if w != nil {
+		w.push(task)
+	}
+}
+
+// hasWatches 是否有正在监控的任务
+func (tw *TaskWatcher) hasWatches() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return len(tw.watches) > 0
+}
+
+// push 加入待通知队列，与上次通知的状态相同时忽略
+// 队列达到上限时丢弃排队的中间状态，只保留最新状态，消费者长时间不读取也不会占用无限内存
+func (w *taskWatch) push(task *Task) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.last != nil && sameTaskState(w.last, task) {
+		return
+	}
+	w.last = task
+	if w.limit > 0 && len(w.queue) >= w.limit {
+		// forward 正在发送的是 queue[0]，保留它以免发送后被误删
+		w.queue = append(w.queue[:1], task)
+	} else {
+		w.queue = append(w.queue, task)
+	}
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// forward 按顺序把队列中的更新写入 ch，停止监控后关闭 ch
+func (w *taskWatch) forward() {
+	defer close(w.ch)
+	for {
+		w.mu.Lock()
+		if len(w.queue) == 0 {
+			w.mu.Unlock()
+			select {
+			case <-w.notify:
+				continue
+			case <-w.done:
+				return
+			}
+		}
+		task := w.queue[0]
+		w.mu.Unlock()
+
+		select {
+		case w.ch <- task:
+			w.mu.Lock()
+			w.queue = w.queue[1:]
+			w.mu.Unlock()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// sameTaskState 两次查询或推送得到的任务状态是否相同
+func sameTaskState(a, b *Task) bool {
+	return a.Status == b.Status &&
+		a.Version == b.Version &&
+		a.CurrentRetry == b.CurrentRetry &&
+		a.UpdatedAt.Equal(b.UpdatedAt)
+}
+
+// sleepContext 等待 d，ctx 先结束时返回 false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,304 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"task-center/sdk"
+)
+
+// writeSSE 按 SSE 格式写出一个任务事件
+func writeSSE(t *testing.T, w http.ResponseWriter, id, eventType string, task *sdk.Task) {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]interface{}{
+		"task_id":   task.ID,
+		"to_status": task.Status,
+		"at":        time.Now(),
+		"task":      task,
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal event: %v", err)
+	}
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", id, eventType, data)
+	w.(http.Flusher).Flush()
+}
+
+// newWatcherTestConfig 测试用的监控器配置，缩短重连间隔
+func newWatcherTestConfig() *WatcherConfig {
+	config := DefaultWatcherConfig()
+	config.PollInterval = 20 * time.Millisecond
+	config.ReconnectDelay = 10 * time.Millisecond
+	config.MaxReconnectDelay = 50 * time.Millisecond
+	return config
+}
+
+// collectStatuses 从通道读取 n 个任务状态，超时后返回已读取的状态
+func collectStatuses(ch <-chan *Task, n int, timeout time.Duration) []TaskStatus {
+	var statuses []TaskStatus
+	deadline := time.After(timeout)
+	for len(statuses) < n {
+		select {
+		case task, ok := <-ch:
+			if !ok {
+				return statuses
+			}
+			statuses = append(statuses, task.Status)
+		case <-deadline:
+			return statuses
+		}
+	}
+	return statuses
+}
+
+func TestClient_StreamTasks(t *testing.T) {
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/tasks/watch" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("task_id"); got != "1,2" {
+			t.Errorf("Expected task_id=1,2, got %q", got)
+		}
+		if got := r.URL.Query().Get("tags"); got != "billing" {
+			t.Errorf("Expected tags=billing, got %q", got)
+		}
+		if got := r.Header.Get("Last-Event-ID"); got != "5" {
+			t.Errorf("Expected Last-Event-ID 5, got %q", got)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		fmt.Fprint(w, "retry: 2500\n\n: heartbeat\n\n")
+		fmt.Fprint(w, "id: 6\nevent: task.unknown\ndata: {}\n\n")
+		writeSSE(t, w, "7", WatchEventStatusChanged, &sdk.Task{ID: 1, Status: sdk.TaskStatusRunning})
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	defer client.Close()
+
+	stream, err := client.StreamTasks(context.Background(), &WatchRequest{TaskIDs: []int64{1, 2}, Tags: []string{"billing"}}, "5")
+	if err != nil {
+		t.Fatalf("StreamTasks() failed: %v", err)
+	}
+	defer stream.Close()
+
+	event, err := stream.Next()
+	if err != nil {
+		t.Fatalf("Next() failed: %v", err)
+	}
+	if event.ID != "7" || event.Type != WatchEventStatusChanged || event.TaskID != 1 || event.Task.Status != StatusRunning {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if stream.RetryDelay() != 2500*time.Millisecond {
+		t.Errorf("Expected retry delay 2.5s, got %v", stream.RetryDelay())
+	}
+	if _, err := stream.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF after the stream ends, got %v", err)
+	}
+}
+
+func TestClient_StreamTasks_Unsupported(t *testing.T) {
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	defer client.Close()
+
+	if _, err := client.StreamTasks(context.Background(), &WatchRequest{TaskIDs: []int64{1}}, ""); !errors.Is(err, ErrStreamUnsupported) {
+		t.Errorf("Expected ErrStreamUnsupported, got %v", err)
+	}
+}
+
+func TestTaskWatcher_StreamResumesAfterDisconnect(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		lastEventIDs []string
+		polls        int
+	)
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/tasks/watch" {
+			mu.Lock()
+			polls++
+			mu.Unlock()
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		mu.Lock()
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+		connection := len(lastEventIDs)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch connection {
+		case 1:
+			writeSSE(t, w, "10", WatchEventSnapshot, &sdk.Task{ID: 1, Status: sdk.TaskStatusPending, Version: 1})
+			writeSSE(t, w, "11", WatchEventStatusChanged, &sdk.Task{ID: 1, Status: sdk.TaskStatusRunning, Version: 2})
+			// 断开连接
+		default:
+			writeSSE(t, w, "12", WatchEventStatusChanged, &sdk.Task{ID: 1, Status: sdk.TaskStatusSucceeded, Version: 3})
+			<-r.Context().Done()
+		}
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	defer client.Close()
+	watcher := NewTaskWatcherWithConfig(client, newWatcherTestConfig())
+	defer watcher.Stop()
+
+	// 不调用 Start，WatchTask 自动启动监控
+	statuses := collectStatuses(watcher.WatchTask(context.Background(), 1), 3, 2*time.Second)
+	want := []TaskStatus{StatusPending, StatusRunning, StatusSucceeded}
+	if fmt.Sprint(statuses) != fmt.Sprint(want) {
+		t.Fatalf("Expected statuses %v, got %v", want, statuses)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lastEventIDs) < 2 || lastEventIDs[0] != "" || lastEventIDs[1] != "11" {
+		t.Errorf("Expected reconnect to resume from event 11, got Last-Event-IDs %q", lastEventIDs)
+	}
+	if polls != 0 {
+		t.Errorf("Expected no polling while streaming, got %d polls", polls)
+	}
+}
+
+func TestTaskWatcher_DoesNotDropUpdates(t *testing.T) {
+	const updates = 30
+	sent := make(chan struct{})
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 1; i <= updates; i++ {
+			writeSSE(t, w, fmt.Sprint(i), WatchEventStatusChanged, &sdk.Task{ID: 1, Status: sdk.TaskStatusRunning, Version: int64(i)})
+		}
+		close(sent)
+		<-r.Context().Done()
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	defer client.Close()
+	config := newWatcherTestConfig()
+	config.BufferSize = 1
+	config.QueueSize = updates
+	watcher := NewTaskWatcherWithConfig(client, config)
+	defer watcher.Stop()
+
+	ch := watcher.WatchTask(context.Background(), 1)
+	<-sent
+	// 消费者在所有更新进入队列之后才开始读取
+	waitForPushed(t, watcher, 1, updates)
+
+	for i := 1; i <= updates; i++ {
+		select {
+		case task := <-ch:
+			if task.Version != int64(i) {
+				t.Fatalf("Expected version %d, got %d", i, task.Version)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for update %d", i)
+		}
+	}
+}
+
+func TestTaskWatcher_CollapsesQueueOnOverflow(t *testing.T) {
+	const updates = 30
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 1; i <= updates; i++ {
+			writeSSE(t, w, fmt.Sprint(i), WatchEventStatusChanged, &sdk.Task{ID: 1, Status: sdk.TaskStatusRunning, Version: int64(i)})
+		}
+		<-r.Context().Done()
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	defer client.Close()
+	config := newWatcherTestConfig()
+	config.BufferSize = 1
+	config.QueueSize = 5
+	watcher := NewTaskWatcherWithConfig(client, config)
+	defer watcher.Stop()
+
+	ch := watcher.WatchTask(context.Background(), 1)
+	waitForPushed(t, watcher, 1, updates)
+
+	var versions []int64
+	for len(versions) == 0 || versions[len(versions)-1] != updates {
+		select {
+		case task := <-ch:
+			versions = append(versions, task.Version)
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for the latest update, got versions %v", versions)
+		}
+	}
+	// 通道缓冲区、正在发送的更新和队列之外的中间状态被丢弃
+	if len(versions) > config.BufferSize+1+config.QueueSize {
+		t.Errorf("Expected intermediate updates to be collapsed, got versions %v", versions)
+	}
+	for i := 1; i < len(versions); i++ {
+		if versions[i] <= versions[i-1] {
+			t.Fatalf("Expected increasing versions, got %v", versions)
+		}
+	}
+}
+
+// waitForPushed 等待任务的第 version 个更新进入监控器
+func waitForPushed(t *testing.T, watcher *TaskWatcher, taskID, version int64) {
+	t.Helper()
+	watcher.mu.Lock()
+	w := watcher.watches[taskID]
+	watcher.mu.Unlock()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		w.mu.Lock()
+		pushed := w.last != nil && w.last.Version == version
+		w.mu.Unlock()
+		if pushed {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for update %d to be queued", version)
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestTaskWatcher_StopsWhenContextDone(t *testing.T) {
+	server := mockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	defer server.Close()
+
+	client := createTestClient(t, server)
+	defer client.Close()
+	watcher := NewTaskWatcherWithConfig(client, newWatcherTestConfig())
+	defer watcher.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := watcher.WatchTask(ctx, 1)
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("Expected channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Error("Channel was not closed after ctx was cancelled")
+	}
+}
@@ -14,6 +14,7 @@ import (
 // TaskService 任务服务接口
 type TaskService interface {
 	Create(ctx context.Context, req *CreateTaskRequest) (*Task, error)
+	CreateWithOutcome(ctx context.Context, req *CreateTaskRequest) (*Task, CreateOutcome, error)
 	Get(ctx context.Context, taskID int64) (*Task, error)
 	GetByBusinessUniqueID(ctx context.Context, businessUniqueID string) (*Task, error)
 	Update(ctx context.Context, taskID int64, req *UpdateTaskRequest) (*Task, error)
@@ -23,6 +24,7 @@ type TaskService interface {
 	BatchCreate(ctx context.Context, req *BatchCreateTasksRequest) (*BatchCreateTasksResponse, error)
 	Cancel(ctx context.Context, taskID int64) error
 	Retry(ctx context.Context, taskID int64) error
+	Events(ctx context.Context, taskID int64) ([]TaskEvent, error)
 }
 
 // taskService 任务服务实现
@@ -35,38 +37,57 @@ func newTaskService(client *Client) TaskService {
 	return &taskService{client: client}
 }
 
+// CreateOutcomeHeader 服务端通过该响应头返回创建结果
+const CreateOutcomeHeader = "X-TaskCenter-Create-Outcome"
+
 // Create 创建任务
+// business_unique_id 已存在时按 req.ConflictPolicy 处理，reject 策略下返回的冲突错误可通过 ConflictingTask 取得已存在的任务
 func (s *taskService) Create(ctx context.Context, req *CreateTaskRequest) (*Task, error) {
+	task, _, err := s.CreateWithOutcome(ctx, req)
+	return task, err
+}
+
+// CreateWithOutcome 创建任务并返回创建结果（新建、返回已存在或替换）
+func (s *taskService) CreateWithOutcome(ctx context.Context, req *CreateTaskRequest) (*Task, CreateOutcome, error) {
 	if err := req.Validate(); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	resp, err := s.client.doRequest(ctx, "POST", "/api/v1/tasks", req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		return nil, s.handleErrorResponse(resp)
+	// 新建返回201，return_existing 和 replace_if_pending 命中已存在的任务时返回200
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return nil, "", s.handleErrorResponse(resp)
+	}
+
+	outcome := CreateOutcome(resp.Header.Get(CreateOutcomeHeader))
+	if outcome == "" {
+		outcome = CreateOutcomeCreated
+		if resp.StatusCode == http.StatusOK {
+			outcome = CreateOutcomeExisting
+		}
 	}
 
 	var apiResp ApiResponse
 	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	taskData, err := json.Marshal(apiResp.Data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal task data: %w", err)
+		return nil, "", fmt.Errorf("failed to marshal task data: %w", err)
 	}
 
 	var task Task
 	if err := json.Unmarshal(taskData, &task); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal task: %w", err)
+		return nil, "", fmt.Errorf("failed to unmarshal task: %w", err)
 	}
 
-	return &task, nil
+	return &task, outcome, nil
 }
 
 // Get 根据ID获取任务
@@ -140,7 +161,15 @@ func (s *taskService) GetByBusinessUniqueID(ctx context.Context, businessUniqueI
 }
 
 // Update 更新任务
+// 设置 req.Version 时只在任务未被修改的情况下更新；版本已过期或状态变更不被允许时返回冲突错误，
+// 可通过 ConflictingTask 取得任务的当前状态后重试
 func (s *taskService) Update(ctx context.Context, taskID int64, req *UpdateTaskRequest) (*Task, error) {
+	if req != nil {
+		if err := ValidateTags(req.Tags); err != nil {
+			return nil, err
+		}
+	}
+
 	path := fmt.Sprintf("/api/v1/tasks/%d", taskID)
 	resp, err := s.client.doRequest(ctx, "PUT", path, req)
 	if err != nil {
@@ -199,6 +228,9 @@ func (s *taskService) List(ctx context.Context, req *ListTasksRequest) (*ListTas
 	if req == nil {
 		req = &ListTasksRequest{}
 	}
+	if !req.TagMatch.IsValid() {
+		return nil, NewValidationError("invalid tag_match: " + string(req.TagMatch))
+	}
 
 	// 设置默认分页参数
 	if req.Page <= 0 {
@@ -210,8 +242,12 @@ func (s *taskService) List(ctx context.Context, req *ListTasksRequest) (*ListTas
 
 	// 构建查询参数
 	params := url.Values{}
-	params.Set("page", strconv.Itoa(req.Page))
 	params.Set("page_size", strconv.Itoa(req.PageSize))
+	if req.UsesCursor() {
+		req.EncodeCursorParams(params)
+	} else {
+		params.Set("page", strconv.Itoa(req.Page))
+	}
 
 	if len(req.Status) > 0 {
 		statuses := make([]string, len(req.Status))
@@ -223,6 +259,9 @@ func (s *taskService) List(ctx context.Context, req *ListTasksRequest) (*ListTas
 
 	if len(req.Tags) > 0 {
 		params.Set("tags", strings.Join(req.Tags, ","))
+		if req.TagMatch != "" {
+			params.Set("tag_match", string(req.TagMatch))
+		}
 	}
 
 	if req.Priority != nil {
@@ -270,6 +309,21 @@ func (s *taskService) List(ctx context.Context, req *ListTasksRequest) (*ListTas
 	return &listResp, nil
 }
 
+// EncodeCursorParams 将游标分页参数写入查询参数
+func (req *ListTasksRequest) EncodeCursorParams(params url.Values) {
+	sortBy := req.SortBy
+	if sortBy == "" {
+		sortBy = ListSortByCreatedAt
+	}
+	params.Set("sort_by", string(sortBy))
+	if req.Descending {
+		params.Set("order", "desc")
+	}
+	if req.Cursor != "" {
+		params.Set("cursor", req.Cursor)
+	}
+}
+
 // Stats 获取任务统计信息
 func (s *taskService) Stats(ctx context.Context) (*TaskStatsResponse, error) {
 	resp, err := s.client.doRequest(ctx, "GET", "/api/v1/tasks/stats", nil)
@@ -306,9 +360,13 @@ func (s *taskService) BatchCreate(ctx context.Context, req *BatchCreateTasksRequ
 		return nil, NewValidationError("at least one task is required")
 	}
 
+	if !req.ConflictPolicy.IsValid() {
+		return nil, NewValidationError("invalid conflict_policy: " + string(req.ConflictPolicy))
+	}
+
 	// 验证所有任务
-	for i, task := range req.Tasks {
-		if err := task.Validate(); err != nil {
+	for i := range req.Tasks {
+		if err := req.Tasks[i].Validate(); err != nil {
 			return nil, NewValidationErrorWithDetails(
 				fmt.Sprintf("validation failed for task at index %d", i),
 				err.Error(),
@@ -384,6 +442,41 @@ func (s *taskService) Retry(ctx context.Context, taskID int64) error {
 	return nil
 }
 
+// Events 获取任务的状态变更时间线，按发生顺序排列
+func (s *taskService) Events(ctx context.Context, taskID int64) ([]TaskEvent, error) {
+	path := fmt.Sprintf("/api/v1/tasks/%d/events", taskID)
+	resp, err := s.client.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, NewNotFoundError("task")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, s.handleErrorResponse(resp)
+	}
+
+	var apiResp ApiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	eventsData, err := json.Marshal(apiResp.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal events data: %w", err)
+	}
+
+	var events []TaskEvent
+	if err := json.Unmarshal(eventsData, &events); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal events: %w", err)
+	}
+
+	return events, nil
+}
+
 // handleErrorResponse 处理错误响应
 func (s *taskService) handleErrorResponse(resp *http.Response) error {
 	body, err := io.ReadAll(resp.Body)
@@ -0,0 +1,236 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// writeJSON 输出JSON响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestTaskService_CreateWithConflictPolicy(t *testing.T) {
+	existing := &Task{ID: 7, BusinessUniqueID: "order-1", CallbackURL: "https://example.com/old", Status: TaskStatusRunning}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CreateTaskRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+
+		switch req.ConflictPolicy {
+		case ConflictPolicyReturnExisting:
+			writeJSON(w, http.StatusOK, ApiResponse{Success: true, Data: existing})
+		case ConflictPolicyReplaceIfPending:
+			w.Header().Set(CreateOutcomeHeader, string(CreateOutcomeReplaced))
+			writeJSON(w, http.StatusOK, ApiResponse{Success: true, Data: &Task{ID: 7, BusinessUniqueID: "order-1", CallbackURL: req.CallbackURL}})
+		case ConflictPolicyReject:
+			writeJSON(w, http.StatusConflict, ErrorResponse{Message: "task already exists", Code: CodeConflictError, Details: existing})
+		default:
+			writeJSON(w, http.StatusCreated, ApiResponse{Success: true, Data: &Task{ID: 8, BusinessUniqueID: req.BusinessUniqueID}})
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithDefaults(server.URL, "test-key", 123)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	tasks := client.Tasks()
+
+	tests := []struct {
+		name            string
+		policy          ConflictPolicy
+		expectedID      int64
+		expectedOutcome CreateOutcome
+	}{
+		{"no conflict", "", 8, CreateOutcomeCreated},
+		{"return existing", ConflictPolicyReturnExisting, 7, CreateOutcomeExisting},
+		{"replace if pending", ConflictPolicyReplaceIfPending, 7, CreateOutcomeReplaced},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := NewTask("order-1", "https://example.com/new").WithConflictPolicy(tt.policy)
+			task, outcome, err := tasks.CreateWithOutcome(ctx, req)
+			if err != nil {
+				t.Fatalf("CreateWithOutcome() failed: %v", err)
+			}
+			if task.ID != tt.expectedID {
+				t.Errorf("Expected task id %d, got %d", tt.expectedID, task.ID)
+			}
+			if outcome != tt.expectedOutcome {
+				t.Errorf("Expected outcome %s, got %s", tt.expectedOutcome, outcome)
+			}
+		})
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		_, err := tasks.Create(ctx, NewTask("order-1", "https://example.com/new").WithConflictPolicy(ConflictPolicyReject))
+		if !IsConflictError(err) {
+			t.Fatalf("Expected conflict error, got %v", err)
+		}
+		task, ok := ConflictingTask(err)
+		if !ok || task.ID != existing.ID || task.Status != TaskStatusRunning {
+			t.Errorf("Expected existing task in conflict details, got %+v", task)
+		}
+	})
+}
+
+func TestTaskService_BatchCreateConflictPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req BatchCreateTasksRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+		if req.ConflictPolicy != ConflictPolicyReturnExisting {
+			t.Errorf("Expected batch conflict policy return_existing, got %s", req.ConflictPolicy)
+		}
+
+		writeJSON(w, http.StatusOK, ApiResponse{Success: true, Data: BatchCreateTasksResponse{
+			Succeeded: []Task{{ID: 1, BusinessUniqueID: "a"}, {ID: 2, BusinessUniqueID: "b"}},
+			Failed:    []BatchTaskError{{Index: 2, Error: "task already exists", Code: CodeConflictError}},
+			Results: []BatchCreateResult{
+				{Index: 0, Outcome: CreateOutcomeCreated, Task: &Task{ID: 1}},
+				{Index: 1, Outcome: CreateOutcomeExisting, Task: &Task{ID: 2}},
+				{Index: 2, Outcome: CreateOutcomeRejected, Task: &Task{ID: 3}, Error: "task already exists", Code: CodeConflictError},
+			},
+		}})
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithDefaults(server.URL, "test-key", 123)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	resp, err := client.Tasks().BatchCreate(context.Background(), &BatchCreateTasksRequest{
+		ConflictPolicy: ConflictPolicyReturnExisting,
+		Tasks: []CreateTaskRequest{
+			*NewTask("a", "https://example.com"),
+			*NewTask("b", "https://example.com"),
+			*NewTask("c", "https://example.com").WithConflictPolicy(ConflictPolicyReject),
+		},
+	})
+	if err != nil {
+		t.Fatalf("BatchCreate() failed: %v", err)
+	}
+
+	if len(resp.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(resp.Results))
+	}
+	if resp.Results[1].Outcome != CreateOutcomeExisting {
+		t.Errorf("Expected outcome existing for index 1, got %s", resp.Results[1].Outcome)
+	}
+	if resp.Results[2].Outcome != CreateOutcomeRejected || resp.Results[2].Task.ID != 3 {
+		t.Errorf("Expected rejected with existing task for index 2, got %+v", resp.Results[2])
+	}
+}
+
+func TestTaskService_BatchCreateInvalidConflictPolicy(t *testing.T) {
+	client, err := NewClientWithDefaults("http://localhost", "test-key", 123)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	_, err = client.Tasks().BatchCreate(context.Background(), &BatchCreateTasksRequest{
+		ConflictPolicy: "overwrite",
+		Tasks:          []CreateTaskRequest{*NewTask("a", "https://example.com")},
+	})
+	if !IsValidationError(err) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
+
+func TestTaskService_UpdateVersionConflict(t *testing.T) {
+	current := &Task{ID: 7, BusinessUniqueID: "order-1", Status: TaskStatusSucceeded, Version: 4}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req UpdateTaskRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+		if req.Version == nil || *req.Version != current.Version {
+			writeJSON(w, http.StatusConflict, ErrorResponse{Message: "task has been modified", Code: CodeConflictError, Details: current})
+			return
+		}
+		writeJSON(w, http.StatusOK, ApiResponse{Success: true, Data: &Task{ID: 7, Status: current.Status, Version: current.Version + 1}})
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithDefaults(server.URL, "test-key", 123)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	stale := int64(3)
+	_, err = client.Tasks().Update(ctx, 7, &UpdateTaskRequest{Version: &stale})
+	if !IsConflictError(err) {
+		t.Fatalf("Expected conflict error, got %v", err)
+	}
+	task, ok := ConflictingTask(err)
+	if !ok || task.Version != current.Version || task.Status != TaskStatusSucceeded {
+		t.Fatalf("Expected current task in conflict details, got %+v", task)
+	}
+
+	updated, err := client.Tasks().Update(ctx, 7, &UpdateTaskRequest{Version: &task.Version})
+	if err != nil {
+		t.Fatalf("Update() with current version failed: %v", err)
+	}
+	if updated.Version != current.Version+1 {
+		t.Errorf("Expected version %d, got %d", current.Version+1, updated.Version)
+	}
+}
+
+func TestTaskService_Events(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/tasks/7/events":
+			writeJSON(w, http.StatusOK, ApiResponse{Success: true, Data: []TaskEvent{
+				{ID: 1, TaskID: 7, FromStatus: TaskStatusPending, ToStatus: TaskStatusRunning, Actor: "executor"},
+				{ID: 2, TaskID: 7, FromStatus: TaskStatusRunning, ToStatus: TaskStatusSucceeded, Actor: "executor", Reason: "http 200"},
+			}})
+		default:
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Message: "task not found", Code: CodeNotFoundError})
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithDefaults(server.URL, "test-key", 123)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	events, err := client.Tasks().Events(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("Events() failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if events[0].ToStatus != TaskStatusRunning || events[1].FromStatus != TaskStatusRunning ||
+		events[1].ToStatus != TaskStatusSucceeded || events[1].Reason != "http 200" {
+		t.Errorf("Unexpected timeline: %+v", events)
+	}
+
+	if _, err := client.Tasks().Events(context.Background(), 8); !IsNotFoundError(err) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+package tasktest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"task-center/sdk"
+)
+
+// CallbackOutcome 模拟的回调结果
+type CallbackOutcome struct {
+	StatusCode int    // 回调地址返回的 HTTP 状态码，2xx 为成功
+	Error      string // 请求没有得到响应的原因，如超时或连接被拒绝；设置时忽略 StatusCode
+}
+
+// 常用的回调结果
+var (
+	CallbackSucceeded = CallbackOutcome{StatusCode: http.StatusOK}
+	CallbackFailed    = CallbackOutcome{StatusCode: http.StatusInternalServerError}
+	CallbackTimedOut  = CallbackOutcome{Error: "callback timed out"}
+)
+
+// succeeded 回调是否成功
+func (o CallbackOutcome) succeeded() bool {
+	return o.Error == "" && o.StatusCode >= 200 && o.StatusCode < 300
+}
+
+// reason 记录在状态变更事件和 error_message 中的原因
+func (o CallbackOutcome) reason() string {
+	if o.Error != "" {
+		return o.Error
+	}
+	return fmt.Sprintf("http %d", o.StatusCode)
+}
+
+// CallbackHandler 决定任务回调的结果，task 为执行时的任务状态
+// 在模拟服务端的锁外调用，可以使用 SDK 访问模拟服务端
+type CallbackHandler func(task *sdk.Task) CallbackOutcome
+
+// CallbackAttempt 一次模拟的回调
+type CallbackAttempt struct {
+	TaskID           int64
+	BusinessUniqueID string
+	Attempt          int // 第几次执行，从 1 开始
+	At               time.Time
+	Outcome          CallbackOutcome
+}
+
+// SetCallbackOutcome 指定业务唯一ID为 businessUniqueID 的任务之后各次回调的结果
+// 按顺序使用，用完后重复最后一个；不传结果时清除指定，恢复使用 SetCallbackHandler 或默认成功
+func (s *Server) SetCallbackOutcome(businessUniqueID string, outcomes ...CallbackOutcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(outcomes) == 0 {
+		delete(s.outcomes, businessUniqueID)
+		return
+	}
+	s.outcomes[businessUniqueID] = append([]CallbackOutcome(nil), outcomes...)
+}
+
+// SetCallbackHandler 设置未通过 SetCallbackOutcome 指定结果的任务的回调处理，为 nil 时回调总是成功
+func (s *Server) SetCallbackHandler(handler CallbackHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = handler
+}
+
+// Callbacks 按执行顺序返回所有模拟的回调
+func (s *Server) Callbacks() []CallbackAttempt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]CallbackAttempt(nil), s.callbacks...)
+}
+
+// Advance 推进虚拟时钟 d，期间到期的任务按到期时间依次执行，返回执行的回调次数
+// 失败的任务按重试间隔重新排期，重试时间在 d 之内时同样会被执行
+func (s *Server) Advance(d time.Duration) int {
+	s.mu.Lock()
+	target := s.now.Add(max(d, 0))
+	s.mu.Unlock()
+
+	executed := 0
+	for {
+		s.mu.Lock()
+		next, ok := s.nextDueLocked()
+		if !ok || next.After(target) {
+			s.now = target
+			s.mu.Unlock()
+			return executed
+		}
+		if next.After(s.now) {
+			s.now = next
+		}
+		s.mu.Unlock()
+		executed += s.RunDue()
+	}
+}
+
+// RunDue 执行当前已到期的待执行任务，不推进时钟，返回执行的回调次数
+// 按优先级、执行时间和ID的顺序执行，与服务端的调度顺序一致
+func (s *Server) RunDue() int {
+	s.mu.Lock()
+	var due []*taskRecord
+	for _, rec := range s.tasks {
+		if rec.task.Status == sdk.TaskStatusPending && !dueTime(&rec.task).After(s.now) {
+			due = append(due, rec)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		a, b := &due[i].task, &due[j].task
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if at, bt := dueTime(a), dueTime(b); !at.Equal(bt) {
+			return at.Before(bt)
+		}
+		return a.ID < b.ID
+	})
+	ids := make([]int64, len(due))
+	for i, rec := range due {
+		ids[i] = rec.task.ID
+	}
+	s.mu.Unlock()
+
+	executed := 0
+	for _, id := range ids {
+		if s.execute(id) {
+			executed++
+		}
+	}
+	return executed
+}
+
+// nextDueLocked 返回最早到期的待执行任务的执行时间
+func (s *Server) nextDueLocked() (time.Time, bool) {
+	var (
+		next  time.Time
+		found bool
+	)
+	for _, rec := range s.tasks {
+		if rec.task.Status != sdk.TaskStatusPending {
+			continue
+		}
+		if at := dueTime(&rec.task); !found || at.Before(next) {
+			next, found = at, true
+		}
+	}
+	return next, found
+}
+
+// dueTime 任务的执行时间，next_execute_at 未设置时为 scheduled_at
+func dueTime(t *sdk.Task) time.Time {
+	if t.NextExecuteAt != nil {
+		return *t.NextExecuteAt
+	}
+	return t.ScheduledAt
+}
+
+// execute 执行一次任务回调：pending → running，再按回调结果变为 succeeded、重新排期或 failed
+func (s *Server) execute(taskID int64) bool {
+	s.mu.Lock()
+	rec, ok := s.tasks[taskID]
+	if !ok || rec.task.Status != sdk.TaskStatusPending || dueTime(&rec.task).After(s.now) {
+		// 等待期间任务已被取消、删除或改期
+		s.mu.Unlock()
+		return false
+	}
+	s.transitionLocked(rec, sdk.TaskStatusRunning, actorExecutor, "")
+	rec.task.Version++
+	s.saveLocked(rec)
+	task := cloneTask(&rec.task)
+	outcome, forced := s.forcedOutcomeLocked(task.BusinessUniqueID)
+	handler := s.handler
+	s.mu.Unlock()
+
+	if !forced {
+		outcome = CallbackSucceeded
+		if handler != nil {
+			outcome = handler(task)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.callbacks = append(s.callbacks, CallbackAttempt{
+		TaskID:           task.ID,
+		BusinessUniqueID: task.BusinessUniqueID,
+		Attempt:          task.CurrentRetry + 1,
+		At:               s.now,
+		Outcome:          outcome,
+	})
+	if rec, ok = s.tasks[taskID]; !ok || rec.task.Status != sdk.TaskStatusRunning {
+		return true
+	}
+
+	t := &rec.task
+	switch {
+	case outcome.succeeded():
+		t.ErrorMessage = ""
+		s.transitionLocked(rec, sdk.TaskStatusSucceeded, actorExecutor, outcome.reason())
+	case t.CurrentRetry < t.MaxRetries:
+		t.CurrentRetry++
+		t.ErrorMessage = outcome.reason()
+		s.transitionLocked(rec, sdk.TaskStatusPending, actorExecutor,
+			fmt.Sprintf("retry %d/%d: %s", t.CurrentRetry, t.MaxRetries, outcome.reason()))
+		next := s.now.Add(s.retryDelay(t))
+		t.NextExecuteAt = &next
+	default:
+		t.ErrorMessage = outcome.reason()
+		s.transitionLocked(rec, sdk.TaskStatusFailed, actorExecutor, outcome.reason())
+	}
+	t.Version++
+	s.saveLocked(rec)
+	return true
+}
+
+// forcedOutcomeLocked 取出 SetCallbackOutcome 指定的下一个结果，最后一个结果一直保留
+func (s *Server) forcedOutcomeLocked(businessUniqueID string) (CallbackOutcome, bool) {
+	outcomes := s.outcomes[businessUniqueID]
+	if len(outcomes) == 0 {
+		return CallbackOutcome{}, false
+	}
+	if len(outcomes) > 1 {
+		s.outcomes[businessUniqueID] = outcomes[1:]
+	}
+	return outcomes[0], true
+}
+
+// retryDelay 第 CurrentRetry 次重试前的等待时间，超出重试间隔列表时使用最后一个间隔，至少一秒
+func (s *Server) retryDelay(t *sdk.Task) time.Duration {
+	intervals := t.RetryIntervals
+	if len(intervals) == 0 {
+		intervals = s.config.DefaultRetryIntervals
+	}
+	seconds := intervals[min(t.CurrentRetry, len(intervals))-1]
+	return time.Duration(max(seconds, 1)) * time.Second
+}
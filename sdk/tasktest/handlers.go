@@ -0,0 +1,613 @@
+package tasktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"task-center/pkg/search"
+	"task-center/sdk"
+)
+
+// tasksPrefix 任务接口的路径前缀
+const tasksPrefix = "/api/v1/tasks"
+
+// route 按路径和方法分发请求
+func (s *Server) route(w http.ResponseWriter, r *http.Request, businessID int64) {
+	rest, ok := strings.CutPrefix(r.URL.EscapedPath(), tasksPrefix)
+	if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+		writeError(w, errNotFound("route"))
+		return
+	}
+	var segments []string
+	if rest = strings.Trim(rest, "/"); rest != "" {
+		segments = strings.Split(rest, "/")
+	}
+
+	// handle 方法匹配时调用 fn，否则返回 405
+	handle := func(method string, fn func(w http.ResponseWriter, r *http.Request, businessID int64)) bool {
+		if r.Method != method {
+			return false
+		}
+		fn(w, r, businessID)
+		return true
+	}
+
+	var handled bool
+	switch {
+	case len(segments) == 0:
+		handled = handle(http.MethodGet, s.handleList) || handle(http.MethodPost, s.handleCreate)
+	case segments[0] == "batch" && len(segments) == 1:
+		handled = handle(http.MethodPost, s.handleBatchCreate) || handle(http.MethodPut, s.handleBatchUpdate) ||
+			handle(http.MethodDelete, s.handleBatchDelete)
+	case segments[0] == "batch" && len(segments) == 2 && segments[1] == "cancel":
+		handled = handle(http.MethodPost, s.handleBatchCancel)
+	case segments[0] == "batch" && len(segments) == 2 && segments[1] == "retry":
+		handled = handle(http.MethodPost, s.handleBatchRetry)
+	case segments[0] == "search" && len(segments) == 1:
+		handled = handle(http.MethodGet, s.handleSearch)
+	case segments[0] == "stats" && len(segments) == 1:
+		handled = handle(http.MethodGet, s.handleStats)
+	case segments[0] == "watch" && len(segments) == 1:
+		handled = handle(http.MethodGet, s.handleWatch)
+	case segments[0] == "reschedule" && len(segments) == 1:
+		handled = handle(http.MethodPost, s.handleBatchReschedule)
+	case segments[0] == "bulk-jobs":
+		handled = s.routeBulkJobs(w, r, businessID, segments[1:])
+	case segments[0] == "business" || segments[0] == "by-business-id":
+		handled = s.routeBusinessID(w, r, businessID, segments)
+	default:
+		taskID, err := strconv.ParseInt(segments[0], 10, 64)
+		if err != nil || taskID <= 0 {
+			writeError(w, errValidation("invalid task id: %s", segments[0]))
+			return
+		}
+		handled = s.routeTask(w, r, businessID, taskID, segments[1:])
+	}
+	if !handled {
+		writeError(w, &apiError{statusCode: http.StatusMethodNotAllowed, code: sdk.CodeValidationError, message: "method not allowed"})
+	}
+}
+
+// routeTask 分发 /api/v1/tasks/{id} 下的请求
+func (s *Server) routeTask(w http.ResponseWriter, r *http.Request, businessID, taskID int64, segments []string) bool {
+	action := strings.Join(segments, "/")
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		s.withTask(w, businessID, taskID, func(rec *taskRecord) {
+			writeData(w, http.StatusOK, cloneTask(&rec.task))
+		})
+	case action == "" && r.Method == http.MethodPut:
+		var req sdk.UpdateTaskRequest
+		if !decodeBody(w, r, &req) {
+			return true
+		}
+		s.withTask(w, businessID, taskID, func(rec *taskRecord) {
+			if err := s.updateLocked(rec, &req); err != nil {
+				writeError(w, err)
+				return
+			}
+			writeData(w, http.StatusOK, cloneTask(&rec.task))
+		})
+	case action == "" && r.Method == http.MethodDelete:
+		s.withTask(w, businessID, taskID, func(rec *taskRecord) {
+			if err := s.deleteLocked(rec); err != nil {
+				writeError(w, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	case action == "cancel" && r.Method == http.MethodPost:
+		s.withTask(w, businessID, taskID, func(rec *taskRecord) {
+			if err := s.cancelLocked(rec, actorAPI, ""); err != nil {
+				writeError(w, err)
+				return
+			}
+			writeData(w, http.StatusOK, cloneTask(&rec.task))
+		})
+	case action == "retry" && r.Method == http.MethodPost:
+		s.withTask(w, businessID, taskID, func(rec *taskRecord) {
+			if err := s.retryLocked(rec, actorAPI, ""); err != nil {
+				writeError(w, err)
+				return
+			}
+			writeData(w, http.StatusOK, cloneTask(&rec.task))
+		})
+	case action == "events" && r.Method == http.MethodGet:
+		s.withTask(w, businessID, taskID, func(rec *taskRecord) {
+			writeData(w, http.StatusOK, s.taskEventsLocked(taskID))
+		})
+	case action == "history" && r.Method == http.MethodGet:
+		// 历史接口直接返回任务数组，不使用 ApiResponse 包装
+		s.withTask(w, businessID, taskID, func(rec *taskRecord) {
+			writeJSON(w, http.StatusOK, rec.history)
+		})
+	case action == "exists" && r.Method == http.MethodHead:
+		s.withTask(w, businessID, taskID, func(rec *taskRecord) {
+			w.WriteHeader(http.StatusOK)
+		})
+	case action == "reschedule" && r.Method == http.MethodPost:
+		var req sdk.RescheduleRequest
+		if !decodeBody(w, r, &req) {
+			return true
+		}
+		if err := req.Validate(); err != nil {
+			writeError(w, errValidation("%s", err.Error()))
+			return true
+		}
+		s.withTask(w, businessID, taskID, func(rec *taskRecord) {
+			writeData(w, http.StatusOK, s.rescheduleLocked(rec, &req))
+		})
+	case action == "" || action == "cancel" || action == "retry" || action == "events" || action == "history" ||
+		action == "exists" || action == "reschedule":
+		return false
+	default:
+		writeError(w, errNotFound("route"))
+	}
+	return true
+}
+
+// routeBusinessID 分发按业务唯一ID查询的请求，sdk 使用 /by-business-id/{id}，sdk/task 使用 /business/{id}
+func (s *Server) routeBusinessID(w http.ResponseWriter, r *http.Request, businessID int64, segments []string) bool {
+	if len(segments) < 2 || len(segments) > 3 || (len(segments) == 3 && segments[2] != "exists") {
+		writeError(w, errNotFound("route"))
+		return true
+	}
+	unescape := url.PathUnescape
+	if segments[0] == "by-business-id" {
+		unescape = url.QueryUnescape
+	}
+	businessUniqueID, err := unescape(segments[1])
+	if err != nil {
+		writeError(w, errValidation("invalid business_unique_id: %s", segments[1]))
+		return true
+	}
+
+	method := http.MethodGet
+	if len(segments) == 3 {
+		method = http.MethodHead
+	}
+	if r.Method != method {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, apiErr := s.findByUniqueLocked(businessID, businessUniqueID)
+	switch {
+	case apiErr != nil:
+		writeError(w, apiErr)
+	case method == http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeData(w, http.StatusOK, cloneTask(&rec.task))
+	}
+	return true
+}
+
+// withTask 持有锁查询任务后调用 fn，任务不存在时返回 404
+func (s *Server) withTask(w http.ResponseWriter, businessID, taskID int64, fn func(rec *taskRecord)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, err := s.findLocked(businessID, taskID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	fn(rec)
+}
+
+// decodeBody 解析 JSON 请求体，失败时写出参数错误并返回 false
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, errValidation("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// handleCreate 处理 POST /api/v1/tasks，新建返回 201，命中已存在的任务返回 200，结果写在 X-TaskCenter-Create-Outcome 中
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request, businessID int64) {
+	var req sdk.CreateTaskRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, outcome, err := s.createLocked(businessID, &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set(sdk.CreateOutcomeHeader, string(outcome))
+	statusCode := http.StatusOK
+	if outcome == sdk.CreateOutcomeCreated {
+		statusCode = http.StatusCreated
+	}
+	writeData(w, statusCode, cloneTask(&rec.task))
+}
+
+// handleList 处理 GET /api/v1/tasks
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request, businessID int64) {
+	q, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.writeList(w, businessID, q)
+}
+
+// handleSearch 处理 GET /api/v1/tasks/search?q=，搜索条件可与列表过滤和分页参数组合
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request, businessID int64) {
+	text := r.URL.Query().Get("q")
+	if strings.TrimSpace(text) == "" {
+		writeError(w, errValidation("search query cannot be empty"))
+		return
+	}
+	query, parseErr := search.Parse(text)
+	if parseErr != nil {
+		writeError(w, errValidation("%s", parseErr.Error()))
+		return
+	}
+	q, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	q.search = query
+	s.writeList(w, businessID, q)
+}
+
+// writeList 查询并写出任务列表
+func (s *Server) writeList(w http.ResponseWriter, businessID int64, q *listQuery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp, err := s.listLocked(businessID, q)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, resp)
+}
+
+// handleStats 处理 GET /api/v1/tasks/stats
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request, businessID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeData(w, http.StatusOK, s.statsLocked(businessID))
+}
+
+// handleBatchCreate 处理 POST /api/v1/tasks/batch，逐项创建，单个任务的失败不影响其他任务
+func (s *Server) handleBatchCreate(w http.ResponseWriter, r *http.Request, businessID int64) {
+	var req sdk.BatchCreateTasksRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if len(req.Tasks) == 0 {
+		writeError(w, errValidation("at least one task is required"))
+		return
+	}
+	if !req.ConflictPolicy.IsValid() {
+		writeError(w, errValidation("invalid conflict_policy: %s", req.ConflictPolicy))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &sdk.BatchCreateTasksResponse{Succeeded: []sdk.Task{}, Failed: []sdk.BatchTaskError{}}
+	for i := range req.Tasks {
+		item := req.Tasks[i]
+		if item.ConflictPolicy == "" {
+			item.ConflictPolicy = req.ConflictPolicy
+		}
+		rec, outcome, err := s.createLocked(businessID, &item)
+		result := sdk.BatchCreateResult{Index: i, Outcome: outcome}
+		if rec != nil {
+			result.Task = cloneTask(&rec.task)
+		}
+		if err != nil {
+			result.Error, result.Code = err.message, err.code
+			resp.Failed = append(resp.Failed, sdk.BatchTaskError{Index: i, Error: err.message, Code: err.code, Request: req.Tasks[i]})
+		} else {
+			resp.Succeeded = append(resp.Succeeded, *cloneTask(&rec.task))
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	writeData(w, http.StatusOK, resp)
+}
+
+// batchIDsRequest 按任务ID批量操作的请求体
+type batchIDsRequest struct {
+	TaskIDs []int64 `json:"task_ids"`
+}
+
+// batchTasksResponse 批量更新、取消和重试的响应，不使用 ApiResponse 包装
+type batchTasksResponse struct {
+	Succeeded []*sdk.Task           `json:"succeeded"`
+	Failed    []*sdk.BatchTaskError `json:"failed"`
+}
+
+// batchError 批量操作中单个任务的错误
+func batchError(index int, err *apiError) *sdk.BatchTaskError {
+	return &sdk.BatchTaskError{Index: index, Error: err.message, Code: err.code}
+}
+
+// handleBatchUpdate 处理 PUT /api/v1/tasks/batch
+func (s *Server) handleBatchUpdate(w http.ResponseWriter, r *http.Request, businessID int64) {
+	var req struct {
+		Updates []struct {
+			TaskID  int64                  `json:"task_id"`
+			Request *sdk.UpdateTaskRequest `json:"request"`
+		} `json:"updates"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if len(req.Updates) == 0 {
+		writeError(w, errValidation("batch update must contain at least one item"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &batchTasksResponse{Succeeded: []*sdk.Task{}, Failed: []*sdk.BatchTaskError{}}
+	for i, item := range req.Updates {
+		if item.Request == nil {
+			resp.Failed = append(resp.Failed, batchError(i, errValidation("request is required")))
+			continue
+		}
+		rec, err := s.findLocked(businessID, item.TaskID)
+		if err == nil {
+			err = s.updateLocked(rec, item.Request)
+		}
+		if err != nil {
+			resp.Failed = append(resp.Failed, batchError(i, err))
+			continue
+		}
+		resp.Succeeded = append(resp.Succeeded, cloneTask(&rec.task))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleBatchCancel 处理 POST /api/v1/tasks/batch/cancel
+func (s *Server) handleBatchCancel(w http.ResponseWriter, r *http.Request, businessID int64) {
+	s.batchTasks(w, r, businessID, func(rec *taskRecord) *apiError {
+		return s.cancelLocked(rec, actorAPI, "")
+	})
+}
+
+// handleBatchRetry 处理 POST /api/v1/tasks/batch/retry
+func (s *Server) handleBatchRetry(w http.ResponseWriter, r *http.Request, businessID int64) {
+	s.batchTasks(w, r, businessID, func(rec *taskRecord) *apiError {
+		return s.retryLocked(rec, actorAPI, "")
+	})
+}
+
+// batchTasks 对每个任务执行 fn，写出成功和失败的任务
+func (s *Server) batchTasks(w http.ResponseWriter, r *http.Request, businessID int64, fn func(rec *taskRecord) *apiError) {
+	var req batchIDsRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if len(req.TaskIDs) == 0 {
+		writeError(w, errValidation("task IDs cannot be empty"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &batchTasksResponse{Succeeded: []*sdk.Task{}, Failed: []*sdk.BatchTaskError{}}
+	for i, id := range req.TaskIDs {
+		rec, err := s.findLocked(businessID, id)
+		if err == nil {
+			err = fn(rec)
+		}
+		if err != nil {
+			resp.Failed = append(resp.Failed, batchError(i, err))
+			continue
+		}
+		resp.Succeeded = append(resp.Succeeded, cloneTask(&rec.task))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleBatchDelete 处理 DELETE /api/v1/tasks/batch
+func (s *Server) handleBatchDelete(w http.ResponseWriter, r *http.Request, businessID int64) {
+	var req batchIDsRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if len(req.TaskIDs) == 0 {
+		writeError(w, errValidation("task IDs cannot be empty"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := struct {
+		Succeeded []int64               `json:"succeeded"`
+		Failed    []*sdk.BatchTaskError `json:"failed"`
+	}{Succeeded: []int64{}, Failed: []*sdk.BatchTaskError{}}
+	for i, id := range req.TaskIDs {
+		rec, err := s.findLocked(businessID, id)
+		if err == nil {
+			err = s.deleteLocked(rec)
+		}
+		if err != nil {
+			resp.Failed = append(resp.Failed, batchError(i, err))
+			continue
+		}
+		resp.Succeeded = append(resp.Succeeded, id)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleBatchReschedule 处理 POST /api/v1/tasks/reschedule，结果与请求的任务ID顺序一致
+func (s *Server) handleBatchReschedule(w http.ResponseWriter, r *http.Request, businessID int64) {
+	var req sdk.BatchRescheduleRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, errValidation("%s", err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 重复的任务ID只改期一次，每个ID都返回处理后的结果
+	done := make(map[int64]*sdk.RescheduleResult, len(req.TaskIDs))
+	resp := &sdk.BatchRescheduleResponse{Results: []sdk.RescheduleResult{}}
+	for _, id := range req.TaskIDs {
+		result, ok := done[id]
+		if !ok {
+			if rec, err := s.findLocked(businessID, id); err != nil {
+				result = &sdk.RescheduleResult{TaskID: id, Outcome: sdk.RescheduleOutcomeNotFound}
+			} else {
+				result = s.rescheduleLocked(rec, &req.RescheduleRequest)
+			}
+			done[id] = result
+		}
+		resp.Results = append(resp.Results, *result)
+	}
+	writeData(w, http.StatusOK, resp)
+}
+
+// bulkJobRecord 模拟服务端保存的批量操作任务
+type bulkJobRecord struct {
+	businessID int64
+	job        sdk.BulkJob
+}
+
+// bulkActionStatuses 各批量操作处理的任务状态，与服务端一致
+var bulkActionStatuses = map[sdk.BulkAction][]sdk.TaskStatus{
+	sdk.BulkActionCancel:     {sdk.TaskStatusPending},
+	sdk.BulkActionRetry:      {sdk.TaskStatusFailed, sdk.TaskStatusExpired},
+	sdk.BulkActionDelete:     {sdk.TaskStatusPending, sdk.TaskStatusSucceeded, sdk.TaskStatusFailed, sdk.TaskStatusCancelled, sdk.TaskStatusExpired},
+	sdk.BulkActionReschedule: {sdk.TaskStatusPending},
+}
+
+// routeBulkJobs 分发 /api/v1/tasks/bulk-jobs 下的请求
+func (s *Server) routeBulkJobs(w http.ResponseWriter, r *http.Request, businessID int64, segments []string) bool {
+	switch {
+	case len(segments) == 0:
+		if r.Method != http.MethodPost {
+			return false
+		}
+		s.handleStartBulkJob(w, r, businessID)
+	case len(segments) == 1 || (len(segments) == 2 && segments[1] == "cancel"):
+		method := http.MethodGet
+		if len(segments) == 2 {
+			method = http.MethodPost
+		}
+		if r.Method != method {
+			return false
+		}
+		jobID, _ := url.PathUnescape(segments[0])
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		rec, ok := s.bulkJobs[jobID]
+		switch {
+		case !ok || rec.businessID != businessID:
+			writeError(w, errNotFound("bulk job"))
+		case method == http.MethodPost && rec.job.Status.IsTerminal():
+			writeError(w, errConflict(rec.job, "bulk job is already %s", rec.job.Status))
+		case method == http.MethodPost:
+			now := s.now
+			rec.job.Status = sdk.BulkJobStatusCancelled
+			rec.job.CompletedAt = &now
+			writeData(w, http.StatusOK, rec.job)
+		default:
+			writeData(w, http.StatusOK, rec.job)
+		}
+	default:
+		writeError(w, errNotFound("route"))
+	}
+	return true
+}
+
+// handleStartBulkJob 处理 POST /api/v1/tasks/bulk-jobs
+// 服务端异步分批执行，模拟服务端在创建时同步执行完毕，返回的任务已是最终状态
+func (s *Server) handleStartBulkJob(w http.ResponseWriter, r *http.Request, businessID int64) {
+	var req sdk.BulkJobRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, errValidation("%s", err.Error()))
+		return
+	}
+	q := listQueryFromRequest(&req.Filter)
+	if strings.TrimSpace(req.Search) != "" {
+		query, err := search.Parse(req.Search)
+		if err != nil {
+			writeError(w, errValidation("%s", err.Error()))
+			return
+		}
+		q.search = query
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextJobID++
+	now := s.now
+	rec := &bulkJobRecord{
+		businessID: businessID,
+		job: sdk.BulkJob{
+			ID:        strconv.FormatInt(s.nextJobID, 10),
+			Action:    req.Action,
+			Status:    sdk.BulkJobStatusCompleted,
+			DryRun:    req.DryRun,
+			CreatedAt: now,
+			StartedAt: &now,
+		},
+	}
+	rec.job.CompletedAt = &now
+	s.bulkJobs[rec.job.ID] = rec
+
+	statuses := bulkActionStatuses[req.Action]
+	reason := fmt.Sprintf("bulk %s job %s", req.Action, rec.job.ID)
+	for _, task := range s.sortedTasksLocked() {
+		if task.businessID != businessID || !q.match(&task.task) || !slices.Contains(statuses, task.task.Status) {
+			continue
+		}
+		rec.job.Total++
+		if req.DryRun {
+			continue
+		}
+
+		var err *apiError
+		switch req.Action {
+		case sdk.BulkActionCancel:
+			err = s.cancelLocked(task, actorBulk, reason)
+		case sdk.BulkActionRetry:
+			err = s.retryLocked(task, actorBulk, reason)
+		case sdk.BulkActionDelete:
+			err = s.deleteLocked(task)
+		case sdk.BulkActionReschedule:
+			s.rescheduleLocked(task, req.Reschedule)
+		}
+		rec.job.Processed++
+		if err != nil {
+			rec.job.Failed++
+		} else {
+			rec.job.Succeeded++
+		}
+	}
+	writeData(w, http.StatusAccepted, rec.job)
+}
@@ -0,0 +1,84 @@
+package tasktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// Request 模拟服务端收到的请求，包括被注入错误或认证失败的请求
+type Request struct {
+	Method     string
+	Path       string // 不含查询参数
+	Query      url.Values
+	Header     http.Header
+	Body       []byte
+	ReceivedAt time.Time // 收到请求时虚拟时钟的时间
+}
+
+// Decode 将请求体解析到 v
+func (r *Request) Decode(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// record 记录收到的请求
+func (s *Server) record(r *http.Request, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, &Request{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Query:      r.URL.Query(),
+		Header:     r.Header.Clone(),
+		Body:       body,
+		ReceivedAt: s.now,
+	})
+}
+
+// Requests 按收到的顺序返回所有请求
+func (s *Server) Requests() []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Request(nil), s.requests...)
+}
+
+// RequestsTo 按收到的顺序返回匹配 method 和 path 的请求，method 为空时匹配任意方法
+func (s *Server) RequestsTo(method, path string) []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matched []*Request
+	for _, r := range s.requests {
+		if (method == "" || r.Method == method) && r.Path == path {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// LastRequest 返回最近一个匹配 method 和 path 的请求，没有时返回 nil
+func (s *Server) LastRequest(method, path string) *Request {
+	requests := s.RequestsTo(method, path)
+	if len(requests) == 0 {
+		return nil
+	}
+	return requests[len(requests)-1]
+}
+
+// ResetRequests 清空已记录的请求，任务状态不受影响
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// AssertRequestCount 断言收到了 want 个匹配 method 和 path 的请求
+func (s *Server) AssertRequestCount(t testing.TB, method, path string, want int) {
+	t.Helper()
+	if got := len(s.RequestsTo(method, path)); got != want {
+		t.Errorf("tasktest: expected %d %s %s requests, got %d", want, method, path, got)
+	}
+}
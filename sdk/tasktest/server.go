@@ -0,0 +1,359 @@
+// Package tasktest 提供进程内的 TaskCenter 模拟服务端，供 SDK 使用方编写单元测试
+//
+// Server 实现 SDK 调用的全部 /api/v1/tasks 接口：按与服务端相同的规则校验请求，维护任务状态、版本、
+// 状态变更事件和历史，错误使用相同的响应格式，SDK 返回的错误类型与连接真实服务端时一致。
+// 任务不会自动执行，测试通过 Advance 推进虚拟时钟，到期的任务按 SetCallbackOutcome 指定的结果完成回调（默认成功），
+// 失败时按重试间隔重新排期，重试耗尽后失败。收到的请求都被记录，可用 Requests 和 AssertRequestCount 断言。
+//
+//	srv := tasktest.NewServer(nil)
+//	defer srv.Close()
+//	client := srv.NewClient(t)
+//
+//	task, _ := client.Tasks().Create(ctx, sdk.NewTask("order-1", "https://example.com/callback"))
+//	srv.SetCallbackOutcome("order-1", tasktest.CallbackFailed)
+//	srv.Advance(time.Hour) // 首次执行失败，之后按重试间隔继续失败，最终为 failed
+//
+//	srv.AssertRequestCount(t, http.MethodPost, "/api/v1/tasks", 1)
+package tasktest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"task-center/sdk"
+)
+
+// Config 模拟服务端配置
+type Config struct {
+	APIKey     string    // 非空时要求请求带 Authorization: Bearer <APIKey>，否则返回 401
+	BusinessID int64     // 非零时只接受该业务系统的请求，其他 X-Business-ID 返回 403；为零时按请求头区分业务系统
+	Start      time.Time // 虚拟时钟的起始时间
+
+	DefaultRetryIntervals []int         // 创建任务未指定重试间隔时使用的间隔（秒），与服务端默认值一致
+	WatchRetry            time.Duration // 任务状态推送建议客户端使用的重连间隔
+}
+
+// DefaultConfig 默认模拟服务端配置
+func DefaultConfig() *Config {
+	return &Config{
+		APIKey:                "test-api-key",
+		BusinessID:            1,
+		Start:                 time.Date(2025, 9, 15, 8, 0, 0, 0, time.UTC),
+		DefaultRetryIntervals: []int{60, 300, 900},
+		WatchRetry:            time.Second,
+	}
+}
+
+// Server 进程内的 TaskCenter 模拟服务端，所有方法都是并发安全的
+type Server struct {
+	config *Config
+	server *httptest.Server
+	done   chan struct{} // 关闭时通知推送连接退出
+	once   sync.Once
+
+	mu          sync.Mutex
+	now         time.Time
+	nextTaskID  int64
+	nextJobID   int64
+	tasks       map[int64]*taskRecord
+	unique      map[uniqueKey]int64
+	events      []*eventRecord
+	changed     chan struct{} // 有新事件时关闭并替换，唤醒推送连接
+	bulkJobs    map[string]*bulkJobRecord
+	idempotency map[string]*idempotentResponse
+	faults      []*fault
+	requests    []*Request
+
+	outcomes  map[string][]CallbackOutcome
+	handler   CallbackHandler
+	callbacks []CallbackAttempt
+}
+
+// NewServer 创建并启动模拟服务端，config 为 nil 时使用默认配置
+func NewServer(config *Config) *Server {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	defaults := DefaultConfig()
+	if config.Start.IsZero() {
+		config.Start = defaults.Start
+	}
+	if len(config.DefaultRetryIntervals) == 0 {
+		config.DefaultRetryIntervals = defaults.DefaultRetryIntervals
+	}
+	if config.WatchRetry <= 0 {
+		config.WatchRetry = defaults.WatchRetry
+	}
+
+	s := &Server{
+		config:      config,
+		done:        make(chan struct{}),
+		now:         config.Start,
+		tasks:       make(map[int64]*taskRecord),
+		unique:      make(map[uniqueKey]int64),
+		changed:     make(chan struct{}),
+		bulkJobs:    make(map[string]*bulkJobRecord),
+		idempotency: make(map[string]*idempotentResponse),
+		outcomes:    make(map[string][]CallbackOutcome),
+	}
+	s.server = httptest.NewServer(s)
+	return s
+}
+
+// URL 返回模拟服务端的地址，用作 sdk.Config.BaseURL
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close 断开任务状态推送连接并关闭模拟服务端
+func (s *Server) Close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.server.Close()
+	})
+}
+
+// NewClient 创建连接到模拟服务端的 SDK 客户端，不自动重试，测试结束时关闭
+func (s *Server) NewClient(t testing.TB) *sdk.Client {
+	t.Helper()
+
+	config := sdk.DefaultConfig()
+	config.BaseURL = s.URL()
+	config.APIKey = s.config.APIKey
+	if config.APIKey == "" {
+		config.APIKey = DefaultConfig().APIKey
+	}
+	config.BusinessID = s.config.BusinessID
+	if config.BusinessID == 0 {
+		config.BusinessID = DefaultConfig().BusinessID
+	}
+	config.RetryPolicy = &sdk.RetryPolicy{}
+
+	client, err := sdk.NewClient(config)
+	if err != nil {
+		t.Fatalf("tasktest: failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// InjectFault 让接下来 times 次匹配 method 和 path 的请求直接返回 statusCode 的错误响应，用于测试重试和错误处理
+// method 或 path 为空时匹配任意值；path 不含查询参数
+func (s *Server) InjectFault(method, path string, statusCode, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault{method: method, path: path, statusCode: statusCode, remaining: times})
+}
+
+// fault 注入的错误响应
+type fault struct {
+	method     string
+	path       string
+	statusCode int
+	remaining  int
+}
+
+// idempotentResponse 按幂等键保存的原始响应，statusCode 为 0 表示原请求仍在处理
+type idempotentResponse struct {
+	fingerprint string
+	statusCode  int
+	header      http.Header
+	body        []byte
+}
+
+// apiError 以服务端错误响应格式返回的错误
+type apiError struct {
+	statusCode int
+	code       string
+	message    string
+	details    interface{}
+}
+
+// errValidation 创建参数错误
+func errValidation(format string, args ...interface{}) *apiError {
+	return &apiError{statusCode: http.StatusBadRequest, code: sdk.CodeValidationError, message: fmt.Sprintf(format, args...)}
+}
+
+// errNotFound 创建资源不存在错误
+func errNotFound(resource string) *apiError {
+	return &apiError{statusCode: http.StatusNotFound, code: sdk.CodeNotFoundError, message: resource + " not found"}
+}
+
+// errConflict 创建冲突错误，details 通常为任务的当前状态
+func errConflict(details interface{}, format string, args ...interface{}) *apiError {
+	return &apiError{statusCode: http.StatusConflict, code: sdk.CodeConflictError, message: fmt.Sprintf(format, args...), details: details}
+}
+
+// ServeHTTP 记录请求并按注入的错误、认证、幂等键和路由依次处理
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, errValidation("failed to read request body"))
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	s.record(r, body)
+
+	if statusCode, ok := s.takeFault(r); ok {
+		writeError(w, &apiError{statusCode: statusCode, code: codeForStatus(statusCode), message: "injected fault: " + http.StatusText(statusCode)})
+		return
+	}
+
+	businessID, apiErr := s.authenticate(r)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	if key := r.Header.Get(sdk.HeaderIdempotencyKey); key != "" && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.serveIdempotent(w, r, businessID, key, body)
+		return
+	}
+	s.route(w, r, businessID)
+}
+
+// takeFault 取出匹配请求的注入错误
+func (s *Server) takeFault(r *http.Request) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.faults {
+		if (f.method != "" && f.method != r.Method) || (f.path != "" && f.path != r.URL.Path) {
+			continue
+		}
+		f.remaining--
+		if f.remaining <= 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		}
+		return f.statusCode, true
+	}
+	return 0, false
+}
+
+// authenticate 校验 API Key 和业务系统ID，返回请求所属的业务系统
+func (s *Server) authenticate(r *http.Request) (int64, *apiError) {
+	if s.config.APIKey != "" && r.Header.Get("Authorization") != "Bearer "+s.config.APIKey {
+		return 0, &apiError{statusCode: http.StatusUnauthorized, code: sdk.CodeAuthenticationError, message: "invalid api key"}
+	}
+	businessID, err := strconv.ParseInt(r.Header.Get("X-Business-ID"), 10, 64)
+	if err != nil || businessID <= 0 {
+		return 0, &apiError{statusCode: http.StatusUnauthorized, code: sdk.CodeAuthenticationError, message: "missing or invalid X-Business-ID"}
+	}
+	if s.config.BusinessID != 0 && businessID != s.config.BusinessID {
+		return 0, &apiError{statusCode: http.StatusForbidden, code: sdk.CodeAuthorizationError,
+			message: fmt.Sprintf("business %d is not allowed to use this api key", businessID)}
+	}
+	return businessID, nil
+}
+
+// serveIdempotent 按 (业务系统, 幂等键) 保存原始响应，重复请求直接返回保存的响应，规则与 model.IdempotencyMiddleware 一致
+func (s *Server) serveIdempotent(w http.ResponseWriter, r *http.Request, businessID int64, key string, body []byte) {
+	sum := sha256.Sum256(body)
+	fingerprint := r.Method + " " + r.URL.RequestURI() + " " + hex.EncodeToString(sum[:])
+	storeKey := strconv.FormatInt(businessID, 10) + ":" + key
+
+	s.mu.Lock()
+	saved, exists := s.idempotency[storeKey]
+	switch {
+	case exists && saved.fingerprint != fingerprint:
+		s.mu.Unlock()
+		writeError(w, &apiError{statusCode: http.StatusUnprocessableEntity, code: sdk.CodeValidationError,
+			message: "idempotency key has been used for a different request"})
+		return
+	case exists && saved.statusCode == 0:
+		s.mu.Unlock()
+		w.Header().Set("Retry-After", "1")
+		writeError(w, errConflict(nil, "a request with the same idempotency key is being processed"))
+		return
+	case exists:
+		s.mu.Unlock()
+		for name, values := range saved.header {
+			w.Header()[name] = values
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(saved.statusCode)
+		w.Write(saved.body)
+		return
+	}
+	s.idempotency[storeKey] = &idempotentResponse{fingerprint: fingerprint}
+	s.mu.Unlock()
+
+	recorder := httptest.NewRecorder()
+	s.route(recorder, r, businessID)
+
+	s.mu.Lock()
+	if recorder.Code >= http.StatusInternalServerError {
+		// 5xx 响应不保存，可以用同一个键重试
+		delete(s.idempotency, storeKey)
+	} else {
+		s.idempotency[storeKey] = &idempotentResponse{
+			fingerprint: fingerprint,
+			statusCode:  recorder.Code,
+			header:      recorder.Header().Clone(),
+			body:        recorder.Body.Bytes(),
+		}
+	}
+	s.mu.Unlock()
+
+	for name, values := range recorder.Header() {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(recorder.Code)
+	w.Write(recorder.Body.Bytes())
+}
+
+// codeForStatus 返回状态码对应的错误代码，与 sdk.ParseHTTPError 的默认映射一致
+func codeForStatus(statusCode int) string {
+	switch {
+	case statusCode == http.StatusBadRequest || statusCode == http.StatusUnprocessableEntity:
+		return sdk.CodeValidationError
+	case statusCode == http.StatusUnauthorized:
+		return sdk.CodeAuthenticationError
+	case statusCode == http.StatusForbidden:
+		return sdk.CodeAuthorizationError
+	case statusCode == http.StatusNotFound:
+		return sdk.CodeNotFoundError
+	case statusCode == http.StatusConflict:
+		return sdk.CodeConflictError
+	case statusCode == http.StatusTooManyRequests:
+		return sdk.CodeRateLimitError
+	case statusCode == http.StatusGatewayTimeout:
+		return sdk.CodeTimeoutError
+	case statusCode >= http.StatusInternalServerError:
+		return sdk.CodeServerError
+	default:
+		return sdk.CodeUnknownError
+	}
+}
+
+// writeJSON 写出 JSON 响应
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeData 以 ApiResponse 包装写出成功响应
+func writeData(w http.ResponseWriter, statusCode int, data interface{}) {
+	writeJSON(w, statusCode, &sdk.ApiResponse{Success: true, Data: data})
+}
+
+// writeError 以 ErrorResponse 写出错误响应
+func writeError(w http.ResponseWriter, err *apiError) {
+	writeJSON(w, err.statusCode, &sdk.ErrorResponse{
+		Success: false,
+		Message: err.message,
+		Code:    err.code,
+		Details: err.details,
+	})
+}
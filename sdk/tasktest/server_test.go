@@ -0,0 +1,294 @@
+package tasktest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"task-center/sdk"
+	"task-center/sdk/task"
+)
+
+// newTestServer 创建测试用的模拟服务端和客户端
+func newTestServer(t *testing.T) (*Server, *sdk.Client) {
+	t.Helper()
+	srv := NewServer(nil)
+	t.Cleanup(srv.Close)
+	return srv, srv.NewClient(t)
+}
+
+func TestServer_CreateAndConflict(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx := context.Background()
+
+	created, err := client.Tasks().Create(ctx, sdk.NewTask("order-1", "https://example.com/callback"))
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if created.ID == 0 || created.Status != sdk.TaskStatusPending || created.Version != 1 {
+		t.Errorf("unexpected task: %+v", created)
+	}
+	if !created.ScheduledAt.Equal(srv.Now()) {
+		t.Errorf("expected scheduled_at %v, got %v", srv.Now(), created.ScheduledAt)
+	}
+
+	_, err = client.Tasks().Create(ctx, sdk.NewTask("order-1", "https://example.com/other"))
+	if !sdk.IsConflictError(err) {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+	existing, ok := sdk.ConflictingTask(err)
+	if !ok || existing.ID != created.ID {
+		t.Errorf("expected conflicting task %d, got %+v", created.ID, existing)
+	}
+
+	req := sdk.NewTask("order-1", "https://example.com/other")
+	req.ConflictPolicy = sdk.ConflictPolicyReturnExisting
+	returned, outcome, err := client.Tasks().CreateWithOutcome(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateWithOutcome failed: %v", err)
+	}
+	if outcome != sdk.CreateOutcomeExisting || returned.ID != created.ID {
+		t.Errorf("expected existing task %d, got %s %d", created.ID, outcome, returned.ID)
+	}
+
+	_, err = client.Tasks().Create(ctx, sdk.NewTask("order-2", "not-a-url"))
+	if !sdk.IsValidationError(err) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+
+	srv.AssertRequestCount(t, http.MethodPost, "/api/v1/tasks", 4)
+	var body sdk.CreateTaskRequest
+	if err := srv.LastRequest(http.MethodPost, "/api/v1/tasks").Decode(&body); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if body.BusinessUniqueID != "order-2" {
+		t.Errorf("expected last request for order-2, got %s", body.BusinessUniqueID)
+	}
+}
+
+func TestServer_AdvanceRetriesUntilFailed(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx := context.Background()
+
+	req := sdk.NewTask("order-1", "https://example.com/callback")
+	req.MaxRetries = 2
+	req.RetryIntervals = []int{60, 120}
+	created, err := client.Tasks().Create(ctx, req)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	srv.SetCallbackOutcome("order-1", CallbackFailed, CallbackTimedOut)
+	if n := srv.Advance(time.Minute); n != 2 {
+		t.Errorf("expected 2 callbacks within a minute, got %d", n)
+	}
+	got, err := client.Tasks().Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != sdk.TaskStatusPending || got.CurrentRetry != 2 {
+		t.Errorf("expected pending task on retry 2, got status %d retry %d", got.Status, got.CurrentRetry)
+	}
+
+	if n := srv.Advance(time.Hour); n != 1 {
+		t.Errorf("expected 1 more callback, got %d", n)
+	}
+	got, err = client.Tasks().Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != sdk.TaskStatusFailed || got.ErrorMessage != CallbackTimedOut.Error {
+		t.Errorf("expected failed task with timeout error, got status %d error %q", got.Status, got.ErrorMessage)
+	}
+
+	callbacks := srv.Callbacks()
+	if len(callbacks) != 3 {
+		t.Fatalf("expected 3 callbacks, got %d", len(callbacks))
+	}
+	start := DefaultConfig().Start
+	for i, want := range []time.Time{start, start.Add(time.Minute), start.Add(3 * time.Minute)} {
+		if !callbacks[i].At.Equal(want) || callbacks[i].Attempt != i+1 {
+			t.Errorf("callback %d: expected attempt %d at %v, got %d at %v", i, i+1, want, callbacks[i].Attempt, callbacks[i].At)
+		}
+	}
+
+	events, err := client.Tasks().Events(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Events failed: %v", err)
+	}
+	// 三次执行各有 pending → running 和 running → pending/failed 两个事件
+	if len(events) != 6 || events[5].ToStatus != sdk.TaskStatusFailed || events[5].Actor != actorExecutor {
+		t.Errorf("unexpected events: %+v", events)
+	}
+}
+
+func TestServer_UpdateVersionConflict(t *testing.T) {
+	_, client := newTestServer(t)
+	ctx := context.Background()
+
+	created, err := client.Tasks().Create(ctx, sdk.NewTask("order-1", "https://example.com/callback"))
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	priority := sdk.TaskPriorityHigh
+	version := created.Version
+	updated, err := client.Tasks().Update(ctx, created.ID, &sdk.UpdateTaskRequest{Priority: &priority, Version: &version})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.Version != version+1 || updated.Priority != priority {
+		t.Errorf("unexpected updated task: %+v", updated)
+	}
+
+	_, err = client.Tasks().Update(ctx, created.ID, &sdk.UpdateTaskRequest{Priority: &priority, Version: &version})
+	if !sdk.IsConflictError(err) {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+	if current, ok := sdk.ConflictingTask(err); !ok || current.Version != updated.Version {
+		t.Errorf("expected current task version %d, got %+v", updated.Version, current)
+	}
+
+	_, err = client.Tasks().Get(ctx, created.ID+100)
+	if !sdk.IsNotFoundError(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestServer_IterateWithCursor(t *testing.T) {
+	_, client := newTestServer(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"order-1", "order-2", "order-3", "order-4", "order-5"} {
+		if _, err := client.Tasks().Create(ctx, sdk.NewTask(id, "https://example.com/callback")); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	filter := task.NewListRequest()
+	filter.PageSize = 2
+	tasks, err := task.NewClient(client).Iterate(ctx, filter).Collect()
+	if err != nil {
+		t.Fatalf("Iterate failed: %v", err)
+	}
+	if len(tasks) != 5 {
+		t.Fatalf("expected 5 tasks, got %d", len(tasks))
+	}
+	seen := make(map[int64]bool)
+	for _, tk := range tasks {
+		if seen[tk.ID] {
+			t.Errorf("task %d returned twice", tk.ID)
+		}
+		seen[tk.ID] = true
+	}
+}
+
+func TestServer_IdempotentReplay(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx := sdk.WithIdempotencyKey(context.Background(), "create-order-1")
+
+	first, err := client.Tasks().Create(ctx, sdk.NewTask("order-1", "https://example.com/callback"))
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	second, err := client.Tasks().Create(ctx, sdk.NewTask("order-1", "https://example.com/callback"))
+	if err != nil {
+		t.Fatalf("replayed Create failed: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("expected replayed task %d, got %d", first.ID, second.ID)
+	}
+	if got := len(srv.Tasks()); got != 1 {
+		t.Errorf("expected 1 task, got %d", got)
+	}
+
+	_, err = client.Tasks().Create(ctx, sdk.NewTask("order-2", "https://example.com/callback"))
+	if !sdk.IsValidationError(err) {
+		t.Errorf("expected validation error for reused key, got %v", err)
+	}
+}
+
+func TestServer_InjectFaultAndAuth(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx := context.Background()
+
+	srv.InjectFault(http.MethodGet, "/api/v1/tasks/stats", http.StatusServiceUnavailable, 1)
+	if _, err := client.Tasks().Stats(ctx); !sdk.IsServerError(err) {
+		t.Errorf("expected server error, got %v", err)
+	}
+	if _, err := client.Tasks().Stats(ctx); err != nil {
+		t.Errorf("expected fault to be used up, got %v", err)
+	}
+	srv.AssertRequestCount(t, http.MethodGet, "/api/v1/tasks/stats", 2)
+
+	config := sdk.DefaultConfig()
+	config.BaseURL = srv.URL()
+	config.APIKey = "wrong-key"
+	config.BusinessID = DefaultConfig().BusinessID
+	config.RetryPolicy = &sdk.RetryPolicy{}
+	unauthorized, err := sdk.NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer unauthorized.Close()
+	if _, err := unauthorized.Tasks().Stats(ctx); !sdk.IsAuthenticationError(err) {
+		t.Errorf("expected authentication error, got %v", err)
+	}
+}
+
+func TestServer_WatchTask(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := client.Tasks().Create(ctx, sdk.NewTask("order-1", "https://example.com/callback"))
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	watcher := task.NewTaskWatcher(task.NewClient(client), time.Hour)
+	defer watcher.Stop()
+	updates := watcher.WatchTask(ctx, created.ID)
+
+	// 快照到达后说明推送连接已建立，之后的状态变更不会丢失
+	select {
+	case got := <-updates:
+		if got.Status != task.StatusPending {
+			t.Fatalf("expected pending snapshot, got %d", got.Status)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for snapshot")
+	}
+
+	srv.Advance(time.Second)
+	for {
+		select {
+		case got := <-updates:
+			if got.Status == task.StatusSucceeded {
+				if srv.LastRequest(http.MethodGet, "/api/v1/tasks/watch") == nil {
+					t.Error("expected watch request to be recorded")
+				}
+				return
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for succeeded status")
+		}
+	}
+}
+
+func TestServer_ErrorEnvelope(t *testing.T) {
+	srv, client := newTestServer(t)
+
+	_, err := client.Tasks().Get(context.Background(), 42)
+	var sdkErr sdk.Error
+	if !errors.As(err, &sdkErr) {
+		t.Fatalf("expected sdk.Error, got %T", err)
+	}
+	if sdkErr.StatusCode() != http.StatusNotFound || sdkErr.Code() != sdk.CodeNotFoundError {
+		t.Errorf("unexpected error: %v", sdkErr)
+	}
+	if req := srv.LastRequest(http.MethodGet, "/api/v1/tasks/42"); req == nil || req.Header.Get("X-Business-ID") != "1" {
+		t.Errorf("expected recorded request with business id header, got %+v", req)
+	}
+}
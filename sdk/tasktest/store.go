@@ -0,0 +1,777 @@
+package tasktest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-center/pkg/search"
+	"task-center/sdk"
+)
+
+// 状态变更事件的操作者，与服务端一致
+const (
+	actorAPI      = "api"
+	actorExecutor = "executor"
+	actorBulk     = "bulk"
+)
+
+// uniqueKey 任务在业务系统内的唯一键
+type uniqueKey struct {
+	businessID       int64
+	businessUniqueID string
+}
+
+// taskRecord 模拟服务端保存的任务
+type taskRecord struct {
+	businessID int64
+	task       sdk.Task
+	history    []*sdk.Task // 创建和每次修改后的任务快照，按修改顺序
+}
+
+// eventRecord 任务状态变更事件，id 全局递增，用于推送续传
+type eventRecord struct {
+	businessID int64
+	event      sdk.TaskEvent
+}
+
+// Now 返回虚拟时钟的当前时间
+func (s *Server) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Task 返回任务的当前状态，用于断言；不存在时返回 false
+func (s *Server) Task(taskID int64) (*sdk.Task, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.tasks[taskID]
+	if !ok {
+		return nil, false
+	}
+	return cloneTask(&rec.task), true
+}
+
+// Tasks 按ID顺序返回所有业务系统的任务
+func (s *Server) Tasks() []*sdk.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]*sdk.Task, 0, len(s.tasks))
+	for _, rec := range s.sortedTasksLocked() {
+		tasks = append(tasks, cloneTask(&rec.task))
+	}
+	return tasks
+}
+
+// sortedTasksLocked 按ID顺序返回所有任务，调用方持有 s.mu
+func (s *Server) sortedTasksLocked() []*taskRecord {
+	records := make([]*taskRecord, 0, len(s.tasks))
+	for _, rec := range s.tasks {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].task.ID < records[j].task.ID })
+	return records
+}
+
+// findLocked 查询业务系统的任务，其他业务系统的任务视为不存在
+func (s *Server) findLocked(businessID, taskID int64) (*taskRecord, *apiError) {
+	rec, ok := s.tasks[taskID]
+	if !ok || rec.businessID != businessID {
+		return nil, errNotFound("task")
+	}
+	return rec, nil
+}
+
+// findByUniqueLocked 按业务唯一ID查询任务
+func (s *Server) findByUniqueLocked(businessID int64, businessUniqueID string) (*taskRecord, *apiError) {
+	id, ok := s.unique[uniqueKey{businessID, businessUniqueID}]
+	if !ok {
+		return nil, errNotFound("task")
+	}
+	return s.tasks[id], nil
+}
+
+// createLocked 创建任务，business_unique_id 已存在时按 req.ConflictPolicy 处理
+// 返回的任务在 rejected 时为已存在的任务，校验失败时为 nil
+func (s *Server) createLocked(businessID int64, req *sdk.CreateTaskRequest) (*taskRecord, sdk.CreateOutcome, *apiError) {
+	if err := validateCreate(req); err != nil {
+		return nil, sdk.CreateOutcomeFailed, err
+	}
+
+	if id, ok := s.unique[uniqueKey{businessID, req.BusinessUniqueID}]; ok {
+		existing := s.tasks[id]
+		switch {
+		case req.ConflictPolicy == sdk.ConflictPolicyReturnExisting:
+			return existing, sdk.CreateOutcomeExisting, nil
+		case req.ConflictPolicy == sdk.ConflictPolicyReplaceIfPending && existing.task.Status == sdk.TaskStatusPending:
+			s.applyCreate(existing, req)
+			existing.task.Version++
+			s.saveLocked(existing)
+			return existing, sdk.CreateOutcomeReplaced, nil
+		}
+		return existing, sdk.CreateOutcomeRejected, errConflict(cloneTask(&existing.task),
+			"task with business_unique_id %q already exists", req.BusinessUniqueID)
+	}
+
+	s.nextTaskID++
+	rec := &taskRecord{
+		businessID: businessID,
+		task: sdk.Task{
+			ID:               s.nextTaskID,
+			BusinessUniqueID: req.BusinessUniqueID,
+			Status:           sdk.TaskStatusPending,
+			Version:          1,
+			CreatedAt:        s.now,
+		},
+	}
+	s.applyCreate(rec, req)
+	s.tasks[rec.task.ID] = rec
+	s.unique[uniqueKey{businessID, req.BusinessUniqueID}] = rec.task.ID
+	s.saveLocked(rec)
+	return rec, sdk.CreateOutcomeCreated, nil
+}
+
+// validateCreate 按服务端规则校验创建请求并填充默认值
+func validateCreate(req *sdk.CreateTaskRequest) *apiError {
+	if err := req.Validate(); err != nil {
+		return errValidation("%s", err.Error())
+	}
+	if err := validateCallbackURL(req.CallbackURL); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateCallbackURL 回调地址必须是 http 或 https 的绝对地址
+func validateCallbackURL(callbackURL string) *apiError {
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errValidation("invalid callback_url: %s", callbackURL)
+	}
+	return nil
+}
+
+// applyCreate 用创建请求设置任务，替换待执行任务时重置执行进度
+func (s *Server) applyCreate(rec *taskRecord, req *sdk.CreateTaskRequest) {
+	t := &rec.task
+	t.CallbackURL = req.CallbackURL
+	t.CallbackMethod = req.CallbackMethod
+	t.CallbackHeaders = maps.Clone(req.CallbackHeaders)
+	t.CallbackBody = req.CallbackBody
+	t.RetryIntervals = slices.Clone(req.RetryIntervals)
+	if len(t.RetryIntervals) == 0 {
+		t.RetryIntervals = slices.Clone(s.config.DefaultRetryIntervals)
+	}
+	t.MaxRetries = req.MaxRetries
+	t.CurrentRetry = 0
+	t.Priority = req.Priority
+	t.Tags = normalizeTags(req.Tags)
+	t.Timeout = req.Timeout
+	t.Metadata = maps.Clone(req.Metadata)
+	t.ErrorMessage = ""
+	t.ScheduledAt = s.now
+	if req.ScheduledAt != nil {
+		t.ScheduledAt = dbTime(*req.ScheduledAt)
+	}
+	next := t.ScheduledAt
+	t.NextExecuteAt = &next
+}
+
+// updateLocked 按更新请求修改任务，版本或状态变更校验失败时返回冲突错误，details 为任务的当前状态
+func (s *Server) updateLocked(rec *taskRecord, req *sdk.UpdateTaskRequest) *apiError {
+	t := &rec.task
+	if req.Version != nil && *req.Version != t.Version {
+		return errConflict(cloneTask(t), "task has been modified: version %d, current version %d", *req.Version, t.Version)
+	}
+	if err := sdk.ValidateTags(req.Tags); err != nil {
+		return errValidation("%s", err.Error())
+	}
+	if req.Status != nil && !t.Status.CanTransitionTo(*req.Status) {
+		return errConflict(cloneTask(t), "cannot change task status from %s to %s", t.Status, *req.Status)
+	}
+	if req.CallbackURL != nil {
+		if err := validateCallbackURL(*req.CallbackURL); err != nil {
+			return err
+		}
+		t.CallbackURL = *req.CallbackURL
+	}
+
+	if req.CallbackMethod != nil {
+		t.CallbackMethod = *req.CallbackMethod
+	}
+	if req.CallbackHeaders != nil {
+		t.CallbackHeaders = maps.Clone(req.CallbackHeaders)
+	}
+	if req.CallbackBody != nil {
+		t.CallbackBody = *req.CallbackBody
+	}
+	if req.RetryIntervals != nil {
+		t.RetryIntervals = slices.Clone(req.RetryIntervals)
+	}
+	if req.MaxRetries != nil {
+		t.MaxRetries = *req.MaxRetries
+	}
+	if req.Priority != nil {
+		t.Priority = *req.Priority
+	}
+	if req.Tags != nil {
+		t.Tags = normalizeTags(req.Tags)
+	}
+	if req.Timeout != nil {
+		t.Timeout = *req.Timeout
+	}
+	if req.Metadata != nil {
+		t.Metadata = maps.Clone(req.Metadata)
+	}
+	if req.ScheduledAt != nil {
+		t.ScheduledAt = dbTime(*req.ScheduledAt)
+		if t.Status == sdk.TaskStatusPending {
+			next := t.ScheduledAt
+			t.NextExecuteAt = &next
+		}
+	}
+	if req.Status != nil && *req.Status != t.Status {
+		s.transitionLocked(rec, *req.Status, actorAPI, "")
+	}
+	t.Version++
+	s.saveLocked(rec)
+	return nil
+}
+
+// cancelLocked 取消待执行的任务
+func (s *Server) cancelLocked(rec *taskRecord, actor, reason string) *apiError {
+	if rec.task.Status != sdk.TaskStatusPending {
+		return errConflict(cloneTask(&rec.task), "task is %s and cannot be cancelled", rec.task.Status)
+	}
+	s.transitionLocked(rec, sdk.TaskStatusCancelled, actor, reason)
+	rec.task.Version++
+	s.saveLocked(rec)
+	return nil
+}
+
+// retryLocked 重新执行失败或过期的任务，重试次数清零并立即执行
+func (s *Server) retryLocked(rec *taskRecord, actor, reason string) *apiError {
+	if rec.task.Status != sdk.TaskStatusFailed && rec.task.Status != sdk.TaskStatusExpired {
+		return errConflict(cloneTask(&rec.task), "task is %s and cannot be retried", rec.task.Status)
+	}
+	s.transitionLocked(rec, sdk.TaskStatusPending, actor, reason)
+	rec.task.CurrentRetry = 0
+	rec.task.ErrorMessage = ""
+	rec.task.Version++
+	s.saveLocked(rec)
+	return nil
+}
+
+// deleteLocked 删除未在执行中的任务
+func (s *Server) deleteLocked(rec *taskRecord) *apiError {
+	if rec.task.Status == sdk.TaskStatusRunning {
+		return errConflict(cloneTask(&rec.task), "task is running and cannot be deleted")
+	}
+	delete(s.tasks, rec.task.ID)
+	delete(s.unique, uniqueKey{rec.businessID, rec.task.BusinessUniqueID})
+	return nil
+}
+
+// rescheduleLocked 调整待执行任务的执行时间，返回改期结果
+func (s *Server) rescheduleLocked(rec *taskRecord, req *sdk.RescheduleRequest) *sdk.RescheduleResult {
+	t := &rec.task
+	if t.Status != sdk.TaskStatusPending {
+		return &sdk.RescheduleResult{TaskID: t.ID, Outcome: sdk.RescheduleOutcomeSkipped, Reason: "task is " + t.Status.String(), Task: cloneTask(t)}
+	}
+	if req.At != nil {
+		t.ScheduledAt = dbTime(*req.At)
+		next := t.ScheduledAt
+		t.NextExecuteAt = &next
+	} else {
+		delay := time.Duration(req.DelaySeconds) * time.Second
+		next := t.ScheduledAt
+		if t.NextExecuteAt != nil {
+			next = *t.NextExecuteAt
+		}
+		next = next.Add(delay)
+		t.NextExecuteAt = &next
+		t.ScheduledAt = t.ScheduledAt.Add(delay)
+	}
+	t.Version++
+	s.saveLocked(rec)
+	return &sdk.RescheduleResult{TaskID: t.ID, Outcome: sdk.RescheduleOutcomeRescheduled, Task: cloneTask(t)}
+}
+
+// transitionLocked 变更任务状态并记录事件，同时维护执行时间：
+// 进入待执行时立即可执行，进入执行中时记录 executed_at，进入终态时记录 completed_at
+func (s *Server) transitionLocked(rec *taskRecord, to sdk.TaskStatus, actor, reason string) {
+	t := &rec.task
+	from := t.Status
+	t.Status = to
+	now := s.now
+	switch to {
+	case sdk.TaskStatusPending:
+		t.NextExecuteAt = &now
+		t.CompletedAt = nil
+	case sdk.TaskStatusRunning:
+		t.ExecutedAt = &now
+		t.NextExecuteAt = nil
+	default:
+		t.CompletedAt = &now
+		t.NextExecuteAt = nil
+	}
+
+	s.events = append(s.events, &eventRecord{
+		businessID: rec.businessID,
+		event: sdk.TaskEvent{
+			ID:         int64(len(s.events) + 1),
+			TaskID:     t.ID,
+			FromStatus: from,
+			ToStatus:   to,
+			Actor:      actor,
+			Reason:     reason,
+			CreatedAt:  now,
+		},
+	})
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// saveLocked 更新 updated_at 并记录任务快照
+func (s *Server) saveLocked(rec *taskRecord) {
+	rec.task.UpdatedAt = s.now
+	rec.history = append(rec.history, cloneTask(&rec.task))
+}
+
+// taskEventsLocked 按发生顺序返回任务的状态变更事件
+func (s *Server) taskEventsLocked(taskID int64) []sdk.TaskEvent {
+	events := []sdk.TaskEvent{}
+	for _, e := range s.events {
+		if e.event.TaskID == taskID {
+			events = append(events, e.event)
+		}
+	}
+	return events
+}
+
+// listQuery 列表、搜索和批量操作的过滤条件与分页参数
+type listQuery struct {
+	statuses    []sdk.TaskStatus
+	tags        []string
+	tagMatch    sdk.TagMatchMode
+	priority    *sdk.TaskPriority
+	createdFrom *time.Time
+	createdTo   *time.Time
+	search      *search.Query
+
+	page     int
+	pageSize int
+	cursor   bool // 使用游标分页
+	sortBy   sdk.ListSortBy
+	desc     bool
+	after    string
+}
+
+// 分页参数，与服务端一致
+const (
+	defaultPageSize = 20
+	maxPageSize     = 1000
+)
+
+// parseListQuery 解析列表接口的查询参数
+func parseListQuery(values url.Values) (*listQuery, *apiError) {
+	q := &listQuery{page: 1, pageSize: defaultPageSize}
+
+	if v := values.Get("status"); v != "" {
+		for _, part := range strings.Split(v, ",") {
+			status, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || status < int(sdk.TaskStatusPending) || status > int(sdk.TaskStatusExpired) {
+				return nil, errValidation("invalid status: %s", part)
+			}
+			q.statuses = append(q.statuses, sdk.TaskStatus(status))
+		}
+	}
+	if v := values.Get("tags"); v != "" {
+		q.tags = strings.Split(v, ",")
+	}
+	q.tagMatch = sdk.TagMatchMode(values.Get("tag_match"))
+	if !q.tagMatch.IsValid() {
+		return nil, errValidation("invalid tag_match: %s", q.tagMatch)
+	}
+	if v := values.Get("priority"); v != "" {
+		priority, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errValidation("invalid priority: %s", v)
+		}
+		p := sdk.TaskPriority(priority)
+		q.priority = &p
+	}
+	for name, dst := range map[string]**time.Time{"created_from": &q.createdFrom, "created_to": &q.createdTo} {
+		if v := values.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, errValidation("invalid %s: %s", name, v)
+			}
+			*dst = &t
+		}
+	}
+
+	if v := values.Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page <= 0 {
+			return nil, errValidation("invalid page: %s", v)
+		}
+		q.page = page
+	}
+	if v := values.Get("page_size"); v != "" {
+		pageSize, err := strconv.Atoi(v)
+		if err != nil || pageSize <= 0 {
+			return nil, errValidation("invalid page_size: %s", v)
+		}
+		q.pageSize = min(pageSize, maxPageSize)
+	}
+
+	q.sortBy = sdk.ListSortBy(values.Get("sort_by"))
+	q.after = values.Get("cursor")
+	q.cursor = q.sortBy != "" || q.after != ""
+	if q.sortBy == "" {
+		q.sortBy = sdk.ListSortByCreatedAt
+	}
+	if q.sortBy != sdk.ListSortByCreatedAt && q.sortBy != sdk.ListSortByNextExecuteAt {
+		return nil, errValidation("unsupported sort_by: %s", q.sortBy)
+	}
+	switch order := values.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		q.desc = true
+	default:
+		return nil, errValidation("invalid order: %s", order)
+	}
+	return q, nil
+}
+
+// listQueryFromRequest 将批量操作的过滤条件转换为查询条件
+func listQueryFromRequest(req *sdk.ListTasksRequest) *listQuery {
+	return &listQuery{
+		statuses:    req.Status,
+		tags:        req.Tags,
+		tagMatch:    req.TagMatch,
+		priority:    req.Priority,
+		createdFrom: req.CreatedFrom,
+		createdTo:   req.CreatedTo,
+	}
+}
+
+// match 任务是否满足过滤条件
+func (q *listQuery) match(t *sdk.Task) bool {
+	if len(q.statuses) > 0 && !slices.Contains(q.statuses, t.Status) {
+		return false
+	}
+	if q.priority != nil && t.Priority != *q.priority {
+		return false
+	}
+	if tags := normalizeTags(q.tags); len(tags) > 0 {
+		matched := 0
+		for _, tag := range tags {
+			if hasTag(t.Tags, tag) {
+				matched++
+			}
+		}
+		if matched == 0 || (q.tagMatch != sdk.TagMatchAny && matched < len(tags)) {
+			return false
+		}
+	}
+	if q.createdFrom != nil && t.CreatedAt.Before(*q.createdFrom) {
+		return false
+	}
+	if q.createdTo != nil && t.CreatedAt.After(*q.createdTo) {
+		return false
+	}
+	return q.search == nil || matchSearch(q.search, t)
+}
+
+// taskCursor 游标分页位置，对调用方不透明
+type taskCursor struct {
+	SortBy sdk.ListSortBy `json:"s"`
+	Desc   bool           `json:"d,omitempty"`
+	Value  time.Time      `json:"v"`
+	ID     int64          `json:"i"`
+}
+
+// listLocked 查询业务系统中匹配的任务并分页
+// 页码分页按创建时间倒序；游标分页按 (sort_by, id) 排序，按 next_execute_at 排序时不含没有下次执行时间的任务
+func (s *Server) listLocked(businessID int64, q *listQuery) (*sdk.ListTasksResponse, *apiError) {
+	var matched []*sdk.Task
+	for _, rec := range s.sortedTasksLocked() {
+		if rec.businessID != businessID || !q.match(&rec.task) {
+			continue
+		}
+		if q.cursor && q.sortBy == sdk.ListSortByNextExecuteAt && rec.task.NextExecuteAt == nil {
+			continue
+		}
+		matched = append(matched, &rec.task)
+	}
+
+	if !q.cursor {
+		sort.SliceStable(matched, func(i, j int) bool {
+			a, b := matched[i], matched[j]
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+			return a.ID > b.ID
+		})
+		resp := &sdk.ListTasksResponse{
+			Tasks:      []sdk.Task{},
+			Total:      len(matched),
+			Page:       q.page,
+			PageSize:   q.pageSize,
+			TotalPages: (len(matched) + q.pageSize - 1) / q.pageSize,
+		}
+		start := min((q.page-1)*q.pageSize, len(matched))
+		end := min(start+q.pageSize, len(matched))
+		for _, t := range matched[start:end] {
+			resp.Tasks = append(resp.Tasks, *cloneTask(t))
+		}
+		return resp, nil
+	}
+
+	sortValue := func(t *sdk.Task) time.Time {
+		if q.sortBy == sdk.ListSortByNextExecuteAt {
+			return *t.NextExecuteAt
+		}
+		return t.CreatedAt
+	}
+	// less 按 (sort_by, id) 比较，倒序时取反
+	less := func(v1 time.Time, id1 int64, v2 time.Time, id2 int64) bool {
+		if !v1.Equal(v2) {
+			return v1.Before(v2) != q.desc
+		}
+		return (id1 < id2) != q.desc
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return less(sortValue(matched[i]), matched[i].ID, sortValue(matched[j]), matched[j].ID)
+	})
+
+	if q.after != "" {
+		cursor, err := decodeCursor(q.after)
+		if err != nil || cursor.SortBy != q.sortBy || cursor.Desc != q.desc {
+			return nil, errValidation("invalid cursor")
+		}
+		start := len(matched)
+		for i, t := range matched {
+			if less(cursor.Value, cursor.ID, sortValue(t), t.ID) {
+				start = i
+				break
+			}
+		}
+		matched = matched[start:]
+	}
+
+	resp := &sdk.ListTasksResponse{Tasks: []sdk.Task{}, PageSize: q.pageSize}
+	if len(matched) > q.pageSize {
+		matched = matched[:q.pageSize]
+		last := matched[len(matched)-1]
+		resp.HasMore = true
+		resp.NextCursor = encodeCursor(&taskCursor{SortBy: q.sortBy, Desc: q.desc, Value: sortValue(last), ID: last.ID})
+	}
+	for _, t := range matched {
+		resp.Tasks = append(resp.Tasks, *cloneTask(t))
+	}
+	return resp, nil
+}
+
+// encodeCursor 编码游标
+func encodeCursor(c *taskCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor 解码游标
+func decodeCursor(s string) (*taskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c taskCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &c, nil
+}
+
+// statsLocked 统计业务系统的任务
+func (s *Server) statsLocked(businessID int64) *sdk.TaskStatsResponse {
+	stats := &sdk.TaskStatsResponse{
+		StatusCounts:   make(map[sdk.TaskStatus]int),
+		PriorityCounts: make(map[sdk.TaskPriority]int),
+		TagCounts:      make(map[string]int),
+	}
+	for _, rec := range s.tasks {
+		if rec.businessID != businessID {
+			continue
+		}
+		stats.TotalTasks++
+		stats.StatusCounts[rec.task.Status]++
+		stats.PriorityCounts[rec.task.Priority]++
+		for _, tag := range rec.task.Tags {
+			stats.TagCounts[strings.ToLower(tag)]++
+		}
+	}
+	return stats
+}
+
+// matchSearch 按搜索条件匹配任务，语义与服务端生成的 SQL 条件一致，文本比较不区分大小写
+func matchSearch(q *search.Query, t *sdk.Task) bool {
+	for key, value := range q.Metadata {
+		if v, ok := metadataValue(t.Metadata, key); !ok || v != value {
+			return false
+		}
+	}
+
+	host := ""
+	if u, err := url.Parse(t.CallbackURL); err == nil {
+		host = strings.ToLower(u.Hostname())
+	}
+	for _, h := range q.Hosts {
+		h = strings.ToLower(h)
+		if strings.HasPrefix(h, "*.") {
+			if !strings.HasSuffix(host, h[1:]) {
+				return false
+			}
+		} else if host != h {
+			return false
+		}
+	}
+	for _, text := range q.URLContains {
+		if !containsFold(t.CallbackURL, text) {
+			return false
+		}
+	}
+	for _, text := range q.ErrorContains {
+		if !containsFold(t.ErrorMessage, text) {
+			return false
+		}
+	}
+	for _, text := range q.Text {
+		if !containsFold(t.BusinessUniqueID, text) {
+			return false
+		}
+	}
+
+	scheduled := t.ScheduledAt
+	created := t.CreatedAt
+	timeRanges := []struct {
+		r *search.TimeRange
+		t *time.Time
+	}{
+		{q.Scheduled, &scheduled},
+		{q.Executed, t.ExecutedAt},
+		{q.Completed, t.CompletedAt},
+		{q.Created, &created},
+	}
+	for _, tr := range timeRanges {
+		if tr.r != nil && !inTimeRange(tr.r, tr.t) {
+			return false
+		}
+	}
+
+	if r := q.Retries; r != nil {
+		if (r.Min != nil && t.CurrentRetry < *r.Min) || (r.Max != nil && t.CurrentRetry > *r.Max) {
+			return false
+		}
+	}
+	return true
+}
+
+// inTimeRange 时间是否在范围内，时间为空时不匹配
+func inTimeRange(r *search.TimeRange, t *time.Time) bool {
+	if t == nil {
+		return false
+	}
+	if r.From != nil && (t.Before(*r.From) || (r.ExcludeFrom && t.Equal(*r.From))) {
+		return false
+	}
+	if r.To != nil && (t.After(*r.To) || (r.ExcludeTo && t.Equal(*r.To))) {
+		return false
+	}
+	return true
+}
+
+// metadataValue 按 a.b 形式的键取出元数据值，返回与 json_unquote(json_extract(...)) 相同的字符串
+func metadataValue(metadata map[string]interface{}, key string) (string, bool) {
+	var value interface{} = metadata
+	for _, part := range strings.Split(key, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if value, ok = m[part]; !ok {
+			return "", false
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		data, err := json.Marshal(v)
+		return string(data), err == nil
+	}
+}
+
+// containsFold 不区分大小写的子串匹配
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// normalizeTags 去除首尾空白，合并只有大小写不同的标签，与服务端存储的标签一致
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !hasTag(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// hasTag 标签比较不区分大小写
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// dbTime 统一使用 UTC 并截断到秒，与服务端存储的精度一致
+func dbTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// cloneTask 复制任务，返回的任务不与模拟服务端共享切片和映射
+func cloneTask(t *sdk.Task) *sdk.Task {
+	c := *t
+	c.CallbackHeaders = maps.Clone(t.CallbackHeaders)
+	c.RetryIntervals = slices.Clone(t.RetryIntervals)
+	c.Tags = slices.Clone(t.Tags)
+	c.Metadata = maps.Clone(t.Metadata)
+	for _, p := range []**time.Time{&c.NextExecuteAt, &c.ExecutedAt, &c.CompletedAt} {
+		if *p != nil {
+			v := **p
+			*p = &v
+		}
+	}
+	return &c
+}
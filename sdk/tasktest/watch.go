@@ -0,0 +1,225 @@
+package tasktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-center/sdk"
+)
+
+// 任务监听事件类型，与服务端一致
+const (
+	watchEventStatusChanged = "task.status_changed"
+	watchEventSnapshot      = "task.snapshot"
+)
+
+// maxWatchTaskIDs 单个连接最多监听的任务数，与服务端默认值一致
+const maxWatchTaskIDs = 100
+
+// watchFilter 监听范围
+type watchFilter struct {
+	taskIDs  []int64
+	statuses []sdk.TaskStatus
+	priority *sdk.TaskPriority
+	tags     []string
+}
+
+// watchPayload SSE 事件的 data
+type watchPayload struct {
+	EventID    int64          `json:"event_id,omitempty"`
+	TaskID     int64          `json:"task_id"`
+	FromStatus sdk.TaskStatus `json:"from_status"`
+	ToStatus   sdk.TaskStatus `json:"to_status"`
+	Actor      string         `json:"actor,omitempty"`
+	Reason     string         `json:"reason,omitempty"`
+	At         time.Time      `json:"at"`
+	Task       *sdk.Task      `json:"task,omitempty"`
+}
+
+// handleWatch 处理 GET /api/v1/tasks/watch，以 SSE 推送匹配的任务状态变更，续传和快照规则与服务端一致：
+// 带 Last-Event-ID 时先补发之后的事件；指定 task_id 且不续传，或 snapshot=true 时，先推送任务的当前状态
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request, businessID int64) {
+	filter, err := parseWatchFilter(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var sent int64
+	if lastEventID != "" {
+		id, parseErr := strconv.ParseInt(lastEventID, 10, 64)
+		if parseErr != nil || id < 0 {
+			writeError(w, errValidation("invalid Last-Event-ID: %s", lastEventID))
+			return
+		}
+		sent = id
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, &apiError{statusCode: http.StatusInternalServerError, code: sdk.CodeServerError, message: "streaming is not supported"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", s.config.WatchRetry.Milliseconds())
+
+	s.mu.Lock()
+	cursor := int64(len(s.events))
+	var frames []string
+	if sent > 0 {
+		frames = s.watchEventsLocked(businessID, filter, sent, cursor)
+	}
+	if len(filter.taskIDs) > 0 && (sent == 0 || r.URL.Query().Get("snapshot") == "true") {
+		frames = append(frames, s.watchSnapshotLocked(businessID, filter, max(sent, cursor))...)
+	}
+	sent = max(sent, cursor)
+	changed := s.changed
+	s.mu.Unlock()
+
+	for {
+		for _, frame := range frames {
+			if _, err := fmt.Fprint(w, frame); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		case <-changed:
+		}
+
+		s.mu.Lock()
+		cursor = int64(len(s.events))
+		frames = s.watchEventsLocked(businessID, filter, sent, cursor)
+		sent = max(sent, cursor)
+		changed = s.changed
+		s.mu.Unlock()
+	}
+}
+
+// parseWatchFilter 解析监听范围：task_id（可重复或逗号分隔）、status（变更后的状态）、priority、tags（须全部包含）
+func parseWatchFilter(r *http.Request) (*watchFilter, *apiError) {
+	query := r.URL.Query()
+	filter := &watchFilter{}
+	for _, value := range query["task_id"] {
+		for _, part := range strings.Split(value, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil || id <= 0 {
+				return nil, errValidation("invalid task_id: %s", part)
+			}
+			filter.taskIDs = append(filter.taskIDs, id)
+		}
+	}
+	if len(filter.taskIDs) > maxWatchTaskIDs {
+		return nil, errValidation("cannot watch more than %d tasks", maxWatchTaskIDs)
+	}
+	if value := query.Get("status"); value != "" {
+		for _, part := range strings.Split(value, ",") {
+			status, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return nil, errValidation("invalid status: %s", part)
+			}
+			filter.statuses = append(filter.statuses, sdk.TaskStatus(status))
+		}
+	}
+	if value := query.Get("priority"); value != "" {
+		priority, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errValidation("invalid priority: %s", value)
+		}
+		p := sdk.TaskPriority(priority)
+		filter.priority = &p
+	}
+	if value := query.Get("tags"); value != "" {
+		filter.tags = normalizeTags(strings.Split(value, ","))
+	}
+	return filter, nil
+}
+
+// matchTask 任务是否满足优先级和标签条件，任务已删除时只在没有这两个条件时匹配
+func (f *watchFilter) matchTask(t *sdk.Task) bool {
+	if t == nil {
+		return f.priority == nil && len(f.tags) == 0
+	}
+	if f.priority != nil && t.Priority != *f.priority {
+		return false
+	}
+	for _, tag := range f.tags {
+		if !hasTag(t.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// watchEventsLocked 生成 (after, until] 之间匹配的事件，task 为推送时任务的最新状态
+func (s *Server) watchEventsLocked(businessID int64, filter *watchFilter, after, until int64) []string {
+	var frames []string
+	for _, e := range s.events[min(after, until):until] {
+		if e.businessID != businessID ||
+			(len(filter.taskIDs) > 0 && !slices.Contains(filter.taskIDs, e.event.TaskID)) ||
+			(len(filter.statuses) > 0 && !slices.Contains(filter.statuses, e.event.ToStatus)) {
+			continue
+		}
+		var task *sdk.Task
+		if rec, ok := s.tasks[e.event.TaskID]; ok {
+			task = cloneTask(&rec.task)
+		}
+		if !filter.matchTask(task) {
+			continue
+		}
+		frames = append(frames, watchFrame(e.event.ID, watchEventStatusChanged, &watchPayload{
+			EventID:    e.event.ID,
+			TaskID:     e.event.TaskID,
+			FromStatus: e.event.FromStatus,
+			ToStatus:   e.event.ToStatus,
+			Actor:      e.event.Actor,
+			Reason:     e.event.Reason,
+			At:         e.event.CreatedAt,
+			Task:       task,
+		}))
+	}
+	return frames
+}
+
+// watchSnapshotLocked 生成监听任务的当前状态，事件 id 为 at，客户端从该位置续传
+func (s *Server) watchSnapshotLocked(businessID int64, filter *watchFilter, at int64) []string {
+	var frames []string
+	for _, id := range filter.taskIDs {
+		rec, err := s.findLocked(businessID, id)
+		if err != nil {
+			continue
+		}
+		if (len(filter.statuses) > 0 && !slices.Contains(filter.statuses, rec.task.Status)) || !filter.matchTask(&rec.task) {
+			continue
+		}
+		frames = append(frames, watchFrame(at, watchEventSnapshot, &watchPayload{
+			TaskID:     rec.task.ID,
+			FromStatus: rec.task.Status,
+			ToStatus:   rec.task.Status,
+			At:         rec.task.UpdatedAt,
+			Task:       cloneTask(&rec.task),
+		}))
+	}
+	return frames
+}
+
+// watchFrame 按 SSE 格式编码事件，JSON 编码的 data 不含换行
+func watchFrame(id int64, eventType string, payload *watchPayload) string {
+	data, _ := json.Marshal(payload)
+	return fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", id, eventType, data)
+}
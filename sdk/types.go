@@ -2,7 +2,10 @@ package sdk
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // TaskStatus 任务状态枚举
@@ -37,6 +40,58 @@ func (s TaskStatus) String() string {
 	}
 }
 
+// taskTransitions 允许的任务状态变更，与服务端一致
+var taskTransitions = map[TaskStatus][]TaskStatus{
+	TaskStatusPending: {TaskStatusRunning, TaskStatusCancelled, TaskStatusExpired},
+	TaskStatusRunning: {TaskStatusSucceeded, TaskStatusFailed, TaskStatusPending},
+	TaskStatusFailed:  {TaskStatusPending},
+	TaskStatusExpired: {TaskStatusPending},
+}
+
+// CanTransitionTo 判断任务能否从当前状态变更为 next，服务端拒绝的变更返回冲突错误
+// 成功和取消是终态，不能再变更
+func (s TaskStatus) CanTransitionTo(next TaskStatus) bool {
+	if s == next {
+		return true
+	}
+	for _, status := range taskTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ConflictPolicy 创建任务时 business_unique_id 已存在的处理策略
+type ConflictPolicy string
+
+const (
+	ConflictPolicyReject           ConflictPolicy = "reject"             // 拒绝创建，返回409并在details中携带已存在的任务（默认）
+	ConflictPolicyReturnExisting   ConflictPolicy = "return_existing"    // 不创建，直接返回已存在的任务
+	ConflictPolicyReplaceIfPending ConflictPolicy = "replace_if_pending" // 已存在的任务仍为待执行时用新请求替换，否则同 reject
+)
+
+// IsValid 检查冲突策略是否合法，空值表示使用默认策略
+func (p ConflictPolicy) IsValid() bool {
+	switch p {
+	case "", ConflictPolicyReject, ConflictPolicyReturnExisting, ConflictPolicyReplaceIfPending:
+		return true
+	default:
+		return false
+	}
+}
+
+// CreateOutcome 创建任务的结果
+type CreateOutcome string
+
+const (
+	CreateOutcomeCreated  CreateOutcome = "created"  // 新建任务
+	CreateOutcomeExisting CreateOutcome = "existing" // 返回已存在的任务
+	CreateOutcomeReplaced CreateOutcome = "replaced" // 替换了待执行的任务
+	CreateOutcomeRejected CreateOutcome = "rejected" // 因冲突被拒绝
+	CreateOutcomeFailed   CreateOutcome = "failed"   // 其他原因失败
+)
+
 // TaskPriority 任务优先级
 type TaskPriority int
 
@@ -69,6 +124,7 @@ type Task struct {
 	CompletedAt      *time.Time        `json:"completed_at,omitempty"`
 	ErrorMessage     string            `json:"error_message,omitempty"`
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	Version          int64             `json:"version,omitempty"`
 	CreatedAt        time.Time         `json:"created_at,omitempty"`
 	UpdatedAt        time.Time         `json:"updated_at,omitempty"`
 }
@@ -87,6 +143,7 @@ type CreateTaskRequest struct {
 	Timeout          int                    `json:"timeout,omitempty"`
 	ScheduledAt      *time.Time             `json:"scheduled_at,omitempty"`
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	ConflictPolicy   ConflictPolicy         `json:"conflict_policy,omitempty"`
 }
 
 // UpdateTaskRequest 更新任务请求
@@ -101,28 +158,100 @@ type UpdateTaskRequest struct {
 	Tags            []string               `json:"tags,omitempty"`
 	Timeout         *int                   `json:"timeout,omitempty"`
 	ScheduledAt     *time.Time             `json:"scheduled_at,omitempty"`
-	Status          *TaskStatus            `json:"status,omitempty"`
+	Status          *TaskStatus            `json:"status,omitempty"` // 只允许状态机定义的变更，见 TaskStatus.CanTransitionTo
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	Version         *int64                 `json:"version,omitempty"` // 读取任务时的版本，任务已被修改时返回冲突错误，为空时不检查
+}
+
+// ListSortBy 游标分页的排序键
+type ListSortBy string
+
+const (
+	ListSortByCreatedAt     ListSortBy = "created_at"      // 按 (created_at, id) 排序
+	ListSortByNextExecuteAt ListSortBy = "next_execute_at" // 按 (next_execute_at, id) 排序，不含没有下次执行时间的任务
+)
+
+// 标签限制，服务端以相同的限制校验
+const (
+	MaxTags      = 10 // 每个任务最多的标签数
+	MaxTagLength = 32 // 单个标签的最大字符数
+)
+
+// TagMatchMode 按多个标签过滤时的匹配方式
+type TagMatchMode string
+
+const (
+	TagMatchAll TagMatchMode = "all" // 包含全部标签（默认）
+	TagMatchAny TagMatchMode = "any" // 包含任一标签
+)
+
+// IsValid 检查匹配方式是否合法，空值表示使用默认方式
+func (m TagMatchMode) IsValid() bool {
+	return m == "" || m == TagMatchAll || m == TagMatchAny
+}
+
+// ValidateTags 校验标签数量和长度，标签不能为空白，也不能包含逗号
+// 只有大小写或首尾空白不同的标签会被服务端视为重复并合并
+func ValidateTags(tags []string) error {
+	if len(tags) > MaxTags {
+		return NewValidationError(fmt.Sprintf("too many tags: %d (max %d)", len(tags), MaxTags))
+	}
+	for _, tag := range tags {
+		trimmed := strings.TrimSpace(tag)
+		switch {
+		case trimmed == "":
+			return NewValidationError("tag cannot be empty")
+		case utf8.RuneCountInString(trimmed) > MaxTagLength:
+			return NewValidationError(fmt.Sprintf("tag %q exceeds %d characters", tag, MaxTagLength))
+		case strings.Contains(trimmed, ","):
+			return NewValidationError(fmt.Sprintf("tag %q cannot contain a comma", tag))
+		}
+	}
+	return nil
 }
 
 // ListTasksRequest 查询任务列表请求
+// 设置 SortBy 或 Cursor 时使用游标分页，此时忽略 Page，PageSize 为每页条数
 type ListTasksRequest struct {
 	Status      []TaskStatus `json:"status,omitempty"`
 	Tags        []string     `json:"tags,omitempty"`
+	TagMatch    TagMatchMode `json:"tag_match,omitempty"` // 多个标签的匹配方式，默认 all
 	Priority    *TaskPriority `json:"priority,omitempty"`
 	CreatedFrom *time.Time   `json:"created_from,omitempty"`
 	CreatedTo   *time.Time   `json:"created_to,omitempty"`
 	Page        int          `json:"page,omitempty"`
 	PageSize    int          `json:"page_size,omitempty"`
+	SortBy      ListSortBy   `json:"sort_by,omitempty"`
+	Descending  bool         `json:"descending,omitempty"`
+	Cursor      string       `json:"cursor,omitempty"`
+}
+
+// UsesCursor 是否使用游标分页
+func (req *ListTasksRequest) UsesCursor() bool {
+	return req.SortBy != "" || req.Cursor != ""
 }
 
 // ListTasksResponse 任务列表响应
+// 游标分页时 Total/Page/TotalPages 不再计算，通过 NextCursor 获取下一页
 type ListTasksResponse struct {
 	Tasks      []Task `json:"tasks"`
 	Total      int    `json:"total"`
 	Page       int    `json:"page"`
 	PageSize   int    `json:"page_size"`
 	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more,omitempty"`
+}
+
+// TaskEvent 任务状态变更事件，GET /api/v1/tasks/{id}/events 按发生顺序返回
+type TaskEvent struct {
+	ID         int64      `json:"id"`
+	TaskID     int64      `json:"task_id"`
+	FromStatus TaskStatus `json:"from_status"`
+	ToStatus   TaskStatus `json:"to_status"`
+	Actor      string     `json:"actor"`            // 操作者，如 api、executor、bulk
+	Reason     string     `json:"reason,omitempty"` // 变更原因
+	CreatedAt  time.Time  `json:"created_at"`
 }
 
 // TaskStatsResponse 任务统计响应
@@ -161,13 +290,24 @@ type CallbackEvent struct {
 
 // BatchCreateTasksRequest 批量创建任务请求
 type BatchCreateTasksRequest struct {
-	Tasks []CreateTaskRequest `json:"tasks"`
+	Tasks          []CreateTaskRequest `json:"tasks"`
+	ConflictPolicy ConflictPolicy      `json:"conflict_policy,omitempty"` // 默认冲突策略，单个任务设置了策略时以任务为准
 }
 
 // BatchCreateTasksResponse 批量创建任务响应
 type BatchCreateTasksResponse struct {
-	Succeeded []Task           `json:"succeeded"`
-	Failed    []BatchTaskError `json:"failed"`
+	Succeeded []Task              `json:"succeeded"`
+	Failed    []BatchTaskError    `json:"failed"`
+	Results   []BatchCreateResult `json:"results,omitempty"` // 按请求顺序排列的逐项结果
+}
+
+// BatchCreateResult 批量创建中单个任务的结果
+type BatchCreateResult struct {
+	Index   int           `json:"index"`
+	Outcome CreateOutcome `json:"outcome"`
+	Task    *Task         `json:"task,omitempty"` // created/existing/replaced 为结果任务，rejected 为已存在的任务
+	Error   string        `json:"error,omitempty"`
+	Code    string        `json:"code,omitempty"`
 }
 
 // BatchTaskError 批量操作中的任务错误
@@ -178,6 +318,191 @@ type BatchTaskError struct {
 	Request CreateTaskRequest `json:"request"`
 }
 
+// BulkAction 按过滤条件批量执行的操作
+type BulkAction string
+
+const (
+	BulkActionCancel BulkAction = "cancel" // 取消待执行的任务
+	BulkActionRetry  BulkAction = "retry"  // 重新执行失败或过期的任务
+	BulkActionDelete BulkAction = "delete" // 删除未在执行中的任务
+
+	BulkActionReschedule BulkAction = "reschedule" // 调整待执行任务的执行时间，需要设置 Reschedule
+)
+
+// IsValid 检查批量操作是否合法
+func (a BulkAction) IsValid() bool {
+	switch a {
+	case BulkActionCancel, BulkActionRetry, BulkActionDelete, BulkActionReschedule:
+		return true
+	default:
+		return false
+	}
+}
+
+// BulkJobStatus 批量任务的执行状态
+type BulkJobStatus string
+
+const (
+	BulkJobStatusPending   BulkJobStatus = "pending"   // 等待执行
+	BulkJobStatusRunning   BulkJobStatus = "running"   // 执行中
+	BulkJobStatusCompleted BulkJobStatus = "completed" // 执行完成
+	BulkJobStatusFailed    BulkJobStatus = "failed"    // 执行出错中止
+	BulkJobStatusCancelled BulkJobStatus = "cancelled" // 被取消，已处理的任务不会回滚
+)
+
+// IsTerminal 是否为最终状态
+func (s BulkJobStatus) IsTerminal() bool {
+	return s == BulkJobStatusCompleted || s == BulkJobStatusFailed || s == BulkJobStatusCancelled
+}
+
+// BulkJobRequest 按过滤条件批量操作任务的请求
+// Filter 的分页和排序字段被忽略；为防止误操作，Filter 和 Search 不能同时为空
+type BulkJobRequest struct {
+	Action BulkAction       `json:"action"`
+	Filter ListTasksRequest `json:"filter"`
+	Search string           `json:"search,omitempty"`  // 搜索语法，见 pkg/search 包
+	DryRun bool             `json:"dry_run,omitempty"` // 只统计会被处理的任务数，不做修改
+
+	Reschedule *RescheduleRequest `json:"reschedule,omitempty"` // 仅 reschedule 操作使用
+}
+
+// Validate 验证批量操作请求
+func (req *BulkJobRequest) Validate() error {
+	if !req.Action.IsValid() {
+		return NewValidationError("invalid bulk action: " + string(req.Action))
+	}
+	if !req.Filter.TagMatch.IsValid() {
+		return NewValidationError("invalid tag_match: " + string(req.Filter.TagMatch))
+	}
+	if req.Action == BulkActionReschedule {
+		// 试运行只统计匹配的任务数，可以不指定改期参数
+		if req.Reschedule == nil && !req.DryRun {
+			return NewValidationError("reschedule is required for the reschedule action")
+		}
+		if req.Reschedule != nil {
+			if err := req.Reschedule.Validate(); err != nil {
+				return err
+			}
+		}
+	} else if req.Reschedule != nil {
+		return NewValidationError("reschedule is only allowed for the reschedule action")
+	}
+	f := req.Filter
+	if len(f.Status) == 0 && len(f.Tags) == 0 && f.Priority == nil && f.CreatedFrom == nil && f.CreatedTo == nil &&
+		strings.TrimSpace(req.Search) == "" {
+		return NewValidationError("bulk job filter cannot be empty")
+	}
+	return nil
+}
+
+// BulkJob 批量操作任务，服务端异步执行
+type BulkJob struct {
+	ID          string        `json:"id"`
+	Action      BulkAction    `json:"action"`
+	Status      BulkJobStatus `json:"status"`
+	DryRun      bool          `json:"dry_run"`
+	Total       int           `json:"total"`     // 开始执行时匹配且可处理的任务数
+	Processed   int           `json:"processed"` // 已处理的任务数
+	Succeeded   int           `json:"succeeded"`
+	Skipped     int           `json:"skipped"` // 处理时状态已变化、不再适用的任务
+	Failed      int           `json:"failed"`
+	Error       string        `json:"error,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	StartedAt   *time.Time    `json:"started_at,omitempty"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+}
+
+// Progress 返回执行进度，取值 0-1
+func (j *BulkJob) Progress() float64 {
+	if j.Status == BulkJobStatusCompleted {
+		return 1
+	}
+	if j.Total <= 0 {
+		return 0
+	}
+	progress := float64(j.Processed) / float64(j.Total)
+	if progress > 1 {
+		progress = 1
+	}
+	return progress
+}
+
+// MaxBatchRescheduleTasks 单次批量改期的最大任务数
+const MaxBatchRescheduleTasks = 500
+
+// RescheduleRequest 调整待执行任务的执行时间，DelaySeconds 与 At 必须且只能设置一个
+// DelaySeconds 在任务当前的执行时间上顺延（负数为提前），At 直接设置新的执行时间
+// scheduled_at 与 next_execute_at 同时更新；执行中或已结束的任务不做修改
+type RescheduleRequest struct {
+	DelaySeconds int64      `json:"delay_seconds,omitempty"`
+	At           *time.Time `json:"at,omitempty"`
+}
+
+// SnoozeFor 创建顺延 d 的改期请求，不足一秒的部分被舍去
+func SnoozeFor(d time.Duration) *RescheduleRequest {
+	return &RescheduleRequest{DelaySeconds: int64(d / time.Second)}
+}
+
+// RescheduleAt 创建改到 at 执行的改期请求
+func RescheduleAt(at time.Time) *RescheduleRequest {
+	return &RescheduleRequest{At: &at}
+}
+
+// Validate 验证改期请求
+func (req *RescheduleRequest) Validate() error {
+	if req.DelaySeconds != 0 && req.At != nil {
+		return NewValidationError("delay_seconds and at are mutually exclusive")
+	}
+	if req.DelaySeconds == 0 && (req.At == nil || req.At.IsZero()) {
+		return NewValidationError("either delay_seconds or at is required")
+	}
+	return nil
+}
+
+// BatchRescheduleRequest 按任务ID批量改期的请求
+type BatchRescheduleRequest struct {
+	TaskIDs []int64 `json:"task_ids"`
+	RescheduleRequest
+}
+
+// Validate 验证批量改期请求
+func (req *BatchRescheduleRequest) Validate() error {
+	if len(req.TaskIDs) == 0 {
+		return NewValidationError("task_ids cannot be empty")
+	}
+	if len(req.TaskIDs) > MaxBatchRescheduleTasks {
+		return NewValidationError(fmt.Sprintf("cannot reschedule more than %d tasks at once", MaxBatchRescheduleTasks))
+	}
+	for _, id := range req.TaskIDs {
+		if id <= 0 {
+			return NewValidationError("task ID must be greater than 0")
+		}
+	}
+	return req.RescheduleRequest.Validate()
+}
+
+// RescheduleOutcome 单个任务的改期结果
+type RescheduleOutcome string
+
+const (
+	RescheduleOutcomeRescheduled RescheduleOutcome = "rescheduled" // 已改期
+	RescheduleOutcomeSkipped     RescheduleOutcome = "skipped"     // 任务不是待执行状态，未修改
+	RescheduleOutcomeNotFound    RescheduleOutcome = "not_found"   // 任务不存在
+)
+
+// RescheduleResult 单个任务的改期结果，Task 为处理后的任务状态
+type RescheduleResult struct {
+	TaskID  int64             `json:"task_id"`
+	Outcome RescheduleOutcome `json:"outcome"`
+	Reason  string            `json:"reason,omitempty"`
+	Task    *Task             `json:"task,omitempty"`
+}
+
+// BatchRescheduleResponse 批量改期响应，Results 与请求的任务ID顺序一致
+type BatchRescheduleResponse struct {
+	Results []RescheduleResult `json:"results"`
+}
+
 // Validate 验证创建任务请求
 func (req *CreateTaskRequest) Validate() error {
 	if req.BusinessUniqueID == "" {
@@ -186,6 +511,12 @@ func (req *CreateTaskRequest) Validate() error {
 	if req.CallbackURL == "" {
 		return NewValidationError("callback_url is required")
 	}
+	if !req.ConflictPolicy.IsValid() {
+		return NewValidationError("invalid conflict_policy: " + string(req.ConflictPolicy))
+	}
+	if err := ValidateTags(req.Tags); err != nil {
+		return err
+	}
 	if req.CallbackMethod == "" {
 		req.CallbackMethod = "POST" // 默认POST方法
 	}
@@ -2,6 +2,7 @@ package sdk
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -29,6 +30,32 @@ func TestTaskStatus_String(t *testing.T) {
 	}
 }
 
+func TestTaskStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to TaskStatus
+		want     bool
+	}{
+		{TaskStatusPending, TaskStatusRunning, true},
+		{TaskStatusPending, TaskStatusCancelled, true},
+		{TaskStatusRunning, TaskStatusSucceeded, true},
+		{TaskStatusRunning, TaskStatusPending, true},
+		{TaskStatusFailed, TaskStatusPending, true},
+		{TaskStatusSucceeded, TaskStatusSucceeded, true},
+		{TaskStatusPending, TaskStatusSucceeded, false},
+		{TaskStatusSucceeded, TaskStatusCancelled, false},
+		{TaskStatusCancelled, TaskStatusPending, false},
+		{TaskStatusRunning, TaskStatusCancelled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from.String()+"->"+tt.to.String(), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("CanTransitionTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCreateTaskRequest_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -59,6 +86,25 @@ func TestCreateTaskRequest_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid conflict policy",
+			req: &CreateTaskRequest{
+				BusinessUniqueID: "test-123",
+				CallbackURL:      "https://example.com/webhook",
+				MaxRetries:       3,
+				ConflictPolicy:   ConflictPolicyReplaceIfPending,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid conflict policy",
+			req: &CreateTaskRequest{
+				BusinessUniqueID: "test-123",
+				CallbackURL:      "https://example.com/webhook",
+				ConflictPolicy:   "overwrite",
+			},
+			wantErr: true,
+		},
 		{
 			name: "valid with defaults applied",
 			req: &CreateTaskRequest{
@@ -363,4 +409,98 @@ func TestCreateTaskRequest_ChainedMethods(t *testing.T) {
 	if task.ScheduledAt == nil || !task.ScheduledAt.Equal(scheduledTime) {
 		t.Errorf("Expected ScheduledAt %v, got %v", scheduledTime, task.ScheduledAt)
 	}
-}
\ No newline at end of file
+}
+
+func TestValidateTags(t *testing.T) {
+	tooMany := make([]string, MaxTags+1)
+	for i := range tooMany {
+		tooMany[i] = "tag" + string(rune('a'+i))
+	}
+
+	tests := []struct {
+		name    string
+		tags    []string
+		wantErr bool
+	}{
+		{"no tags", nil, false},
+		{"valid tags", []string{"billing", "订单", "eu-west"}, false},
+		{"max length multibyte", []string{strings.Repeat("标", MaxTagLength)}, false},
+		{"too many", tooMany, true},
+		{"too long", []string{strings.Repeat("a", MaxTagLength+1)}, true},
+		{"blank", []string{"  "}, true},
+		{"comma", []string{"a,b"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTags(tt.tags)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !IsValidationError(err) {
+				t.Errorf("Expected validation error, got %T", err)
+			}
+		})
+	}
+
+	req := &CreateTaskRequest{BusinessUniqueID: "test", CallbackURL: "https://example.com", Tags: tooMany}
+	if err := req.Validate(); err == nil {
+		t.Error("Expected CreateTaskRequest.Validate to reject too many tags")
+	}
+}
+
+func TestRescheduleRequest_Validate(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		req     *RescheduleRequest
+		wantErr bool
+	}{
+		{"snooze", SnoozeFor(2 * time.Hour), false},
+		{"pull forward", SnoozeFor(-time.Minute), false},
+		{"absolute", RescheduleAt(at), false},
+		{"sub-second delay", SnoozeFor(500 * time.Millisecond), true},
+		{"empty", &RescheduleRequest{}, true},
+		{"zero time", &RescheduleRequest{At: &time.Time{}}, true},
+		{"both", &RescheduleRequest{DelaySeconds: 60, At: &at}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if SnoozeFor(2*time.Hour).DelaySeconds != 7200 {
+		t.Errorf("Expected SnoozeFor(2h) to be 7200 seconds")
+	}
+}
+
+func TestBulkJobRequest_ValidateReschedule(t *testing.T) {
+	filter := ListTasksRequest{Tags: []string{"reminder"}}
+
+	tests := []struct {
+		name    string
+		req     BulkJobRequest
+		wantErr bool
+	}{
+		{"reschedule", BulkJobRequest{Action: BulkActionReschedule, Filter: filter, Reschedule: SnoozeFor(time.Hour)}, false},
+		{"dry run without params", BulkJobRequest{Action: BulkActionReschedule, Filter: filter, DryRun: true}, false},
+		{"missing params", BulkJobRequest{Action: BulkActionReschedule, Filter: filter}, true},
+		{"invalid params", BulkJobRequest{Action: BulkActionReschedule, Filter: filter, Reschedule: &RescheduleRequest{}}, true},
+		{"params on other action", BulkJobRequest{Action: BulkActionCancel, Filter: filter, Reschedule: SnoozeFor(time.Hour)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,495 @@
+// Package memory 基于内存的存储实现，用于单元测试
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"task-center/model"
+	"task-center/storage"
+)
+
+var _ storage.Store = (*Store)(nil)
+
+// Store 内存存储，并发安全，返回的记录都是副本
+type Store struct {
+	mu    sync.Mutex
+	clock storage.Clock
+
+	tasks      map[int64]*model.Tasks
+	executions map[int64][]*model.TaskExecutions
+	events     map[int64][]*model.TaskEvents
+	locks      map[string]*model.TaskLocks
+	businesses map[int64]*model.BusinessSystems
+
+	nextTaskId      int64
+	nextExecutionId int64
+	nextEventId     int64
+	nextLockId      int64
+	nextBusinessId  int64
+}
+
+// New 创建内存存储，clock 为 nil 时使用当前时间
+func New(clock storage.Clock) *Store {
+	return &Store{
+		clock:      clock,
+		tasks:      make(map[int64]*model.Tasks),
+		executions: make(map[int64][]*model.TaskExecutions),
+		events:     make(map[int64][]*model.TaskEvents),
+		locks:      make(map[string]*model.TaskLocks),
+		businesses: make(map[int64]*model.BusinessSystems),
+	}
+}
+
+// Tasks 返回任务存储
+func (s *Store) Tasks() storage.TaskStore { return taskStore{s} }
+
+// Executions 返回执行记录存储
+func (s *Store) Executions() storage.ExecutionStore { return executionStore{s} }
+
+// Locks 返回任务锁存储
+func (s *Store) Locks() storage.LockStore { return lockStore{s} }
+
+// Businesses 返回业务系统存储
+func (s *Store) Businesses() storage.BusinessStore { return businessStore{s} }
+
+// Events 返回任务事件存储
+func (s *Store) Events() storage.EventStore { return eventStore{s} }
+
+type taskStore struct{ s *Store }
+
+// normalizeTask 与数据库保持一致的时间精度
+func normalizeTask(task *model.Tasks) {
+	task.ScheduledAt = storage.DBTime(task.ScheduledAt)
+	task.NextExecuteAt = storage.DBNullTime(task.NextExecuteAt)
+	task.ExecutedAt = storage.DBNullTime(task.ExecutedAt)
+	task.CompletedAt = storage.DBNullTime(task.CompletedAt)
+}
+
+func (t taskStore) Create(ctx context.Context, task *model.Tasks) error {
+	s := t.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.tasks {
+		if existing.BusinessId == task.BusinessId && existing.BusinessUniqueId == task.BusinessUniqueId {
+			return storage.ErrDuplicate
+		}
+	}
+
+	s.nextTaskId++
+	now := s.clock.Now()
+	task.Id = s.nextTaskId
+	task.Version = 1
+	task.CreatedAt = now
+	task.UpdatedAt = now
+	normalizeTask(task)
+
+	stored := *task
+	s.tasks[task.Id] = &stored
+	return nil
+}
+
+func (t taskStore) Get(ctx context.Context, id int64) (*model.Tasks, error) {
+	s := t.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	found := *task
+	return &found, nil
+}
+
+func (t taskStore) GetByBusinessUniqueId(ctx context.Context, businessId int64, businessUniqueId string) (*model.Tasks, error) {
+	s := t.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, task := range s.tasks {
+		if task.BusinessId == businessId && task.BusinessUniqueId == businessUniqueId {
+			found := *task
+			return &found, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (t taskStore) Update(ctx context.Context, task *model.Tasks) error {
+	transition, err := t.update(ctx, task)
+	if err != nil {
+		return err
+	}
+	// 钩子可能再次访问存储，在释放锁之后执行
+	if transition != nil {
+		model.DefaultTaskStateMachine.Fire(ctx, transition)
+	}
+	return nil
+}
+
+// update 在锁内更新任务，状态变化时写入事件并返回状态变更
+func (t taskStore) update(ctx context.Context, task *model.Tasks) (*model.TaskTransition, error) {
+	s := t.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.tasks[task.Id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	if existing.Version != task.Version {
+		return nil, storage.ErrConflict
+	}
+	if !model.DefaultTaskStateMachine.Can(existing.Status, task.Status) {
+		return nil, storage.ErrInvalidTransition
+	}
+	for _, other := range s.tasks {
+		if other.Id != task.Id && other.BusinessId == task.BusinessId && other.BusinessUniqueId == task.BusinessUniqueId {
+			return nil, storage.ErrDuplicate
+		}
+	}
+
+	now := s.clock.Now()
+	task.Version++
+	task.CreatedAt = existing.CreatedAt
+	task.UpdatedAt = now
+	normalizeTask(task)
+
+	stored := *task
+	s.tasks[task.Id] = &stored
+
+	if existing.Status == task.Status {
+		return nil, nil
+	}
+	transition := model.NewTaskTransition(ctx, task, existing.Status, task.Status, now)
+	s.nextEventId++
+	s.events[task.Id] = append(s.events[task.Id], &model.TaskEvents{
+		Id:         s.nextEventId,
+		TaskId:     transition.TaskId,
+		BusinessId: transition.BusinessId,
+		FromStatus: transition.From,
+		ToStatus:   transition.To,
+		Actor:      transition.Actor,
+		Reason:     sql.NullString{String: transition.Reason, Valid: transition.Reason != ""},
+		CreatedAt:  now,
+	})
+	return transition, nil
+}
+
+func (t taskStore) Delete(ctx context.Context, id int64) error {
+	s := t.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[id]; !ok {
+		return storage.ErrNotFound
+	}
+	delete(s.tasks, id)
+	// 与数据库的外键级联删除一致
+	delete(s.executions, id)
+	delete(s.events, id)
+	for key, lock := range s.locks {
+		if lock.TaskId == id {
+			delete(s.locks, key)
+		}
+	}
+	return nil
+}
+
+// matches 任务是否满足过滤条件，不考虑分页
+func matches(task *model.Tasks, filter *storage.TaskFilter) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.BusinessId != 0 && task.BusinessId != filter.BusinessId {
+		return false
+	}
+	if len(filter.Status) == 0 {
+		return true
+	}
+	for _, status := range filter.Status {
+		if task.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (t taskStore) List(ctx context.Context, filter *storage.TaskFilter) ([]*model.Tasks, error) {
+	s := t.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tasks []*model.Tasks
+	for _, task := range s.tasks {
+		if filter != nil && task.Id <= filter.AfterId {
+			continue
+		}
+		if matches(task, filter) {
+			found := *task
+			tasks = append(tasks, &found)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Id < tasks[j].Id })
+	if filter != nil && filter.Limit > 0 && len(tasks) > filter.Limit {
+		tasks = tasks[:filter.Limit]
+	}
+	return tasks, nil
+}
+
+func (t taskStore) Count(ctx context.Context, filter *storage.TaskFilter) (int64, error) {
+	s := t.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for _, task := range s.tasks {
+		if matches(task, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// executeAt 任务的执行时间
+func executeAt(task *model.Tasks) time.Time {
+	if task.NextExecuteAt.Valid {
+		return task.NextExecuteAt.Time
+	}
+	return task.ScheduledAt
+}
+
+func (t taskStore) ListDue(ctx context.Context, limit int) ([]*model.Tasks, error) {
+	s := t.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	var tasks []*model.Tasks
+	for _, task := range s.tasks {
+		if task.Status == model.TaskStatusPending && !executeAt(task).After(now) {
+			found := *task
+			tasks = append(tasks, &found)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if !executeAt(a).Equal(executeAt(b)) {
+			return executeAt(a).Before(executeAt(b))
+		}
+		return a.Id < b.Id
+	})
+	if limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
+
+type executionStore struct{ s *Store }
+
+func (e executionStore) Create(ctx context.Context, execution *model.TaskExecutions) error {
+	s := e.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[execution.TaskId]; !ok {
+		// 与数据库的外键约束一致
+		return storage.ErrNotFound
+	}
+
+	s.nextExecutionId++
+	execution.Id = s.nextExecutionId
+	execution.CreatedAt = s.clock.Now()
+	if execution.ExecutionTime.IsZero() {
+		execution.ExecutionTime = execution.CreatedAt
+	}
+	execution.ExecutionTime = storage.DBTime(execution.ExecutionTime)
+	execution.RetryAfter = storage.DBNullTime(execution.RetryAfter)
+
+	stored := *execution
+	s.executions[execution.TaskId] = append(s.executions[execution.TaskId], &stored)
+	return nil
+}
+
+func (e executionStore) ListByTask(ctx context.Context, taskId int64) ([]*model.TaskExecutions, error) {
+	s := e.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var executions []*model.TaskExecutions
+	for _, execution := range s.executions[taskId] {
+		found := *execution
+		executions = append(executions, &found)
+	}
+	sort.SliceStable(executions, func(i, j int) bool {
+		return executions[i].ExecutionSequence < executions[j].ExecutionSequence
+	})
+	return executions, nil
+}
+
+type lockStore struct{ s *Store }
+
+func (l lockStore) Acquire(ctx context.Context, lockKey string, taskId int64, nodeId string, ttl time.Duration) (bool, error) {
+	s := l.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	lock, ok := s.locks[lockKey]
+	if !ok {
+		s.nextLockId++
+		lock = &model.TaskLocks{Id: s.nextLockId, LockKey: lockKey}
+		s.locks[lockKey] = lock
+	} else if lock.NodeId != nodeId && lock.ExpiresAt.After(now) {
+		return false, nil
+	}
+
+	lock.TaskId = taskId
+	lock.NodeId = nodeId
+	lock.LockedAt = now
+	lock.ExpiresAt = storage.DBTime(now.Add(ttl))
+	lock.Version++
+	return true, nil
+}
+
+func (l lockStore) Renew(ctx context.Context, lockKey, nodeId string, ttl time.Duration) (bool, error) {
+	s := l.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.locks[lockKey]
+	if !ok || lock.NodeId != nodeId {
+		return false, nil
+	}
+	lock.ExpiresAt = storage.DBTime(s.clock.Now().Add(ttl))
+	lock.Version++
+	return true, nil
+}
+
+func (l lockStore) Release(ctx context.Context, lockKey, nodeId string) error {
+	s := l.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lock, ok := s.locks[lockKey]; ok && lock.NodeId == nodeId {
+		delete(s.locks, lockKey)
+	}
+	return nil
+}
+
+func (l lockStore) Get(ctx context.Context, lockKey string) (*model.TaskLocks, error) {
+	s := l.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.locks[lockKey]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	found := *lock
+	return &found, nil
+}
+
+type businessStore struct{ s *Store }
+
+// conflicts 是否与其他业务系统的 business_code 或 api_key 重复
+func (b businessStore) conflicts(business *model.BusinessSystems) bool {
+	for _, other := range b.s.businesses {
+		if other.Id != business.Id && (other.BusinessCode == business.BusinessCode || other.ApiKey == business.ApiKey) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b businessStore) Create(ctx context.Context, business *model.BusinessSystems) error {
+	s := b.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	business.Id = 0
+	if b.conflicts(business) {
+		return storage.ErrDuplicate
+	}
+
+	s.nextBusinessId++
+	now := s.clock.Now()
+	business.Id = s.nextBusinessId
+	business.CreatedAt = now
+	business.UpdatedAt = now
+
+	stored := *business
+	s.businesses[business.Id] = &stored
+	return nil
+}
+
+func (b businessStore) Get(ctx context.Context, id int64) (*model.BusinessSystems, error) {
+	s := b.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	business, ok := s.businesses[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	found := *business
+	return &found, nil
+}
+
+func (b businessStore) GetByApiKey(ctx context.Context, apiKey string) (*model.BusinessSystems, error) {
+	s := b.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, business := range s.businesses {
+		if business.ApiKey == apiKey {
+			found := *business
+			return &found, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (b businessStore) Update(ctx context.Context, business *model.BusinessSystems) error {
+	s := b.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.businesses[business.Id]
+	if !ok {
+		return storage.ErrNotFound
+	}
+	if b.conflicts(business) {
+		return storage.ErrDuplicate
+	}
+
+	business.CreatedAt = existing.CreatedAt
+	business.UpdatedAt = s.clock.Now()
+
+	stored := *business
+	s.businesses[business.Id] = &stored
+	return nil
+}
+
+type eventStore struct{ s *Store }
+
+func (e eventStore) ListByTask(ctx context.Context, taskId int64) ([]*model.TaskEvents, error) {
+	s := e.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []*model.TaskEvents
+	for _, event := range s.events[taskId] {
+		found := *event
+		events = append(events, &found)
+	}
+	return events, nil
+}
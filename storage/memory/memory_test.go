@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"task-center/storage"
+	"task-center/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, clock storage.Clock) storage.Store {
+		return New(clock)
+	})
+}
@@ -0,0 +1,303 @@
+// Package mysql 基于 goctl 模型的 MySQL 存储实现，是生产环境使用的存储
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/zeromicro/go-zero/core/stores/builder"
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+
+	"task-center/model"
+	"task-center/storage"
+)
+
+var _ storage.Store = (*Store)(nil)
+
+// mysqlErrDuplicateEntry 唯一键冲突错误码
+const mysqlErrDuplicateEntry = 1062
+
+var (
+	taskRows      = strings.Join(builder.RawFieldNames(&model.Tasks{}), ",")
+	executionRows = strings.Join(builder.RawFieldNames(&model.TaskExecutions{}), ",")
+)
+
+// Store MySQL 存储，单条记录的读写走模型缓存，列表查询直接查库
+type Store struct {
+	conn  sqlx.SqlConn
+	clock storage.Clock
+
+	tasks      model.TasksModel
+	executions model.TaskExecutionsModel
+	locks      model.TaskLocksModel
+	businesses model.BusinessSystemsModel
+	events     model.TaskEventsModel
+
+	metrics *model.QueueMetrics
+}
+
+// New 创建 MySQL 存储，clock 为 nil 时使用当前时间
+// 创建时间和更新时间由数据库填写，clock 只用于到期判断和锁的过期时间
+func New(conn sqlx.SqlConn, c cache.CacheConf, clock storage.Clock, opts ...cache.Option) *Store {
+	return &Store{
+		conn:       conn,
+		clock:      clock,
+		tasks:      model.NewTasksModel(conn, c, opts...),
+		executions: model.NewTaskExecutionsModel(conn, c, opts...),
+		locks:      model.NewTaskLocksModel(conn, c, opts...),
+		businesses: model.NewBusinessSystemsModel(conn, c, opts...),
+		events:     model.NewTaskEventsModel(conn, c, opts...),
+	}
+}
+
+// WithQueueMetrics 上报回调耗时和锁竞争：写入执行记录时按记录的耗时和状态码记录回调耗时，
+// 获取任务锁时使用 QueueMetrics.InstrumentTaskLocks 包装的任务锁模型；需在使用存储前调用
+func (s *Store) WithQueueMetrics(metrics *model.QueueMetrics) *Store {
+	s.metrics = metrics
+	s.locks = metrics.InstrumentTaskLocks(s.locks)
+	return s
+}
+
+// Tasks 返回任务存储
+func (s *Store) Tasks() storage.TaskStore { return taskStore{s} }
+
+// Executions 返回执行记录存储
+func (s *Store) Executions() storage.ExecutionStore { return executionStore{s} }
+
+// Locks 返回任务锁存储
+func (s *Store) Locks() storage.LockStore { return lockStore{s} }
+
+// Businesses 返回业务系统存储
+func (s *Store) Businesses() storage.BusinessStore { return businessStore{s} }
+
+// Events 返回任务事件存储
+func (s *Store) Events() storage.EventStore { return s.events }
+
+// translate 将模型和驱动错误转换为 storage 错误
+func translate(err error) error {
+	if errors.Is(err, model.ErrNotFound) {
+		return storage.ErrNotFound
+	}
+	var conflict *model.TaskConflictError
+	if errors.As(err, &conflict) {
+		return storage.ErrDuplicate
+	}
+	var versionConflict *model.TaskVersionConflictError
+	if errors.As(err, &versionConflict) {
+		return storage.ErrConflict
+	}
+	var transition *model.TaskTransitionError
+	if errors.As(err, &transition) {
+		return storage.ErrInvalidTransition
+	}
+	var mysqlErr *mysqldriver.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+		return storage.ErrDuplicate
+	}
+	return err
+}
+
+type taskStore struct{ s *Store }
+
+func (t taskStore) Create(ctx context.Context, task *model.Tasks) error {
+	created, _, err := t.s.tasks.InsertWithPolicy(ctx, task, model.ConflictPolicyReject)
+	if err != nil {
+		return translate(err)
+	}
+	*task = *created
+	return nil
+}
+
+func (t taskStore) Get(ctx context.Context, id int64) (*model.Tasks, error) {
+	task, err := t.s.tasks.FindOne(ctx, id)
+	return task, translate(err)
+}
+
+func (t taskStore) GetByBusinessUniqueId(ctx context.Context, businessId int64, businessUniqueId string) (*model.Tasks, error) {
+	task, err := t.s.tasks.FindOneByBusinessIdBusinessUniqueId(ctx, businessId, businessUniqueId)
+	return task, translate(err)
+}
+
+func (t taskStore) Update(ctx context.Context, task *model.Tasks) error {
+	return translate(t.s.tasks.UpdateWithVersion(ctx, task))
+}
+
+func (t taskStore) Delete(ctx context.Context, id int64) error {
+	// 执行记录、锁和事件由外键级联删除
+	return translate(t.s.tasks.Delete(ctx, id))
+}
+
+// where 生成过滤条件，不包含分页
+func where(filter *storage.TaskFilter) (string, []any) {
+	if filter == nil {
+		return "1 = 1", nil
+	}
+
+	conditions := []string{"1 = 1"}
+	var args []any
+	if filter.BusinessId != 0 {
+		conditions = append(conditions, "`business_id` = ?")
+		args = append(args, filter.BusinessId)
+	}
+	if len(filter.Status) > 0 {
+		conditions = append(conditions, "`status` in (?"+strings.Repeat(", ?", len(filter.Status)-1)+")")
+		for _, status := range filter.Status {
+			args = append(args, status)
+		}
+	}
+	return strings.Join(conditions, " and "), args
+}
+
+func (t taskStore) List(ctx context.Context, filter *storage.TaskFilter) ([]*model.Tasks, error) {
+	cond, args := where(filter)
+	query := fmt.Sprintf("select %s from `tasks` where %s", taskRows, cond)
+	if filter != nil && filter.AfterId > 0 {
+		query += " and `id` > ?"
+		args = append(args, filter.AfterId)
+	}
+	query += " order by `id`"
+	if filter != nil && filter.Limit > 0 {
+		query += " limit ?"
+		args = append(args, filter.Limit)
+	}
+
+	var tasks []*model.Tasks
+	if err := t.s.conn.QueryRowsCtx(ctx, &tasks, query, args...); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (t taskStore) Count(ctx context.Context, filter *storage.TaskFilter) (int64, error) {
+	cond, args := where(filter)
+	var count int64
+	err := t.s.conn.QueryRowCtx(ctx, &count, "select count(*) from `tasks` where "+cond, args...)
+	return count, err
+}
+
+func (t taskStore) ListDue(ctx context.Context, limit int) ([]*model.Tasks, error) {
+	query := fmt.Sprintf("select %s from `tasks` where `status` = ? and coalesce(`next_execute_at`, `scheduled_at`) <= ? "+
+		"order by `priority`, coalesce(`next_execute_at`, `scheduled_at`), `id`", taskRows)
+	args := []any{model.TaskStatusPending, t.s.clock.Now()}
+	if limit > 0 {
+		query += " limit ?"
+		args = append(args, limit)
+	}
+
+	var tasks []*model.Tasks
+	if err := t.s.conn.QueryRowsCtx(ctx, &tasks, query, args...); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+type executionStore struct{ s *Store }
+
+func (e executionStore) Create(ctx context.Context, execution *model.TaskExecutions) error {
+	task, err := e.s.tasks.FindOne(ctx, execution.TaskId)
+	if err != nil {
+		return translate(err)
+	}
+	if execution.ExecutionTime.IsZero() {
+		execution.ExecutionTime = e.s.clock.Now()
+	}
+	execution.ExecutionTime = storage.DBTime(execution.ExecutionTime)
+	execution.RetryAfter = storage.DBNullTime(execution.RetryAfter)
+
+	result, err := e.s.executions.Insert(ctx, execution)
+	if err != nil {
+		return translate(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	created, err := e.s.executions.FindOne(ctx, id)
+	if err != nil {
+		return err
+	}
+	*execution = *created
+	e.observeCallback(task, execution)
+	return nil
+}
+
+// observeCallback 按执行记录上报回调耗时，没有耗时的记录（如未发出回调）不上报，没有状态码记为未收到响应
+func (e executionStore) observeCallback(task *model.Tasks, execution *model.TaskExecutions) {
+	if e.s.metrics == nil || !execution.Duration.Valid {
+		return
+	}
+	statusCode := 0
+	if execution.HttpStatus.Valid {
+		statusCode = int(execution.HttpStatus.Int64)
+	}
+	e.s.metrics.ObserveCallback(task.CallbackUrl, statusCode, time.Duration(execution.Duration.Int64)*time.Millisecond)
+}
+
+func (e executionStore) ListByTask(ctx context.Context, taskId int64) ([]*model.TaskExecutions, error) {
+	query := fmt.Sprintf("select %s from `task_executions` where `task_id` = ? order by `execution_sequence`, `id`", executionRows)
+	var executions []*model.TaskExecutions
+	if err := e.s.conn.QueryRowsCtx(ctx, &executions, query, taskId); err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
+type lockStore struct{ s *Store }
+
+func (l lockStore) Acquire(ctx context.Context, lockKey string, taskId int64, nodeId string, ttl time.Duration) (bool, error) {
+	now := l.s.clock.Now()
+	return l.s.locks.Acquire(ctx, lockKey, taskId, nodeId, now, storage.DBTime(now.Add(ttl)))
+}
+
+func (l lockStore) Renew(ctx context.Context, lockKey, nodeId string, ttl time.Duration) (bool, error) {
+	return l.s.locks.Renew(ctx, lockKey, nodeId, storage.DBTime(l.s.clock.Now().Add(ttl)))
+}
+
+func (l lockStore) Release(ctx context.Context, lockKey, nodeId string) error {
+	return l.s.locks.Release(ctx, lockKey, nodeId)
+}
+
+func (l lockStore) Get(ctx context.Context, lockKey string) (*model.TaskLocks, error) {
+	lock, err := l.s.locks.FindOneByLockKey(ctx, lockKey)
+	return lock, translate(err)
+}
+
+type businessStore struct{ s *Store }
+
+func (b businessStore) Create(ctx context.Context, business *model.BusinessSystems) error {
+	result, err := b.s.businesses.Insert(ctx, business)
+	if err != nil {
+		return translate(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	created, err := b.s.businesses.FindOne(ctx, id)
+	if err != nil {
+		return err
+	}
+	*business = *created
+	return nil
+}
+
+func (b businessStore) Get(ctx context.Context, id int64) (*model.BusinessSystems, error) {
+	business, err := b.s.businesses.FindOne(ctx, id)
+	return business, translate(err)
+}
+
+func (b businessStore) GetByApiKey(ctx context.Context, apiKey string) (*model.BusinessSystems, error) {
+	business, err := b.s.businesses.FindOneByApiKey(ctx, apiKey)
+	return business, translate(err)
+}
+
+func (b businessStore) Update(ctx context.Context, business *model.BusinessSystems) error {
+	return translate(b.s.businesses.Update(ctx, business))
+}
@@ -0,0 +1,81 @@
+//go:build integration
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+
+	"task-center/database"
+	"task-center/model"
+	"task-center/storage"
+	"task-center/storage/storagetest"
+)
+
+// 需要真实的 MySQL 和 Redis，测试会清空任务相关的表
+// 运行命令: TASKCENTER_DSN=... TASKCENTER_REDIS=localhost:6379 go test -tags=integration ./storage/mysql
+func TestConformance(t *testing.T) {
+	dsn, redisAddr := os.Getenv("TASKCENTER_DSN"), os.Getenv("TASKCENTER_REDIS")
+	if dsn == "" || redisAddr == "" {
+		t.Skip("TASKCENTER_DSN and TASKCENTER_REDIS are required")
+	}
+
+	cfg, err := mysqldriver.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("Invalid TASKCENTER_DSN: %v", err)
+	}
+	cfg.MultiStatements = true
+	cfg.ParseTime = true
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("Failed to open mysql: %v", err)
+	}
+	defer db.Close()
+	if err := database.NewMigrationManager(db, nil).RunMigrations(); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	redisConf := redis.RedisConf{Host: redisAddr, Type: redis.NodeType}
+	rds := redis.MustNewRedis(redisConf)
+	cacheConf := cache.CacheConf{{RedisConf: redisConf, Weight: 100}}
+
+	storagetest.Run(t, func(t *testing.T, clock storage.Clock) storage.Store {
+		reset(t, db, rds)
+		// 带上队列指标运行，包装后的任务锁同样需要通过一致性测试
+		return New(sqlx.NewSqlConnFromDB(db), cacheConf, clock).WithQueueMetrics(model.NewQueueMetrics(nil, nil))
+	})
+}
+
+// reset 清空数据和模型缓存，并创建一致性测试使用的业务系统 1 和 2
+func reset(t *testing.T, db *sql.DB, rds *redis.Redis) {
+	ctx := context.Background()
+	for _, table := range []string{"task_executions", "task_locks", "task_tags", "task_events", "tasks", "bulk_jobs", "business_systems"} {
+		if _, err := db.ExecContext(ctx, "delete from "+table); err != nil {
+			t.Fatalf("Failed to clear %s: %v", table, err)
+		}
+	}
+
+	keys, err := rds.KeysCtx(ctx, "cache:*")
+	if err != nil {
+		t.Fatalf("Failed to list cache keys: %v", err)
+	}
+	if len(keys) > 0 {
+		if _, err := rds.DelCtx(ctx, keys...); err != nil {
+			t.Fatalf("Failed to clear cache: %v", err)
+		}
+	}
+
+	for _, id := range []int64{1, 2} {
+		if _, err := db.ExecContext(ctx, "insert into business_systems (id, business_code, business_name, api_key, api_secret) "+
+			"values (?, concat('storagetest-', ?), 'storagetest', concat('storagetest-key-', ?), 'secret')", id, id, id); err != nil {
+			t.Fatalf("Failed to create business %d: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,550 @@
+// Package sqlite 基于 SQLite 的存储实现，用于本地开发和不依赖 MySQL 的集成测试
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"task-center/model"
+	"task-center/storage"
+)
+
+var _ storage.Store = (*Store)(nil)
+
+// schema 与 core_tables_no_fk.sql 对应的 SQLite 表结构
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS business_systems (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  business_code TEXT NOT NULL UNIQUE,
+  business_name TEXT NOT NULL,
+  api_key TEXT NOT NULL UNIQUE,
+  api_secret TEXT NOT NULL,
+  rate_limit INTEGER NOT NULL DEFAULT 1000,
+  status INTEGER NOT NULL DEFAULT 1,
+  description TEXT,
+  contact_info TEXT,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL
+)`,
+	`CREATE TABLE IF NOT EXISTS tasks (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  business_id INTEGER NOT NULL,
+  business_unique_id TEXT NOT NULL,
+  callback_url TEXT NOT NULL,
+  callback_method TEXT NOT NULL DEFAULT 'POST',
+  callback_headers TEXT,
+  callback_body TEXT,
+  retry_intervals TEXT NOT NULL DEFAULT '[60,300,900]',
+  max_retries INTEGER NOT NULL DEFAULT 3,
+  current_retry INTEGER NOT NULL DEFAULT 0,
+  status INTEGER NOT NULL DEFAULT 0,
+  priority INTEGER NOT NULL DEFAULT 5,
+  tags TEXT,
+  timeout INTEGER NOT NULL DEFAULT 30,
+  scheduled_at DATETIME NOT NULL,
+  next_execute_at DATETIME,
+  executed_at DATETIME,
+  completed_at DATETIME,
+  error_message TEXT,
+  metadata TEXT,
+  version INTEGER NOT NULL DEFAULT 1,
+  trace_parent TEXT,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL,
+  UNIQUE (business_id, business_unique_id)
+)`,
+	`CREATE INDEX IF NOT EXISTS idx_tasks_status_priority ON tasks (status, priority)`,
+	`CREATE TABLE IF NOT EXISTS task_executions (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  task_id INTEGER NOT NULL,
+  execution_sequence INTEGER NOT NULL,
+  execution_time DATETIME NOT NULL,
+  duration INTEGER,
+  http_status INTEGER,
+  response_headers TEXT,
+  response_data TEXT,
+  error_message TEXT,
+  retry_after DATETIME,
+  execution_node TEXT,
+  trace_id TEXT,
+  created_at DATETIME NOT NULL
+)`,
+	`CREATE INDEX IF NOT EXISTS idx_task_executions_task_sequence ON task_executions (task_id, execution_sequence)`,
+	`CREATE TABLE IF NOT EXISTS task_events (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  task_id INTEGER NOT NULL,
+  business_id INTEGER NOT NULL,
+  from_status INTEGER NOT NULL,
+  to_status INTEGER NOT NULL,
+  actor TEXT NOT NULL,
+  reason TEXT,
+  created_at DATETIME NOT NULL
+)`,
+	`CREATE INDEX IF NOT EXISTS idx_task_events_task_id ON task_events (task_id, id)`,
+	`CREATE TABLE IF NOT EXISTS task_locks (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  task_id INTEGER NOT NULL,
+  lock_key TEXT NOT NULL UNIQUE,
+  node_id TEXT NOT NULL,
+  locked_at DATETIME NOT NULL,
+  expires_at DATETIME NOT NULL,
+  version INTEGER NOT NULL DEFAULT 1
+)`,
+}
+
+const (
+	taskColumns = "id, business_id, business_unique_id, callback_url, callback_method, callback_headers, callback_body, " +
+		"retry_intervals, max_retries, current_retry, status, priority, tags, timeout, scheduled_at, next_execute_at, " +
+		"executed_at, completed_at, error_message, metadata, version, trace_parent, created_at, updated_at"
+	executionColumns = "id, task_id, execution_sequence, execution_time, duration, http_status, response_headers, " +
+		"response_data, error_message, retry_after, execution_node, trace_id, created_at"
+	lockColumns     = "id, task_id, lock_key, node_id, locked_at, expires_at, version"
+	eventColumns    = "id, task_id, business_id, from_status, to_status, actor, reason, created_at"
+	businessColumns = "id, business_code, business_name, api_key, api_secret, rate_limit, status, description, " +
+		"contact_info, created_at, updated_at"
+)
+
+// Store SQLite 存储
+type Store struct {
+	db    *sql.DB
+	clock storage.Clock
+}
+
+// New 创建 SQLite 存储并建表，clock 为 nil 时使用当前时间
+// SQLite 同一时间只允许一个写事务，调用方应设置 db.SetMaxOpenConns(1)
+func New(ctx context.Context, db *sql.DB, clock storage.Clock) (*Store, error) {
+	for _, stmt := range schema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, err
+		}
+	}
+	return &Store{db: db, clock: clock}, nil
+}
+
+// Tasks 返回任务存储
+func (s *Store) Tasks() storage.TaskStore { return taskStore{s} }
+
+// Executions 返回执行记录存储
+func (s *Store) Executions() storage.ExecutionStore { return executionStore{s} }
+
+// Locks 返回任务锁存储
+func (s *Store) Locks() storage.LockStore { return lockStore{s} }
+
+// Businesses 返回业务系统存储
+func (s *Store) Businesses() storage.BusinessStore { return businessStore{s} }
+
+// Events 返回任务事件存储
+func (s *Store) Events() storage.EventStore { return eventStore{s} }
+
+// scanner 兼容 *sql.Row 和 *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// translate 将驱动错误转换为 storage 错误
+func translate(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return storage.ErrDuplicate
+	}
+	return err
+}
+
+// requireAffected 更新或删除没有命中任何记录时返回 ErrNotFound
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+type taskStore struct{ s *Store }
+
+func scanTask(row scanner) (*model.Tasks, error) {
+	var task model.Tasks
+	err := row.Scan(&task.Id, &task.BusinessId, &task.BusinessUniqueId, &task.CallbackUrl, &task.CallbackMethod,
+		&task.CallbackHeaders, &task.CallbackBody, &task.RetryIntervals, &task.MaxRetries, &task.CurrentRetry,
+		&task.Status, &task.Priority, &task.Tags, &task.Timeout, &task.ScheduledAt, &task.NextExecuteAt,
+		&task.ExecutedAt, &task.CompletedAt, &task.ErrorMessage, &task.Metadata, &task.Version, &task.TraceParent, &task.CreatedAt, &task.UpdatedAt)
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &task, nil
+}
+
+func (t taskStore) queryTasks(ctx context.Context, query string, args ...any) ([]*model.Tasks, error) {
+	rows, err := t.s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*model.Tasks
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+func (t taskStore) Create(ctx context.Context, task *model.Tasks) error {
+	now := t.s.clock.Now()
+	result, err := t.s.db.ExecContext(ctx, "INSERT INTO tasks ("+strings.TrimPrefix(taskColumns, "id, ")+") "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1, ?, ?, ?)",
+		task.BusinessId, task.BusinessUniqueId, task.CallbackUrl, task.CallbackMethod, task.CallbackHeaders,
+		task.CallbackBody, task.RetryIntervals, task.MaxRetries, task.CurrentRetry, task.Status, task.Priority,
+		task.Tags, task.Timeout, storage.DBTime(task.ScheduledAt), storage.DBNullTime(task.NextExecuteAt),
+		storage.DBNullTime(task.ExecutedAt), storage.DBNullTime(task.CompletedAt), task.ErrorMessage, task.Metadata, task.TraceParent, now, now)
+	if err != nil {
+		return translate(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	created, err := t.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	*task = *created
+	return nil
+}
+
+func (t taskStore) Get(ctx context.Context, id int64) (*model.Tasks, error) {
+	return scanTask(t.s.db.QueryRowContext(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id = ?", id))
+}
+
+func (t taskStore) GetByBusinessUniqueId(ctx context.Context, businessId int64, businessUniqueId string) (*model.Tasks, error) {
+	return scanTask(t.s.db.QueryRowContext(ctx, "SELECT "+taskColumns+" FROM tasks WHERE business_id = ? AND business_unique_id = ?",
+		businessId, businessUniqueId))
+}
+
+func (t taskStore) Update(ctx context.Context, task *model.Tasks) error {
+	tx, err := t.s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status, version int64
+	if err := tx.QueryRowContext(ctx, "SELECT status, version FROM tasks WHERE id = ?", task.Id).Scan(&status, &version); err != nil {
+		return translate(err)
+	}
+	if version != task.Version {
+		return storage.ErrConflict
+	}
+	if !model.DefaultTaskStateMachine.Can(status, task.Status) {
+		return storage.ErrInvalidTransition
+	}
+
+	now := t.s.clock.Now()
+	_, err = tx.ExecContext(ctx, "UPDATE tasks SET business_id = ?, business_unique_id = ?, callback_url = ?, "+
+		"callback_method = ?, callback_headers = ?, callback_body = ?, retry_intervals = ?, max_retries = ?, current_retry = ?, "+
+		"status = ?, priority = ?, tags = ?, timeout = ?, scheduled_at = ?, next_execute_at = ?, executed_at = ?, "+
+		"completed_at = ?, error_message = ?, metadata = ?, trace_parent = ?, version = version + 1, updated_at = ? WHERE id = ?",
+		task.BusinessId, task.BusinessUniqueId, task.CallbackUrl, task.CallbackMethod, task.CallbackHeaders,
+		task.CallbackBody, task.RetryIntervals, task.MaxRetries, task.CurrentRetry, task.Status, task.Priority,
+		task.Tags, task.Timeout, storage.DBTime(task.ScheduledAt), storage.DBNullTime(task.NextExecuteAt),
+		storage.DBNullTime(task.ExecutedAt), storage.DBNullTime(task.CompletedAt), task.ErrorMessage, task.Metadata,
+		task.TraceParent, now, task.Id)
+	if err != nil {
+		return translate(err)
+	}
+
+	var transition *model.TaskTransition
+	if status != task.Status {
+		transition = model.NewTaskTransition(ctx, task, status, task.Status, now)
+		_, err = tx.ExecContext(ctx, "INSERT INTO task_events ("+strings.TrimPrefix(eventColumns, "id, ")+") "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?)", transition.TaskId, transition.BusinessId, transition.From, transition.To,
+			transition.Actor, sql.NullString{String: transition.Reason, Valid: transition.Reason != ""}, now)
+		if err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	task.Version++
+	if transition != nil {
+		model.DefaultTaskStateMachine.Fire(ctx, transition)
+	}
+	return nil
+}
+
+func (t taskStore) Delete(ctx context.Context, id int64) error {
+	tx, err := t.s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM tasks WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+	// 与 MySQL 的外键级联删除一致
+	for _, table := range []string{"task_executions", "task_locks", "task_events"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE task_id = ?", id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// where 生成过滤条件，不包含分页
+func where(filter *storage.TaskFilter) (string, []any) {
+	if filter == nil {
+		return "1 = 1", nil
+	}
+
+	conditions := []string{"1 = 1"}
+	var args []any
+	if filter.BusinessId != 0 {
+		conditions = append(conditions, "business_id = ?")
+		args = append(args, filter.BusinessId)
+	}
+	if len(filter.Status) > 0 {
+		conditions = append(conditions, "status IN (?"+strings.Repeat(", ?", len(filter.Status)-1)+")")
+		for _, status := range filter.Status {
+			args = append(args, status)
+		}
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+func (t taskStore) List(ctx context.Context, filter *storage.TaskFilter) ([]*model.Tasks, error) {
+	cond, args := where(filter)
+	query := "SELECT " + taskColumns + " FROM tasks WHERE " + cond
+	if filter != nil && filter.AfterId > 0 {
+		query += " AND id > ?"
+		args = append(args, filter.AfterId)
+	}
+	query += " ORDER BY id"
+	if filter != nil && filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+	return t.queryTasks(ctx, query, args...)
+}
+
+func (t taskStore) Count(ctx context.Context, filter *storage.TaskFilter) (int64, error) {
+	cond, args := where(filter)
+	var count int64
+	err := t.s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM tasks WHERE "+cond, args...).Scan(&count)
+	return count, err
+}
+
+func (t taskStore) ListDue(ctx context.Context, limit int) ([]*model.Tasks, error) {
+	query := "SELECT " + taskColumns + " FROM tasks WHERE status = ? AND COALESCE(next_execute_at, scheduled_at) <= ? " +
+		"ORDER BY priority, COALESCE(next_execute_at, scheduled_at), id"
+	args := []any{model.TaskStatusPending, t.s.clock.Now()}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	return t.queryTasks(ctx, query, args...)
+}
+
+type executionStore struct{ s *Store }
+
+func (e executionStore) Create(ctx context.Context, execution *model.TaskExecutions) error {
+	now := e.s.clock.Now()
+	if execution.ExecutionTime.IsZero() {
+		execution.ExecutionTime = now
+	}
+
+	tx, err := e.s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 与 MySQL 的外键约束一致
+	var exists int
+	if err := tx.QueryRowContext(ctx, "SELECT 1 FROM tasks WHERE id = ?", execution.TaskId).Scan(&exists); err != nil {
+		return translate(err)
+	}
+
+	result, err := tx.ExecContext(ctx, "INSERT INTO task_executions ("+strings.TrimPrefix(executionColumns, "id, ")+") "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		execution.TaskId, execution.ExecutionSequence, storage.DBTime(execution.ExecutionTime), execution.Duration,
+		execution.HttpStatus, execution.ResponseHeaders, execution.ResponseData, execution.ErrorMessage,
+		storage.DBNullTime(execution.RetryAfter), execution.ExecutionNode, execution.TraceId, now)
+	if err != nil {
+		return translate(err)
+	}
+	if execution.Id, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	execution.ExecutionTime = storage.DBTime(execution.ExecutionTime)
+	execution.RetryAfter = storage.DBNullTime(execution.RetryAfter)
+	execution.CreatedAt = now
+	return nil
+}
+
+func (e executionStore) ListByTask(ctx context.Context, taskId int64) ([]*model.TaskExecutions, error) {
+	rows, err := e.s.db.QueryContext(ctx, "SELECT "+executionColumns+" FROM task_executions WHERE task_id = ? "+
+		"ORDER BY execution_sequence, id", taskId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []*model.TaskExecutions
+	for rows.Next() {
+		var execution model.TaskExecutions
+		if err := rows.Scan(&execution.Id, &execution.TaskId, &execution.ExecutionSequence, &execution.ExecutionTime,
+			&execution.Duration, &execution.HttpStatus, &execution.ResponseHeaders, &execution.ResponseData,
+			&execution.ErrorMessage, &execution.RetryAfter, &execution.ExecutionNode, &execution.TraceId,
+			&execution.CreatedAt); err != nil {
+			return nil, err
+		}
+		executions = append(executions, &execution)
+	}
+	return executions, rows.Err()
+}
+
+type lockStore struct{ s *Store }
+
+func (l lockStore) Acquire(ctx context.Context, lockKey string, taskId int64, nodeId string, ttl time.Duration) (bool, error) {
+	now := l.s.clock.Now()
+	expiresAt := storage.DBTime(now.Add(ttl))
+
+	// 锁已过期或已由本节点持有时接管，否则尝试插入新锁，唯一索引保证只有一个节点成功
+	result, err := l.s.db.ExecContext(ctx, "UPDATE task_locks SET task_id = ?, node_id = ?, locked_at = ?, expires_at = ?, "+
+		"version = version + 1 WHERE lock_key = ? AND (expires_at <= ? OR node_id = ?)",
+		taskId, nodeId, now, expiresAt, lockKey, now, nodeId)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 1 {
+		return affected == 1, err
+	}
+
+	result, err = l.s.db.ExecContext(ctx, "INSERT OR IGNORE INTO task_locks (task_id, lock_key, node_id, locked_at, expires_at, version) "+
+		"VALUES (?, ?, ?, ?, ?, 1)", taskId, lockKey, nodeId, now, expiresAt)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+func (l lockStore) Renew(ctx context.Context, lockKey, nodeId string, ttl time.Duration) (bool, error) {
+	result, err := l.s.db.ExecContext(ctx, "UPDATE task_locks SET expires_at = ?, version = version + 1 "+
+		"WHERE lock_key = ? AND node_id = ?", storage.DBTime(l.s.clock.Now().Add(ttl)), lockKey, nodeId)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+func (l lockStore) Release(ctx context.Context, lockKey, nodeId string) error {
+	_, err := l.s.db.ExecContext(ctx, "DELETE FROM task_locks WHERE lock_key = ? AND node_id = ?", lockKey, nodeId)
+	return err
+}
+
+func (l lockStore) Get(ctx context.Context, lockKey string) (*model.TaskLocks, error) {
+	var lock model.TaskLocks
+	err := l.s.db.QueryRowContext(ctx, "SELECT "+lockColumns+" FROM task_locks WHERE lock_key = ?", lockKey).
+		Scan(&lock.Id, &lock.TaskId, &lock.LockKey, &lock.NodeId, &lock.LockedAt, &lock.ExpiresAt, &lock.Version)
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &lock, nil
+}
+
+type businessStore struct{ s *Store }
+
+func scanBusiness(row scanner) (*model.BusinessSystems, error) {
+	var business model.BusinessSystems
+	err := row.Scan(&business.Id, &business.BusinessCode, &business.BusinessName, &business.ApiKey, &business.ApiSecret,
+		&business.RateLimit, &business.Status, &business.Description, &business.ContactInfo, &business.CreatedAt,
+		&business.UpdatedAt)
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &business, nil
+}
+
+func (b businessStore) Create(ctx context.Context, business *model.BusinessSystems) error {
+	now := b.s.clock.Now()
+	result, err := b.s.db.ExecContext(ctx, "INSERT INTO business_systems ("+strings.TrimPrefix(businessColumns, "id, ")+") "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		business.BusinessCode, business.BusinessName, business.ApiKey, business.ApiSecret, business.RateLimit,
+		business.Status, business.Description, business.ContactInfo, now, now)
+	if err != nil {
+		return translate(err)
+	}
+	if business.Id, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	business.CreatedAt = now
+	business.UpdatedAt = now
+	return nil
+}
+
+func (b businessStore) Get(ctx context.Context, id int64) (*model.BusinessSystems, error) {
+	return scanBusiness(b.s.db.QueryRowContext(ctx, "SELECT "+businessColumns+" FROM business_systems WHERE id = ?", id))
+}
+
+func (b businessStore) GetByApiKey(ctx context.Context, apiKey string) (*model.BusinessSystems, error) {
+	return scanBusiness(b.s.db.QueryRowContext(ctx, "SELECT "+businessColumns+" FROM business_systems WHERE api_key = ?", apiKey))
+}
+
+func (b businessStore) Update(ctx context.Context, business *model.BusinessSystems) error {
+	result, err := b.s.db.ExecContext(ctx, "UPDATE business_systems SET business_code = ?, business_name = ?, api_key = ?, "+
+		"api_secret = ?, rate_limit = ?, status = ?, description = ?, contact_info = ?, updated_at = ? WHERE id = ?",
+		business.BusinessCode, business.BusinessName, business.ApiKey, business.ApiSecret, business.RateLimit,
+		business.Status, business.Description, business.ContactInfo, b.s.clock.Now(), business.Id)
+	if err != nil {
+		return translate(err)
+	}
+	return requireAffected(result)
+}
+
+type eventStore struct{ s *Store }
+
+func (e eventStore) ListByTask(ctx context.Context, taskId int64) ([]*model.TaskEvents, error) {
+	rows, err := e.s.db.QueryContext(ctx, "SELECT "+eventColumns+" FROM task_events WHERE task_id = ? ORDER BY id", taskId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*model.TaskEvents
+	for rows.Next() {
+		var event model.TaskEvents
+		if err := rows.Scan(&event.Id, &event.TaskId, &event.BusinessId, &event.FromStatus, &event.ToStatus,
+			&event.Actor, &event.Reason, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
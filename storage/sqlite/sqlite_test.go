@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"task-center/storage"
+	"task-center/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, clock storage.Clock) storage.Store {
+		db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "storage.db"))
+		if err != nil {
+			t.Fatalf("Failed to open sqlite: %v", err)
+		}
+		db.SetMaxOpenConns(1)
+		t.Cleanup(func() { db.Close() })
+
+		store, err := New(context.Background(), db, clock)
+		if err != nil {
+			t.Fatalf("Failed to create store: %v", err)
+		}
+		return store
+	})
+}
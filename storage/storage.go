@@ -0,0 +1,128 @@
+// Package storage 定义任务中心服务使用的存储接口
+//
+// 业务逻辑只依赖 Store，生产环境使用 storage/mysql（基于 goctl 模型），
+// 单元测试使用 storage/memory 或 storage/sqlite，不需要启动 MySQL 和 Redis。
+// 所有实现都需要通过 storage/storagetest 中的一致性测试。
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"task-center/model"
+)
+
+var (
+	// ErrNotFound 记录不存在
+	ErrNotFound = errors.New("storage: record not found")
+	// ErrDuplicate 违反唯一约束，如 (business_id, business_unique_id) 已存在
+	ErrDuplicate = errors.New("storage: duplicate record")
+	// ErrConflict 按版本号更新时记录已被修改，调用方应重新读取后再更新
+	ErrConflict = errors.New("storage: version conflict")
+	// ErrInvalidTransition 不允许的任务状态变更，见 model.TaskStateMachine
+	ErrInvalidTransition = errors.New("storage: invalid status transition")
+)
+
+// Clock 时间来源，为 nil 时使用 time.Now，测试中可以注入固定时间
+type Clock func() time.Time
+
+// Now 返回当前时间，已按 DBTime 处理
+func (c Clock) Now() time.Time {
+	if c == nil {
+		return DBTime(time.Now())
+	}
+	return DBTime(c())
+}
+
+// DBTime 统一使用 UTC 并截断到秒，与 MySQL timestamp 的精度一致，保证各实现的比较结果相同
+func DBTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// DBNullTime 对可空时间执行 DBTime
+func DBNullTime(t sql.NullTime) sql.NullTime {
+	if !t.Valid {
+		return t
+	}
+	return sql.NullTime{Time: DBTime(t.Time), Valid: true}
+}
+
+// Store 任务中心的存储
+type Store interface {
+	Tasks() TaskStore
+	Executions() ExecutionStore
+	Locks() LockStore
+	Businesses() BusinessStore
+	Events() EventStore
+}
+
+// TaskFilter 任务列表过滤条件，结果按 id 升序
+type TaskFilter struct {
+	BusinessId int64   // 业务系统ID，0 表示不限
+	Status     []int64 // 任务状态，为空表示不限
+	AfterId    int64   // 只返回 id 大于该值的任务，用于分页，Count 忽略该条件
+	Limit      int     // 最多返回的条数，0 表示不限，Count 忽略该条件
+}
+
+// TaskStore 任务存储
+type TaskStore interface {
+	// Create 创建任务，成功后回填 Id、Version、CreatedAt、UpdatedAt；(BusinessId, BusinessUniqueId) 已存在时返回 ErrDuplicate
+	Create(ctx context.Context, task *model.Tasks) error
+	// Get 按ID查询任务
+	Get(ctx context.Context, id int64) (*model.Tasks, error)
+	// GetByBusinessUniqueId 按业务唯一ID查询任务
+	GetByBusinessUniqueId(ctx context.Context, businessId int64, businessUniqueId string) (*model.Tasks, error)
+	// Update 按 Id 覆盖任务的所有字段，task.Version 为读取时的版本，成功后加一
+	// 版本不一致时返回 ErrConflict，状态变更不被 model.DefaultTaskStateMachine 允许时返回 ErrInvalidTransition
+	// 状态变化时同时写入事件，操作者和原因来自 model.WithTaskActor，提交后执行状态机钩子
+	Update(ctx context.Context, task *model.Tasks) error
+	// Delete 删除任务，任务不存在时返回 ErrNotFound
+	Delete(ctx context.Context, id int64) error
+	// List 按条件查询任务
+	List(ctx context.Context, filter *TaskFilter) ([]*model.Tasks, error)
+	// Count 按条件统计任务数
+	Count(ctx context.Context, filter *TaskFilter) (int64, error)
+	// ListDue 查询已到执行时间的待执行任务，按优先级、执行时间、id 排序
+	// 执行时间为 next_execute_at，未设置时为 scheduled_at
+	ListDue(ctx context.Context, limit int) ([]*model.Tasks, error)
+}
+
+// ExecutionStore 任务执行记录存储
+type ExecutionStore interface {
+	// Create 写入执行记录，成功后回填 Id 和 CreatedAt
+	Create(ctx context.Context, execution *model.TaskExecutions) error
+	// ListByTask 按执行序号返回任务的所有执行记录
+	ListByTask(ctx context.Context, taskId int64) ([]*model.TaskExecutions, error)
+}
+
+// LockStore 任务执行锁存储，锁以 lock_key 区分，过期后可被其他节点获取
+type LockStore interface {
+	// Acquire 获取锁，锁不存在、已过期或已由 nodeId 持有时成功，每次成功获取 version 加一
+	Acquire(ctx context.Context, lockKey string, taskId int64, nodeId string, ttl time.Duration) (bool, error)
+	// Renew 延长 nodeId 持有的锁，锁已被其他节点获取时返回 false
+	Renew(ctx context.Context, lockKey, nodeId string, ttl time.Duration) (bool, error)
+	// Release 释放 nodeId 持有的锁，锁已被其他节点获取时不做任何操作
+	Release(ctx context.Context, lockKey, nodeId string) error
+	// Get 查询锁的当前状态
+	Get(ctx context.Context, lockKey string) (*model.TaskLocks, error)
+}
+
+// BusinessStore 业务系统存储
+type BusinessStore interface {
+	// Create 创建业务系统，成功后回填 Id、CreatedAt、UpdatedAt；business_code 或 api_key 已存在时返回 ErrDuplicate
+	Create(ctx context.Context, business *model.BusinessSystems) error
+	// Get 按ID查询业务系统
+	Get(ctx context.Context, id int64) (*model.BusinessSystems, error)
+	// GetByApiKey 按 API Key 查询业务系统，用于请求认证
+	GetByApiKey(ctx context.Context, apiKey string) (*model.BusinessSystems, error)
+	// Update 按 Id 覆盖业务系统的所有字段
+	Update(ctx context.Context, business *model.BusinessSystems) error
+}
+
+// EventStore 任务状态变更事件存储，事件由 TaskStore.Update 写入
+type EventStore interface {
+	// ListByTask 按发生顺序返回任务的状态变更事件
+	ListByTask(ctx context.Context, taskId int64) ([]*model.TaskEvents, error)
+}
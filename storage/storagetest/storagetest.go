@@ -0,0 +1,600 @@
+// Package storagetest 存储实现的一致性测试
+//
+// 新的存储实现在自己的测试中调用 Run，保证与 MySQL 实现的行为一致：
+//
+//	func TestConformance(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T, clock storage.Clock) storage.Store {
+//			return memory.New(clock)
+//		})
+//	}
+package storagetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"task-center/model"
+	"task-center/storage"
+)
+
+// Factory 创建一个空的存储，所有时间都从 clock 获取
+type Factory func(t *testing.T, clock storage.Clock) storage.Store
+
+// Clock 测试时钟，可以手动推进
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock 创建从 start 开始的测试时钟
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: storage.DBTime(start)}
+}
+
+// Now 返回当前时间
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance 推进时钟
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// env 单个用例的测试环境
+type env struct {
+	ctx   context.Context
+	store storage.Store
+	clock *Clock
+}
+
+// Run 运行一致性测试，每个用例使用 factory 创建的新存储
+func Run(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, e *env)
+	}{
+		{"TaskCreateAndGet", testTaskCreateAndGet},
+		{"TaskUpdate", testTaskUpdate},
+		{"TaskUpdateConflict", testTaskUpdateConflict},
+		{"TaskEvents", testTaskEvents},
+		{"TaskDelete", testTaskDelete},
+		{"TaskListAndCount", testTaskListAndCount},
+		{"TaskListDue", testTaskListDue},
+		{"Executions", testExecutions},
+		{"Locks", testLocks},
+		{"Businesses", testBusinesses},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewClock(time.Date(2025, 9, 15, 8, 0, 0, 0, time.UTC))
+			tt.fn(t, &env{
+				ctx:   context.Background(),
+				store: factory(t, clock.Now),
+				clock: clock,
+			})
+		})
+	}
+}
+
+// newTask 创建测试任务，计划时间为当前时间
+func (e *env) newTask(t *testing.T, businessId int64, uniqueId string, mutate func(task *model.Tasks)) *model.Tasks {
+	t.Helper()
+	task := &model.Tasks{
+		BusinessId:       businessId,
+		BusinessUniqueId: uniqueId,
+		CallbackUrl:      "https://example.com/callback",
+		CallbackMethod:   "POST",
+		RetryIntervals:   "[60,300,900]",
+		MaxRetries:       3,
+		Status:           model.TaskStatusPending,
+		Priority:         5,
+		Timeout:          30,
+		ScheduledAt:      e.clock.Now(),
+	}
+	if mutate != nil {
+		mutate(task)
+	}
+	if err := e.store.Tasks().Create(e.ctx, task); err != nil {
+		t.Fatalf("Failed to create task %s: %v", uniqueId, err)
+	}
+	return task
+}
+
+// ids 返回任务ID列表
+func ids(tasks []*model.Tasks) string {
+	var result []int64
+	for _, task := range tasks {
+		result = append(result, task.Id)
+	}
+	return fmt.Sprint(result)
+}
+
+func testTaskCreateAndGet(t *testing.T, e *env) {
+	task := e.newTask(t, 1, "order-1", func(task *model.Tasks) {
+		task.CallbackHeaders = sql.NullString{String: `{"X-Token":"abc"}`, Valid: true}
+		task.Metadata = sql.NullString{String: `{"order":1}`, Valid: true}
+		task.TraceParent = sql.NullString{String: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", Valid: true}
+		task.NextExecuteAt = sql.NullTime{Time: e.clock.Now().Add(time.Minute), Valid: true}
+	})
+	if task.Id <= 0 {
+		t.Fatalf("Expected id to be assigned, got %d", task.Id)
+	}
+	// MySQL 的创建时间由数据库填写，这里只要求已回填
+	if task.CreatedAt.IsZero() || task.UpdatedAt.IsZero() {
+		t.Errorf("Expected timestamps to be assigned, got created_at %v, updated_at %v", task.CreatedAt, task.UpdatedAt)
+	}
+
+	got, err := e.store.Tasks().Get(e.ctx, task.Id)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.BusinessUniqueId != "order-1" || got.CallbackHeaders != task.CallbackHeaders || got.Metadata != task.Metadata ||
+		got.TraceParent != task.TraceParent || got.RetryIntervals != task.RetryIntervals || got.Priority != 5 || got.Status != model.TaskStatusPending {
+		t.Errorf("Unexpected task: %+v", got)
+	}
+	if !got.ScheduledAt.Equal(task.ScheduledAt) || !got.NextExecuteAt.Valid || !got.NextExecuteAt.Time.Equal(task.NextExecuteAt.Time) {
+		t.Errorf("Times not preserved: scheduled %v, next %v", got.ScheduledAt, got.NextExecuteAt)
+	}
+	if got.ExecutedAt.Valid || got.ErrorMessage.Valid {
+		t.Errorf("Expected null columns to stay null: %+v", got)
+	}
+
+	byUnique, err := e.store.Tasks().GetByBusinessUniqueId(e.ctx, 1, "order-1")
+	if err != nil || byUnique.Id != task.Id {
+		t.Errorf("GetByBusinessUniqueId returned %+v, %v", byUnique, err)
+	}
+
+	duplicate := *task
+	if err := e.store.Tasks().Create(e.ctx, &duplicate); !errors.Is(err, storage.ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate, got %v", err)
+	}
+	e.newTask(t, 2, "order-1", nil)
+
+	if _, err := e.store.Tasks().Get(e.ctx, task.Id+1000); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := e.store.Tasks().GetByBusinessUniqueId(e.ctx, 3, "order-1"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func testTaskUpdate(t *testing.T, e *env) {
+	task := e.newTask(t, 1, "order-1", nil)
+	if task.Version != 1 {
+		t.Fatalf("Expected version 1 after create, got %d", task.Version)
+	}
+
+	task.Status = model.TaskStatusRunning
+	if err := e.store.Tasks().Update(e.ctx, task); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if task.Version != 2 {
+		t.Errorf("Expected version 2 after update, got %d", task.Version)
+	}
+
+	e.clock.Advance(time.Minute)
+	task.Status = model.TaskStatusFailed
+	task.CurrentRetry = 1
+	task.ErrorMessage = sql.NullString{String: "timeout", Valid: true}
+	task.ExecutedAt = sql.NullTime{Time: e.clock.Now(), Valid: true}
+	task.NextExecuteAt = sql.NullTime{Time: e.clock.Now().Add(5 * time.Minute), Valid: true}
+	if err := e.store.Tasks().Update(e.ctx, task); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	got, err := e.store.Tasks().Get(e.ctx, task.Id)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != model.TaskStatusFailed || got.CurrentRetry != 1 || got.ErrorMessage.String != "timeout" {
+		t.Errorf("Update not applied: %+v", got)
+	}
+	if !got.ExecutedAt.Time.Equal(task.ExecutedAt.Time) || !got.NextExecuteAt.Time.Equal(task.NextExecuteAt.Time) {
+		t.Errorf("Times not updated: executed %v, next %v", got.ExecutedAt, got.NextExecuteAt)
+	}
+
+	if got.Version != 3 {
+		t.Errorf("Expected version 3, got %d", got.Version)
+	}
+
+	missing := *task
+	missing.Id = task.Id + 1000
+	if err := e.store.Tasks().Update(e.ctx, &missing); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func testTaskUpdateConflict(t *testing.T, e *env) {
+	task := e.newTask(t, 1, "order-1", nil)
+
+	// 两个调用方读取同一版本，后提交的一方失败
+	stale := *task
+	task.Status = model.TaskStatusRunning
+	if err := e.store.Tasks().Update(e.ctx, task); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	stale.Status = model.TaskStatusCancelled
+	if err := e.store.Tasks().Update(e.ctx, &stale); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("Expected ErrConflict for stale version, got %v", err)
+	}
+
+	got, err := e.store.Tasks().Get(e.ctx, task.Id)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Status != model.TaskStatusRunning || got.Version != task.Version {
+		t.Errorf("Stale update must not be applied, got status %d version %d", got.Status, got.Version)
+	}
+
+	// 终态任务不能再变更状态
+	got.Status = model.TaskStatusSucceeded
+	if err := e.store.Tasks().Update(e.ctx, got); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	version := got.Version
+	got.Status = model.TaskStatusCancelled
+	if err := e.store.Tasks().Update(e.ctx, got); !errors.Is(err, storage.ErrInvalidTransition) {
+		t.Fatalf("Expected ErrInvalidTransition, got %v", err)
+	}
+	if got.Version != version {
+		t.Errorf("Rejected update must not change version, got %d want %d", got.Version, version)
+	}
+}
+
+func testTaskEvents(t *testing.T, e *env) {
+	task := e.newTask(t, 1, "order-1", nil)
+
+	var fired []*model.TaskTransition
+	remove := model.DefaultTaskStateMachine.OnTransition(model.TaskStatusAny, model.TaskStatusAny,
+		func(ctx context.Context, transition *model.TaskTransition) {
+			if transition.TaskId == task.Id {
+				fired = append(fired, transition)
+			}
+		})
+	defer remove()
+
+	ctx := model.WithTaskActor(e.ctx, "worker-1", "picked up")
+	task.Status = model.TaskStatusRunning
+	if err := e.store.Tasks().Update(ctx, task); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	// 状态不变的更新不产生事件
+	task.CurrentRetry = 1
+	if err := e.store.Tasks().Update(e.ctx, task); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	e.clock.Advance(time.Minute)
+	task.Status = model.TaskStatusSucceeded
+	if err := e.store.Tasks().Update(e.ctx, task); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	// 被拒绝的变更不产生事件
+	task.Status = model.TaskStatusCancelled
+	if err := e.store.Tasks().Update(e.ctx, task); !errors.Is(err, storage.ErrInvalidTransition) {
+		t.Fatalf("Expected ErrInvalidTransition, got %v", err)
+	}
+
+	events, err := e.store.Events().ListByTask(e.ctx, task.Id)
+	if err != nil {
+		t.Fatalf("ListByTask failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	first, second := events[0], events[1]
+	if first.FromStatus != model.TaskStatusPending || first.ToStatus != model.TaskStatusRunning ||
+		first.Actor != "worker-1" || first.Reason.String != "picked up" || first.BusinessId != 1 {
+		t.Errorf("Unexpected first event: %+v", first)
+	}
+	if second.FromStatus != model.TaskStatusRunning || second.ToStatus != model.TaskStatusSucceeded ||
+		second.Actor != model.TaskActorSystem || second.Reason.Valid {
+		t.Errorf("Unexpected second event: %+v", second)
+	}
+	if first.CreatedAt.IsZero() || second.CreatedAt.Before(first.CreatedAt) {
+		t.Errorf("Events out of order: %v, %v", first.CreatedAt, second.CreatedAt)
+	}
+
+	if len(fired) != 2 || fired[0].To != model.TaskStatusRunning || fired[1].To != model.TaskStatusSucceeded {
+		t.Errorf("Expected hooks for both transitions, got %+v", fired)
+	}
+
+	if err := e.store.Tasks().Delete(e.ctx, task.Id); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if events, err := e.store.Events().ListByTask(e.ctx, task.Id); err != nil || len(events) != 0 {
+		t.Errorf("Expected events to be deleted with the task, got %d, %v", len(events), err)
+	}
+}
+
+func testTaskDelete(t *testing.T, e *env) {
+	task := e.newTask(t, 1, "order-1", nil)
+	if err := e.store.Executions().Create(e.ctx, &model.TaskExecutions{TaskId: task.Id, ExecutionSequence: 1}); err != nil {
+		t.Fatalf("Failed to create execution: %v", err)
+	}
+
+	if err := e.store.Tasks().Delete(e.ctx, task.Id); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := e.store.Tasks().Get(e.ctx, task.Id); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if err := e.store.Tasks().Delete(e.ctx, task.Id); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound on second delete, got %v", err)
+	}
+
+	executions, err := e.store.Executions().ListByTask(e.ctx, task.Id)
+	if err != nil || len(executions) != 0 {
+		t.Errorf("Expected executions to be deleted with the task, got %d, %v", len(executions), err)
+	}
+
+	// 删除后可以用相同的业务唯一ID重新创建
+	e.newTask(t, 1, "order-1", nil)
+}
+
+func testTaskListAndCount(t *testing.T, e *env) {
+	var created []*model.Tasks
+	for i, spec := range []struct {
+		business int64
+		status   int64
+	}{
+		{1, model.TaskStatusPending},
+		{1, model.TaskStatusSucceeded},
+		{2, model.TaskStatusPending},
+		{1, model.TaskStatusFailed},
+		{1, model.TaskStatusPending},
+	} {
+		spec := spec
+		created = append(created, e.newTask(t, spec.business, fmt.Sprintf("task-%d", i), func(task *model.Tasks) {
+			task.Status = spec.status
+		}))
+	}
+
+	tests := []struct {
+		name   string
+		filter *storage.TaskFilter
+		want   []*model.Tasks
+		count  int64
+	}{
+		{"all", nil, created, 5},
+		{"business", &storage.TaskFilter{BusinessId: 1}, []*model.Tasks{created[0], created[1], created[3], created[4]}, 4},
+		{"status", &storage.TaskFilter{Status: []int64{model.TaskStatusPending}}, []*model.Tasks{created[0], created[2], created[4]}, 3},
+		{"business and statuses", &storage.TaskFilter{BusinessId: 1, Status: []int64{model.TaskStatusSucceeded, model.TaskStatusFailed}},
+			[]*model.Tasks{created[1], created[3]}, 2},
+		{"first page", &storage.TaskFilter{BusinessId: 1, Limit: 2}, []*model.Tasks{created[0], created[1]}, 4},
+		{"next page", &storage.TaskFilter{BusinessId: 1, AfterId: created[1].Id, Limit: 2}, []*model.Tasks{created[3], created[4]}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.store.Tasks().List(e.ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if ids(got) != ids(tt.want) {
+				t.Errorf("Expected tasks %s, got %s", ids(tt.want), ids(got))
+			}
+
+			count, err := e.store.Tasks().Count(e.ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("Count failed: %v", err)
+			}
+			if count != tt.count {
+				t.Errorf("Expected count %d, got %d", tt.count, count)
+			}
+		})
+	}
+}
+
+func testTaskListDue(t *testing.T, e *env) {
+	now := e.clock.Now()
+	low := e.newTask(t, 1, "low", func(task *model.Tasks) { task.Priority = 9 })
+	early := e.newTask(t, 1, "early", func(task *model.Tasks) { task.ScheduledAt = now.Add(-time.Hour) })
+	late := e.newTask(t, 1, "late", nil)
+	urgent := e.newTask(t, 2, "urgent", func(task *model.Tasks) { task.Priority = 1 })
+	// next_execute_at 优先于 scheduled_at
+	retry := e.newTask(t, 1, "retry", func(task *model.Tasks) {
+		task.ScheduledAt = now.Add(-2 * time.Hour)
+		task.NextExecuteAt = sql.NullTime{Time: now.Add(time.Minute), Valid: true}
+	})
+	e.newTask(t, 1, "future", func(task *model.Tasks) { task.ScheduledAt = now.Add(time.Hour) })
+	e.newTask(t, 1, "running", func(task *model.Tasks) { task.Status = model.TaskStatusRunning })
+
+	due, err := e.store.Tasks().ListDue(e.ctx, 0)
+	if err != nil {
+		t.Fatalf("ListDue failed: %v", err)
+	}
+	if want := []*model.Tasks{urgent, early, late, low}; ids(due) != ids(want) {
+		t.Errorf("Expected due tasks %s, got %s", ids(want), ids(due))
+	}
+
+	due, err = e.store.Tasks().ListDue(e.ctx, 2)
+	if err != nil || ids(due) != ids([]*model.Tasks{urgent, early}) {
+		t.Errorf("Expected limit to apply, got %s, %v", ids(due), err)
+	}
+
+	e.clock.Advance(time.Minute)
+	due, err = e.store.Tasks().ListDue(e.ctx, 0)
+	if want := []*model.Tasks{urgent, early, late, retry, low}; err != nil || ids(due) != ids(want) {
+		t.Errorf("Expected due tasks %s after advancing the clock, got %s, %v", ids(want), ids(due), err)
+	}
+}
+
+func testExecutions(t *testing.T, e *env) {
+	task := e.newTask(t, 1, "order-1", nil)
+	other := e.newTask(t, 1, "order-2", nil)
+
+	second := &model.TaskExecutions{
+		TaskId:            task.Id,
+		ExecutionSequence: 2,
+		ExecutionTime:     e.clock.Now().Add(time.Minute),
+		Duration:          sql.NullInt64{Int64: 120, Valid: true},
+		HttpStatus:        sql.NullInt64{Int64: 200, Valid: true},
+		ResponseData:      sql.NullString{String: "ok", Valid: true},
+		ExecutionNode:     sql.NullString{String: "node-1", Valid: true},
+	}
+	first := &model.TaskExecutions{
+		TaskId:            task.Id,
+		ExecutionSequence: 1,
+		ExecutionTime:     e.clock.Now(),
+		HttpStatus:        sql.NullInt64{Int64: 503, Valid: true},
+		ErrorMessage:      sql.NullString{String: "service unavailable", Valid: true},
+		RetryAfter:        sql.NullTime{Time: e.clock.Now().Add(time.Minute), Valid: true},
+	}
+	for _, execution := range []*model.TaskExecutions{second, first, {TaskId: other.Id, ExecutionSequence: 1}} {
+		if err := e.store.Executions().Create(e.ctx, execution); err != nil {
+			t.Fatalf("Create execution failed: %v", err)
+		}
+		if execution.Id <= 0 {
+			t.Fatalf("Expected execution id to be assigned")
+		}
+	}
+
+	executions, err := e.store.Executions().ListByTask(e.ctx, task.Id)
+	if err != nil {
+		t.Fatalf("ListByTask failed: %v", err)
+	}
+	if len(executions) != 2 || executions[0].Id != first.Id || executions[1].Id != second.Id {
+		t.Fatalf("Expected executions ordered by sequence, got %+v", executions)
+	}
+	got := executions[0]
+	if got.HttpStatus.Int64 != 503 || got.ErrorMessage.String != "service unavailable" || got.Duration.Valid ||
+		!got.ExecutionTime.Equal(first.ExecutionTime) || !got.RetryAfter.Time.Equal(first.RetryAfter.Time) {
+		t.Errorf("Unexpected execution: %+v", got)
+	}
+	if executions[1].Duration.Int64 != 120 || executions[1].ExecutionNode.String != "node-1" {
+		t.Errorf("Unexpected execution: %+v", executions[1])
+	}
+
+	err = e.store.Executions().Create(e.ctx, &model.TaskExecutions{TaskId: other.Id + 1000, ExecutionSequence: 1})
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing task, got %v", err)
+	}
+}
+
+func testLocks(t *testing.T, e *env) {
+	task := e.newTask(t, 1, "order-1", nil)
+	locks := e.store.Locks()
+	const key = "task:1"
+
+	acquired, err := locks.Acquire(e.ctx, key, task.Id, "node-a", time.Minute)
+	if err != nil || !acquired {
+		t.Fatalf("Expected node-a to acquire the lock, got %v, %v", acquired, err)
+	}
+	lock, err := locks.Get(e.ctx, key)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if lock.NodeId != "node-a" || lock.TaskId != task.Id || !lock.ExpiresAt.Equal(e.clock.Now().Add(time.Minute)) {
+		t.Errorf("Unexpected lock: %+v", lock)
+	}
+	version := lock.Version
+
+	if acquired, err := locks.Acquire(e.ctx, key, task.Id, "node-b", time.Minute); err != nil || acquired {
+		t.Errorf("Expected node-b to be rejected, got %v, %v", acquired, err)
+	}
+	if acquired, err := locks.Acquire(e.ctx, key, task.Id, "node-a", time.Minute); err != nil || !acquired {
+		t.Errorf("Expected node-a to re-acquire its own lock, got %v, %v", acquired, err)
+	}
+
+	e.clock.Advance(30 * time.Second)
+	if held, err := locks.Renew(e.ctx, key, "node-a", time.Minute); err != nil || !held {
+		t.Errorf("Expected node-a to renew, got %v, %v", held, err)
+	}
+	if held, err := locks.Renew(e.ctx, key, "node-b", time.Minute); err != nil || held {
+		t.Errorf("Expected node-b renew to fail, got %v, %v", held, err)
+	}
+	lock, _ = locks.Get(e.ctx, key)
+	if !lock.ExpiresAt.Equal(e.clock.Now().Add(time.Minute)) {
+		t.Errorf("Expected renewed expiry %v, got %v", e.clock.Now().Add(time.Minute), lock.ExpiresAt)
+	}
+
+	// 过期后其他节点可以接管
+	e.clock.Advance(time.Minute)
+	if acquired, err := locks.Acquire(e.ctx, key, task.Id, "node-b", time.Minute); err != nil || !acquired {
+		t.Fatalf("Expected node-b to take over the expired lock, got %v, %v", acquired, err)
+	}
+	lock, _ = locks.Get(e.ctx, key)
+	if lock.NodeId != "node-b" || lock.Version <= version {
+		t.Errorf("Expected node-b with a newer version than %d, got %+v", version, lock)
+	}
+	if held, err := locks.Renew(e.ctx, key, "node-a", time.Minute); err != nil || held {
+		t.Errorf("Expected node-a to have lost the lock, got %v, %v", held, err)
+	}
+
+	if err := locks.Release(e.ctx, key, "node-a"); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, err := locks.Get(e.ctx, key); err != nil {
+		t.Errorf("Expected release by a non-owner to be ignored, got %v", err)
+	}
+	if err := locks.Release(e.ctx, key, "node-b"); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, err := locks.Get(e.ctx, key); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after release, got %v", err)
+	}
+}
+
+func testBusinesses(t *testing.T, e *env) {
+	businesses := e.store.Businesses()
+	business := &model.BusinessSystems{
+		BusinessCode: "order-service",
+		BusinessName: "订单服务",
+		ApiKey:       "key-1",
+		ApiSecret:    "secret-1",
+		RateLimit:    1000,
+		Status:       1,
+		ContactInfo:  sql.NullString{String: `{"owner":"ops"}`, Valid: true},
+	}
+	if err := businesses.Create(e.ctx, business); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if business.Id <= 0 {
+		t.Fatalf("Expected id to be assigned")
+	}
+
+	got, err := businesses.GetByApiKey(e.ctx, "key-1")
+	if err != nil {
+		t.Fatalf("GetByApiKey failed: %v", err)
+	}
+	if got.Id != business.Id || got.BusinessName != "订单服务" || got.ApiSecret != "secret-1" || got.ContactInfo != business.ContactInfo {
+		t.Errorf("Unexpected business: %+v", got)
+	}
+
+	for _, dup := range []*model.BusinessSystems{
+		{BusinessCode: "order-service", BusinessName: "dup", ApiKey: "key-2", ApiSecret: "s"},
+		{BusinessCode: "user-service", BusinessName: "dup", ApiKey: "key-1", ApiSecret: "s"},
+	} {
+		if err := businesses.Create(e.ctx, dup); !errors.Is(err, storage.ErrDuplicate) {
+			t.Errorf("Expected ErrDuplicate for %+v, got %v", dup, err)
+		}
+	}
+
+	business.Status = 0
+	business.RateLimit = 10
+	if err := businesses.Update(e.ctx, business); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	got, err = businesses.Get(e.ctx, business.Id)
+	if err != nil || got.Status != 0 || got.RateLimit != 10 {
+		t.Errorf("Update not applied: %+v, %v", got, err)
+	}
+
+	if _, err := businesses.Get(e.ctx, business.Id+1000); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := businesses.GetByApiKey(e.ctx, "missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}